@@ -12,6 +12,12 @@ const (
 	kafkaImage = "bitnami/kafka:3.5.1"
 	// natsImage is the image used for NATS.
 	natsImage = "nats:2.10"
+	// rabbitmqImage is the image used for RabbitMQ.
+	rabbitmqImage = "rabbitmq:3.13"
+	// mosquittoImage is the image used for MQTT.
+	mosquittoImage = "eclipse-mosquitto:2"
+	// redisImage is the image used for Redis Streams.
+	redisImage = "redis:7"
 )
 
 func bindBrokers(brokers map[string]*dagger.Service) func(r *dagger.Container) *dagger.Container {
@@ -45,6 +51,15 @@ func brokerServices() map[string]*dagger.Service {
 	brokers["nats-jetstream-tls"] = brokerNATSJetstreamSecure().AsService()
 	brokers["nats-jetstream-tls-basic-auth"] = brokerNATSJetstreamSecureBasicAuth().AsService()
 
+	// RabbitMQ
+	brokers["rabbitmq"] = brokerRabbitMQ().AsService()
+
+	// MQTT
+	brokers["mosquitto"] = brokerMosquitto().AsService()
+
+	// Redis Streams
+	brokers["redis"] = brokerRedis().AsService()
+
 	return brokers
 }
 
@@ -273,3 +288,37 @@ func brokerNATSJetstreamSecureBasicAuth() *dagger.Container {
 			"--pass", "password",
 		})
 }
+
+// brokerRabbitMQ returns a container for the RabbitMQ broker
+// with user: user password: password.
+func brokerRabbitMQ() *dagger.Container {
+	return dag.Container().
+		// Add base image
+		From(rabbitmqImage).
+		// Add credentials, as default guest user can only connect from localhost
+		WithEnvVariable("RABBITMQ_DEFAULT_USER", "user").
+		WithEnvVariable("RABBITMQ_DEFAULT_PASS", "password").
+		// Add exposed ports
+		WithExposedPort(5672)
+}
+
+// brokerMosquitto returns a container for the MQTT broker.
+func brokerMosquitto() *dagger.Container {
+	return dag.Container().
+		// Add base image
+		From(mosquittoImage).
+		// Add exposed ports
+		WithExposedPort(1883).
+		// Start mosquitto without authentication on all interfaces
+		WithoutEntrypoint().
+		WithExec([]string{"mosquitto", "-c", "/mosquitto-no-auth.conf"})
+}
+
+// brokerRedis returns a container for the Redis Streams broker.
+func brokerRedis() *dagger.Container {
+	return dag.Container().
+		// Add base image
+		From(redisImage).
+		// Add exposed ports
+		WithExposedPort(6379)
+}
@@ -131,12 +131,20 @@ func (ci *AsyncapiCodegenCi) Examples(
 	return containers, nil
 }
 
+// testModules are the modules with their own dependencies, only used in tests
+// (see TEST_MODULES in Makefile).
+var testModules = []string{
+	"test/v3/features/outbox",
+	"test/v3/features/tracing",
+	"test/brokers/redis",
+}
+
 // Test run tests from AsyncAPICodegen
 func (ci *AsyncapiCodegenCi) Test(
 	ctx context.Context,
 	srcDir *dagger.Directory,
 ) *dagger.Container {
-	return dag.Container().
+	container := dag.Container().
 		// Add base image
 		From(golangImage).
 		// Add source code as work directory
@@ -145,6 +153,13 @@ func (ci *AsyncapiCodegenCi) Test(
 		With(bindBrokers(ci.cachedBrokers())).
 		// Execute command
 		WithExec([]string{"go", "test", "./..."})
+
+	// Execute command on the test modules (with their own dependencies)
+	for _, module := range testModules {
+		container = container.WithExec([]string{"go", "test", "-C", module, "./..."})
+	}
+
+	return container
 }
 
 // Publish tag on git repository and docker image(s) on Docker Hub
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/lerenn/asyncapi-codegen/pkg/asyncapi/parser"
 	"github.com/lerenn/asyncapi-codegen/pkg/codegen/options"
 	"github.com/spf13/cobra"
 )
@@ -12,16 +13,26 @@ import (
 var (
 	// ErrInvalidGenerate happens when using an invalid generation argument.
 	ErrInvalidGenerate = errors.New("invalid generate argument")
+	// ErrInvalidConfigFile happens when the configuration file is invalid.
+	ErrInvalidConfigFile = errors.New("invalid configuration file")
 )
 
 // Flags contains all command line flags.
 type Flags struct {
+	// ConfigPath is the path of the configuration file containing the
+	// generation targets
+	ConfigPath string
+
 	// InputPaths are the path of the AsyncAPI specification file and its dependencies
 	InputPaths []string
 
 	// OutputPath is the path of the generated code file
 	OutputPath string
 
+	// OutputDir is the directory where each generated part is written in a
+	// separate file, instead of OutputPath
+	OutputDir string
+
 	// PackageName is the package name of the generated code
 	PackageName string
 
@@ -49,14 +60,22 @@ type Flags struct {
 
 	// ForcePointers can be used to force all struct fields to be generated as pointers
 	ForcePointers bool
+
+	// CacheDir is the directory where the remote files referenced in
+	// specifications are cached
+	CacheDir string
 }
 
 // SetToCommand adds the flags to a cobra command.
 func (f *Flags) SetToCommand(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.ConfigPath, "config", "",
+		"Configuration file with generation targets (default \""+DefaultConfigFile+"\" if it exists)")
 	cmd.Flags().StringSliceVarP(
 		&f.InputPaths, "input", "i", []string{"asyncapi.yaml"},
 		"AsyncAPI specification file to use, and its dependencies")
 	cmd.Flags().StringVarP(&f.OutputPath, "output", "o", "asyncapi.gen.go", "Destination file")
+	cmd.Flags().StringVarP(&f.OutputDir, "output-dir", "d", "",
+		"Destination directory, with one file per generated part (overrides --output)")
 	cmd.Flags().StringVarP(&f.PackageName, "package", "p", "asyncapi", "Golang package name")
 	cmd.Flags().StringVarP(&f.Generate, "generate", "g", "user,application,types", "Generation options")
 	cmd.Flags().BoolVarP(&f.DisableFormatting, "disable-formatting", "f", false, "Disables the code generation formatting")
@@ -67,12 +86,29 @@ func (f *Flags) SetToCommand(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&f.IgnoreStringFormat, "ignore-string-format", false,
 		"Ignores the format (date, date-time) on string properties, generating golang string, instead of dates")
 	cmd.Flags().BoolVar(&f.ForcePointers, "force-pointers", false, "Forces all struct fields to be generated as pointers")
+	cmd.Flags().StringVar(&f.CacheDir, "cache-dir", "",
+		"Directory where remote files referenced in specifications are cached (no cache if empty)")
+}
+
+// Fetcher returns the fetcher to get the remote files referenced in specifications.
+//
+//nolint:ireturn,nolintlint
+func (f Flags) Fetcher() parser.Fetcher {
+	if f.CacheDir == "" {
+		return parser.HTTPFetcher{}
+	}
+
+	return parser.CacheFetcher{
+		Fetcher: parser.HTTPFetcher{},
+		Dir:     f.CacheDir,
+	}
 }
 
 // ToCodegenOptions processes command line flags structure to code generation tool options.
 func (f Flags) ToCodegenOptions() (options.Options, error) {
 	opt := options.Options{
 		OutputPath:         f.OutputPath,
+		OutputDir:          f.OutputDir,
 		PackageName:        f.PackageName,
 		DisableFormatting:  f.DisableFormatting,
 		ConvertKeys:        f.ConvertKeys,
@@ -91,6 +127,10 @@ func (f Flags) ToCodegenOptions() (options.Options, error) {
 				opt.Generate.User = true
 			case "types":
 				opt.Generate.Types = true
+			case "mocks":
+				opt.Generate.Mocks = true
+			case "servers":
+				opt.Generate.Servers = true
 			default:
 				return opt, fmt.Errorf("%w: %q", ErrInvalidGenerate, v)
 			}
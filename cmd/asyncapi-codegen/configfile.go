@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// DefaultConfigFile is the configuration file used when it exists in the
+// current directory, if no configuration file, input or output is given.
+const DefaultConfigFile = "asyncapi-codegen.yaml"
+
+// ConfigFile is the content of a configuration file.
+type ConfigFile struct {
+	// Targets are the code generations to execute
+	Targets []Target `json:"targets"`
+}
+
+// Target is a code generation of the configuration file.
+// Unset values take the default value of the corresponding flag.
+type Target struct {
+	// Inputs are the paths of the AsyncAPI specification file and its dependencies
+	Inputs []string `json:"inputs"`
+
+	// Output is the path of the generated code file
+	Output string `json:"output"`
+
+	// OutputDir is the directory where each generated part is written in a
+	// separate file, instead of Output
+	OutputDir string `json:"output-dir"`
+
+	// Package is the package name of the generated code
+	Package string `json:"package"`
+
+	// Generate contains the parts of golang code that should be generated
+	Generate []string `json:"generate"`
+
+	// DisableFormatting states if the formatting should be disabled when
+	// writing the generated code
+	DisableFormatting bool `json:"disable-formatting"`
+
+	// ConvertKeys defines a schema property keys conversion strategy.
+	// Supported values: snake, camel, kebab, none
+	ConvertKeys string `json:"convert-keys"`
+
+	// NamingScheme defines the naming case for generated golang structs
+	// Supported values: camel, none
+	NamingScheme string `json:"naming-scheme"`
+
+	// IgnoreStringFormat states whether the properties' format (date, date-time) should impact the type in types
+	IgnoreStringFormat bool `json:"ignore-string-format"`
+
+	// ForcePointers can be used to force all struct fields to be generated as pointers
+	ForcePointers bool `json:"force-pointers"`
+}
+
+// LoadConfigFile reads a configuration file. Relative paths of the targets
+// are resolved from the directory of the configuration file.
+func LoadConfigFile(path string) (ConfigFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ConfigFile{}, err
+	}
+
+	var cf ConfigFile
+	if err := yaml.Unmarshal(data, &cf); err != nil {
+		return ConfigFile{}, fmt.Errorf("%w: %s: %w", ErrInvalidConfigFile, path, err)
+	}
+
+	if len(cf.Targets) == 0 {
+		return ConfigFile{}, fmt.Errorf("%w: %s: no target", ErrInvalidConfigFile, path)
+	}
+
+	dir := filepath.Dir(path)
+	for i, t := range cf.Targets {
+		if len(t.Inputs) == 0 {
+			return ConfigFile{}, fmt.Errorf("%w: %s: no input on target %d", ErrInvalidConfigFile, path, i)
+		}
+
+		for j, input := range t.Inputs {
+			t.Inputs[j] = resolvePath(dir, input)
+		}
+		if t.Output != "" {
+			cf.Targets[i].Output = resolvePath(dir, t.Output)
+		}
+		if t.OutputDir != "" {
+			cf.Targets[i].OutputDir = resolvePath(dir, t.OutputDir)
+		}
+	}
+
+	return cf, nil
+}
+
+// isOutputChanged returns true if the input or output of the generation has been
+// set on the command line.
+func isOutputChanged(changed func(name string) bool) bool {
+	return changed("input") || changed("output") || changed("output-dir")
+}
+
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// Targets returns the flags of each code generation to execute.
+//
+// If there is a configuration file, there is one per target of the file, with
+// the flags that have been changed on the command line overriding the values
+// of the file. Otherwise, the flags are returned as the only target.
+func (f Flags) Targets(changed func(name string) bool) ([]Flags, error) {
+	path := f.ConfigPath
+	if path == "" {
+		// Only use the default configuration file if the generation is not
+		// set from the command line
+		if isOutputChanged(changed) {
+			return []Flags{f}, nil
+		}
+
+		if _, err := os.Stat(DefaultConfigFile); errors.Is(err, os.ErrNotExist) {
+			return []Flags{f}, nil
+		}
+		path = DefaultConfigFile
+	}
+
+	cf, err := LoadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Input and output can't be the same for several targets
+	if len(cf.Targets) > 1 && isOutputChanged(changed) {
+		return nil, fmt.Errorf("%w: %s: input and output flags can't override several targets",
+			ErrInvalidConfigFile, path)
+	}
+
+	targets := make([]Flags, 0, len(cf.Targets))
+	for _, t := range cf.Targets {
+		targets = append(targets, f.fromTarget(t, changed))
+	}
+
+	return targets, nil
+}
+
+// fromTarget returns the flags of a target, starting from the default values
+// of the flags, then setting the target values and finally the changed flags.
+func (f Flags) fromTarget(t Target, changed func(name string) bool) Flags {
+	tf := f
+
+	for _, v := range []struct {
+		flag   string
+		set    bool
+		assign func()
+	}{
+		{flag: "input", set: len(t.Inputs) > 0, assign: func() { tf.InputPaths = t.Inputs }},
+		{flag: "output", set: t.Output != "", assign: func() { tf.OutputPath = t.Output }},
+		{flag: "output-dir", set: t.OutputDir != "", assign: func() { tf.OutputDir = t.OutputDir }},
+		{flag: "package", set: t.Package != "", assign: func() { tf.PackageName = t.Package }},
+		{flag: "generate", set: len(t.Generate) > 0, assign: func() { tf.Generate = strings.Join(t.Generate, ",") }},
+		{flag: "disable-formatting", set: t.DisableFormatting, assign: func() { tf.DisableFormatting = true }},
+		{flag: "convert-keys", set: t.ConvertKeys != "", assign: func() { tf.ConvertKeys = t.ConvertKeys }},
+		{flag: "naming-scheme", set: t.NamingScheme != "", assign: func() { tf.NamingScheme = t.NamingScheme }},
+		{flag: "ignore-string-format", set: t.IgnoreStringFormat, assign: func() { tf.IgnoreStringFormat = true }},
+		{flag: "force-pointers", set: t.ForcePointers, assign: func() { tf.ForcePointers = true }},
+	} {
+		// Command line flags override the configuration file
+		if v.set && !changed(v.flag) {
+			v.assign()
+		}
+	}
+
+	return tf
+}
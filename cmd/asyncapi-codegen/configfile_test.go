@@ -0,0 +1,198 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestConfigFileSuite(t *testing.T) {
+	suite.Run(t, new(ConfigFileSuite))
+}
+
+type ConfigFileSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (suite *ConfigFileSuite) SetupTest() {
+	suite.dir = suite.T().TempDir()
+}
+
+func (suite *ConfigFileSuite) writeConfig(content string) string {
+	path := filepath.Join(suite.dir, DefaultConfigFile)
+	suite.Require().NoError(os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// parse returns the flags from the command line arguments, as done by the command.
+func (suite *ConfigFileSuite) parse(args ...string) (Flags, func(string) bool) {
+	var f Flags
+	cmd := &cobra.Command{}
+	f.SetToCommand(cmd)
+	suite.Require().NoError(cmd.ParseFlags(args))
+	return f, cmd.Flags().Changed
+}
+
+func (suite *ConfigFileSuite) TestTargets() {
+	path := suite.writeConfig(`
+targets:
+  - inputs: [./specs/users.yaml, ./specs/common.yaml]
+    output: ./users/asyncapi.gen.go
+    package: users
+    generate: [application, types]
+    convert-keys: snake
+    force-pointers: true
+  - inputs: [/specs/orders.yaml]
+    output: ./orders/asyncapi.gen.go
+    package: orders
+    naming-scheme: camel
+`)
+
+	f, changed := suite.parse("--config", path)
+	targets, err := f.Targets(changed)
+	suite.Require().NoError(err)
+	suite.Require().Len(targets, 2)
+
+	suite.Require().Equal([]string{
+		filepath.Join(suite.dir, "specs/users.yaml"),
+		filepath.Join(suite.dir, "specs/common.yaml"),
+	}, targets[0].InputPaths)
+	suite.Require().Equal(filepath.Join(suite.dir, "users/asyncapi.gen.go"), targets[0].OutputPath)
+	suite.Require().Equal("users", targets[0].PackageName)
+	suite.Require().Equal("application,types", targets[0].Generate)
+	suite.Require().Equal("snake", targets[0].ConvertKeys)
+	suite.Require().Equal("none", targets[0].NamingScheme)
+	suite.Require().True(targets[0].ForcePointers)
+
+	// Unset values should have flags default values
+	suite.Require().Equal([]string{"/specs/orders.yaml"}, targets[1].InputPaths)
+	suite.Require().Equal("orders", targets[1].PackageName)
+	suite.Require().Equal("user,application,types", targets[1].Generate)
+	suite.Require().Equal("none", targets[1].ConvertKeys)
+	suite.Require().Equal("camel", targets[1].NamingScheme)
+	suite.Require().False(targets[1].ForcePointers)
+}
+
+func (suite *ConfigFileSuite) TestFlagsOverrideTargets() {
+	path := suite.writeConfig(`
+targets:
+  - inputs: [./a.yaml]
+    package: a
+    generate: [types]
+    convert-keys: snake
+  - inputs: [./b.yaml]
+    package: b
+`)
+
+	f, changed := suite.parse("--config", path, "-g", "types,mocks", "-c", "camel")
+	targets, err := f.Targets(changed)
+	suite.Require().NoError(err)
+	suite.Require().Len(targets, 2)
+
+	for _, t := range targets {
+		suite.Require().Equal("types,mocks", t.Generate)
+		suite.Require().Equal("camel", t.ConvertKeys)
+	}
+	suite.Require().Equal("a", targets[0].PackageName)
+	suite.Require().Equal("b", targets[1].PackageName)
+
+	// Input or output can't override several targets
+	f, changed = suite.parse("--config", path, "-o", "out.gen.go")
+	_, err = f.Targets(changed)
+	suite.Require().ErrorIs(err, ErrInvalidConfigFile)
+}
+
+func (suite *ConfigFileSuite) TestDefaultConfigFile() {
+	suite.writeConfig(`
+targets:
+  - inputs: [./a.yaml]
+    package: a
+`)
+
+	wd, err := os.Getwd()
+	suite.Require().NoError(err)
+	suite.Require().NoError(os.Chdir(suite.dir))
+	defer func() { suite.Require().NoError(os.Chdir(wd)) }()
+
+	// Default configuration file should be used
+	f, changed := suite.parse()
+	targets, err := f.Targets(changed)
+	suite.Require().NoError(err)
+	suite.Require().Len(targets, 1)
+	suite.Require().Equal("a", targets[0].PackageName)
+
+	// Default configuration file should be ignored with an input on command line
+	f, changed = suite.parse("-i", "b.yaml")
+	targets, err = f.Targets(changed)
+	suite.Require().NoError(err)
+	suite.Require().Len(targets, 1)
+	suite.Require().Equal([]string{"b.yaml"}, targets[0].InputPaths)
+	suite.Require().Equal("asyncapi", targets[0].PackageName)
+}
+
+func (suite *ConfigFileSuite) TestInvalidConfigFile() {
+	for _, content := range []string{
+		"targets: []",
+		"targets:\n  - package: a",
+		"targets: {",
+	} {
+		f, changed := suite.parse("--config", suite.writeConfig(content))
+		_, err := f.Targets(changed)
+		suite.Require().ErrorIs(err, ErrInvalidConfigFile, content)
+	}
+}
+
+func (suite *ConfigFileSuite) TestTargetsOptionsDontLeak() {
+	spec := filepath.Join(suite.dir, "asyncapi.yaml")
+	suite.Require().NoError(os.WriteFile(spec, []byte(`
+asyncapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+components:
+  schemas:
+    Object:
+      type: object
+      required: [ReqField]
+      properties:
+        ReqField:
+          type: string
+        DateField:
+          type: string
+          format: date-time
+`), 0o600))
+
+	f, changed := suite.parse("--config", suite.writeConfig(`
+targets:
+  - inputs: [`+spec+`]
+    output: `+filepath.Join(suite.dir, "a.gen.go")+`
+    package: a
+    generate: [types]
+    force-pointers: true
+    ignore-string-format: true
+  - inputs: [`+spec+`]
+    output: `+filepath.Join(suite.dir, "b.gen.go")+`
+    package: b
+    generate: [types]
+`))
+	targets, err := f.Targets(changed)
+	suite.Require().NoError(err)
+	for _, t := range targets {
+		suite.Require().NoError(generate(t))
+	}
+
+	a, err := os.ReadFile(filepath.Join(suite.dir, "a.gen.go"))
+	suite.Require().NoError(err)
+	suite.Require().Regexp(`ReqField +\*string`, string(a))
+	suite.Require().Regexp(`DateField +\*string`, string(a))
+
+	// Options of the first target should not be kept for the second one
+	b, err := os.ReadFile(filepath.Join(suite.dir, "b.gen.go"))
+	suite.Require().NoError(err)
+	suite.Require().Regexp(`ReqField +string`, string(b))
+	suite.Require().Regexp(`DateField +\*time.Time`, string(b))
+}
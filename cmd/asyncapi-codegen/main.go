@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -19,29 +20,48 @@ Just plug your application to your favorite message broker!
 More info on README: https://github.com/lerenn/asyncapi-codegen
 `,
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
-		cg, err := codegen.FromFile(flags.InputPaths[0], flags.InputPaths[1:]...)
+		targets, err := flags.Targets(cmd.Flags().Changed)
 		if err != nil {
 			return err
 		}
 
-		opt, err := flags.ToCodegenOptions()
-		if err != nil {
-			return err
-		}
-
-		if err := cg.Generate(opt); err != nil {
-			return err
+		for _, t := range targets {
+			if err := generate(t); err != nil {
+				if len(targets) > 1 {
+					return fmt.Errorf("generating %q: %w", t.OutputPath, err)
+				}
+				return err
+			}
 		}
 
 		return nil
 	},
 }
 
+func generate(f Flags) error {
+	cg, err := codegen.FromFileWithFetcher(f.Fetcher(), f.InputPaths[0], f.InputPaths[1:]...)
+	if err != nil {
+		return err
+	}
+
+	opt, err := f.ToCodegenOptions()
+	if err != nil {
+		return err
+	}
+
+	return cg.Generate(opt)
+}
+
 func main() {
 	flags.SetToCommand(cmd)
+	validateFlags.SetToCommand(validateCmd)
+	cmd.AddCommand(validateCmd)
 
 	if err := cmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
-		os.Exit(1)
+		// The validation report has already been written
+		if !errors.Is(err, ErrInvalidSpecification) {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		}
+		os.Exit(exitCode(err))
 	}
 }
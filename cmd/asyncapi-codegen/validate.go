@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/asyncapi/parser"
+	"github.com/lerenn/asyncapi-codegen/pkg/codegen/linter"
+	"github.com/lerenn/asyncapi-codegen/pkg/codegen/options"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// ExitCodeInvalidSpecification is the exit code when the specification
+	// has errors (or warnings, in strict mode).
+	ExitCodeInvalidSpecification = 1
+	// ExitCodeInvalidUsage is the exit code when the validation cannot be
+	// executed because of invalid arguments.
+	ExitCodeInvalidUsage = 2
+)
+
+var (
+	// ErrInvalidSpecification happens when the validated specification has
+	// errors (or warnings, in strict mode).
+	ErrInvalidSpecification = errors.New("invalid specification")
+	// ErrInvalidUsage happens when the validate command arguments are invalid.
+	ErrInvalidUsage = errors.New("invalid usage")
+)
+
+// ValidateFlags contains the command line flags of the validate command.
+type ValidateFlags struct {
+	// InputPaths are the path of the AsyncAPI specification file and its dependencies
+	InputPaths []string
+
+	// Format is the output format of the report: human or json
+	Format string
+
+	// Strict states if warnings should make the validation fail
+	Strict bool
+
+	// NamingScheme defines the naming case for generated golang structs,
+	// used to detect Go identifiers collisions
+	NamingScheme string
+
+	// CacheDir is the directory where the remote files referenced in
+	// specifications are cached
+	CacheDir string
+}
+
+// SetToCommand adds the flags to a cobra command.
+func (f *ValidateFlags) SetToCommand(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVarP(
+		&f.InputPaths, "input", "i", []string{"asyncapi.yaml"},
+		"AsyncAPI specification file to validate, and its dependencies")
+	cmd.Flags().StringVar(&f.Format, "format", "human", "Output format.\nSupported values: human, json.")
+	cmd.Flags().BoolVar(&f.Strict, "strict", false, "Fails on warnings too")
+	cmd.Flags().StringVarP(&f.NamingScheme, "naming-scheme", "n", "none",
+		"Naming scheme for generated golang elements.\nSupported values: camel, none.")
+	cmd.Flags().StringVar(&f.CacheDir, "cache-dir", "",
+		"Directory where remote files referenced in specifications are cached (no cache if empty)")
+
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return fmt.Errorf("%w: %w", ErrInvalidUsage, err)
+	})
+}
+
+var validateFlags ValidateFlags
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validates an AsyncAPI specification before generating code from it.",
+	Long: `Validates an AsyncAPI specification before generating code from it.
+
+It reports structural errors, unresolved references, Go identifiers collisions,
+unsupported schema constructs, operations without message and invalid
+correlation IDs.
+
+Exit codes:
+  0: the specification is valid (it can still have warnings, unless --strict)
+  1: the specification has errors (or warnings, with --strict)
+  2: the validation cannot be executed (i.e. invalid flags)
+`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return validate(cmd.OutOrStdout(), validateFlags)
+	},
+}
+
+// validationResult is the JSON output of the validate command.
+type validationResult struct {
+	Input    string         `json:"input"`
+	Valid    bool           `json:"valid"`
+	Errors   int            `json:"errors"`
+	Warnings int            `json:"warnings"`
+	Issues   []linter.Issue `json:"issues"`
+}
+
+func validate(w io.Writer, f ValidateFlags) error {
+	if f.Format != "human" && f.Format != "json" {
+		return fmt.Errorf("%w: unknown format %q", ErrInvalidUsage, f.Format)
+	}
+	if len(f.InputPaths) == 0 {
+		return fmt.Errorf("%w: no input", ErrInvalidUsage)
+	}
+
+	var fetcher parser.Fetcher = parser.HTTPFetcher{}
+	if f.CacheDir != "" {
+		fetcher = parser.CacheFetcher{Fetcher: fetcher, Dir: f.CacheDir}
+	}
+
+	report := linter.LintFile(fetcher, options.Options{NamingScheme: f.NamingScheme},
+		f.InputPaths[0], f.InputPaths[1:]...)
+
+	res := validationResult{
+		Input:    f.InputPaths[0],
+		Errors:   report.Count(linter.SeverityError),
+		Warnings: report.Count(linter.SeverityWarning),
+		Issues:   report.Issues,
+	}
+	res.Valid = res.Errors == 0 && (!f.Strict || res.Warnings == 0)
+	if res.Issues == nil {
+		res.Issues = []linter.Issue{}
+	}
+
+	if err := writeValidationResult(w, f.Format, res); err != nil {
+		return err
+	}
+
+	if !res.Valid {
+		return ErrInvalidSpecification
+	}
+	return nil
+}
+
+func writeValidationResult(w io.Writer, format string, res validationResult) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+
+	for _, i := range res.Issues {
+		if _, err := fmt.Fprintf(w, "%s: %s\n", res.Input, i); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%s: %d error(s), %d warning(s)\n", res.Input, res.Errors, res.Warnings)
+	return err
+}
+
+// exitCode returns the exit code corresponding to an error of a command.
+func exitCode(err error) int {
+	if errors.Is(err, ErrInvalidUsage) {
+		return ExitCodeInvalidUsage
+	}
+	return ExitCodeInvalidSpecification
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/codegen/linter"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestValidateSuite(t *testing.T) {
+	suite.Run(t, new(ValidateSuite))
+}
+
+type ValidateSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (suite *ValidateSuite) SetupTest() {
+	suite.dir = suite.T().TempDir()
+}
+
+func (suite *ValidateSuite) writeSpec(schemaType string) string {
+	path := filepath.Join(suite.dir, "asyncapi.yaml")
+	suite.Require().NoError(os.WriteFile(path, []byte(`
+asyncapi: 3.0.0
+info:
+  title: Sample App
+  version: 1.2.3
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: `+schemaType+`
+        any: {}
+`), 0o600))
+	return path
+}
+
+func (suite *ValidateSuite) TestValid() {
+	var out bytes.Buffer
+	err := validate(&out, ValidateFlags{InputPaths: []string{suite.writeSpec("string")}, Format: "human"})
+	suite.Require().NoError(err)
+	suite.Require().Contains(out.String(), "0 error(s), 1 warning(s)")
+}
+
+func (suite *ValidateSuite) TestStrict() {
+	var out bytes.Buffer
+	err := validate(&out, ValidateFlags{InputPaths: []string{suite.writeSpec("string")}, Format: "human", Strict: true})
+	suite.Require().ErrorIs(err, ErrInvalidSpecification)
+	suite.Require().Equal(ExitCodeInvalidSpecification, exitCode(err))
+}
+
+func (suite *ValidateSuite) TestInvalidJSON() {
+	var out bytes.Buffer
+	err := validate(&out, ValidateFlags{InputPaths: []string{suite.writeSpec("unknown")}, Format: "json"})
+	suite.Require().ErrorIs(err, ErrInvalidSpecification)
+
+	var res validationResult
+	suite.Require().NoError(json.Unmarshal(out.Bytes(), &res))
+	suite.Require().False(res.Valid)
+	suite.Require().Equal(1, res.Errors)
+	suite.Require().Equal(1, res.Warnings)
+	suite.Require().Equal(linter.RuleUnsupportedSchema, res.Issues[0].Rule)
+}
+
+func (suite *ValidateSuite) TestInvalidFormat() {
+	err := validate(&bytes.Buffer{}, ValidateFlags{InputPaths: []string{suite.writeSpec("string")}, Format: "xml"})
+	suite.Require().ErrorIs(err, ErrInvalidUsage)
+	suite.Require().Equal(ExitCodeInvalidUsage, exitCode(err))
+}
@@ -29,6 +29,7 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	defer broker.Close()
 
 	// Create a new user controller
 	ctrl, err := NewUserController(
@@ -52,6 +52,7 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	defer broker.Close()
 
 	// Create a new app controller
 	ctrl, err := NewAppController(
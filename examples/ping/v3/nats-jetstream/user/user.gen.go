@@ -1,12 +1,12 @@
 // Package "main" provides primitives to interact with the AsyncAPI specification.
 //
-// Code generated by github.com/lerenn/asyncapi-codegen version (devel) DO NOT EDIT.
+// Code generated by github.com/lerenn/asyncapi-codegen version v0.45.1 DO NOT EDIT.
 package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"sync"
 
 	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
 
@@ -27,38 +27,35 @@ func NewUserController(bc extensions.BrokerController, options ...ControllerOpti
 	}
 
 	// Create default controller
-	controller := controller{
+	c := &UserController{controller: controller{
 		broker:        bc,
-		subscriptions: make(map[string]extensions.BrokerChannelSubscription),
+		subscriptions: make(map[string]subscription),
 		logger:        extensions.DummyLogger{},
 		middlewares:   make([]extensions.Middleware, 0),
 		errorHandler:  extensions.DefaultErrorHandler(),
-	}
+		concurrency:   1,
+	}}
 
 	// Apply options
 	for _, option := range options {
-		option(&controller)
+		option(&c.controller)
 	}
 
-	return &UserController{controller: controller}, nil
+	return c, nil
 }
 
-func (c UserController) wrapMiddlewares(
+func (c *UserController) wrapMiddlewares(
 	middlewares []extensions.Middleware,
 	callback extensions.NextMiddleware,
 ) func(ctx context.Context, msg *extensions.BrokerMessage) error {
-	var called bool
-
 	// If there is no more middleware
 	if len(middlewares) == 0 {
 		return func(ctx context.Context, msg *extensions.BrokerMessage) error {
-			// Call the callback if it exists and it has not been called already
-			if callback != nil && !called {
-				called = true
+			// Call the callback if it exists
+			if callback != nil {
 				return callback(ctx)
 			}
 
-			// Nil can be returned, as the callback has already been called
 			return nil
 		}
 	}
@@ -66,33 +63,31 @@ func (c UserController) wrapMiddlewares(
 	// Get the next function to call from next middlewares or callback
 	next := c.wrapMiddlewares(middlewares[1:], callback)
 
-	// Wrap middleware into a check function that will call execute the middleware
-	// and call the next wrapped middleware if the returned function has not been
-	// called already
+	// Wrap middleware into a function that will execute the middleware and call
+	// the next wrapped middleware if the middleware has not called it already
 	return func(ctx context.Context, msg *extensions.BrokerMessage) error {
-		// Call the middleware and the following if it has not been done already
-		if !called {
-			// Create the next call with the context and the message
-			nextWithArgs := func(ctx context.Context) error {
-				return next(ctx, msg)
-			}
-
-			// Call the middleware and register it as already called
+		// Create the next call with the context and the message. It can be
+		// called several times by the middleware (i.e. to retry the processing).
+		var called bool
+		nextWithArgs := func(ctx context.Context) error {
 			called = true
-			if err := middlewares[0](ctx, msg, nextWithArgs); err != nil {
-				return err
-			}
+			return next(ctx, msg)
+		}
 
-			// If next has already been called in middleware, it should not be executed again
-			return nextWithArgs(ctx)
+		// Call the middleware
+		if err := middlewares[0](ctx, msg, nextWithArgs); err != nil {
+			return err
 		}
 
-		// Nil can be returned, as the next middleware has already been called
-		return nil
+		// If next has already been called in middleware, it should not be executed again
+		if called {
+			return nil
+		}
+		return nextWithArgs(ctx)
 	}
 }
 
-func (c UserController) executeMiddlewares(ctx context.Context, msg *extensions.BrokerMessage, callback extensions.NextMiddleware) error {
+func (c *UserController) executeMiddlewares(ctx context.Context, msg *extensions.BrokerMessage, callback extensions.NextMiddleware) error {
 	// Wrap middleware to have 'next' function when calling them
 	wrapped := c.wrapMiddlewares(c.middlewares, callback)
 
@@ -111,11 +106,22 @@ func (c *UserController) Close(ctx context.Context) {
 	// Unsubscribing remaining channels
 }
 
+// Shutdown will gracefully stop the controller: it stops the intake of new
+// messages, and waits for the messages being processed to be acknowledged
+// before stopping the subscriptions, until the context is done.
+//
+// Messages received after the intake has been stopped are rejected (Nak) in
+// order to be processed again later, possibly by another instance. An error
+// wrapping extensions.ErrSubscriptionNotDrained is returned for each
+// subscription that still has messages being processed when the context is
+// done, and the context given to these messages is canceled.
+func (c *UserController) Shutdown(ctx context.Context) error {
+	return nil
+}
+
 // SendToPingRequestOperation will send a Ping message on Ping channel.
 //
 // NOTE: this won't wait for reply, use the normal version to get the reply or do the catching reply manually.
-// NOTE: for now, this only support the first message from AsyncAPI list.
-// If you need support for other messages, please raise an issue.
 func (c *UserController) SendToPingRequestOperation(
 	ctx context.Context,
 	msg PingMessage,
@@ -133,6 +139,13 @@ func (c *UserController) SendToPingRequestOperation(
 	ctx = context.WithValue(ctx, extensions.ContextKeyIsDirection, "publication")
 	ctx = context.WithValue(ctx, extensions.ContextKeyIsCorrelationID, msg.CorrelationID())
 
+	// Validate message if required
+	if c.validation {
+		if err := msg.Validate(); err != nil {
+			return err
+		}
+	}
+
 	// Convert to BrokerMessage
 	brokerMsg, err := msg.toBrokerMessage()
 	if err != nil {
@@ -283,7 +296,9 @@ type controller struct {
 	// broker is the broker controller that will be used to communicate
 	broker extensions.BrokerController
 	// subscriptions is a map of all subscriptions
-	subscriptions map[string]extensions.BrokerChannelSubscription
+	subscriptions map[string]subscription
+	// subscriptionsMutex protects the subscriptions map
+	subscriptionsMutex sync.Mutex
 	// logger is the logger that will be used² to log operations on controller
 	logger extensions.Logger
 	// middlewares are the middlewares that will be executed when sending or
@@ -291,6 +306,25 @@ type controller struct {
 	middlewares []extensions.Middleware
 	// handler to handle errors from consumers and middlewares
 	errorHandler extensions.ErrorHandler
+	// validation is set if messages should be validated against the constraints
+	// of the AsyncAPI specification
+	validation bool
+	// concurrency is the maximum number of messages processed in parallel
+	// for each subscription
+	concurrency int
+	// concurrencyKey is the key extractor used to process messages with the
+	// same key in order when processing them in parallel
+	concurrencyKey extensions.KeyExtractor
+	// autoUnsubscribe is set if subscriptions should be stopped when the
+	// context given on subscription is done
+	autoUnsubscribe bool
+}
+
+// subscription is a subscription to a broker channel, with the tracker of the
+// messages being processed by its listener
+type subscription struct {
+	broker   extensions.BrokerChannelSubscription
+	listener *extensions.ListenerTracker
 }
 
 // ControllerOption is the type of the options that can be passed
@@ -318,6 +352,62 @@ func WithErrorHandler(handler extensions.ErrorHandler) ControllerOption {
 	}
 }
 
+// WithValidation enables the validation of messages against the constraints of
+// the AsyncAPI specification: invalid messages are rejected when sent, and
+// given to the error handler instead of the subscriber when received.
+func WithValidation() ControllerOption {
+	return func(controller *controller) {
+		controller.validation = true
+	}
+}
+
+// WithConcurrency sets the maximum number of messages processed in parallel for
+// each subscription (default is 1). If a key extractor is given (i.e.
+// extensions.KeyFromHeader), messages with the same key are processed
+// sequentially, in the order they were received.
+func WithConcurrency(n int, key ...extensions.KeyExtractor) ControllerOption {
+	return func(controller *controller) {
+		controller.concurrency = n
+		if len(key) > 0 {
+			controller.concurrencyKey = key[0]
+		}
+	}
+}
+
+// WithAutoUnsubscribe stops a subscription when the context given on
+// subscription is done, as if the corresponding unsubscribe function was called.
+func WithAutoUnsubscribe() ControllerOption {
+	return func(controller *controller) {
+		controller.autoUnsubscribe = true
+	}
+}
+
+// unsubscribeWhenDone stops the subscription on the channel when the context is
+// done, unless its listener has been stopped before (i.e. unsubscribed).
+func (c *controller) unsubscribeWhenDone(ctx context.Context, addr string, listener *extensions.ListenerTracker) {
+	// Wait for the context to be done or the listener to be stopped
+	if err := listener.WaitStopped(ctx); err == nil {
+		return
+	}
+
+	// Remove the subscription if it is still the same
+	c.subscriptionsMutex.Lock()
+	sub, exists := c.subscriptions[addr]
+	if !exists || sub.listener != listener {
+		c.subscriptionsMutex.Unlock()
+		return
+	}
+	delete(c.subscriptions, addr)
+	c.subscriptionsMutex.Unlock()
+
+	// Stop the subscription, waiting for the broker clean up even if the
+	// context is done, and cancel the context of the messages being processed
+	sub.listener.CancelProcessing()
+	sub.broker.Cancel(context.WithoutCancel(ctx))
+
+	c.logger.Info(ctx, "Unsubscribed from channel as context is done")
+}
+
 type MessageWithCorrelationID interface {
 	CorrelationID() string
 	SetCorrelationID(id string)
@@ -348,11 +438,26 @@ type HeadersFromPingMessage struct {
 	CorrelationId *string `json:"correlationId,omitempty"`
 }
 
+// Validate checks that HeadersFromPingMessage respects the constraints of the AsyncAPI specification.
+func (s HeadersFromPingMessage) Validate() error {
+	return nil
+}
+
 // PingMessagePayload is a schema from the AsyncAPI specification required in messages
 type PingMessagePayload struct {
 	Event *string `json:"event,omitempty" validate:"omitempty,eq=ping"`
 }
 
+// Validate checks that PingMessagePayload respects the constraints of the AsyncAPI specification.
+func (s PingMessagePayload) Validate() error {
+	if s.Event != nil {
+		if err := extensions.ValidateConst("event", (*s.Event), "ping"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // PingMessage is the message expected for 'PingMessage' channel.
 type PingMessage struct {
 	// Headers will be used to fill the message headers
@@ -376,8 +481,14 @@ func NewPingMessage() PingMessage {
 func brokerMessageToPingMessage(bMsg extensions.BrokerMessage) (PingMessage, error) {
 	var msg PingMessage
 
+	// Get codec from the message content type, or from the one in specification
+	codec, err := extensions.CodecForBrokerMessage(bMsg, "application/json")
+	if err != nil {
+		return msg, err
+	}
+
 	// Unmarshal payload to expected message payload format
-	err := json.Unmarshal(bMsg.Payload, &msg.Payload)
+	err = codec.Unmarshal(bMsg.Payload, &msg.Payload)
 	if err != nil {
 		return msg, err
 	}
@@ -393,17 +504,17 @@ func brokerMessageToPingMessage(bMsg extensions.BrokerMessage) (PingMessage, err
 		}
 	}
 
-	// TODO: run checks on msg type
-
 	return msg, nil
 }
 
 // toBrokerMessage will generate a generic broker message from PingMessage data
 func (msg PingMessage) toBrokerMessage() (extensions.BrokerMessage, error) {
-	// TODO: implement checks on message
 
-	// Marshal payload to JSON
-	payload, err := json.Marshal(msg.Payload)
+	// Get codec from the content type in specification
+	codec := extensions.SpecificationCodec("application/json")
+
+	// Marshal payload with the codec
+	payload, err := codec.Marshal(msg.Payload)
 	if err != nil {
 		return extensions.BrokerMessage{}, err
 	}
@@ -416,12 +527,26 @@ func (msg PingMessage) toBrokerMessage() (extensions.BrokerMessage, error) {
 		headers["correlationId"] = []byte(*msg.Headers.CorrelationId)
 	}
 
+	// Set content type, so the receiver can decode the payload
+	headers[extensions.ContentTypeHeader] = []byte("application/json")
+
 	return extensions.BrokerMessage{
 		Headers: headers,
 		Payload: payload,
 	}, nil
 }
 
+// Validate checks that PingMessage respects the constraints of the AsyncAPI specification.
+func (msg PingMessage) Validate() error {
+	if err := msg.Headers.Validate(); err != nil {
+		return extensions.WithValidationField("headers", err)
+	}
+	if err := msg.Payload.Validate(); err != nil {
+		return extensions.WithValidationField("payload", err)
+	}
+	return nil
+}
+
 // CorrelationID will give the correlation ID of the message, based on AsyncAPI spec
 func (msg PingMessage) CorrelationID() string {
 	if msg.Headers.CorrelationId != nil {
@@ -450,11 +575,26 @@ type HeadersFromPongMessage struct {
 	CorrelationId *string `json:"correlationId,omitempty"`
 }
 
+// Validate checks that HeadersFromPongMessage respects the constraints of the AsyncAPI specification.
+func (s HeadersFromPongMessage) Validate() error {
+	return nil
+}
+
 // PongMessagePayload is a schema from the AsyncAPI specification required in messages
 type PongMessagePayload struct {
 	Event *string `json:"event,omitempty" validate:"omitempty,eq=pong"`
 }
 
+// Validate checks that PongMessagePayload respects the constraints of the AsyncAPI specification.
+func (s PongMessagePayload) Validate() error {
+	if s.Event != nil {
+		if err := extensions.ValidateConst("event", (*s.Event), "pong"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // PongMessage is the message expected for 'PongMessage' channel.
 type PongMessage struct {
 	// Headers will be used to fill the message headers
@@ -478,8 +618,14 @@ func NewPongMessage() PongMessage {
 func brokerMessageToPongMessage(bMsg extensions.BrokerMessage) (PongMessage, error) {
 	var msg PongMessage
 
+	// Get codec from the message content type, or from the one in specification
+	codec, err := extensions.CodecForBrokerMessage(bMsg, "application/json")
+	if err != nil {
+		return msg, err
+	}
+
 	// Unmarshal payload to expected message payload format
-	err := json.Unmarshal(bMsg.Payload, &msg.Payload)
+	err = codec.Unmarshal(bMsg.Payload, &msg.Payload)
 	if err != nil {
 		return msg, err
 	}
@@ -495,17 +641,17 @@ func brokerMessageToPongMessage(bMsg extensions.BrokerMessage) (PongMessage, err
 		}
 	}
 
-	// TODO: run checks on msg type
-
 	return msg, nil
 }
 
 // toBrokerMessage will generate a generic broker message from PongMessage data
 func (msg PongMessage) toBrokerMessage() (extensions.BrokerMessage, error) {
-	// TODO: implement checks on message
 
-	// Marshal payload to JSON
-	payload, err := json.Marshal(msg.Payload)
+	// Get codec from the content type in specification
+	codec := extensions.SpecificationCodec("application/json")
+
+	// Marshal payload with the codec
+	payload, err := codec.Marshal(msg.Payload)
 	if err != nil {
 		return extensions.BrokerMessage{}, err
 	}
@@ -518,12 +664,26 @@ func (msg PongMessage) toBrokerMessage() (extensions.BrokerMessage, error) {
 		headers["correlationId"] = []byte(*msg.Headers.CorrelationId)
 	}
 
+	// Set content type, so the receiver can decode the payload
+	headers[extensions.ContentTypeHeader] = []byte("application/json")
+
 	return extensions.BrokerMessage{
 		Headers: headers,
 		Payload: payload,
 	}, nil
 }
 
+// Validate checks that PongMessage respects the constraints of the AsyncAPI specification.
+func (msg PongMessage) Validate() error {
+	if err := msg.Headers.Validate(); err != nil {
+		return extensions.WithValidationField("headers", err)
+	}
+	if err := msg.Payload.Validate(); err != nil {
+		return extensions.WithValidationField("payload", err)
+	}
+	return nil
+}
+
 // CorrelationID will give the correlation ID of the message, based on AsyncAPI spec
 func (msg PongMessage) CorrelationID() string {
 	if msg.Headers.CorrelationId != nil {
@@ -1,12 +1,13 @@
 // Package "main" provides primitives to interact with the AsyncAPI specification.
 //
-// Code generated by github.com/lerenn/asyncapi-codegen version (devel) DO NOT EDIT.
+// Code generated by github.com/lerenn/asyncapi-codegen version v0.45.1 DO NOT EDIT.
 package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
 
@@ -33,38 +34,35 @@ func NewAppController(bc extensions.BrokerController, options ...ControllerOptio
 	}
 
 	// Create default controller
-	controller := controller{
+	c := &AppController{controller: controller{
 		broker:        bc,
-		subscriptions: make(map[string]extensions.BrokerChannelSubscription),
+		subscriptions: make(map[string]subscription),
 		logger:        extensions.DummyLogger{},
 		middlewares:   make([]extensions.Middleware, 0),
 		errorHandler:  extensions.DefaultErrorHandler(),
-	}
+		concurrency:   1,
+	}}
 
 	// Apply options
 	for _, option := range options {
-		option(&controller)
+		option(&c.controller)
 	}
 
-	return &AppController{controller: controller}, nil
+	return c, nil
 }
 
-func (c AppController) wrapMiddlewares(
+func (c *AppController) wrapMiddlewares(
 	middlewares []extensions.Middleware,
 	callback extensions.NextMiddleware,
 ) func(ctx context.Context, msg *extensions.BrokerMessage) error {
-	var called bool
-
 	// If there is no more middleware
 	if len(middlewares) == 0 {
 		return func(ctx context.Context, msg *extensions.BrokerMessage) error {
-			// Call the callback if it exists and it has not been called already
-			if callback != nil && !called {
-				called = true
+			// Call the callback if it exists
+			if callback != nil {
 				return callback(ctx)
 			}
 
-			// Nil can be returned, as the callback has already been called
 			return nil
 		}
 	}
@@ -72,33 +70,31 @@ func (c AppController) wrapMiddlewares(
 	// Get the next function to call from next middlewares or callback
 	next := c.wrapMiddlewares(middlewares[1:], callback)
 
-	// Wrap middleware into a check function that will call execute the middleware
-	// and call the next wrapped middleware if the returned function has not been
-	// called already
+	// Wrap middleware into a function that will execute the middleware and call
+	// the next wrapped middleware if the middleware has not called it already
 	return func(ctx context.Context, msg *extensions.BrokerMessage) error {
-		// Call the middleware and the following if it has not been done already
-		if !called {
-			// Create the next call with the context and the message
-			nextWithArgs := func(ctx context.Context) error {
-				return next(ctx, msg)
-			}
-
-			// Call the middleware and register it as already called
+		// Create the next call with the context and the message. It can be
+		// called several times by the middleware (i.e. to retry the processing).
+		var called bool
+		nextWithArgs := func(ctx context.Context) error {
 			called = true
-			if err := middlewares[0](ctx, msg, nextWithArgs); err != nil {
-				return err
-			}
+			return next(ctx, msg)
+		}
 
-			// If next has already been called in middleware, it should not be executed again
-			return nextWithArgs(ctx)
+		// Call the middleware
+		if err := middlewares[0](ctx, msg, nextWithArgs); err != nil {
+			return err
 		}
 
-		// Nil can be returned, as the next middleware has already been called
-		return nil
+		// If next has already been called in middleware, it should not be executed again
+		if called {
+			return nil
+		}
+		return nextWithArgs(ctx)
 	}
 }
 
-func (c AppController) executeMiddlewares(ctx context.Context, msg *extensions.BrokerMessage, callback extensions.NextMiddleware) error {
+func (c *AppController) executeMiddlewares(ctx context.Context, msg *extensions.BrokerMessage, callback extensions.NextMiddleware) error {
 	// Wrap middleware to have 'next' function when calling them
 	wrapped := c.wrapMiddlewares(c.middlewares, callback)
 
@@ -120,6 +116,55 @@ func (c *AppController) Close(ctx context.Context) {
 	c.logger.Info(ctx, "Closed app controller")
 }
 
+// Shutdown will gracefully stop the controller: it stops the intake of new
+// messages, and waits for the messages being processed to be acknowledged
+// before stopping the subscriptions, until the context is done.
+//
+// Messages received after the intake has been stopped are rejected (Nak) in
+// order to be processed again later, possibly by another instance. An error
+// wrapping extensions.ErrSubscriptionNotDrained is returned for each
+// subscription that still has messages being processed when the context is
+// done, and the context given to these messages is canceled.
+func (c *AppController) Shutdown(ctx context.Context) error {
+	// Take every subscription from the controller
+	c.subscriptionsMutex.Lock()
+	subscriptions := c.subscriptions
+	c.subscriptions = make(map[string]subscription)
+	c.subscriptionsMutex.Unlock()
+
+	// Stop the intake of new messages on every subscription
+	for _, sub := range subscriptions {
+		sub.listener.StopIntake()
+	}
+
+	var errs []error
+	for addr, sub := range subscriptions {
+		// Set context
+		subCtx := addAppContextValues(ctx, addr)
+
+		// Wait for the messages being processed, so they can still be
+		// acknowledged on the subscription, and cancel the context of the
+		// ones still being processed when the context is done (a timeout is
+		// reported below)
+		if err := sub.listener.Drain(subCtx); err != nil {
+			sub.listener.CancelProcessing()
+		}
+
+		// Stop the subscription and wait for its listener
+		sub.broker.Cancel(subCtx)
+		if err := sub.listener.WaitStopped(subCtx); err != nil {
+			err = fmt.Errorf("%w: %d messages still being processed on channel %q",
+				extensions.ErrSubscriptionNotDrained, sub.listener.InFlight(), addr)
+			c.logger.Error(subCtx, err.Error())
+			errs = append(errs, err)
+		}
+	}
+
+	c.logger.Info(ctx, "Shut down app controller")
+
+	return errors.Join(errs...)
+}
+
 // SubscribeToAllChannels will receive messages from channels where channel has
 // no parameter on which the app is expecting messages. For channels with parameters,
 // they should be subscribed independently.
@@ -144,13 +189,12 @@ func (c *AppController) UnsubscribeFromAllChannels(ctx context.Context) {
 //
 // Callback function 'fn' will be called each time a new message is received.
 //
-// NOTE: for now, this only support the first message from AsyncAPI list.
-//
-// NOTE: for now, this only support the first message from AsyncAPI list.
-// If you need support for other messages, please raise an issue.
+// Options (i.e. extensions.WithStartEarliest, extensions.WithQueueGroup) can be
+// given to override the broker controller ones for this subscription.
 func (c *AppController) SubscribeToPingRequestOperation(
 	ctx context.Context,
 	fn func(ctx context.Context, msg PingMessage) error,
+	opts ...extensions.SubscribeOption,
 ) error {
 	// Get channel address
 	addr := "ping.v3"
@@ -159,6 +203,10 @@ func (c *AppController) SubscribeToPingRequestOperation(
 	ctx = addAppContextValues(ctx, addr)
 	ctx = context.WithValue(ctx, extensions.ContextKeyIsDirection, "reception")
 
+	// Lock the subscriptions to avoid conflicts
+	c.subscriptionsMutex.Lock()
+	defer c.subscriptionsMutex.Unlock()
+
 	// Check if the controller is already subscribed
 	_, exists := c.subscriptions[addr]
 	if exists {
@@ -168,7 +216,7 @@ func (c *AppController) SubscribeToPingRequestOperation(
 	}
 
 	// Subscribe to broker channel
-	sub, err := c.broker.Subscribe(ctx, addr)
+	sub, err := extensions.Subscribe(ctx, c.broker, addr, opts...)
 	if err != nil {
 		c.logger.Error(ctx, err.Error())
 		return err
@@ -176,38 +224,56 @@ func (c *AppController) SubscribeToPingRequestOperation(
 	c.logger.Info(ctx, "Subscribed to channel")
 
 	// Asynchronously listen to new messages and pass them to app receiver
-	go func() {
-		for {
-			// Listen to next message
-			stop, err := c.listenToPingRequestOperationNextMessage(addr, sub, fn)
-			if err != nil {
-				c.logger.Error(ctx, err.Error())
-			}
-
-			// Stop if required
-			if stop {
-				return
-			}
-		}
-	}()
+	listener := extensions.NewListenerTracker()
+	go c.listenToPingRequestOperationMessages(ctx, addr, sub, listener, fn)
 
 	// Add the cancel channel to the inside map
-	c.subscriptions[addr] = sub
+	c.subscriptions[addr] = subscription{broker: sub, listener: listener}
+
+	// Stop the subscription when the context is done, if required
+	if c.autoUnsubscribe {
+		go c.unsubscribeWhenDone(ctx, addr, listener)
+	}
 
 	return nil
 }
 
+func (c *AppController) listenToPingRequestOperationMessages(
+	ctx context.Context,
+	addr string,
+	sub extensions.BrokerChannelSubscription,
+	listener *extensions.ListenerTracker,
+	fn func(ctx context.Context, msg PingMessage) error,
+) {
+	// Mark the listener as stopped once every message has been processed
+	defer listener.Stopped()
+
+	// Process messages with the configured concurrency, and wait for
+	// the messages being processed before stopping
+	executor := extensions.NewConcurrentExecutor(c.concurrency, c.concurrencyKey)
+	defer executor.Wait()
+
+	for {
+		// Listen to next message
+		stop, err := c.listenToPingRequestOperationNextMessage(addr, sub, listener, executor, fn)
+		if err != nil {
+			c.logger.Error(ctx, err.Error())
+		}
+
+		// Stop if required
+		if stop {
+			return
+		}
+	}
+}
+
 func (c *AppController) listenToPingRequestOperationNextMessage(
 	addr string,
 	sub extensions.BrokerChannelSubscription,
+	listener *extensions.ListenerTracker,
+	executor *extensions.ConcurrentExecutor,
 	fn func(ctx context.Context, msg PingMessage) error,
 ) (stop bool, err error) {
-	// Create a context for the received response
-	msgCtx, cancel := context.WithCancel(context.Background())
-	msgCtx = addAppContextValues(msgCtx, addr)
-	msgCtx = context.WithValue(msgCtx, extensions.ContextKeyIsDirection, "reception")
-	defer cancel()
-
 	// Wait for next message
 	acknowledgeableBrokerMessage, open := <-sub.MessagesChannel()
 
@@ -217,36 +283,69 @@ func (c *AppController) listenToPingRequestOperationNextMessage(
 		return true, nil
 	}
 
-	// Set broker message to context
-	msgCtx = context.WithValue(msgCtx, extensions.ContextKeyIsBrokerMessage, acknowledgeableBrokerMessage.String())
+	// Get the actual channel address, as the subscription address can
+	// have parameters in case of wildcard subscription
+	if acknowledgeableBrokerMessage.Channel != "" {
+		addr = acknowledgeableBrokerMessage.Channel
+	}
 
-	// Execute middlewares before handling the message
-	if err := c.executeMiddlewares(msgCtx, &acknowledgeableBrokerMessage.BrokerMessage, func(middlewareCtx context.Context) error {
-		// Process message
-		msg, err := brokerMessageToPingMessage(acknowledgeableBrokerMessage.BrokerMessage)
-		if err != nil {
-			return err
-		}
+	// Reject the message if the intake has been stopped (i.e. on shutdown),
+	// so it can be processed again later
+	if !listener.MessageReceived() {
+		acknowledgeableBrokerMessage.Nak()
+		return false, nil
+	}
 
-		// Add correlation ID to context if it exists
-		if id := msg.CorrelationID(); id != "" {
-			middlewareCtx = context.WithValue(middlewareCtx, extensions.ContextKeyIsCorrelationID, id)
-		}
+	// Process the message, possibly in parallel of other messages
+	executor.Execute(acknowledgeableBrokerMessage.BrokerMessage, func() {
+		// Register the message as processed once acknowledged
+		defer listener.MessageProcessed()
 
-		// Execute the subscription function
-		if err := fn(middlewareCtx, msg); err != nil {
-			return err
+		// Create a context for the received message, canceled when unsubscribing
+		msgCtx, cancel := context.WithCancel(listener.Context())
+		msgCtx = addAppContextValues(msgCtx, addr)
+		msgCtx = context.WithValue(msgCtx, extensions.ContextKeyIsDirection, "reception")
+		defer cancel()
+
+		// Set broker message to context
+		msgCtx = context.WithValue(msgCtx, extensions.ContextKeyIsBrokerMessage, acknowledgeableBrokerMessage.String())
+
+		// Execute middlewares before handling the message
+		if err := c.executeMiddlewares(msgCtx, &acknowledgeableBrokerMessage.BrokerMessage, func(middlewareCtx context.Context) error {
+			// Process message
+			msg, err := brokerMessageToPingMessage(acknowledgeableBrokerMessage.BrokerMessage)
+			if err != nil {
+				return err
+			}
+
+			// Validate message if required
+			if c.validation {
+				if err := msg.Validate(); err != nil {
+					return err
+				}
+			}
+
+			// Add correlation ID to context if it exists
+			if id := msg.CorrelationID(); id != "" {
+				middlewareCtx = context.WithValue(middlewareCtx, extensions.ContextKeyIsCorrelationID, id)
+			}
+
+			// Execute the subscription function
+			return fn(middlewareCtx, msg)
+		}); err != nil {
+			c.errorHandler(msgCtx, addr, &acknowledgeableBrokerMessage, err)
+			// On error execute the acknowledgeableBrokerMessage nack() function and
+			// let the BrokerAcknowledgment decide what is the right nack behavior for the broker
+			acknowledgeableBrokerMessage.Nak()
+			return
 		}
 
+		// Acknowledge the message once the middlewares and the subscription
+		// function have returned without error, even if a middleware has not
+		// called the next one (i.e. a retry middleware sending it to a
+		// dead-letter channel)
 		acknowledgeableBrokerMessage.Ack()
-
-		return nil
-	}); err != nil {
-		c.errorHandler(msgCtx, addr, &acknowledgeableBrokerMessage, err)
-		// On error execute the acknowledgeableBrokerMessage nack() function and
-		// let the BrokerAcknowledgment decide what is the right nack behavior for the broker
-		acknowledgeableBrokerMessage.Nak()
-	}
+	})
 
 	return false, nil
 }
@@ -266,6 +365,7 @@ func (c *AppController) ReplyToPingRequestOperation(ctx context.Context, recvMsg
 }
 
 // UnsubscribeFromPingRequestOperation will stop the reception of Ping messages from Ping channel.
+// The context given to the messages being processed is canceled.
 // A timeout can be set in context to avoid blocking operation, if needed.
 func (c *AppController) UnsubscribeFromPingRequestOperation(
 	ctx context.Context,
@@ -273,8 +373,11 @@ func (c *AppController) UnsubscribeFromPingRequestOperation(
 	// Get channel address
 	addr := "ping.v3"
 
-	// Check if there receivers for this channel
+	// Check if there receivers for this channel and remove it from the receivers
+	c.subscriptionsMutex.Lock()
 	sub, exists := c.subscriptions[addr]
+	delete(c.subscriptions, addr)
+	c.subscriptionsMutex.Unlock()
 	if !exists {
 		return
 	}
@@ -282,19 +385,14 @@ func (c *AppController) UnsubscribeFromPingRequestOperation(
 	// Set context
 	ctx = addAppContextValues(ctx, addr)
 
-	// Stop the subscription
-	sub.Cancel(ctx)
-
-	// Remove if from the receivers
-	delete(c.subscriptions, addr)
+	// Stop the subscription and cancel the context of the messages being processed
+	sub.listener.CancelProcessing()
+	sub.broker.Cancel(ctx)
 
 	c.logger.Info(ctx, "Unsubscribed from channel")
 }
 
 // SendAsReplyToPingRequestOperation will send a Pong message on Pong channel.
-//
-// NOTE: for now, this only support the first message from AsyncAPI list.
-// If you need support for other messages, please raise an issue.
 func (c *AppController) SendAsReplyToPingRequestOperation(
 	ctx context.Context,
 	msg PongMessage,
@@ -314,6 +412,13 @@ func (c *AppController) SendAsReplyToPingRequestOperation(
 	ctx = context.WithValue(ctx, extensions.ContextKeyIsDirection, "publication")
 	ctx = context.WithValue(ctx, extensions.ContextKeyIsCorrelationID, msg.CorrelationID())
 
+	// Validate message if required
+	if c.validation {
+		if err := msg.Validate(); err != nil {
+			return err
+		}
+	}
+
 	// Convert to BrokerMessage
 	brokerMsg, err := msg.toBrokerMessage()
 	if err != nil {
@@ -338,7 +443,9 @@ type controller struct {
 	// broker is the broker controller that will be used to communicate
 	broker extensions.BrokerController
 	// subscriptions is a map of all subscriptions
-	subscriptions map[string]extensions.BrokerChannelSubscription
+	subscriptions map[string]subscription
+	// subscriptionsMutex protects the subscriptions map
+	subscriptionsMutex sync.Mutex
 	// logger is the logger that will be used² to log operations on controller
 	logger extensions.Logger
 	// middlewares are the middlewares that will be executed when sending or
@@ -346,6 +453,25 @@ type controller struct {
 	middlewares []extensions.Middleware
 	// handler to handle errors from consumers and middlewares
 	errorHandler extensions.ErrorHandler
+	// validation is set if messages should be validated against the constraints
+	// of the AsyncAPI specification
+	validation bool
+	// concurrency is the maximum number of messages processed in parallel
+	// for each subscription
+	concurrency int
+	// concurrencyKey is the key extractor used to process messages with the
+	// same key in order when processing them in parallel
+	concurrencyKey extensions.KeyExtractor
+	// autoUnsubscribe is set if subscriptions should be stopped when the
+	// context given on subscription is done
+	autoUnsubscribe bool
+}
+
+// subscription is a subscription to a broker channel, with the tracker of the
+// messages being processed by its listener
+type subscription struct {
+	broker   extensions.BrokerChannelSubscription
+	listener *extensions.ListenerTracker
 }
 
 // ControllerOption is the type of the options that can be passed
@@ -373,6 +499,62 @@ func WithErrorHandler(handler extensions.ErrorHandler) ControllerOption {
 	}
 }
 
+// WithValidation enables the validation of messages against the constraints of
+// the AsyncAPI specification: invalid messages are rejected when sent, and
+// given to the error handler instead of the subscriber when received.
+func WithValidation() ControllerOption {
+	return func(controller *controller) {
+		controller.validation = true
+	}
+}
+
+// WithConcurrency sets the maximum number of messages processed in parallel for
+// each subscription (default is 1). If a key extractor is given (i.e.
+// extensions.KeyFromHeader), messages with the same key are processed
+// sequentially, in the order they were received.
+func WithConcurrency(n int, key ...extensions.KeyExtractor) ControllerOption {
+	return func(controller *controller) {
+		controller.concurrency = n
+		if len(key) > 0 {
+			controller.concurrencyKey = key[0]
+		}
+	}
+}
+
+// WithAutoUnsubscribe stops a subscription when the context given on
+// subscription is done, as if the corresponding unsubscribe function was called.
+func WithAutoUnsubscribe() ControllerOption {
+	return func(controller *controller) {
+		controller.autoUnsubscribe = true
+	}
+}
+
+// unsubscribeWhenDone stops the subscription on the channel when the context is
+// done, unless its listener has been stopped before (i.e. unsubscribed).
+func (c *controller) unsubscribeWhenDone(ctx context.Context, addr string, listener *extensions.ListenerTracker) {
+	// Wait for the context to be done or the listener to be stopped
+	if err := listener.WaitStopped(ctx); err == nil {
+		return
+	}
+
+	// Remove the subscription if it is still the same
+	c.subscriptionsMutex.Lock()
+	sub, exists := c.subscriptions[addr]
+	if !exists || sub.listener != listener {
+		c.subscriptionsMutex.Unlock()
+		return
+	}
+	delete(c.subscriptions, addr)
+	c.subscriptionsMutex.Unlock()
+
+	// Stop the subscription, waiting for the broker clean up even if the
+	// context is done, and cancel the context of the messages being processed
+	sub.listener.CancelProcessing()
+	sub.broker.Cancel(context.WithoutCancel(ctx))
+
+	c.logger.Info(ctx, "Unsubscribed from channel as context is done")
+}
+
 type MessageWithCorrelationID interface {
 	CorrelationID() string
 	SetCorrelationID(id string)
@@ -403,11 +585,26 @@ type HeadersFromPingMessage struct {
 	CorrelationId *string `json:"correlationId,omitempty"`
 }
 
+// Validate checks that HeadersFromPingMessage respects the constraints of the AsyncAPI specification.
+func (s HeadersFromPingMessage) Validate() error {
+	return nil
+}
+
 // PingMessagePayload is a schema from the AsyncAPI specification required in messages
 type PingMessagePayload struct {
 	Event *string `json:"event,omitempty" validate:"omitempty,eq=ping"`
 }
 
+// Validate checks that PingMessagePayload respects the constraints of the AsyncAPI specification.
+func (s PingMessagePayload) Validate() error {
+	if s.Event != nil {
+		if err := extensions.ValidateConst("event", (*s.Event), "ping"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // PingMessage is the message expected for 'PingMessage' channel.
 type PingMessage struct {
 	// Headers will be used to fill the message headers
@@ -431,8 +628,14 @@ func NewPingMessage() PingMessage {
 func brokerMessageToPingMessage(bMsg extensions.BrokerMessage) (PingMessage, error) {
 	var msg PingMessage
 
+	// Get codec from the message content type, or from the one in specification
+	codec, err := extensions.CodecForBrokerMessage(bMsg, "application/json")
+	if err != nil {
+		return msg, err
+	}
+
 	// Unmarshal payload to expected message payload format
-	err := json.Unmarshal(bMsg.Payload, &msg.Payload)
+	err = codec.Unmarshal(bMsg.Payload, &msg.Payload)
 	if err != nil {
 		return msg, err
 	}
@@ -448,17 +651,17 @@ func brokerMessageToPingMessage(bMsg extensions.BrokerMessage) (PingMessage, err
 		}
 	}
 
-	// TODO: run checks on msg type
-
 	return msg, nil
 }
 
 // toBrokerMessage will generate a generic broker message from PingMessage data
 func (msg PingMessage) toBrokerMessage() (extensions.BrokerMessage, error) {
-	// TODO: implement checks on message
 
-	// Marshal payload to JSON
-	payload, err := json.Marshal(msg.Payload)
+	// Get codec from the content type in specification
+	codec := extensions.SpecificationCodec("application/json")
+
+	// Marshal payload with the codec
+	payload, err := codec.Marshal(msg.Payload)
 	if err != nil {
 		return extensions.BrokerMessage{}, err
 	}
@@ -471,12 +674,26 @@ func (msg PingMessage) toBrokerMessage() (extensions.BrokerMessage, error) {
 		headers["correlationId"] = []byte(*msg.Headers.CorrelationId)
 	}
 
+	// Set content type, so the receiver can decode the payload
+	headers[extensions.ContentTypeHeader] = []byte("application/json")
+
 	return extensions.BrokerMessage{
 		Headers: headers,
 		Payload: payload,
 	}, nil
 }
 
+// Validate checks that PingMessage respects the constraints of the AsyncAPI specification.
+func (msg PingMessage) Validate() error {
+	if err := msg.Headers.Validate(); err != nil {
+		return extensions.WithValidationField("headers", err)
+	}
+	if err := msg.Payload.Validate(); err != nil {
+		return extensions.WithValidationField("payload", err)
+	}
+	return nil
+}
+
 // CorrelationID will give the correlation ID of the message, based on AsyncAPI spec
 func (msg PingMessage) CorrelationID() string {
 	if msg.Headers.CorrelationId != nil {
@@ -505,11 +722,26 @@ type HeadersFromPongMessage struct {
 	CorrelationId *string `json:"correlationId,omitempty"`
 }
 
+// Validate checks that HeadersFromPongMessage respects the constraints of the AsyncAPI specification.
+func (s HeadersFromPongMessage) Validate() error {
+	return nil
+}
+
 // PongMessagePayload is a schema from the AsyncAPI specification required in messages
 type PongMessagePayload struct {
 	Event *string `json:"event,omitempty" validate:"omitempty,eq=pong"`
 }
 
+// Validate checks that PongMessagePayload respects the constraints of the AsyncAPI specification.
+func (s PongMessagePayload) Validate() error {
+	if s.Event != nil {
+		if err := extensions.ValidateConst("event", (*s.Event), "pong"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // PongMessage is the message expected for 'PongMessage' channel.
 type PongMessage struct {
 	// Headers will be used to fill the message headers
@@ -533,8 +765,14 @@ func NewPongMessage() PongMessage {
 func brokerMessageToPongMessage(bMsg extensions.BrokerMessage) (PongMessage, error) {
 	var msg PongMessage
 
+	// Get codec from the message content type, or from the one in specification
+	codec, err := extensions.CodecForBrokerMessage(bMsg, "application/json")
+	if err != nil {
+		return msg, err
+	}
+
 	// Unmarshal payload to expected message payload format
-	err := json.Unmarshal(bMsg.Payload, &msg.Payload)
+	err = codec.Unmarshal(bMsg.Payload, &msg.Payload)
 	if err != nil {
 		return msg, err
 	}
@@ -550,17 +788,17 @@ func brokerMessageToPongMessage(bMsg extensions.BrokerMessage) (PongMessage, err
 		}
 	}
 
-	// TODO: run checks on msg type
-
 	return msg, nil
 }
 
 // toBrokerMessage will generate a generic broker message from PongMessage data
 func (msg PongMessage) toBrokerMessage() (extensions.BrokerMessage, error) {
-	// TODO: implement checks on message
 
-	// Marshal payload to JSON
-	payload, err := json.Marshal(msg.Payload)
+	// Get codec from the content type in specification
+	codec := extensions.SpecificationCodec("application/json")
+
+	// Marshal payload with the codec
+	payload, err := codec.Marshal(msg.Payload)
 	if err != nil {
 		return extensions.BrokerMessage{}, err
 	}
@@ -573,12 +811,26 @@ func (msg PongMessage) toBrokerMessage() (extensions.BrokerMessage, error) {
 		headers["correlationId"] = []byte(*msg.Headers.CorrelationId)
 	}
 
+	// Set content type, so the receiver can decode the payload
+	headers[extensions.ContentTypeHeader] = []byte("application/json")
+
 	return extensions.BrokerMessage{
 		Headers: headers,
 		Payload: payload,
 	}, nil
 }
 
+// Validate checks that PongMessage respects the constraints of the AsyncAPI specification.
+func (msg PongMessage) Validate() error {
+	if err := msg.Headers.Validate(); err != nil {
+		return extensions.WithValidationField("headers", err)
+	}
+	if err := msg.Payload.Validate(); err != nil {
+		return extensions.WithValidationField("payload", err)
+	}
+	return nil
+}
+
 // CorrelationID will give the correlation ID of the message, based on AsyncAPI spec
 func (msg PongMessage) CorrelationID() string {
 	if msg.Headers.CorrelationId != nil {
@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+)
+
+// ErrFetchFailed is returned when a remote file cannot be fetched.
+var ErrFetchFailed = fmt.Errorf("%w: fetch failed", extensions.ErrAsyncAPI)
+
+// Fetcher gets the content of the remote files (i.e. 'https://...') referenced
+// by a specification.
+type Fetcher interface {
+	Fetch(url string) ([]byte, error)
+}
+
+// HTTPFetcher is a Fetcher that gets remote files with HTTP(S).
+type HTTPFetcher struct {
+	// Client is the HTTP client used to get remote files.
+	// If nil, a client with a 30 seconds timeout is used.
+	Client *http.Client
+}
+
+// Fetch gets the remote file with an HTTP GET request.
+func (f HTTPFetcher) Fetch(url string) ([]byte, error) {
+	client := f.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFetchFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%w: %q returned status %q", ErrFetchFailed, url, resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// CacheFetcher is a Fetcher that keeps the remote files in a local directory,
+// and only uses the underlying Fetcher for files that are not in the cache yet.
+type CacheFetcher struct {
+	// Fetcher is the fetcher used on cache miss.
+	Fetcher Fetcher
+	// Dir is the directory containing the cached files.
+	Dir string
+}
+
+// Fetch gets the remote file from the cache or, if missing, from the
+// underlying Fetcher before saving it in the cache.
+func (f CacheFetcher) Fetch(url string) ([]byte, error) {
+	sum := sha256.Sum256([]byte(url))
+	path := filepath.Join(f.Dir, hex.EncodeToString(sum[:]))
+
+	// Get from cache
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return data, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	// Get from fetcher
+	data, err = f.Fetcher.Fetch(url)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save in cache
+	if err := os.MkdirAll(f.Dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
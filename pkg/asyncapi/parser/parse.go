@@ -3,8 +3,6 @@ package parser
 import (
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 	"reflect"
 	"strconv"
 
@@ -26,40 +24,31 @@ var (
 // FromFileParams are the parameters to parse an AsyncAPI specification from a file.
 type FromFileParams struct {
 	// Path to the file that contains the AsyncAPI specification.
+	// It can also be an HTTP(S) URL.
 	Path string
 	// MajorVersion is the major version of the AsyncAPI specification.
 	// If it is 0, it will try to get it from the specification.
 	MajorVersion int
+	// Fetcher is used to get the remote files (i.e. 'https://...') referenced
+	// in the specification. If nil, HTTPFetcher is used.
+	Fetcher Fetcher
 }
 
 // FromFile parses the AsyncAPI specification either from a YAML file or a JSON file.
 // If there is no version provided, it will try to get it from the specification.
 //
+// The files referenced in the specification (i.e. './schemas.yaml#/components/schemas/User'
+// or 'https://example.com/schemas.yaml#/User') are also parsed and added as
+// dependencies, recursively. Relative references are resolved from the file
+// containing them.
+//
 // NOTE: It returns the Specification with filled fields, but this doesn't
 // generate metadata, link references, apply traits, etc. You have to call
 // method `Process` for this.
 //
 //nolint:ireturn,nolintlint
 func FromFile(params FromFileParams) (asyncapi.Specification, error) {
-	data, err := os.ReadFile(params.Path)
-	if err != nil {
-		return nil, err
-	}
-
-	switch filepath.Ext(params.Path) {
-	case ".yaml", ".yml":
-		return FromYAML(FromYAMLParams{
-			Data:         data,
-			MajorVersion: params.MajorVersion,
-		})
-	case ".json":
-		return FromJSON(FromJSONParams{
-			Data:         data,
-			MajorVersion: params.MajorVersion,
-		})
-	default:
-		return nil, fmt.Errorf("%w: %q", ErrInvalidFileFormat, params.MajorVersion)
-	}
+	return newResolver(params.Fetcher, params.MajorVersion).load(params.Path)
 }
 
 // FromYAMLParams are the parameters to parse an AsyncAPI specification from a YAML file.
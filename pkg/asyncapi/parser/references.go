@@ -0,0 +1,228 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/lerenn/asyncapi-codegen/pkg/asyncapi"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+)
+
+var (
+	// ErrCircularReference is returned when files are referencing each other.
+	ErrCircularReference = fmt.Errorf("%w: circular reference between files", extensions.ErrAsyncAPI)
+
+	// ErrUnresolvableReference is returned when a file referenced by a
+	// specification cannot be read.
+	ErrUnresolvableReference = fmt.Errorf("%w: unresolvable reference", extensions.ErrAsyncAPI)
+)
+
+// resolver loads a specification file and, recursively, the files that are
+// referenced in it, as its dependencies.
+type resolver struct {
+	fetcher      Fetcher
+	majorVersion int
+
+	// loaded are the already loaded files, by location
+	loaded map[string]asyncapi.Specification
+	// stack are the locations of the files being loaded, to detect cycles
+	stack []string
+}
+
+func newResolver(fetcher Fetcher, majorVersion int) *resolver {
+	if fetcher == nil {
+		fetcher = HTTPFetcher{}
+	}
+
+	return &resolver{
+		fetcher:      fetcher,
+		majorVersion: majorVersion,
+		loaded:       make(map[string]asyncapi.Specification),
+	}
+}
+
+//nolint:ireturn,nolintlint
+func (r *resolver) load(location string) (asyncapi.Specification, error) {
+	r.stack = append(r.stack, location)
+	defer func() { r.stack = r.stack[:len(r.stack)-1] }()
+
+	// Read and parse the file
+	data, err := r.read(location)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := FromJSON(FromJSONParams{
+		Data:         data,
+		MajorVersion: r.majorVersion,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", location, err)
+	}
+	r.loaded[location] = spec
+
+	// Dependencies should have the same version
+	if r.majorVersion == 0 {
+		r.majorVersion = spec.MajorVersion()
+	}
+
+	// Load the referenced files as dependencies
+	files, err := referencedFiles(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", location, err)
+	}
+	for _, file := range files {
+		dep, err := r.loadReferenced(location, file)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := spec.AddDependency(file, dep); err != nil {
+			return nil, err
+		}
+	}
+
+	return spec, nil
+}
+
+//nolint:ireturn,nolintlint
+func (r *resolver) loadReferenced(from, file string) (asyncapi.Specification, error) {
+	location, err := resolveLocation(from, file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q from %q: %w", ErrUnresolvableReference, file, from, err)
+	}
+
+	// Check that the file is not already being loaded
+	if i := slices.Index(r.stack, location); i >= 0 {
+		return nil, fmt.Errorf("%w: %s", ErrCircularReference,
+			strings.Join(append(slices.Clone(r.stack[i:]), location), " -> "))
+	}
+
+	// Use the file if already loaded
+	if spec, ok := r.loaded[location]; ok {
+		return spec, nil
+	}
+
+	spec, err := r.load(location)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q from %q: %w", ErrUnresolvableReference, file, from, err)
+	}
+
+	return spec, nil
+}
+
+// read returns the content of the file as JSON.
+func (r *resolver) read(location string) ([]byte, error) {
+	var data []byte
+	var err error
+	var ext string
+
+	if isRemote(location) {
+		u, err := url.Parse(location)
+		if err != nil {
+			return nil, err
+		}
+		ext = path.Ext(u.Path)
+
+		data, err = r.fetcher.Fetch(location)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		ext = filepath.Ext(location)
+
+		data, err = os.ReadFile(location)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch ext {
+	case ".yaml", ".yml":
+		return yaml.YAMLToJSON(data)
+	case ".json":
+		return data, nil
+	default:
+		// Remote files can have no extension, and JSON is also valid YAML
+		if isRemote(location) {
+			return yaml.YAMLToJSON(data)
+		}
+		return nil, fmt.Errorf("%w: %q", ErrInvalidFileFormat, location)
+	}
+}
+
+func isRemote(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// resolveLocation returns the location of a file referenced from another one.
+func resolveLocation(from, file string) (string, error) {
+	switch {
+	case isRemote(file):
+		return file, nil
+	case isRemote(from):
+		base, err := url.Parse(from)
+		if err != nil {
+			return "", err
+		}
+		ref, err := url.Parse(file)
+		if err != nil {
+			return "", err
+		}
+		return base.ResolveReference(ref).String(), nil
+	case filepath.IsAbs(file):
+		return filepath.Clean(file), nil
+	default:
+		return filepath.Join(filepath.Dir(from), file), nil
+	}
+}
+
+// referencedFiles returns the files referenced in a JSON document, from the
+// references with a file part (i.e. './schemas.yaml#/components/schemas/User').
+func referencedFiles(data []byte) ([]string, error) {
+	var document any
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]struct{})
+	walkReferences(document, func(ref string) {
+		if file, _, _ := strings.Cut(ref, "#"); file != "" {
+			files[file] = struct{}{}
+		}
+	})
+
+	// Sort files to load them in a deterministic order
+	list := make([]string, 0, len(files))
+	for file := range files {
+		list = append(list, file)
+	}
+	sort.Strings(list)
+
+	return list, nil
+}
+
+func walkReferences(value any, fn func(ref string)) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if ref, ok := child.(string); ok && key == "$ref" {
+				fn(ref)
+				continue
+			}
+			walkReferences(child, fn)
+		}
+	case []any:
+		for _, child := range v {
+			walkReferences(child, fn)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package parser
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	asyncapiv3 "github.com/lerenn/asyncapi-codegen/pkg/asyncapi/v3"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestReferencesSuite(t *testing.T) {
+	suite.Run(t, new(ReferencesSuite))
+}
+
+type ReferencesSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (suite *ReferencesSuite) SetupTest() {
+	suite.dir = suite.T().TempDir()
+}
+
+func (suite *ReferencesSuite) writeFile(name, content string) string {
+	path := filepath.Join(suite.dir, name)
+	suite.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+	suite.Require().NoError(os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+// specWithPayloadRef returns a specification whose message payload is the
+// given reference.
+func specWithPayloadRef(ref string) string {
+	return `
+asyncapi: 3.0.0
+info:
+  title: Sample App
+  version: 1.2.3
+channels:
+  users:
+    address: users
+    messages:
+      user:
+        payload:
+          $ref: '` + ref + `'
+`
+}
+
+// payload returns the followed payload of the 'user' message of the
+// specification after processing it.
+func (suite *ReferencesSuite) payload(path string, fetcher Fetcher) *asyncapiv3.Schema {
+	spec, err := FromFile(FromFileParams{Path: path, Fetcher: fetcher})
+	suite.Require().NoError(err)
+	suite.Require().NoError(spec.Process())
+
+	v3, err := asyncapiv3.FromUnknownVersion(spec)
+	suite.Require().NoError(err)
+	return v3.Channels["users"].Messages["user"].Payload.Follow()
+}
+
+const userSchemaFile = `
+User:
+  type: object
+  properties:
+    id:
+      $ref: './common.yaml#/components/schemas/Id'
+    name:
+      type: string
+`
+
+const commonSchemaFile = `
+components:
+  schemas:
+    Id:
+      type: string
+      format: uuid
+`
+
+func (suite *ReferencesSuite) TestRelativeReferences() {
+	path := suite.writeFile("asyncapi.yaml", specWithPayloadRef("./schemas/user.yaml#/User"))
+	suite.writeFile("schemas/user.yaml", userSchemaFile)
+	suite.writeFile("schemas/common.yaml", commonSchemaFile)
+
+	payload := suite.payload(path, nil)
+	suite.Require().Equal("object", payload.Type)
+	suite.Require().Equal("UserSchema", payload.Name)
+	suite.Require().Equal("string", payload.Properties["name"].Type)
+
+	// Reference relative to the schema file
+	id := payload.Properties["id"].Follow()
+	suite.Require().Equal("string", id.Type)
+	suite.Require().Equal("uuid", id.Format)
+}
+
+func (suite *ReferencesSuite) TestRemoteReferences() {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/schemas/user.yaml":
+			_, _ = w.Write([]byte(userSchemaFile))
+		case "/schemas/common.yaml":
+			_, _ = w.Write([]byte(commonSchemaFile))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	path := suite.writeFile("asyncapi.yaml", specWithPayloadRef(server.URL+"/schemas/user.yaml#/User"))
+	fetcher := CacheFetcher{
+		Fetcher: HTTPFetcher{Client: server.Client()},
+		Dir:     filepath.Join(suite.dir, "cache"),
+	}
+
+	// Reference relative to the remote file
+	payload := suite.payload(path, fetcher)
+	suite.Require().Equal("uuid", payload.Properties["id"].Follow().Format)
+	suite.Require().Equal(int32(2), requests.Load())
+
+	// Second time should come from the cache
+	server.Close()
+	payload = suite.payload(path, fetcher)
+	suite.Require().Equal("uuid", payload.Properties["id"].Follow().Format)
+	suite.Require().Equal(int32(2), requests.Load())
+}
+
+func (suite *ReferencesSuite) TestRemoteReferenceNotFound() {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	path := suite.writeFile("asyncapi.yaml", specWithPayloadRef(server.URL+"/user.yaml#/User"))
+	_, err := FromFile(FromFileParams{Path: path, Fetcher: HTTPFetcher{Client: server.Client()}})
+	suite.Require().ErrorIs(err, ErrUnresolvableReference)
+	suite.Require().ErrorIs(err, ErrFetchFailed)
+}
+
+func (suite *ReferencesSuite) TestCircularReferences() {
+	path := suite.writeFile("asyncapi.yaml", specWithPayloadRef("./a.yaml#/A"))
+	suite.writeFile("a.yaml", "A:\n  $ref: './b.yaml#/B'\n")
+	suite.writeFile("b.yaml", "B:\n  $ref: './a.yaml#/A'\n")
+
+	_, err := FromFile(FromFileParams{Path: path})
+	suite.Require().ErrorIs(err, ErrCircularReference)
+	suite.Require().ErrorContains(err, "a.yaml -> "+filepath.Join(suite.dir, "b.yaml")+" -> ")
+}
+
+func (suite *ReferencesSuite) TestMissingFile() {
+	path := suite.writeFile("asyncapi.yaml", specWithPayloadRef("./missing.yaml#/User"))
+
+	_, err := FromFile(FromFileParams{Path: path})
+	suite.Require().ErrorIs(err, ErrUnresolvableReference)
+	suite.Require().ErrorIs(err, os.ErrNotExist)
+}
+
+func (suite *ReferencesSuite) TestSharedDependency() {
+	// Both files reference the same file, which should be loaded only once
+	path := suite.writeFile("asyncapi.yaml", `
+asyncapi: 3.0.0
+info:
+  title: Sample App
+  version: 1.2.3
+channels:
+  users:
+    address: users
+    messages:
+      user:
+        payload:
+          type: object
+          properties:
+            id:
+              $ref: './common.yaml#/components/schemas/Id'
+            user:
+              $ref: './user.yaml#/User'
+`)
+	suite.writeFile("user.yaml", userSchemaFile)
+	suite.writeFile("common.yaml", commonSchemaFile)
+
+	payload := suite.payload(path, nil)
+	suite.Require().Same(payload.Properties["id"].Follow(), payload.Properties["user"].Follow().Properties["id"].Follow())
+}
+
+func (suite *ReferencesSuite) TestSchemaNameCollision() {
+	// Two different files define a schema with the same name
+	path := suite.writeFile("asyncapi.yaml", `
+asyncapi: 3.0.0
+info:
+  title: Sample App
+  version: 1.2.3
+channels:
+  users:
+    address: users
+    messages:
+      user:
+        payload:
+          type: object
+          properties:
+            a:
+              $ref: './a.yaml#/User'
+            b:
+              $ref: './b.yaml#/User'
+`)
+	suite.writeFile("a.yaml", "User:\n  type: string\n")
+	suite.writeFile("b.yaml", "User:\n  type: integer\n")
+
+	spec, err := FromFile(FromFileParams{Path: path})
+	suite.Require().NoError(err)
+	suite.Require().ErrorIs(spec.Process(), asyncapiv3.ErrSchemaNameCollision)
+}
+
+func (suite *ReferencesSuite) TestComponentReferencingDependency() {
+	// A component referencing a schema of the same name is not a collision
+	path := suite.writeFile("asyncapi.yaml", `
+asyncapi: 3.0.0
+info:
+  title: Sample App
+  version: 1.2.3
+channels:
+  users:
+    address: users
+    messages:
+      user:
+        payload:
+          $ref: '#/components/schemas/UserSchema'
+components:
+  schemas:
+    UserSchema:
+      $ref: './user.yaml#/User'
+`)
+	suite.writeFile("user.yaml", userSchemaFile)
+	suite.writeFile("common.yaml", commonSchemaFile)
+
+	payload := suite.payload(path, nil)
+	suite.Require().Equal("object", payload.Follow().Type)
+}
+
+func (suite *ReferencesSuite) TestSameSchemaNameAndDefinition() {
+	// Two different files define the same schema, which is not a collision
+	path := suite.writeFile("asyncapi.yaml", `
+asyncapi: 3.0.0
+info:
+  title: Sample App
+  version: 1.2.3
+channels:
+  users:
+    address: users
+    messages:
+      user:
+        payload:
+          type: object
+          properties:
+            a:
+              $ref: './a.yaml#/User'
+            b:
+              $ref: './b.yaml#/User'
+`)
+	suite.writeFile("a.yaml", "User:\n  type: string\n")
+	suite.writeFile("b.yaml", "User:\n  type: string\n")
+
+	spec, err := FromFile(FromFileParams{Path: path})
+	suite.Require().NoError(err)
+	suite.Require().NoError(spec.Process())
+}
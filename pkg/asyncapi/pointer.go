@@ -0,0 +1,57 @@
+package asyncapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+)
+
+// ErrInvalidPointer is returned when a JSON pointer doesn't target any value
+// of a document.
+var ErrInvalidPointer = fmt.Errorf("%w: invalid JSON pointer", extensions.ErrAsyncAPI)
+
+// PointedValue returns the raw JSON value of the document targeted by the JSON
+// pointer, given as its unescaped parts (i.e. '#/a/b' is []string{"a", "b"}).
+func PointedValue(document []byte, pointer []string) (json.RawMessage, error) {
+	value := json.RawMessage(document)
+
+	for i, part := range pointer {
+		// Unescape part, as defined in RFC 6901
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+
+		next, err := pointedChild(value, part)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %w", ErrInvalidPointer, "/"+strings.Join(pointer[:i+1], "/"), err)
+		}
+		value = next
+	}
+
+	return value, nil
+}
+
+func pointedChild(value json.RawMessage, part string) (json.RawMessage, error) {
+	// Try as an object
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(value, &obj); err == nil {
+		child, ok := obj[part]
+		if !ok {
+			return nil, fmt.Errorf("no field %q", part)
+		}
+		return child, nil
+	}
+
+	// Try as an array
+	var arr []json.RawMessage
+	if err := json.Unmarshal(value, &arr); err == nil {
+		i, err := strconv.Atoi(part)
+		if err != nil || i < 0 || i >= len(arr) {
+			return nil, fmt.Errorf("no index %q", part)
+		}
+		return arr[i], nil
+	}
+
+	return nil, fmt.Errorf("no object or array for %q", part)
+}
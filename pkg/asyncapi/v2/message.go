@@ -42,8 +42,15 @@ type Message struct {
 	OneOf         []*Message     `json:"oneOf"`
 	Payload       *Schema        `json:"payload"`
 	CorrelationID *CorrelationID `json:"correlationID"`
+	ContentType   string         `json:"contentType"`
 	Reference     string         `json:"$ref"`
 
+	// --- Extensions ----------------------------------------------------------
+
+	// ExtMessageKey is the location of the message key, as a runtime
+	// expression (i.e. '$message.payload#/orderId').
+	ExtMessageKey string `json:"x-message-key"`
+
 	// --- Non AsyncAPI fields -------------------------------------------------
 
 	Name        string   `json:"-"`
@@ -53,6 +60,11 @@ type Message struct {
 	// According to: https://www.asyncapi.com/docs/reference/specification/v2.6.0#correlationIDObject
 	CorrelationIDLocation string `json:"-"`
 	CorrelationIDRequired bool   `json:"-"`
+
+	// KeyLocation will indicate where the message key is, from the
+	// 'x-message-key' extension.
+	KeyLocation string `json:"-"`
+	KeyRequired bool   `json:"-"`
 }
 
 // generateMetadata generates the metadata for the Message and its children.
@@ -109,10 +121,30 @@ func (msg *Message) setDependencies(spec Specification) error {
 		return err
 	}
 
+	// Set content type from specification default if not set
+	msg.setDefaultContentType(spec)
+
+	// Set key dependencies, after payload and headers as the key is in them
+	if err := msg.setKeyDependencies(); err != nil {
+		return err
+	}
+
 	// Set CorrelationID dependencies
 	return msg.setCorrelationIDDependencies(spec)
 }
 
+func (msg *Message) setDefaultContentType(spec Specification) {
+	if msg.ContentType != "" {
+		return
+	}
+
+	if spec.DefaultContentType != "" {
+		msg.ContentType = spec.DefaultContentType
+	} else {
+		msg.ContentType = extensions.DefaultContentType
+	}
+}
+
 func (msg *Message) generateHeadersMetadata() error {
 	// check headers exists
 	if msg.Headers == nil {
@@ -161,6 +193,31 @@ func (msg *Message) setCorrelationIDDependencies(spec Specification) error {
 	return nil
 }
 
+func (msg *Message) setKeyDependencies() error {
+	if msg.ExtMessageKey == "" {
+		return nil
+	}
+
+	if !isMessageLocation(msg.ExtMessageKey) {
+		return fmt.Errorf("%w: %q message key should be a location in header or payload (i.e. "+
+			"'$message.payload#/id'), is %q", extensions.ErrAsyncAPI, msg.Name, msg.ExtMessageKey)
+	}
+
+	msg.KeyLocation = msg.ExtMessageKey
+	keyParent := msg.createTreeUntilLocation(msg.KeyLocation)
+	path := strings.Split(msg.KeyLocation, "/")
+	msg.KeyRequired = keyParent.IsFieldRequired(path[len(path)-1])
+
+	return nil
+}
+
+// isMessageLocation checks that the runtime expression is a location in the
+// message header or payload.
+func isMessageLocation(location string) bool {
+	return strings.HasPrefix(location, "$message.header#/") ||
+		strings.HasPrefix(location, "$message.payload#/")
+}
+
 func (msg *Message) generateOneOfMetadata() error {
 	for k, v := range msg.OneOf {
 		// Process the OneOf
@@ -214,36 +271,40 @@ func (msg Message) isCorrelationIDRequired() bool {
 		return false
 	}
 
-	correlationIDParent := msg.createTreeUntilCorrelationID()
+	correlationIDParent := msg.createTreeUntilLocation(msg.CorrelationID.Location)
 	path := strings.Split(msg.CorrelationID.Location, "/")
 	return correlationIDParent.IsFieldRequired(path[len(path)-1])
 }
 
 func (msg *Message) createCorrelationIDFieldIfMissing() {
-	_ = msg.createTreeUntilCorrelationID()
+	if msg.CorrelationID == nil {
+		return
+	}
+
+	_ = msg.createTreeUntilLocation(msg.CorrelationID.Location)
 }
 
-func (msg *Message) createTreeUntilCorrelationID() (correlationIDParent *Schema) {
-	// Check that correlationID exists
-	if msg.CorrelationID == nil || msg.CorrelationID.Location == "" {
+func (msg *Message) createTreeUntilLocation(location string) (locationParent *Schema) {
+	// Check location
+	if location == "" {
 		return utils.ToPointer(NewSchema())
 	}
 
-	// Check that the correlation ID is in header
-	if strings.HasPrefix(msg.CorrelationID.Location, "$message.header#") {
-		return msg.createTreeUntilCorrelationIDFromMessageType(MessageFieldIsHeader)
+	// Check that the location is in header
+	if strings.HasPrefix(location, "$message.header#") {
+		return msg.createTreeUntilLocationFromMessageType(MessageFieldIsHeader, location)
 	}
 
-	// Check that the correlation ID is in payload
-	if strings.HasPrefix(msg.CorrelationID.Location, "$message.payload#") {
-		return msg.createTreeUntilCorrelationIDFromMessageType(MessageFieldIsPayload)
+	// Check that the location is in payload
+	if strings.HasPrefix(location, "$message.payload#") {
+		return msg.createTreeUntilLocationFromMessageType(MessageFieldIsPayload, location)
 	}
 
 	// Default to nothing
 	return utils.ToPointer(NewSchema())
 }
 
-func (msg *Message) createTreeUntilCorrelationIDFromMessageType(t MessageField) (correlationIDParent *Schema) {
+func (msg *Message) createTreeUntilLocationFromMessageType(t MessageField, location string) (locationParent *Schema) {
 	// Get correct top level placeholder
 	var placeholder **Schema
 	if t == MessageFieldIsHeader {
@@ -268,20 +329,20 @@ func (msg *Message) createTreeUntilCorrelationIDFromMessageType(t MessageField)
 		child = (*placeholder)
 	}
 
-	// Go down the path to correlation ID
-	return msg.downToCorrelationID(child)
+	// Go down the path to the location
+	return msg.downToLocation(child, location)
 }
 
-func (msg Message) downToCorrelationID(child *Schema) (correlationIDParent *Schema) {
+func (msg Message) downToLocation(child *Schema, location string) (locationParent *Schema) {
 	var exists bool
 
-	path := strings.Split(msg.CorrelationID.Location, "/")
+	path := strings.Split(location, "/")
 	for i, v := range path[1:] {
 		// Keep the parent
-		correlationIDParent = child
+		locationParent = child
 
 		// Get the corresponding child
-		child, exists = correlationIDParent.Properties[v]
+		child, exists = locationParent.Properties[v]
 		if !exists { // If it doesn't exist
 			// Create child
 			child = utils.ToPointer(NewSchema())
@@ -293,14 +354,14 @@ func (msg Message) downToCorrelationID(child *Schema) (correlationIDParent *Sche
 			}
 
 			// Add it to parent
-			if correlationIDParent.Properties == nil {
-				correlationIDParent.Properties = make(map[string]*Schema)
+			if locationParent.Properties == nil {
+				locationParent.Properties = make(map[string]*Schema)
 			}
-			correlationIDParent.Properties[v] = child
+			locationParent.Properties[v] = child
 		}
 	}
 
-	return correlationIDParent
+	return locationParent
 }
 
 func (msg *Message) referenceFrom(ref []string) any {
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/lerenn/asyncapi-codegen/pkg/asyncapi"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
 	"github.com/lerenn/asyncapi-codegen/pkg/utils"
 	"github.com/stretchr/testify/suite"
 )
@@ -161,3 +162,28 @@ func (suite *MessageSuite) TestIsCorrelationIDRequiredWithEmptyMessage() {
 	// Check if true
 	suite.Require().False(msg.isCorrelationIDRequired())
 }
+
+func (suite *MessageSuite) TestSetKeyDependencies() {
+	// Set message
+	msg := Message{
+		Headers: &Schema{
+			Validations: asyncapi.Validations[Schema]{
+				Required: []string{"accountId"},
+			},
+		},
+		ExtMessageKey: "$message.header#/accountId",
+	}
+
+	// Check the key location
+	suite.Require().NoError(msg.setKeyDependencies())
+	suite.Require().Equal("$message.header#/accountId", msg.KeyLocation)
+	suite.Require().True(msg.KeyRequired)
+}
+
+func (suite *MessageSuite) TestSetKeyDependenciesWithInvalidLocation() {
+	// Set message
+	msg := Message{ExtMessageKey: "accountId"}
+
+	// Check the error
+	suite.Require().ErrorIs(msg.setKeyDependencies(), extensions.ErrAsyncAPI)
+}
@@ -2,6 +2,7 @@ package asyncapiv3
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
 )
@@ -152,10 +153,28 @@ func (ch *Channel) Follow() *Channel {
 	return ch
 }
 
-// GetMessage will return the channel message.
+// GetMessage will return the first channel message (sorted by key).
 func (ch Channel) GetMessage() (*Message, error) {
-	for _, m := range ch.Follow().Messages {
-		return m.Follow(), nil // TODO: change
+	msgs := ch.GetMessages()
+	if len(msgs) == 0 {
+		return nil, fmt.Errorf("%w: channel %q", ErrNoMessageInChannel, ch.Name)
 	}
-	return nil, fmt.Errorf("%w: channel %q", ErrNoMessageInChannel, ch.Name)
+	return msgs[0], nil
+}
+
+// GetMessages will return the channel messages, sorted by key.
+func (ch Channel) GetMessages() []*Message {
+	messages := ch.Follow().Messages
+
+	keys := make([]string, 0, len(messages))
+	for k := range messages {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	msgs := make([]*Message, 0, len(keys))
+	for _, k := range keys {
+		msgs = append(msgs, messages[k].Follow())
+	}
+	return msgs
 }
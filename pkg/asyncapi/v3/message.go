@@ -53,6 +53,12 @@ type Message struct {
 	Traits        []*MessageTrait        `json:"traits"`
 	Reference     string                 `json:"$ref"`
 
+	// --- Extensions ----------------------------------------------------------
+
+	// ExtMessageKey is the location of the message key, as a runtime
+	// expression (i.e. '$message.payload#/orderId').
+	ExtMessageKey string `json:"x-message-key"`
+
 	// --- Non AsyncAPI fields -------------------------------------------------
 
 	ReferenceTo *Message `json:"-"`
@@ -60,6 +66,11 @@ type Message struct {
 	// CorrelationIDLocation will indicate where the correlation id is
 	// According to: https://www.asyncapi.com/docs/reference/specification/v3.0.0#correlationIdObject
 	CorrelationIDRequired bool `json:"-"`
+
+	// KeyLocation will indicate where the message key is, from the
+	// 'x-message-key' extension or the Kafka message binding 'key'.
+	KeyLocation string `json:"-"`
+	KeyRequired bool   `json:"-"`
 }
 
 // generateMetadata generates metadata for the Message.
@@ -160,7 +171,57 @@ func (msg *Message) setDependencies(spec Specification) error {
 	}
 
 	// Set traits dependencies
-	return msg.setTraitsDependencies(spec)
+	if err := msg.setTraitsDependencies(spec); err != nil {
+		return err
+	}
+
+	// Set content type from specification default if not set, after traits
+	// as they can also set it
+	msg.setDefaultContentType(spec)
+
+	// Set key location, after traits and bindings as they can also set it
+	return msg.setKeyLocation()
+}
+
+func (msg *Message) setDefaultContentType(spec Specification) {
+	if msg.ContentType != "" {
+		return
+	}
+
+	if spec.DefaultContentType != "" {
+		msg.ContentType = spec.DefaultContentType
+	} else {
+		msg.ContentType = extensions.DefaultContentType
+	}
+}
+
+func (msg *Message) setKeyLocation() error {
+	location := msg.ExtMessageKey
+	if location == "" {
+		location = msg.Bindings.KafkaKeyLocation()
+	}
+	if location == "" {
+		return nil
+	}
+
+	if !isMessageLocation(location) {
+		return fmt.Errorf("%w: %q message key should be a location in header or payload (i.e. "+
+			"'$message.payload#/id'), is %q", extensions.ErrAsyncAPI, msg.Name, location)
+	}
+
+	msg.KeyLocation = location
+	keyParent := msg.createTreeUntilLocation(location)
+	path := strings.Split(location, "/")
+	msg.KeyRequired = keyParent.IsFieldRequired(path[len(path)-1])
+
+	return nil
+}
+
+// isMessageLocation checks that the runtime expression is a location in the
+// message header or payload.
+func isMessageLocation(location string) bool {
+	return strings.HasPrefix(location, "$message.header#/") ||
+		strings.HasPrefix(location, "$message.payload#/")
 }
 
 func (msg *Message) setReference(spec Specification) error {
@@ -524,6 +585,11 @@ func (msg *Message) ApplyTrait(mt *MessageTrait, spec Specification) error {
 		msg.ContentType = mt.ContentType
 	}
 
+	// Override key location if not set
+	if msg.ExtMessageKey == "" {
+		msg.ExtMessageKey = mt.ExtMessageKey
+	}
+
 	// Override title if not set
 	if msg.Title == "" {
 		msg.Title = mt.Title
@@ -595,6 +661,11 @@ func (msg *Message) mergePayload(spec Specification, payload *Schema) error {
 	return msg.Headers.MergeWith(spec, *payload)
 }
 
+// HaveKey check that the message have a key location.
+func (msg Message) HaveKey() bool {
+	return msg.Follow().KeyLocation != ""
+}
+
 // HaveCorrelationID check that the message have a correlation ID.
 func (msg Message) HaveCorrelationID() bool {
 	return msg.Follow().CorrelationID.Exists()
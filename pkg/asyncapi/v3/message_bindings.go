@@ -1,5 +1,7 @@
 package asyncapiv3
 
+import "strings"
+
 // MessageBindings is a representation of the corresponding asyncapi object filled
 // from an asyncapi specification that will be used to generate code.
 // Source: https://www.asyncapi.com/docs/reference/specification/v3.0.0#messageBindingsObject
@@ -62,3 +64,32 @@ func (mb *MessageBindings) setDependencies(spec Specification) error {
 
 	return nil
 }
+
+// Follow returns referenced message bindings if specified or the actual ones.
+func (mb *MessageBindings) Follow() *MessageBindings {
+	if mb.ReferenceTo != nil {
+		return mb.ReferenceTo
+	}
+	return mb
+}
+
+// KafkaKeyLocation returns the Kafka message binding 'key' if it is a runtime
+// expression (i.e. '$message.payload#/orderId'), or an empty string otherwise
+// (i.e. if it is the key schema).
+func (mb *MessageBindings) KafkaKeyLocation() string {
+	if mb == nil {
+		return ""
+	}
+
+	binding, ok := mb.Follow().Kafka.(map[string]any)
+	if !ok {
+		return ""
+	}
+
+	key, ok := binding["key"].(string)
+	if !ok || !strings.HasPrefix(key, "$message.") {
+		return ""
+	}
+
+	return key
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/lerenn/asyncapi-codegen/pkg/asyncapi"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
 	"github.com/lerenn/asyncapi-codegen/pkg/utils"
 	"github.com/stretchr/testify/suite"
 )
@@ -161,3 +162,58 @@ func (suite *MessageSuite) TestIsCorrelationIDRequiredWithEmptyMessage() {
 	// Check if true
 	suite.Require().False(msg.isCorrelationIDRequired())
 }
+
+func (suite *MessageSuite) TestSetKeyLocationFromExtension() {
+	// Set message
+	msg := Message{
+		Payload: &Schema{
+			ReferenceTo: utils.ToPointer(Schema{
+				Validations: asyncapi.Validations[Schema]{
+					Required: []string{"orderId"},
+				},
+			}),
+		},
+		ExtMessageKey: "$message.payload#/orderId",
+	}
+
+	// Check the key location
+	suite.Require().NoError(msg.setKeyLocation())
+	suite.Require().Equal("$message.payload#/orderId", msg.KeyLocation)
+	suite.Require().True(msg.KeyRequired)
+	suite.Require().True(msg.HaveKey())
+}
+
+func (suite *MessageSuite) TestSetKeyLocationFromKafkaBinding() {
+	// Set message
+	msg := Message{
+		Bindings: &MessageBindings{
+			Kafka: map[string]any{"key": "$message.header#/accountId"},
+		},
+	}
+
+	// Check the key location
+	suite.Require().NoError(msg.setKeyLocation())
+	suite.Require().Equal("$message.header#/accountId", msg.KeyLocation)
+	suite.Require().False(msg.KeyRequired)
+}
+
+func (suite *MessageSuite) TestSetKeyLocationIgnoresKafkaBindingKeySchema() {
+	// Set message
+	msg := Message{
+		Bindings: &MessageBindings{
+			Kafka: map[string]any{"key": map[string]any{"type": "string"}},
+		},
+	}
+
+	// Check there is no key location
+	suite.Require().NoError(msg.setKeyLocation())
+	suite.Require().False(msg.HaveKey())
+}
+
+func (suite *MessageSuite) TestSetKeyLocationWithInvalidLocation() {
+	// Set message
+	msg := Message{ExtMessageKey: "orderId"}
+
+	// Check the error
+	suite.Require().ErrorIs(msg.setKeyLocation(), extensions.ErrAsyncAPI)
+}
@@ -20,6 +20,12 @@ type MessageTrait struct {
 	Examples      []*MessageExample      `json:"examples"`
 	Reference     string                 `json:"$ref"`
 
+	// --- Extensions ----------------------------------------------------------
+
+	// ExtMessageKey is the location of the message key, as a runtime
+	// expression (i.e. '$message.payload#/orderId').
+	ExtMessageKey string `json:"x-message-key"`
+
 	// --- Non AsyncAPI fields -------------------------------------------------
 
 	ReferenceTo *MessageTrait `json:"-"`
@@ -182,15 +182,58 @@ func (op *Operation) generateReply() {
 	}
 }
 
-// GetMessage will return the operation message.
+// GetMessage will return the first operation message.
 func (op Operation) GetMessage() (*Message, error) {
 	if len(op.Messages) > 0 {
-		return op.Messages[0], nil // TODO: change
+		return op.Messages[0], nil
 	}
 
 	return op.Channel.GetMessage()
 }
 
+// GetMessages will return the operation messages, or the channel messages
+// if the operation doesn't specify them.
+func (op Operation) GetMessages() []*Message {
+	if len(op.Messages) == 0 {
+		return op.Channel.GetMessages()
+	}
+
+	// Follow references until the actual message, as operation messages
+	// reference channel messages that can also be references
+	msgs := make([]*Message, 0, len(op.Messages))
+	for _, m := range op.Messages {
+		for m.ReferenceTo != nil {
+			m = m.ReferenceTo
+		}
+		msgs = append(msgs, m)
+	}
+	return msgs
+}
+
+// HasMultipleMessages returns true if the operation can send or receive
+// different messages.
+func (op Operation) HasMultipleMessages() bool {
+	return len(op.GetMessages()) > 1
+}
+
+// HaveCorrelationID returns true if every message of the operation has a
+// correlation ID at the same location.
+func (op Operation) HaveCorrelationID() bool {
+	msgs := op.GetMessages()
+	if len(msgs) == 0 {
+		return false
+	}
+
+	for _, msg := range msgs {
+		if !msg.HaveCorrelationID() ||
+			msg.Follow().CorrelationID.Location != msgs[0].Follow().CorrelationID.Location {
+			return false
+		}
+	}
+
+	return true
+}
+
 // ApplyTrait applies a trait to the operation.
 func (op *Operation) ApplyTrait(ot *OperationTrait, spec Specification) {
 	// Check operation is not nil
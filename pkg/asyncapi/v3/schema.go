@@ -53,9 +53,10 @@ type Schema struct {
 
 	// --- AsyncAPI specific ---------------------------------------------------
 
-	Description string `json:"description"`
-	Format      string `json:"format"`
-	Default     any    `json:"default"`
+	Description   string `json:"description"`
+	Format        string `json:"format"`
+	Default       any    `json:"default"`
+	Discriminator string `json:"discriminator"`
 
 	Reference string `json:"$ref"`
 
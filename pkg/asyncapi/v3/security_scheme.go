@@ -79,6 +79,14 @@ func (s *SecurityScheme) setDependencies(spec Specification) error {
 	return nil
 }
 
+// Follow returns referenced security scheme if specified or the actual security scheme.
+func (s *SecurityScheme) Follow() *SecurityScheme {
+	if s.ReferenceTo != nil {
+		return s.ReferenceTo
+	}
+	return s
+}
+
 // RemoveDuplicateSecuritySchemes removes the security schemes that have the same
 // name, keeping the first occurrence.
 func RemoveDuplicateSecuritySchemes(securities []*SecurityScheme) []*SecurityScheme {
@@ -47,3 +47,11 @@ func (sv *ServerVariable) setDependencies(spec Specification) error {
 
 	return nil
 }
+
+// Follow returns referenced server variable if specified or the actual server variable.
+func (sv *ServerVariable) Follow() *ServerVariable {
+	if sv.ReferenceTo != nil {
+		return sv.ReferenceTo
+	}
+	return sv
+}
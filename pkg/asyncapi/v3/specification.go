@@ -1,6 +1,8 @@
 package asyncapiv3
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strings"
@@ -17,6 +19,9 @@ const (
 var (
 	// ErrInvalidReference is sent when a reference is invalid.
 	ErrInvalidReference = fmt.Errorf("%w: invalid reference", extensions.ErrAsyncAPI)
+	// ErrSchemaNameCollision is sent when different schemas referenced from
+	// dependencies have the same name, as their definitions would collide.
+	ErrSchemaNameCollision = fmt.Errorf("%w: schema name collision", extensions.ErrAsyncAPI)
 )
 
 // Specification is the asyncapi specification struct that will be used to generate
@@ -39,24 +44,67 @@ type Specification struct {
 	// specificationReferenced is a map of all the outside specifications that
 	// are referenced in this specification.
 	dependencies map[string]*Specification
+	// processed is true when the specification has already been processed.
+	processed bool
+	// dependenciesSet is true when dependencies have already been set, as a
+	// dependency can be shared between several specifications.
+	dependenciesSet bool
+
+	// document is the raw JSON document of the specification, used to get
+	// schemas outside of the AsyncAPI fields (i.e. in a schema file).
+	document []byte
+	// documentSchemas are the schemas that have been referenced outside of
+	// the AsyncAPI fields, by their JSON pointer.
+	documentSchemas map[string]*Schema
+	// dependenciesSchemas are the schemas from dependencies that are referenced
+	// in this specification, by their name.
+	dependenciesSchemas map[string]*Schema
 }
 
 // NewSpecification creates a new Specification struct.
 func NewSpecification() *Specification {
 	return &Specification{
-		Channels:     make(map[string]*Channel),
-		Operations:   make(map[string]*Operation),
-		dependencies: make(map[string]*Specification),
+		Channels:            make(map[string]*Channel),
+		Operations:          make(map[string]*Operation),
+		dependencies:        make(map[string]*Specification),
+		documentSchemas:     make(map[string]*Schema),
+		dependenciesSchemas: make(map[string]*Schema),
 	}
 }
 
+// UnmarshalJSON unmarshals the specification and keeps its raw document.
+func (s *Specification) UnmarshalJSON(data []byte) error {
+	type specification Specification
+	if err := json.Unmarshal(data, (*specification)(s)); err != nil {
+		return err
+	}
+
+	s.document = data
+	return nil
+}
+
 // Process processes the Specification to make it ready for code generation.
+// It does nothing if the Specification has already been processed.
 func (s *Specification) Process() error {
+	if s.processed {
+		return nil
+	}
+
 	if err := s.generateMetadata(); err != nil {
 		return err
 	}
 
-	return s.setDependencies()
+	if err := s.setDependencies(); err != nil {
+		return err
+	}
+
+	// Check that the schemas from dependencies can be generated with their name
+	if _, err := s.DependenciesSchemas(); err != nil {
+		return err
+	}
+
+	s.processed = true
+	return nil
 }
 
 // AddDependency adds a specification dependency to the Specification.
@@ -130,10 +178,11 @@ func (s *Specification) generateMetadata() error {
 //
 //nolint:cyclop // Not necessary to reduce statements
 func (s *Specification) setDependencies() error {
-	// Prevent modification if nil
-	if s == nil {
+	// Prevent modification if nil or if already done
+	if s == nil || s.dependenciesSet {
 		return nil
 	}
+	s.dependenciesSet = true
 
 	// Set dependencies for dependencies
 	for _, spec := range s.dependencies {
@@ -677,8 +726,91 @@ func (s Specification) getDependencyBasedOnRef(ref string) (*Specification, stri
 	return s2, ref, nil
 }
 
-//nolint:funlen,cyclop // Not necessary to reduce statements and cyclop
 func (s Specification) reference(ref string) (any, error) {
+	obj, err := s.referenceObject(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep schemas from dependencies, as their definitions should be generated
+	schema, isSchema := obj.(*Schema)
+	if isSchema && schema != nil && !strings.HasPrefix(ref, "#") && s.dependenciesSchemas != nil {
+		if existing, exists := s.dependenciesSchemas[schema.Name]; exists && !sameDefinition(existing, schema) {
+			return nil, fmt.Errorf("%w: %q (from %q) has the same name as another referenced schema",
+				ErrSchemaNameCollision, schema.Name, ref)
+		}
+		s.dependenciesSchemas[schema.Name] = schema
+	}
+
+	return obj, nil
+}
+
+// DependenciesSchemas returns the schemas from dependencies that are referenced
+// in the specification or in its dependencies, by their name. Schemas from the
+// specification components (or referenced by them) are not returned.
+//
+// An error wrapping ErrSchemaNameCollision is returned if schemas with different
+// definitions have the same name, including a schema from the specification
+// components.
+func (s Specification) DependenciesSchemas() (map[string]*Schema, error) {
+	schemas := make(map[string]*Schema)
+	if err := s.collectDependenciesSchemas(schemas, make(map[*Specification]bool)); err != nil {
+		return nil, err
+	}
+
+	for _, schema := range s.Components.Schemas {
+		existing, exists := schemas[schema.Name]
+		if !exists {
+			continue
+		}
+		// The component can be a reference to the schema from dependencies
+		if !sameDefinition(existing, schema) && !sameDefinition(existing, schema.Follow()) {
+			return nil, fmt.Errorf("%w: %q from dependencies has the same name as a schema from components",
+				ErrSchemaNameCollision, schema.Name)
+		}
+		delete(schemas, schema.Name)
+	}
+
+	return schemas, nil
+}
+
+// sameDefinition returns true if both schemas are the same or have the same
+// definition, in which case generating only one of them is enough.
+func sameDefinition(a, b *Schema) bool {
+	if a == b {
+		return true
+	}
+
+	aJSON, aErr := json.Marshal(a)
+	bJSON, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aJSON, bJSON)
+}
+
+func (s Specification) collectDependenciesSchemas(schemas map[string]*Schema, visited map[*Specification]bool) error {
+	for name, schema := range s.dependenciesSchemas {
+		if existing, exists := schemas[name]; exists && !sameDefinition(existing, schema) {
+			return fmt.Errorf("%w: %q is the name of different schemas from dependencies",
+				ErrSchemaNameCollision, name)
+		}
+		if _, exists := schemas[name]; !exists {
+			schemas[name] = schema
+		}
+	}
+
+	for _, dep := range s.dependencies {
+		if !visited[dep] {
+			visited[dep] = true
+			if err := dep.collectDependenciesSchemas(schemas, visited); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+//nolint:funlen,cyclop // Not necessary to reduce statements and cyclop
+func (s Specification) referenceObject(ref string) (any, error) {
 	// Separate file from path
 	usedSpec, ref, err := s.getDependencyBasedOnRef(ref)
 	if err != nil {
@@ -747,8 +879,42 @@ func (s Specification) reference(ref string) (any, error) {
 			return nil, fmt.Errorf("%w: %q from reference %q is not supported", ErrInvalidReference, refPath[2], ref)
 		}
 	default:
-		return nil, fmt.Errorf("%w: %q from reference %q is not supported", ErrInvalidReference, refPath[0], ref)
+		// Outside of the AsyncAPI fields, it can only be a schema (i.e. in a schema file)
+		return usedSpec.referenceDocumentSchema(refPath)
+	}
+}
+
+// referenceDocumentSchema returns the schema targeted by the JSON pointer in
+// the raw document of the specification. The schema is only created once.
+func (s Specification) referenceDocumentSchema(refPath []string) (*Schema, error) {
+	pointer := strings.Join(refPath, "/")
+	if schema, ok := s.documentSchemas[pointer]; ok {
+		return schema, nil
 	}
+
+	// Get the schema from the document
+	data, err := asyncapi.PointedValue(s.document, refPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidReference, err)
+	}
+
+	schema := NewSchema()
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("%w: %q is not a schema: %w", ErrInvalidReference, pointer, err)
+	}
+
+	// Generate its metadata and set its dependencies from its own specification
+	if err := schema.generateMetadata("", refPath[len(refPath)-1]+"_Schema", nil, false); err != nil {
+		return nil, err
+	}
+	if s.documentSchemas != nil {
+		s.documentSchemas[pointer] = &schema
+	}
+	if err := schema.setDependencies(s); err != nil {
+		return nil, err
+	}
+
+	return &schema, nil
 }
 
 // MajorVersion returns the asyncapi major version of this document.
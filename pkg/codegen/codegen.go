@@ -1,9 +1,12 @@
 package codegen
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime/debug"
+	"sync"
 
 	"github.com/lerenn/asyncapi-codegen/pkg/asyncapi"
 	"github.com/lerenn/asyncapi-codegen/pkg/asyncapi/parser"
@@ -18,6 +21,14 @@ import (
 	"golang.org/x/tools/imports"
 )
 
+// ErrNoOutputPath is returned when generating code without output path nor
+// output directory.
+var ErrNoOutputPath = errors.New("no output path nor output directory")
+
+// generationMutex serializes the generations, as the options are set on the
+// templates helpers that are shared between them.
+var generationMutex sync.Mutex
+
 // CodeGen is the main structure for the code generation.
 type CodeGen struct {
 	specification asyncapi.Specification
@@ -27,9 +38,17 @@ type CodeGen struct {
 
 // FromFile returns a code generator from a specification file path.
 func FromFile(path string, dependencies ...string) (CodeGen, error) {
+	return FromFileWithFetcher(nil, path, dependencies...)
+}
+
+// FromFileWithFetcher returns a code generator from a specification file path,
+// using the fetcher to get the remote files referenced in the specification.
+// If the fetcher is nil, the remote files are fetched with HTTP(S).
+func FromFileWithFetcher(fetcher parser.Fetcher, path string, dependencies ...string) (CodeGen, error) {
 	// Get specification from file
 	spec, err := parser.FromFile(parser.FromFileParams{
-		Path: path,
+		Path:    path,
+		Fetcher: fetcher,
 	})
 	if err != nil {
 		return CodeGen{}, err
@@ -40,6 +59,7 @@ func FromFile(path string, dependencies ...string) (CodeGen, error) {
 		dep, err := parser.FromFile(parser.FromFileParams{
 			Path:         path,
 			MajorVersion: spec.MajorVersion(),
+			Fetcher:      fetcher,
 		})
 		if err != nil {
 			return CodeGen{}, err
@@ -81,62 +101,127 @@ func modulePathVersion() (path, version string) {
 }
 
 // Generate generates code from the code generation structure, that have already
-// processed the AsyncAPI file when creating it.
+// processed the AsyncAPI file when creating it, and writes it in OutputPath or,
+// if set, in separate files in OutputDir.
 func (cg CodeGen) Generate(opt options.Options) error {
-	if err := template.SetConvertKeyFn(opt.ConvertKeys); err != nil {
+	files, err := cg.GenerateFiles(opt)
+	if err != nil {
 		return err
 	}
 
-	if err := template.SetNamifyFn(opt.NamingScheme); err != nil {
+	// Write to single file
+	if opt.OutputDir == "" {
+		return os.WriteFile(opt.OutputPath, files[filepath.Base(opt.OutputPath)], 0644)
+	}
+
+	// Write to files in directory
+	if err := os.MkdirAll(opt.OutputDir, 0755); err != nil {
 		return err
 	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(opt.OutputDir, name), content, 0644); err != nil {
+			return err
+		}
+	}
 
-	if opt.IgnoreStringFormat {
-		template.DisableDateOrTimeGeneration()
+	return nil
+}
+
+// GenerateFiles generates code from the code generation structure, that have
+// already processed the AsyncAPI file when creating it, without writing it.
+//
+// It returns the content of each generated file by its name: one file for each
+// generated part (i.e. 'app.gen.go', 'types.gen.go') if OutputDir is set,
+// otherwise a single file named after OutputPath.
+//
+// It can be called concurrently, but the generations are executed one at a
+// time, as the options are set on templates helpers shared between them.
+func (cg CodeGen) GenerateFiles(opt options.Options) (map[string][]byte, error) {
+	if opt.OutputDir == "" && opt.OutputPath == "" {
+		return nil, ErrNoOutputPath
 	}
-	if opt.ForcePointers {
-		templatesv2.ForcePointerOnFields()
-		templatesv3.ForcePointerOnFields()
+
+	generationMutex.Lock()
+	defer generationMutex.Unlock()
+
+	if err := template.SetConvertKeyFn(opt.ConvertKeys); err != nil {
+		return nil, err
 	}
 
+	if err := template.SetNamifyFn(opt.NamingScheme); err != nil {
+		return nil, err
+	}
+
+	// Set every option, as they are kept from a generation to another
+	template.SetDateOrDateTimeGeneration(!opt.IgnoreStringFormat)
+	templatesv2.SetForcePointerOnFields(opt.ForcePointers)
+	templatesv3.SetForcePointerOnFields(opt.ForcePointers)
+
 	// Process specification
 	if err := cg.specification.Process(); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Generate content
-	content, err := cg.generateContent(opt)
+	contents, err := cg.generateContents(opt)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Format content if not disabled
-	var fileContent []byte
-	if !opt.DisableFormatting {
-		fileContent, err = imports.Process("", []byte(content), &imports.Options{
+	files := make(map[string][]byte, len(contents))
+	for name, content := range contents {
+		if opt.DisableFormatting {
+			files[name] = []byte(content)
+			continue
+		}
+
+		files[name], err = imports.Process("", []byte(content), &imports.Options{
 			TabWidth:  8,
 			TabIndent: true,
 			Comments:  true,
 			Fragment:  true,
 		})
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("%s: %w", name, err)
 		}
-	} else {
-		fileContent = []byte(content)
 	}
 
-	// Write to file
-	return os.WriteFile(opt.OutputPath, fileContent, 0644)
+	return files, nil
+}
+
+// generator is a code generator for a major version of AsyncAPI.
+type generator interface {
+	Generate() (string, error)
+	GenerateFiles() (map[string]string, error)
+}
+
+func (cg CodeGen) generateContents(opt options.Options) (map[string]string, error) {
+	gen, err := cg.generator(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate separate files
+	if opt.OutputDir != "" {
+		return gen.GenerateFiles()
+	}
+
+	// Generate single file
+	content, err := gen.Generate()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{filepath.Base(opt.OutputPath): content}, nil
 }
 
-func (cg CodeGen) generateContent(opt options.Options) (string, error) {
+func (cg CodeGen) generator(opt options.Options) (generator, error) {
 	version := cg.specification.MajorVersion()
 	switch version {
 	case 2:
 		spec, err := asyncapiv2.FromUnknownVersion(cg.specification)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
 		return generatorv2.Generator{
@@ -144,11 +229,11 @@ func (cg CodeGen) generateContent(opt options.Options) (string, error) {
 			Options:       opt,
 			ModulePath:    cg.modulePath,
 			ModuleVersion: cg.moduleVersion,
-		}.Generate()
+		}, nil
 	case 3:
 		spec, err := asyncapiv3.FromUnknownVersion(cg.specification)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
 		return generatorv3.Generator{
@@ -156,8 +241,8 @@ func (cg CodeGen) generateContent(opt options.Options) (string, error) {
 			Options:       opt,
 			ModulePath:    cg.modulePath,
 			ModuleVersion: cg.moduleVersion,
-		}.Generate()
+		}, nil
 	default:
-		return "", fmt.Errorf("unsupported major version (%q)", version)
+		return nil, fmt.Errorf("unsupported major version (%q)", version)
 	}
 }
@@ -2,6 +2,8 @@ package generators
 
 import (
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 
 	"github.com/lerenn/asyncapi-codegen/pkg/asyncapi"
@@ -15,6 +17,13 @@ func appendDirectiveIfDefined(directives []string, tagName string, value float64
 	return directives
 }
 
+func appendDirectiveIfSet(directives []string, tagName string, value *float64) []string {
+	if value != nil {
+		return append(directives, fmt.Sprintf("%s=%g", tagName, *value))
+	}
+	return directives
+}
+
 // GenerateJSONTags returns the "json" tag for a given field in a struct, based on the asyncapi contract.
 func GenerateJSONTags[T any](schema asyncapi.Validations[T], field string) string {
 	directives := []string{
@@ -38,10 +47,10 @@ func GenerateValidateTags[T any](schema asyncapi.Validations[T], isPointer bool,
 
 	directives = appendDirectiveIfDefined(directives, "min", float64(schema.MinLength))
 	directives = appendDirectiveIfDefined(directives, "max", float64(schema.MaxLength))
-	directives = appendDirectiveIfDefined(directives, "gte", schema.Minimum)
-	directives = appendDirectiveIfDefined(directives, "lte", schema.Maximum)
-	directives = appendDirectiveIfDefined(directives, "gt", schema.ExclusiveMinimum)
-	directives = appendDirectiveIfDefined(directives, "lt", schema.ExclusiveMaximum)
+	directives = appendDirectiveIfSet(directives, "gte", schema.Minimum)
+	directives = appendDirectiveIfSet(directives, "lte", schema.Maximum)
+	directives = appendDirectiveIfSet(directives, "gt", schema.ExclusiveMinimum)
+	directives = appendDirectiveIfSet(directives, "lt", schema.ExclusiveMaximum)
 
 	if schema.UniqueItems {
 		directives = append(directives, "unique")
@@ -82,3 +91,52 @@ func appendEnumDirectives[T any](schema asyncapi.Validations[T], directives []st
 	}
 	return directives
 }
+
+// GenerateEnumLiterals returns the comma separated Go literals of the enum
+// values that match the schema type, to be used in generated validation code.
+func GenerateEnumLiterals[T any](schema asyncapi.Validations[T], schemaType string) string {
+	literals := make([]string, 0, len(schema.Enum))
+	for _, e := range schema.Enum {
+		if l, ok := goLiteral(e, schemaType); ok {
+			literals = append(literals, l)
+		}
+	}
+	return strings.Join(literals, ", ")
+}
+
+// GenerateConstLiteral returns the Go literal of the const value if it
+// matches the schema type, or an empty string otherwise.
+func GenerateConstLiteral[T any](schema asyncapi.Validations[T], schemaType string) string {
+	if schema.Const == nil {
+		return ""
+	}
+
+	l, _ := goLiteral(schema.Const, schemaType)
+	return l
+}
+
+func goLiteral(value any, schemaType string) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		if schemaType == "string" {
+			return strconv.Quote(v), true
+		}
+	case bool:
+		if schemaType == "boolean" {
+			return strconv.FormatBool(v), true
+		}
+	case float64:
+		switch {
+		case schemaType == "number":
+			return strconv.FormatFloat(v, 'g', -1, 64), true
+		case schemaType == "integer" && v == math.Trunc(v):
+			return strconv.FormatInt(int64(v), 10), true
+		}
+	case int:
+		if schemaType == "integer" || schemaType == "number" {
+			return strconv.Itoa(v), true
+		}
+	}
+
+	return "", false
+}
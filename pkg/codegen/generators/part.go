@@ -0,0 +1,18 @@
+package generators
+
+// File names of the generated parts, when they are generated in separate files.
+const (
+	ApplicationFileName = "app.gen.go"
+	UserFileName        = "user.gen.go"
+	TypesFileName       = "types.gen.go"
+	MocksFileName       = "mocks.gen.go"
+	ServersFileName     = "servers.gen.go"
+)
+
+// Part is a part of the generated code (i.e. application, user, types).
+type Part struct {
+	// FileName is the name of the file of the part, when generated in separate files
+	FileName string
+	// Content is the generated code of the part, without package and imports
+	Content string
+}
@@ -86,6 +86,7 @@ func (asg ControllerGenerator) Generate() (string, error) {
 		schemaDefinitionTemplatePath,
 		schemaNameTemplatePath,
 		messageTemplatePath,
+		validationTemplatePath,
 	)
 	if err != nil {
 		return "", err
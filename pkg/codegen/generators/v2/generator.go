@@ -24,29 +24,65 @@ func (g Generator) Generate() (string, error) {
 		return "", err
 	}
 
-	for remainingParts, part := true, ""; remainingParts; part = "" {
-		switch {
-		case g.Options.Generate.Application:
-			part, err = g.generateApp()
-			g.Options.Generate.Application = false
-		case g.Options.Generate.User:
-			part, err = g.generateUser()
-			g.Options.Generate.User = false
-		case g.Options.Generate.Types:
-			part, err = g.generateTypes()
-			g.Options.Generate.Types = false
-		default:
-			remainingParts = false
+	parts, err := g.generateParts()
+	if err != nil {
+		return "", err
+	}
+
+	for _, part := range parts {
+		content += part.Content
+	}
+
+	return content, nil
+}
+
+// GenerateFiles generates the source code from the specification, with each
+// part in a separate file (i.e. 'app.gen.go', 'types.gen.go'). It returns the
+// content of each file by its name.
+func (g Generator) GenerateFiles() (map[string]string, error) {
+	imports, err := g.generateImports(g.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	parts, err := g.generateParts()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string, len(parts))
+	for _, part := range parts {
+		files[part.FileName] = imports + part.Content
+	}
+
+	return files, nil
+}
+
+func (g Generator) generateParts() ([]generators.Part, error) {
+	parts := make([]generators.Part, 0, 4)
+	for _, p := range []struct {
+		enabled  bool
+		fileName string
+		generate func() (string, error)
+	}{
+		{enabled: g.Options.Generate.Application, fileName: generators.ApplicationFileName, generate: g.generateApp},
+		{enabled: g.Options.Generate.User, fileName: generators.UserFileName, generate: g.generateUser},
+		{enabled: g.Options.Generate.Types, fileName: generators.TypesFileName, generate: g.generateTypes},
+		{enabled: g.Options.Generate.Mocks, fileName: generators.MocksFileName, generate: g.generateMocks},
+	} {
+		if !p.enabled {
+			continue
 		}
 
+		content, err := p.generate()
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
-		content += part
+		parts = append(parts, generators.Part{FileName: p.fileName, Content: content})
 	}
 
-	return content, nil
+	return parts, nil
 }
 
 func (g Generator) generateImports(opts options.Options) (string, error) {
@@ -117,3 +153,27 @@ func (g Generator) generateUser() (string, error) {
 
 	return content, nil
 }
+
+func (g Generator) generateMocks() (string, error) {
+	var content string
+
+	for _, side := range []struct {
+		enabled bool
+		side    generators.Side
+	}{
+		{enabled: g.Options.Generate.Application, side: generators.SideIsApplication},
+		{enabled: g.Options.Generate.User, side: generators.SideIsUser},
+	} {
+		if !side.enabled {
+			continue
+		}
+
+		mocks, err := NewMocksGenerator(side.side, g.Specification).Generate()
+		if err != nil {
+			return "", err
+		}
+		content += mocks
+	}
+
+	return content, nil
+}
@@ -0,0 +1,36 @@
+package generatorv2
+
+import (
+	"bytes"
+
+	asyncapi "github.com/lerenn/asyncapi-codegen/pkg/asyncapi/v2"
+	"github.com/lerenn/asyncapi-codegen/pkg/codegen/generators"
+)
+
+// MocksGenerator is a code generator for controllers interfaces and fakes that
+// can replace the generated controllers in tests.
+type MocksGenerator struct {
+	ControllerGenerator
+}
+
+// NewMocksGenerator will create a new mocks code generator.
+func NewMocksGenerator(side generators.Side, spec asyncapi.Specification) MocksGenerator {
+	return MocksGenerator{
+		ControllerGenerator: NewControllerGenerator(side, spec),
+	}
+}
+
+// Generate will generate the mocks code.
+func (mg MocksGenerator) Generate() (string, error) {
+	tmplt, err := loadTemplate(mocksTemplatePath)
+	if err != nil {
+		return "", err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := tmplt.Execute(buf, mg); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
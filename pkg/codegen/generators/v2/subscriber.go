@@ -55,6 +55,7 @@ func (asg SubscriberGenerator) Generate() (string, error) {
 		schemaDefinitionTemplatePath,
 		schemaNameTemplatePath,
 		messageTemplatePath,
+		validationTemplatePath,
 	)
 	if err != nil {
 		return "", err
@@ -18,8 +18,10 @@ const (
 	schemaDefinitionTemplatePath = templatesDir + "/schema_definition.tmpl"
 	schemaNameTemplatePath       = templatesDir + "/schema_name.tmpl"
 	messageTemplatePath          = templatesDir + "/message.tmpl"
+	validationTemplatePath       = templatesDir + "/validation.tmpl"
 	subscriberTemplatePath       = templatesDir + "/subscriber.tmpl"
 	controllerTemplatePath       = templatesDir + "/controller.tmpl"
+	mocksTemplatePath            = templatesDir + "/mocks.tmpl"
 	parameterTemplatePath        = templatesDir + "/parameter.tmpl"
 
 	marshalingTemplatesDir                     = templatesDir + "/marshaling"
@@ -3,6 +3,7 @@ package templates
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -141,14 +142,112 @@ func OperationName(channel asyncapi.Channel) string {
 	return templateutil.Namify(name)
 }
 
-var isFieldPointer = func(parent asyncapi.Schema, field string, schema asyncapi.Schema) bool {
-	return !(IsRequired(parent, field) || schema.IsRequired) && schema.Type != "array"
+var forcePointerOnFields bool
+
+// SetForcePointerOnFields sets whether all fields are generated as pointers,
+// except for arrays, or only the fields that are not required.
+func SetForcePointerOnFields(force bool) {
+	forcePointerOnFields = force
+}
+
+func isFieldPointer(parent asyncapi.Schema, field string, schema asyncapi.Schema) bool {
+	if schema.Type == "array" {
+		return false
+	}
+	return forcePointerOnFields || !(IsRequired(parent, field) || schema.IsRequired)
+}
+
+// ValidationValue is a value checked by a generated Validate method.
+type ValidationValue struct {
+	// Schema is the schema of the value.
+	Schema *asyncapi.Schema
+	// Expr is the Go expression to access the value.
+	Expr string
+	// Field is the Go expression of the field name used in validation errors.
+	Field string
+	// IsPointer is set if the value is a pointer.
+	IsPointer bool
+	// IsRequired is set if the value should be present.
+	IsRequired bool
+	// Depth is the depth of the value in arrays, used to name loop variables.
+	Depth int
+}
+
+// Deref returns the value pointed by the value.
+func (v ValidationValue) Deref() ValidationValue {
+	v.Expr = "(*" + v.Expr + ")"
+	v.IsPointer = false
+	return v
+}
+
+// Index returns the name of the index variable when iterating on the value.
+func (v ValidationValue) Index() string {
+	return fmt.Sprintf("i%d", v.Depth)
+}
+
+// Items returns the value of the items when iterating on the value.
+func (v ValidationValue) Items() ValidationValue {
+	return ValidationValue{
+		Schema: v.Schema.Items,
+		Expr:   fmt.Sprintf("item%d", v.Depth),
+		Field:  fmt.Sprintf(`fmt.Sprintf("%%s[%%d]", %s, %s)`, v.Field, v.Index()),
+		Depth:  v.Depth + 1,
+	}
 }
 
-// ForcePointerOnFields is used to force the generation of all fields as pointers, except for arrays.
-func ForcePointerOnFields() {
-	isFieldPointer = func(parent asyncapi.Schema, field string, schema asyncapi.Schema) bool {
-		return schema.Type != "array"
+// ValidationRoot returns the value to validate from a Go expression and its field name.
+func ValidationRoot(schema *asyncapi.Schema, expr, field string) ValidationValue {
+	return ValidationValue{
+		Schema: schema,
+		Expr:   expr,
+		Field:  strconv.Quote(field),
+	}
+}
+
+// ValidationProperty returns the value to validate from an object property.
+func ValidationProperty(parent asyncapi.Schema, key string, schema *asyncapi.Schema) ValidationValue {
+	return ValidationValue{
+		Schema:     schema,
+		Expr:       "s." + templateutil.Namify(key),
+		Field:      strconv.Quote(templateutil.ConvertKey(key)),
+		IsPointer:  isFieldPointer(parent, key, *schema),
+		IsRequired: IsRequired(parent, key) || schema.IsRequired,
+	}
+}
+
+// NeedsValidation returns true if the generated code for the schema needs to
+// be validated, either with constraints or with a Validate method.
+//
+//nolint:cyclop // Not necessary to reduce statements
+func NeedsValidation(schema *asyncapi.Schema) bool {
+	if schema == nil || schema.ExtGoType != "" {
+		return false
+	}
+
+	v := schema.Validations
+	hasValues := generators.GenerateEnumLiterals(v, schema.Type) != "" ||
+		generators.GenerateConstLiteral(v, schema.Type) != ""
+
+	switch schema.Type {
+	case "object":
+		return true
+	case "array":
+		return v.MinItems != 0 || v.MaxItems != 0 || v.UniqueItems || NeedsValidation(schema.Items)
+	case "string":
+		if templateutil.IsDateOrDateTimeGenerated(schema.Format) {
+			return false
+		}
+		return v.MinLength != 0 || v.MaxLength != 0 || hasValues ||
+			(v.Pattern != "" && templateutil.IsPatternSupported(v.Pattern))
+	case "integer", "number":
+		return v.Minimum != nil || v.Maximum != nil || v.ExclusiveMinimum != nil ||
+			v.ExclusiveMaximum != nil || v.MultipleOf != nil || hasValues
+	case "boolean":
+		return hasValues
+	case "":
+		return schema.ReferenceTo != nil
+	default:
+		return false
 	}
 }
 
@@ -166,5 +265,10 @@ func HelpersFunctions() template.FuncMap {
 		"referenceToTypeName":            ReferenceToTypeName,
 		"generateValidateTags":           generators.GenerateValidateTags[asyncapi.Schema],
 		"generateJSONTags":               generators.GenerateJSONTags[asyncapi.Schema],
+		"generateEnumLiterals":           generators.GenerateEnumLiterals[asyncapi.Schema],
+		"generateConstLiteral":           generators.GenerateConstLiteral[asyncapi.Schema],
+		"validationRoot":                 ValidationRoot,
+		"validationProperty":             ValidationProperty,
+		"needsValidation":                NeedsValidation,
 	}
 }
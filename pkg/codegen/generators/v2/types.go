@@ -19,6 +19,7 @@ func (tg TypesGenerator) Generate() (string, error) {
 		schemaDefinitionTemplatePath,
 		schemaNameTemplatePath,
 		messageTemplatePath,
+		validationTemplatePath,
 		parameterTemplatePath,
 
 		marshalingAdditionalPropertiesTemplatePath,
@@ -0,0 +1,28 @@
+package generatorv3
+
+import (
+	"bytes"
+
+	asyncapi "github.com/lerenn/asyncapi-codegen/pkg/asyncapi/v3"
+)
+
+// ServersGenerator is a code generator for the factory creating broker
+// controllers from the servers of an asyncapi specification.
+type ServersGenerator struct {
+	asyncapi.Specification
+}
+
+// Generate will generate the servers code.
+func (sg ServersGenerator) Generate() (string, error) {
+	tmplt, err := loadTemplate(serversTemplatePath)
+	if err != nil {
+		return "", err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := tmplt.ExecuteTemplate(buf, "servers-factory", sg); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
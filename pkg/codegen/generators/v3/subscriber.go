@@ -38,6 +38,7 @@ func (asg SubscriberGenerator) Generate() (string, error) {
 		schemaDefinitionTemplatePath,
 		schemaNameTemplatePath,
 		messageTemplatePath,
+		validationTemplatePath,
 	)
 	if err != nil {
 		return "", err
@@ -18,8 +18,11 @@ const (
 	schemaDefinitionTemplatePath = templatesDir + "/schema_definition.tmpl"
 	schemaNameTemplatePath       = templatesDir + "/schema_name.tmpl"
 	messageTemplatePath          = templatesDir + "/message.tmpl"
+	validationTemplatePath       = templatesDir + "/validation.tmpl"
+	serversTemplatePath          = templatesDir + "/servers.tmpl"
 	subscriberTemplatePath       = templatesDir + "/subscriber.tmpl"
 	controllerTemplatePath       = templatesDir + "/controller.tmpl"
+	mocksTemplatePath            = templatesDir + "/mocks.tmpl"
 
 	marshalingTemplatesDir                     = templatesDir + "/marshaling"
 	marshalingAdditionalPropertiesTemplatePath = marshalingTemplatesDir + "/additional_properties.tmpl"
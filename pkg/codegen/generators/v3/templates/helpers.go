@@ -3,11 +3,14 @@ package templates
 import (
 	"fmt"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"text/template"
 
 	asyncapi "github.com/lerenn/asyncapi-codegen/pkg/asyncapi/v3"
 	"github.com/lerenn/asyncapi-codegen/pkg/codegen/generators"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
 	"github.com/lerenn/asyncapi-codegen/pkg/utils"
 	templateutil "github.com/lerenn/asyncapi-codegen/pkg/utils/template"
 )
@@ -78,7 +81,14 @@ func ChannelToMessageTypeName(ch asyncapi.Channel) string {
 
 // OpToMsgTypeName will convert an operation to a message type name in the
 // form of golang conventional type names.
+//
+// If the operation has multiple messages, this will be the name of the type
+// grouping all of them.
 func OpToMsgTypeName(op asyncapi.Operation) string {
+	if op.Follow().HasMultipleMessages() {
+		return templateutil.Namify(op.Follow().Name) + "Message"
+	}
+
 	msg, err := op.Follow().GetMessage()
 	if err != nil {
 		panic(err)
@@ -86,6 +96,112 @@ func OpToMsgTypeName(op asyncapi.Operation) string {
 	return templateutil.Namify(msg.Follow().Name)
 }
 
+// MessageName returns the name of the message used to identify it among
+// the messages of an operation (i.e. in extensions.MessageNameHeader header).
+func MessageName(msg asyncapi.Message) string {
+	return strings.TrimSuffix(templateutil.Namify(msg.Follow().Name), "Message")
+}
+
+// ErrIndistinguishableMessages is returned when the messages of an operation
+// can't be told apart from their payload.
+var ErrIndistinguishableMessages = fmt.Errorf("%w: messages can't be told apart", extensions.ErrAsyncAPI)
+
+// OpToDiscriminator returns the payload field used to discriminate the
+// messages of the operation: the discriminator of the payloads, or else a
+// property having a constant in every payload. It returns an empty string if
+// there is none, as the messages can then only be told apart from the
+// extensions.MessageNameHeader header.
+//
+// It returns an error if two messages share the same value, as the received
+// messages couldn't be told apart.
+func OpToDiscriminator(op asyncapi.Operation) (string, error) {
+	msgs := op.Follow().GetMessages()
+
+	field, err := payloadsDiscriminator(msgs)
+	if err != nil {
+		return "", fmt.Errorf("%w on '%s' operation", err, op.Name)
+	} else if field == "" {
+		field = constantDiscriminator(msgs)
+	}
+	if field == "" {
+		return "", nil
+	}
+
+	// Check that each message has its own value
+	names := make(map[string]string, len(msgs))
+	for _, msg := range msgs {
+		value := DiscriminatorValue(*msg, field)
+		if name, exists := names[value]; exists {
+			return "", fmt.Errorf("%w on '%s' operation: %q and %q have the same '%s' value %q",
+				ErrIndistinguishableMessages, op.Name, name, MessageName(*msg), field, value)
+		}
+		names[value] = MessageName(*msg)
+	}
+
+	return field, nil
+}
+
+// payloadsDiscriminator returns the discriminator set on the messages payloads,
+// or an empty string if there is none.
+func payloadsDiscriminator(msgs []*asyncapi.Message) (string, error) {
+	var field string
+	for _, msg := range msgs {
+		if msg.Payload == nil || msg.Payload.Follow().Discriminator == "" {
+			continue
+		}
+
+		d := msg.Payload.Follow().Discriminator
+		if field != "" && field != d {
+			return "", fmt.Errorf("%w: different discriminators '%s' and '%s'", ErrIndistinguishableMessages, field, d)
+		}
+		field = d
+	}
+	return field, nil
+}
+
+// constantDiscriminator returns the first property (in alphabetical order)
+// having a constant in every message payload, or an empty string if there is
+// none.
+func constantDiscriminator(msgs []*asyncapi.Message) string {
+	if len(msgs) == 0 || msgs[0].Payload == nil {
+		return ""
+	}
+
+	fields := make([]string, 0, len(msgs[0].Payload.Follow().Properties))
+	for field := range msgs[0].Payload.Follow().Properties {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+
+	for _, field := range fields {
+		if slices.IndexFunc(msgs, func(msg *asyncapi.Message) bool {
+			if msg.Payload == nil {
+				return true
+			}
+			prop, ok := msg.Payload.Follow().Properties[field]
+			return !ok || prop.Follow().Const == nil
+		}) == -1 {
+			return field
+		}
+	}
+
+	return ""
+}
+
+// DiscriminatorValue returns the value of the discriminator field identifying
+// the message: the constant set on this field in the message payload, or the
+// message name.
+func DiscriminatorValue(msg asyncapi.Message, field string) string {
+	if p := msg.Payload; p != nil {
+		if prop, ok := p.Follow().Properties[field]; ok {
+			if c := prop.Follow().Const; c != nil {
+				return fmt.Sprint(c)
+			}
+		}
+	}
+	return MessageName(msg)
+}
+
 // OpToChannelTypeName will convert an operation to a channel type name in the
 // form of golang conventional type names.
 func OpToChannelTypeName(op asyncapi.Operation) string {
@@ -127,15 +243,170 @@ func GenerateChannelAddr(ch *asyncapi.Channel) string {
 	return sprint[:len(sprint)-1] + ")"
 }
 
-var isFieldPointer = func(parent asyncapi.Schema, field string, schema asyncapi.Schema) bool {
-	return !(IsRequired(parent, field) || schema.IsRequired) && schema.Type != "array"
+var forcePointerOnFields bool
+
+// SetForcePointerOnFields sets whether all fields are generated as pointers,
+// except for arrays, or only the fields that are not required.
+func SetForcePointerOnFields(force bool) {
+	forcePointerOnFields = force
+}
+
+func isFieldPointer(parent asyncapi.Schema, field string, schema asyncapi.Schema) bool {
+	if schema.Type == "array" {
+		return false
+	}
+	return forcePointerOnFields || !(IsRequired(parent, field) || schema.IsRequired)
+}
+
+// ValidationValue is a value checked by a generated Validate method.
+type ValidationValue struct {
+	// Schema is the schema of the value.
+	Schema *asyncapi.Schema
+	// Expr is the Go expression to access the value.
+	Expr string
+	// Field is the Go expression of the field name used in validation errors.
+	Field string
+	// IsPointer is set if the value is a pointer.
+	IsPointer bool
+	// IsRequired is set if the value should be present.
+	IsRequired bool
+	// Depth is the depth of the value in arrays, used to name loop variables.
+	Depth int
+}
+
+// Deref returns the value pointed by the value.
+func (v ValidationValue) Deref() ValidationValue {
+	v.Expr = "(*" + v.Expr + ")"
+	v.IsPointer = false
+	return v
+}
+
+// Index returns the name of the index variable when iterating on the value.
+func (v ValidationValue) Index() string {
+	return fmt.Sprintf("i%d", v.Depth)
+}
+
+// Items returns the value of the items when iterating on the value.
+func (v ValidationValue) Items() ValidationValue {
+	return ValidationValue{
+		Schema: v.Schema.Items,
+		Expr:   fmt.Sprintf("item%d", v.Depth),
+		Field:  fmt.Sprintf(`fmt.Sprintf("%%s[%%d]", %s, %s)`, v.Field, v.Index()),
+		Depth:  v.Depth + 1,
+	}
+}
+
+// ValidationRoot returns the value to validate from a Go expression and its field name.
+func ValidationRoot(schema *asyncapi.Schema, expr, field string) ValidationValue {
+	return ValidationValue{
+		Schema: schema,
+		Expr:   expr,
+		Field:  strconv.Quote(field),
+	}
+}
+
+// ValidationProperty returns the value to validate from an object property.
+func ValidationProperty(parent asyncapi.Schema, key string, schema *asyncapi.Schema) ValidationValue {
+	return ValidationValue{
+		Schema:     schema,
+		Expr:       "s." + templateutil.Namify(key),
+		Field:      strconv.Quote(templateutil.ConvertKey(key)),
+		IsPointer:  isFieldPointer(parent, key, *schema),
+		IsRequired: IsRequired(parent, key) || schema.IsRequired,
+	}
+}
+
+// NeedsValidation returns true if the generated code for the schema needs to
+// be validated, either with constraints or with a Validate method.
+//
+//nolint:cyclop // Not necessary to reduce statements
+func NeedsValidation(schema *asyncapi.Schema) bool {
+	if schema == nil || schema.ExtGoType != "" {
+		return false
+	}
+
+	v := schema.Validations
+	hasValues := generators.GenerateEnumLiterals(v, schema.Type) != "" ||
+		generators.GenerateConstLiteral(v, schema.Type) != ""
+
+	switch schema.Type {
+	case "object":
+		return true
+	case "array":
+		return v.MinItems != 0 || v.MaxItems != 0 || v.UniqueItems || NeedsValidation(schema.Items)
+	case "string":
+		if templateutil.IsDateOrDateTimeGenerated(schema.Format) {
+			return false
+		}
+		return v.MinLength != 0 || v.MaxLength != 0 || hasValues ||
+			(v.Pattern != "" && templateutil.IsPatternSupported(v.Pattern))
+	case "integer", "number":
+		return v.Minimum != nil || v.Maximum != nil || v.ExclusiveMinimum != nil ||
+			v.ExclusiveMaximum != nil || v.MultipleOf != nil || hasValues
+	case "boolean":
+		return hasValues
+	case "":
+		return schema.ReferenceTo != nil
+	default:
+		return false
+	}
+}
+
+// serverProtocolsBrokers are the brokers packages (in 'pkg/extensions/brokers')
+// that can create a controller for a server, by server protocol.
+var serverProtocolsBrokers = map[string]string{
+	"amqp":         "amqp",
+	"amqps":        "amqp",
+	"kafka":        "kafka",
+	"kafka-secure": "kafka",
+	"mqtt":         "mqtt",
+	"mqtt5":        "mqtt",
+	"mqtts":        "mqtt",
+	"secure-mqtt":  "mqtt",
+	"nats":         "nats",
+	"redis":        "redis",
+}
+
+// ServerBroker is a broker package that can create a controller for servers
+// with one of the protocols.
+type ServerBroker struct {
+	Package   string
+	Protocols []string
+}
+
+// ServersBrokers returns the brokers packages that can create a controller for
+// the servers, with the servers protocols, sorted by package.
+func ServersBrokers(servers map[string]*asyncapi.Server) []ServerBroker {
+	protocols := make(map[string][]string)
+	for _, srv := range servers {
+		protocol := srv.Follow().Protocol
+		pkg, supported := serverProtocolsBrokers[protocol]
+		if !supported || slices.Contains(protocols[pkg], protocol) {
+			continue
+		}
+		protocols[pkg] = append(protocols[pkg], protocol)
+	}
+
+	brokers := make([]ServerBroker, 0, len(protocols))
+	for pkg, p := range protocols {
+		slices.Sort(p)
+		brokers = append(brokers, ServerBroker{Package: pkg, Protocols: p})
+	}
+	slices.SortFunc(brokers, func(a, b ServerBroker) int {
+		return strings.Compare(a.Package, b.Package)
+	})
+
+	return brokers
 }
 
-// ForcePointerOnFields is used to force the generation of all fields as pointers, except for arrays.
-func ForcePointerOnFields() {
-	isFieldPointer = func(parent asyncapi.Schema, field string, schema asyncapi.Schema) bool {
-		return schema.Type != "array"
+// SecuritySchemeName returns the name of a security scheme in the specification
+// components if it is a reference, or its type otherwise.
+func SecuritySchemeName(s *asyncapi.SecurityScheme) string {
+	if s.Reference != "" {
+		path := strings.Split(s.Reference, "/")
+		return path[len(path)-1]
 	}
+	return s.Type
 }
 
 // HelpersFunctions returns the functions that can be used as helpers
@@ -146,6 +417,9 @@ func HelpersFunctions() template.FuncMap {
 		"channelToMessageTypeName":       ChannelToMessageTypeName,
 		"opToMsgTypeName":                OpToMsgTypeName,
 		"opToChannelTypeName":            OpToChannelTypeName,
+		"opToDiscriminator":              OpToDiscriminator,
+		"messageName":                    MessageName,
+		"discriminatorValue":             DiscriminatorValue,
 		"isRequired":                     IsRequired,
 		"isFieldPointer":                 isFieldPointer,
 		"generateChannelAddr":            GenerateChannelAddr,
@@ -153,5 +427,12 @@ func HelpersFunctions() template.FuncMap {
 		"referenceToStructAttributePath": ReferenceToStructAttributePath,
 		"generateValidateTags":           generators.GenerateValidateTags[asyncapi.Schema],
 		"generateJSONTags":               generators.GenerateJSONTags[asyncapi.Schema],
+		"generateEnumLiterals":           generators.GenerateEnumLiterals[asyncapi.Schema],
+		"generateConstLiteral":           generators.GenerateConstLiteral[asyncapi.Schema],
+		"validationRoot":                 ValidationRoot,
+		"validationProperty":             ValidationProperty,
+		"needsValidation":                NeedsValidation,
+		"serversBrokers":                 ServersBrokers,
+		"securitySchemeName":             SecuritySchemeName,
 	}
 }
@@ -52,3 +52,90 @@ func (suite *HelpersSuite) TestIsRequired() {
 func (suite *HelpersSuite) TestGetChildrenObjectSchemas() {
 	// TODO
 }
+
+func (suite *HelpersSuite) TestServersBrokers() {
+	servers := map[string]*asyncapiv3.Server{
+		"production": {Protocol: "kafka-secure"},
+		"staging":    {Protocol: "kafka"},
+		"local":      {Protocol: "kafka"},
+		"events":     {Protocol: "nats"},
+		"unknown":    {Protocol: "ws"},
+	}
+
+	suite.Require().Equal([]ServerBroker{
+		{Package: "kafka", Protocols: []string{"kafka", "kafka-secure"}},
+		{Package: "nats", Protocols: []string{"nats"}},
+	}, ServersBrokers(servers))
+}
+
+func (suite *HelpersSuite) TestSecuritySchemeName() {
+	suite.Require().Equal("saslScram", SecuritySchemeName(&asyncapiv3.SecurityScheme{
+		Reference: "#/components/securitySchemes/saslScram",
+	}))
+	suite.Require().Equal("scramSha512", SecuritySchemeName(&asyncapiv3.SecurityScheme{Type: "scramSha512"}))
+}
+
+func (suite *HelpersSuite) TestOpToDiscriminator() {
+	msg := func(name, discriminator string, consts map[string]any) *asyncapiv3.Message {
+		payload := &asyncapiv3.Schema{Discriminator: discriminator, Properties: map[string]*asyncapiv3.Schema{}}
+		for field, c := range consts {
+			payload.Properties[field] = &asyncapiv3.Schema{
+				Validations: asyncapi.Validations[asyncapiv3.Schema]{Const: c},
+			}
+		}
+		return &asyncapiv3.Message{Name: name, Payload: payload}
+	}
+	op := func(msgs ...*asyncapiv3.Message) asyncapiv3.Operation {
+		return asyncapiv3.Operation{Name: "Op", Messages: msgs}
+	}
+
+	cases := []struct {
+		Operation asyncapiv3.Operation
+		Field     string
+		Error     bool
+	}{
+		// Discriminator set on payloads
+		{
+			Operation: op(
+				msg("AMessage", "type", map[string]any{"type": "a"}),
+				msg("BMessage", "type", nil)),
+			Field: "type",
+		},
+		// Constant set on every payload
+		{
+			Operation: op(
+				msg("AMessage", "", map[string]any{"kind": "a", "other": "x"}),
+				msg("BMessage", "", map[string]any{"kind": "b"})),
+			Field: "kind",
+		},
+		// Different discriminators
+		{
+			Operation: op(msg("AMessage", "type", nil), msg("BMessage", "kind", nil)),
+			Error:     true,
+		},
+		// No discriminator nor common constant
+		{
+			Operation: op(
+				msg("AMessage", "", map[string]any{"kind": "a"}),
+				msg("BMessage", "", nil)),
+			Field: "",
+		},
+		// Same value for different messages
+		{
+			Operation: op(
+				msg("AMessage", "", map[string]any{"kind": "a"}),
+				msg("BMessage", "", map[string]any{"kind": "a"})),
+			Error: true,
+		},
+	}
+
+	for i, c := range cases {
+		field, err := OpToDiscriminator(c.Operation)
+		if c.Error {
+			suite.Require().ErrorIs(err, ErrIndistinguishableMessages, i)
+			continue
+		}
+		suite.Require().NoError(err, i)
+		suite.Require().Equal(c.Field, field, i)
+	}
+}
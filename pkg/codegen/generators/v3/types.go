@@ -19,6 +19,8 @@ func (tg TypesGenerator) Generate() (string, error) {
 		schemaDefinitionTemplatePath,
 		schemaNameTemplatePath,
 		messageTemplatePath,
+		validationTemplatePath,
+		serversTemplatePath,
 
 		marshalingAdditionalPropertiesTemplatePath,
 		marshalingTimeTemplatePath,
@@ -0,0 +1,173 @@
+package linter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strings"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/asyncapi"
+	"github.com/lerenn/asyncapi-codegen/pkg/codegen"
+	"github.com/lerenn/asyncapi-codegen/pkg/codegen/options"
+)
+
+// lintGeneratedCode generates the code of every part and checks that there is
+// no Go identifier declared more than once.
+func (l *linter) lintGeneratedCode(spec asyncapi.Specification, opt options.Options) {
+	opt.OutputPath = "asyncapi.gen.go"
+	opt.OutputDir = ""
+	opt.DisableFormatting = true
+	opt.Generate = options.GeneratorOptions{Application: true, User: true, Types: true, Mocks: true, Servers: true}
+	if opt.PackageName == "" {
+		opt.PackageName = "asyncapi"
+	}
+	if opt.ConvertKeys == "" {
+		opt.ConvertKeys = "none"
+	}
+	if opt.NamingScheme == "" {
+		opt.NamingScheme = "none"
+	}
+
+	cg, err := codegen.New(spec)
+	if err != nil {
+		l.report.add(SeverityError, RuleGeneration, "", "%s", err)
+		return
+	}
+
+	files, err := cg.GenerateFiles(opt)
+	if err != nil {
+		l.report.add(SeverityError, RuleGeneration, "", "%s", err)
+		return
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), opt.OutputPath, files[opt.OutputPath], parser.SkipObjectResolution)
+	if err != nil {
+		l.report.add(SeverityError, RuleGeneration, "", "generated code is not valid Go: %s", err)
+		return
+	}
+
+	l.lintDeclarations(declarationsCount(file))
+}
+
+// declarationsCount returns the number of declarations of each top-level
+// identifier and method (as '<type>.<method>') of a Go file.
+func declarationsCount(file *ast.File) map[string]int {
+	count := make(map[string]int)
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					count[s.Name.Name]++
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						count[n.Name]++
+					}
+				}
+			}
+		case *ast.FuncDecl:
+			name := d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				name = receiverTypeName(d.Recv.List[0].Type) + "." + name
+			}
+			count[name]++
+		}
+	}
+
+	// Blank and init identifiers can be declared several times
+	delete(count, "_")
+	delete(count, "init")
+
+	return count
+}
+
+func receiverTypeName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(e.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(e.X)
+	case *ast.Ident:
+		return e.Name
+	default:
+		return ""
+	}
+}
+
+// lintDeclarations reports the identifiers declared several times.
+func (l *linter) lintDeclarations(count map[string]int) {
+	names := make([]string, 0)
+	for name, c := range count {
+		if c < 2 {
+			continue
+		}
+
+		// Methods of a type declared several times are duplicated too
+		if typ, _, isMethod := strings.Cut(name, "."); isMethod && count[typ] > 1 {
+			continue
+		}
+
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Group identifiers generated from the same elements of the specification
+	unknown := make([]string, 0)
+	groups := make(map[string][]string)
+	order := make([]string, 0)
+	for _, name := range names {
+		from := strings.Join(l.declarationPaths(name), ", ")
+		if from == "" {
+			unknown = append(unknown, name)
+			continue
+		}
+
+		if _, ok := groups[from]; !ok {
+			order = append(order, from)
+		}
+		groups[from] = append(groups[from], name)
+	}
+
+	for _, from := range order {
+		l.report.add(SeverityError, RuleNameCollision, "",
+			"Go identifiers %s are generated several times (from %s)", quoteAll(groups[from]), from)
+	}
+	for _, name := range unknown {
+		l.report.add(SeverityError, RuleNameCollision, "",
+			"Go identifier %q is generated %d times", name, count[name])
+	}
+}
+
+func quoteAll(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, n := range names {
+		quoted = append(quoted, fmt.Sprintf("%q", n))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// declarationPaths returns the locations in the specification of the elements
+// generating the identifier, or the elements whose name ends the identifier if
+// there is none (i.e. 'SubscribeToUserOperation' for 'UserOperation').
+func (l *linter) declarationPaths(identifier string) []string {
+	_, name, isMethod := strings.Cut(identifier, ".")
+	if !isMethod {
+		name = identifier
+	}
+
+	if paths, ok := l.declarations[name]; ok {
+		return paths
+	}
+
+	longest := ""
+	for n := range l.declarations {
+		if n != "" && len(n) > len(longest) && strings.HasSuffix(name, n) {
+			longest = n
+		}
+	}
+	return l.declarations[longest]
+}
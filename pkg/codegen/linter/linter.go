@@ -0,0 +1,250 @@
+package linter
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/asyncapi"
+	"github.com/lerenn/asyncapi-codegen/pkg/asyncapi/parser"
+	asyncapiv2 "github.com/lerenn/asyncapi-codegen/pkg/asyncapi/v2"
+	asyncapiv3 "github.com/lerenn/asyncapi-codegen/pkg/asyncapi/v3"
+	"github.com/lerenn/asyncapi-codegen/pkg/codegen/options"
+)
+
+// Severity is the severity of an issue.
+type Severity string
+
+const (
+	// SeverityError is the severity of an issue that prevents the code
+	// generation or produces broken code.
+	SeverityError Severity = "error"
+	// SeverityWarning is the severity of an issue that produces code that may
+	// not behave as expected.
+	SeverityWarning Severity = "warning"
+)
+
+// Rule is the check that found an issue.
+type Rule string
+
+const (
+	// RuleStructure is the rule for specifications that cannot be parsed or
+	// processed, or that have missing mandatory fields.
+	RuleStructure Rule = "structure"
+	// RuleReference is the rule for references that cannot be resolved.
+	RuleReference Rule = "reference"
+	// RuleNameCollision is the rule for elements of the specification that
+	// produce the same Go identifier.
+	RuleNameCollision Rule = "name-collision"
+	// RuleUnsupportedSchema is the rule for schema constructs that are not
+	// supported by the code generation.
+	RuleUnsupportedSchema Rule = "unsupported-schema"
+	// RuleOperationWithoutMessage is the rule for operations without any message.
+	RuleOperationWithoutMessage Rule = "operation-without-message"
+	// RuleCorrelationID is the rule for correlation IDs that cannot be used.
+	RuleCorrelationID Rule = "correlation-id"
+	// RuleContentType is the rule for content types without codec.
+	RuleContentType Rule = "content-type"
+	// RuleMessageDispatch is the rule for operations whose messages can't be
+	// told apart from their payload.
+	RuleMessageDispatch Rule = "message-dispatch"
+	// RuleGeneration is the rule for code generation that fails or produces
+	// code that is not valid Go.
+	RuleGeneration Rule = "generation"
+)
+
+// Issue is a problem found in a specification.
+type Issue struct {
+	Severity Severity `json:"severity"`
+	Rule     Rule     `json:"rule"`
+	// Path is the location of the problem in the specification, as a JSON
+	// pointer (i.e. '#/channels/user/messages/signup'). It can be empty.
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// String returns the issue in a human readable format.
+func (i Issue) String() string {
+	if i.Path == "" {
+		return fmt.Sprintf("%s: %s [%s]", i.Severity, i.Message, i.Rule)
+	}
+	return fmt.Sprintf("%s: %s: %s [%s]", i.Severity, i.Path, i.Message, i.Rule)
+}
+
+// Report contains the issues found in a specification.
+type Report struct {
+	Issues []Issue `json:"issues"`
+}
+
+// Count returns the number of issues with the given severity.
+func (r Report) Count(severity Severity) int {
+	count := 0
+	for _, i := range r.Issues {
+		if i.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// HasErrors returns true if at least one issue is an error.
+func (r Report) HasErrors() bool {
+	return r.Count(SeverityError) > 0
+}
+
+func (r *Report) add(severity Severity, rule Rule, path, format string, args ...any) {
+	r.Issues = append(r.Issues, Issue{
+		Severity: severity,
+		Rule:     rule,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// LintFile parses the specification file and its dependencies, then lints it.
+// If the fetcher is nil, the remote files are fetched with HTTP(S).
+func LintFile(fetcher parser.Fetcher, opt options.Options, path string, dependencies ...string) Report {
+	var report Report
+
+	spec, err := parser.FromFile(parser.FromFileParams{Path: path, Fetcher: fetcher})
+	if err != nil {
+		report.add(SeverityError, ruleFromError(err), "", "%s", err)
+		return report
+	}
+
+	for _, depPath := range dependencies {
+		dep, err := parser.FromFile(parser.FromFileParams{
+			Path:         depPath,
+			MajorVersion: spec.MajorVersion(),
+			Fetcher:      fetcher,
+		})
+		if err != nil {
+			report.add(SeverityError, ruleFromError(err), "", "%s", err)
+			return report
+		}
+
+		if err := spec.AddDependency(depPath, dep); err != nil {
+			report.add(SeverityError, RuleStructure, "", "%s", err)
+			return report
+		}
+	}
+
+	return Lint(spec, opt)
+}
+
+// Lint checks a specification for problems that would prevent the code
+// generation, or produce broken code. The specification is processed if it
+// has not been already.
+//
+// The options are used to generate the code in order to detect Go identifiers
+// collisions, with every part generated.
+func Lint(spec asyncapi.Specification, opt options.Options) Report {
+	l := newLinter()
+
+	// Process the specification
+	if err := spec.Process(); err != nil {
+		l.report.add(SeverityError, ruleFromError(err), "", "%s", err)
+		return l.report
+	}
+
+	// Check the specification elements
+	switch spec.MajorVersion() {
+	case asyncapiv2.MajorVersion:
+		specV2, err := asyncapiv2.FromUnknownVersion(spec)
+		if err != nil {
+			l.report.add(SeverityError, RuleStructure, "", "%s", err)
+			return l.report
+		}
+		l.lintSpecificationV2(specV2)
+	case asyncapiv3.MajorVersion:
+		specV3, err := asyncapiv3.FromUnknownVersion(spec)
+		if err != nil {
+			l.report.add(SeverityError, RuleStructure, "", "%s", err)
+			return l.report
+		}
+		l.lintSpecificationV3(specV3)
+	default:
+		l.report.add(SeverityError, RuleStructure, "", "unsupported major version %d", spec.MajorVersion())
+		return l.report
+	}
+
+	// Check the generated code, only if it can be generated
+	if !l.report.HasErrors() {
+		l.lintGeneratedCode(spec, opt)
+	}
+
+	return l.report
+}
+
+func ruleFromError(err error) Rule {
+	switch {
+	case errors.Is(err, parser.ErrUnresolvableReference),
+		errors.Is(err, parser.ErrCircularReference),
+		errors.Is(err, asyncapiv2.ErrInvalidReference),
+		errors.Is(err, asyncapiv3.ErrInvalidReference):
+		return RuleReference
+	default:
+		return RuleStructure
+	}
+}
+
+type linter struct {
+	report Report
+
+	// declarations are the locations in the specification of the elements
+	// generating Go declarations, by Go identifier
+	declarations map[string][]string
+	// visited are the schemas already checked
+	visited map[any]bool
+}
+
+func newLinter() *linter {
+	return &linter{
+		declarations: make(map[string][]string),
+		visited:      make(map[any]bool),
+	}
+}
+
+// declare records the location of an element generating a Go declaration.
+func (l *linter) declare(name, path string) {
+	l.declarations[name] = append(l.declarations[name], path)
+}
+
+// isVisited returns true if the element has already been visited, and marks
+// it as visited otherwise.
+func (l *linter) isVisited(element any) bool {
+	if l.visited[element] {
+		return true
+	}
+	l.visited[element] = true
+	return false
+}
+
+// supportedTypes are the schema types supported by the code generation.
+var supportedTypes = map[string]bool{
+	"object":  true,
+	"array":   true,
+	"string":  true,
+	"integer": true,
+	"number":  true,
+	"boolean": true,
+}
+
+// pointer returns the JSON pointer of an element from its parent pointer and
+// its key, escaped as defined in RFC 6901.
+func pointer(parent string, keys ...string) string {
+	for _, k := range keys {
+		parent += "/" + strings.ReplaceAll(strings.ReplaceAll(k, "~", "~0"), "/", "~1")
+	}
+	return parent
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
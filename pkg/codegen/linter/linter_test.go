@@ -0,0 +1,289 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/asyncapi/parser"
+	"github.com/lerenn/asyncapi-codegen/pkg/codegen/options"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestLinterSuite(t *testing.T) {
+	suite.Run(t, new(LinterSuite))
+}
+
+type LinterSuite struct {
+	suite.Suite
+}
+
+func (suite *LinterSuite) lint(spec string) Report {
+	s, err := parser.FromYAML(parser.FromYAMLParams{Data: []byte(spec)})
+	suite.Require().NoError(err)
+	return Lint(s, options.Options{})
+}
+
+// requireIssue checks that the report contains an issue with the severity,
+// rule and path.
+func (suite *LinterSuite) requireIssue(r Report, severity Severity, rule Rule, path string) Issue {
+	for _, i := range r.Issues {
+		if i.Severity == severity && i.Rule == rule && i.Path == path {
+			return i
+		}
+	}
+
+	suite.FailNow("issue not found", "%s %s at %q in %v", severity, rule, path, r.Issues)
+	return Issue{}
+}
+
+const header = `
+asyncapi: 3.0.0
+info:
+  title: Sample App
+  version: 1.2.3
+`
+
+func (suite *LinterSuite) TestValid() {
+	r := suite.lint(header + `
+channels:
+  signup:
+    address: user.signup
+    messages:
+      user:
+        correlationId:
+          location: $message.header#/correlationId
+        payload:
+          $ref: '#/components/schemas/User'
+operations:
+  signup:
+    action: receive
+    channel:
+      $ref: '#/channels/signup'
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+`)
+	suite.Require().Empty(r.Issues)
+	suite.Require().False(r.HasErrors())
+}
+
+func (suite *LinterSuite) TestUnresolvedReference() {
+	r := suite.lint(header + `
+channels:
+  user:
+    address: user
+    messages:
+      user:
+        payload:
+          $ref: '#/components/schemas/Missing'
+`)
+	suite.requireIssue(r, SeverityError, RuleReference, "")
+}
+
+func (suite *LinterSuite) TestNameCollision() {
+	r := suite.lint(header + `
+components:
+  messages:
+    user-created:
+      payload:
+        type: string
+    userCreated:
+      payload:
+        type: string
+`)
+	i := suite.requireIssue(r, SeverityError, RuleNameCollision, "")
+	suite.Require().Contains(i.Message, `"UserCreatedMessage"`)
+	suite.Require().Contains(i.Message, "#/components/messages/user-created")
+	suite.Require().Contains(i.Message, "#/components/messages/userCreated")
+}
+
+func (suite *LinterSuite) TestUnsupportedSchema() {
+	r := suite.lint(header + `
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        nothing:
+          type: "null"
+        list:
+          type: array
+        any: {}
+        other:
+          type: string
+          not:
+            type: integer
+`)
+	suite.requireIssue(r, SeverityError, RuleUnsupportedSchema, "#/components/schemas/User/properties/nothing")
+	suite.requireIssue(r, SeverityError, RuleUnsupportedSchema, "#/components/schemas/User/properties/list")
+	suite.requireIssue(r, SeverityWarning, RuleUnsupportedSchema, "#/components/schemas/User/properties/any")
+	suite.requireIssue(r, SeverityWarning, RuleUnsupportedSchema, "#/components/schemas/User/properties/other")
+}
+
+func (suite *LinterSuite) TestUnsupportedPattern() {
+	r := suite.lint(header + `
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        password:
+          type: string
+          pattern: '^(?=.*[0-9]).{8,}$'
+        name:
+          type: string
+          pattern: '^[a-z]+$'
+`)
+	suite.requireIssue(r, SeverityWarning, RuleUnsupportedSchema, "#/components/schemas/User/properties/password/pattern")
+	suite.Require().Len(r.Issues, 1)
+}
+
+func (suite *LinterSuite) TestOperationWithoutMessage() {
+	r := suite.lint(header + `
+channels:
+  user:
+    address: user
+operations:
+  signup:
+    action: receive
+    channel:
+      $ref: '#/channels/user'
+`)
+	suite.requireIssue(r, SeverityError, RuleOperationWithoutMessage, "#/operations/signup")
+}
+
+func (suite *LinterSuite) TestOperationWithoutChannel() {
+	r := suite.lint(header + `
+operations:
+  signup:
+    action: publish
+`)
+	suite.requireIssue(r, SeverityError, RuleStructure, "#/operations/signup")
+}
+
+func (suite *LinterSuite) TestCorrelationID() {
+	r := suite.lint(header + `
+channels:
+  ping:
+    address: ping
+    messages:
+      ping:
+        correlationId:
+          description: no location
+        payload:
+          type: string
+  pong:
+    address: pong
+    messages:
+      pong:
+        payload:
+          type: string
+operations:
+  ping:
+    action: send
+    channel:
+      $ref: '#/channels/ping'
+    reply:
+      channel:
+        $ref: '#/channels/pong'
+`)
+	suite.requireIssue(r, SeverityError, RuleCorrelationID, "#/channels/ping/messages/ping/correlationId")
+	suite.requireIssue(r, SeverityWarning, RuleCorrelationID, "#/operations/ping")
+}
+
+func (suite *LinterSuite) TestContentType() {
+	r := suite.lint(header + `
+channels:
+  files:
+    address: files
+    messages:
+      file:
+        contentType: application/octet-stream
+        payload:
+          type: object
+      msgpack:
+        contentType: application/msgpack
+        payload:
+          type: object
+`)
+	i := suite.requireIssue(r, SeverityWarning, RuleContentType, "#/channels/files/messages/file/contentType")
+	suite.Require().Contains(i.Message, "application/octet-stream")
+	suite.Require().Len(r.Issues, 1)
+}
+
+func (suite *LinterSuite) TestMessageDispatch() {
+	r := suite.lint(header + `
+channels:
+  users:
+    address: users
+    messages:
+      created:
+        payload:
+          type: object
+          properties:
+            name:
+              type: string
+      deleted:
+        payload:
+          type: object
+          properties:
+            id:
+              type: string
+  shapes:
+    address: shapes
+    messages:
+      circle:
+        payload:
+          type: object
+          properties:
+            kind:
+              type: string
+              const: shape
+      square:
+        payload:
+          type: object
+          properties:
+            kind:
+              type: string
+              const: shape
+operations:
+  receiveUsers:
+    action: receive
+    channel:
+      $ref: '#/channels/users'
+  receiveShapes:
+    action: receive
+    channel:
+      $ref: '#/channels/shapes'
+`)
+	i := suite.requireIssue(r, SeverityWarning, RuleMessageDispatch, "#/operations/receiveUsers")
+	suite.Require().Contains(i.Message, "x-message-name")
+	suite.requireIssue(r, SeverityError, RuleMessageDispatch, "#/operations/receiveShapes")
+	suite.Require().Len(r.Issues, 2)
+}
+
+func (suite *LinterSuite) TestV2() {
+	r := suite.lint(`
+asyncapi: 2.6.0
+info:
+  title: Sample App
+  version: 1.2.3
+channels:
+  user/signup:
+    subscribe:
+      message:
+        correlationId:
+          location: $message.body#/id
+        payload:
+          type: "null"
+  user/login:
+    publish:
+      operationId: login
+`)
+	suite.requireIssue(r, SeverityError, RuleCorrelationID, "#/channels/user~1signup/subscribe/message/correlationId")
+	suite.requireIssue(r, SeverityError, RuleUnsupportedSchema, "#/channels/user~1signup/subscribe/message/payload")
+	suite.requireIssue(r, SeverityError, RuleOperationWithoutMessage, "#/channels/user~1login/publish")
+}
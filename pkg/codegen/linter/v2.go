@@ -0,0 +1,107 @@
+package linter
+
+import (
+	"strconv"
+
+	asyncapiv2 "github.com/lerenn/asyncapi-codegen/pkg/asyncapi/v2"
+	"github.com/lerenn/asyncapi-codegen/pkg/utils/template"
+)
+
+func (l *linter) lintSpecificationV2(spec *asyncapiv2.Specification) {
+	for _, name := range sortedKeys(spec.Channels) {
+		l.lintChannelV2(pointer("#/channels", name), spec.Channels[name])
+	}
+
+	for _, name := range sortedKeys(spec.Components.Messages) {
+		l.lintMessageV2(pointer("#/components/messages", name), spec.Components.Messages[name])
+	}
+
+	for _, name := range sortedKeys(spec.Components.Schemas) {
+		s := spec.Components.Schemas[name]
+		if s != nil && s.Reference == "" {
+			l.declare(template.Namify(s.Name), pointer("#/components/schemas", name))
+		}
+		l.lintSchemaV2(pointer("#/components/schemas", name), s)
+	}
+}
+
+func (l *linter) lintChannelV2(path string, ch *asyncapiv2.Channel) {
+	if ch == nil {
+		return
+	}
+
+	if len(ch.Parameters) > 0 {
+		l.declare(template.NamifyWithoutParams(ch.Name)+"Parameters", path)
+	}
+
+	l.lintOperationV2(pointer(path, "subscribe"), ch.Subscribe)
+	l.lintOperationV2(pointer(path, "publish"), ch.Publish)
+}
+
+func (l *linter) lintOperationV2(path string, op *asyncapiv2.Operation) {
+	if op == nil {
+		return
+	}
+	l.declare(template.Namify(op.Name), path)
+
+	msg := op.Message
+	if msg.Reference == "" && msg.Payload == nil && msg.Headers == nil && len(msg.OneOf) == 0 {
+		l.report.add(SeverityError, RuleOperationWithoutMessage, path, "operation has no message")
+		return
+	}
+
+	l.lintMessageV2(pointer(path, "message"), &op.Message)
+}
+
+func (l *linter) lintMessageV2(path string, msg *asyncapiv2.Message) {
+	if msg == nil || msg.Reference != "" || l.isVisited(msg) {
+		return
+	}
+	l.declare(template.Namify(msg.Name), path)
+
+	if msg.CorrelationID != nil {
+		l.lintCorrelationIDLocation(pointer(path, "correlationId"), msg.CorrelationID.Location)
+	}
+	l.lintContentType(pointer(path, "contentType"), msg.ContentType)
+
+	if msg.Headers != nil && msg.Headers.Reference == "" {
+		l.declare(template.Namify(msg.Headers.Name), pointer(path, "headers"))
+	}
+	l.lintSchemaV2(pointer(path, "headers"), msg.Headers)
+
+	if msg.Payload != nil && msg.Payload.Reference == "" {
+		l.declare(template.Namify(msg.Payload.Name), pointer(path, "payload"))
+	}
+	l.lintSchemaV2(pointer(path, "payload"), msg.Payload)
+
+	for i, m := range msg.OneOf {
+		l.lintMessageV2(pointer(path, "oneOf", strconv.Itoa(i)), m)
+	}
+}
+
+func (l *linter) lintSchemaV2(path string, s *asyncapiv2.Schema) {
+	if s == nil || s.Reference != "" || l.isVisited(s) {
+		return
+	}
+
+	// Check type
+	l.lintSchemaType(path, s.Type, s.ExtGoType, s.Items != nil,
+		len(s.AnyOf) > 0 || len(s.OneOf) > 0 || s.ReferenceTo != nil)
+	l.lintSchemaPattern(pointer(path, "pattern"), s.Pattern)
+
+	// Check children
+	if s.Type == "object" && s.Name != "" {
+		l.declare(template.Namify(s.Name), path)
+	}
+	for _, name := range sortedKeys(s.Properties) {
+		l.lintSchemaV2(pointer(path, "properties", name), s.Properties[name])
+	}
+	l.lintSchemaV2(pointer(path, "additionalProperties"), s.AdditionalProperties)
+	l.lintSchemaV2(pointer(path, "items"), s.Items)
+	for i, c := range s.AnyOf {
+		l.lintSchemaV2(pointer(path, "anyOf", strconv.Itoa(i)), c)
+	}
+	for i, c := range s.OneOf {
+		l.lintSchemaV2(pointer(path, "oneOf", strconv.Itoa(i)), c)
+	}
+}
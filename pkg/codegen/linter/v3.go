@@ -0,0 +1,232 @@
+package linter
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	asyncapiv3 "github.com/lerenn/asyncapi-codegen/pkg/asyncapi/v3"
+	templatesv3 "github.com/lerenn/asyncapi-codegen/pkg/codegen/generators/v3/templates"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/lerenn/asyncapi-codegen/pkg/utils/template"
+)
+
+func (l *linter) lintSpecificationV3(spec *asyncapiv3.Specification) {
+	for _, name := range sortedKeys(spec.Channels) {
+		l.lintChannelV3(pointer("#/channels", name), spec.Channels[name])
+	}
+
+	for _, name := range sortedKeys(spec.Operations) {
+		l.lintOperationV3(pointer("#/operations", name), spec.Operations[name])
+	}
+
+	for _, name := range sortedKeys(spec.Components.Messages) {
+		l.lintMessageV3(pointer("#/components/messages", name), spec.Components.Messages[name])
+	}
+
+	for _, name := range sortedKeys(spec.Components.Schemas) {
+		s := spec.Components.Schemas[name]
+		if s != nil && s.Reference == "" {
+			l.declare(template.Namify(s.Name), pointer("#/components/schemas", name))
+		}
+		l.lintSchemaV3(pointer("#/components/schemas", name), s)
+	}
+}
+
+func (l *linter) lintChannelV3(path string, ch *asyncapiv3.Channel) {
+	if ch == nil || ch.Reference != "" {
+		return
+	}
+
+	if len(ch.Parameters) > 0 {
+		l.declare(template.NamifyWithoutParams(ch.Name)+"Parameters", path)
+	}
+
+	for _, name := range sortedKeys(ch.Messages) {
+		l.lintMessageV3(pointer(path, "messages", name), ch.Messages[name])
+	}
+}
+
+func (l *linter) lintOperationV3(path string, op *asyncapiv3.Operation) {
+	if op == nil || op.Reference != "" {
+		return
+	}
+	l.declare(template.Namify(op.Name), path)
+
+	if !op.Action.IsSend() && !op.Action.IsReceive() {
+		l.report.add(SeverityError, RuleStructure, path,
+			"action %q should be either %q or %q", op.Action,
+			asyncapiv3.OperationActionIsSend, asyncapiv3.OperationActionIsReceive)
+	}
+
+	if op.Channel == nil {
+		l.report.add(SeverityError, RuleStructure, path, "operation has no channel")
+		return
+	}
+
+	if len(op.GetMessages()) == 0 {
+		l.report.add(SeverityError, RuleOperationWithoutMessage, path,
+			"operation has no message, neither in the operation nor in its channel")
+	} else if op.HasMultipleMessages() {
+		l.lintMessageDispatch(path, op)
+	}
+
+	// Check the reply, as the request will wait for its message
+	if op.Reply == nil || op.ReplyIs == nil {
+		return
+	}
+	reply := op.ReplyIs
+	if reply.Channel == nil {
+		l.report.add(SeverityError, RuleStructure, pointer(path, "reply"), "reply has no channel")
+		return
+	}
+	if len(reply.GetMessages()) == 0 {
+		l.report.add(SeverityError, RuleOperationWithoutMessage, pointer(path, "reply"),
+			"reply has no message, neither in the reply nor in its channel")
+		return
+	}
+	if !op.HaveCorrelationID() || !reply.HaveCorrelationID() {
+		l.report.add(SeverityWarning, RuleCorrelationID, path,
+			"request and reply messages should have a correlation ID, "+
+				"otherwise replies to concurrent requests can be mixed up")
+	}
+}
+
+// lintMessageDispatch checks that the messages of an operation can be told
+// apart from their payload, and not only from the message name header.
+func (l *linter) lintMessageDispatch(path string, op *asyncapiv3.Operation) {
+	field, err := templatesv3.OpToDiscriminator(*op)
+	switch {
+	case err != nil:
+		l.report.add(SeverityError, RuleMessageDispatch, path, "%s", err)
+	case field == "":
+		l.report.add(SeverityWarning, RuleMessageDispatch, path,
+			"messages have no payload discriminator nor constant property in common: "+
+				"they can only be told apart with the %q header set by generated code", extensions.MessageNameHeader)
+	}
+}
+
+func (l *linter) lintMessageV3(path string, msg *asyncapiv3.Message) {
+	if msg == nil || msg.Reference != "" || l.isVisited(msg) {
+		return
+	}
+	l.declare(template.Namify(msg.Name), path)
+
+	if msg.CorrelationID != nil && msg.CorrelationID.Reference == "" {
+		l.lintCorrelationIDLocation(pointer(path, "correlationId"), msg.CorrelationID.Location)
+	}
+	l.lintContentType(pointer(path, "contentType"), msg.ContentType)
+
+	if msg.Headers != nil && msg.Headers.Reference == "" {
+		l.declare(template.Namify(msg.Headers.Name), pointer(path, "headers"))
+	}
+	l.lintSchemaV3(pointer(path, "headers"), msg.Headers)
+
+	if msg.Payload != nil && msg.Payload.Reference == "" {
+		l.declare(template.Namify(msg.Payload.Name), pointer(path, "payload"))
+	}
+	l.lintSchemaV3(pointer(path, "payload"), msg.Payload)
+
+	for i, m := range msg.OneOf {
+		l.lintMessageV3(pointer(path, "oneOf", strconv.Itoa(i)), m)
+	}
+}
+
+// lintCorrelationIDLocation checks a correlation ID location, which is common
+// to every version.
+func (l *linter) lintCorrelationIDLocation(path, location string) {
+	switch {
+	case location == "":
+		l.report.add(SeverityError, RuleCorrelationID, path, "correlation ID has no location")
+	case !strings.HasPrefix(location, "$message.header#/") && !strings.HasPrefix(location, "$message.payload#/"):
+		l.report.add(SeverityError, RuleCorrelationID, path,
+			"location %q should start with '$message.header#/' or '$message.payload#/'", location)
+	}
+}
+
+// lintContentType checks that a message content type has a codec, which is
+// common to every version.
+func (l *linter) lintContentType(path, contentType string) {
+	if contentType == "" {
+		return
+	}
+
+	if _, err := extensions.GetCodec(contentType); err != nil {
+		l.report.add(SeverityWarning, RuleContentType, path,
+			"content type %q has no built-in codec: payloads are encoded in JSON, "+
+				"unless a codec is registered with 'extensions.RegisterCodec'", contentType)
+	}
+}
+
+// lintSchemaPattern checks that a schema pattern can be compiled by Go regular
+// expressions, which is common to every version.
+func (l *linter) lintSchemaPattern(path, pattern string) {
+	if pattern == "" {
+		return
+	}
+
+	if _, err := regexp.Compile(pattern); err != nil {
+		l.report.add(SeverityWarning, RuleUnsupportedSchema, path,
+			"pattern is not supported by Go regular expressions and is not checked by validation: %s", err)
+	}
+}
+
+//nolint:cyclop // Not necessary to split checks
+func (l *linter) lintSchemaV3(path string, s *asyncapiv3.Schema) {
+	if s == nil || s.Reference != "" || l.isVisited(s) {
+		return
+	}
+
+	// Check unsupported keywords
+	for _, k := range []struct {
+		keyword string
+		used    bool
+	}{
+		{keyword: "patternProperties", used: len(s.PatternProperties) > 0},
+		{keyword: "additionalItems", used: len(s.AdditionalItems) > 0},
+		{keyword: "contains", used: len(s.Contains) > 0},
+		{keyword: "propertyNames", used: len(s.PropertyNames) > 0},
+		{keyword: "not", used: s.Not != nil},
+	} {
+		if k.used {
+			l.report.add(SeverityWarning, RuleUnsupportedSchema, path,
+				"%q is not supported and is ignored by the code generation", k.keyword)
+		}
+	}
+
+	// Check type
+	l.lintSchemaType(path, s.Type, s.ExtGoType, s.Items != nil,
+		len(s.AnyOf) > 0 || len(s.OneOf) > 0 || s.ReferenceTo != nil)
+	l.lintSchemaPattern(pointer(path, "pattern"), s.Pattern)
+
+	// Check children
+	if s.Type == "object" && s.Name != "" {
+		l.declare(template.Namify(s.Name), path)
+	}
+	for _, name := range sortedKeys(s.Properties) {
+		l.lintSchemaV3(pointer(path, "properties", name), s.Properties[name])
+	}
+	l.lintSchemaV3(pointer(path, "additionalProperties"), s.AdditionalProperties)
+	l.lintSchemaV3(pointer(path, "items"), s.Items)
+	for i, c := range s.AnyOf {
+		l.lintSchemaV3(pointer(path, "anyOf", strconv.Itoa(i)), c)
+	}
+	for i, c := range s.OneOf {
+		l.lintSchemaV3(pointer(path, "oneOf", strconv.Itoa(i)), c)
+	}
+}
+
+// lintSchemaType checks the type of a schema, which is common to every version.
+func (l *linter) lintSchemaType(path, schemaType, extGoType string, hasItems, isComposed bool) {
+	switch {
+	case extGoType != "":
+		// Custom Go type is used as is
+	case schemaType == "" && !isComposed:
+		l.report.add(SeverityWarning, RuleUnsupportedSchema, path,
+			"schema has no type and is generated as 'interface{}'")
+	case schemaType != "" && !supportedTypes[schemaType]:
+		l.report.add(SeverityError, RuleUnsupportedSchema, path, "type %q is not supported", schemaType)
+	case schemaType == "array" && !hasItems:
+		l.report.add(SeverityError, RuleUnsupportedSchema, path, "array has no 'items'")
+	}
+}
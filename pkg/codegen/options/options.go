@@ -8,6 +8,12 @@ type GeneratorOptions struct {
 	User bool
 	// Types should be true for type code (or common code) generation to be generated
 	Types bool
+	// Mocks should be true for controllers interfaces and fakes to be generated,
+	// for the application and/or user controllers that are generated
+	Mocks bool
+	// Servers should be true for the broker controllers factory from the
+	// specification servers to be generated, which depends on every broker
+	Servers bool
 }
 
 // Options is the struct that gather configuration of codegen.
@@ -15,6 +21,11 @@ type Options struct {
 	// OutputPath is the path to the generated code file
 	OutputPath string
 
+	// OutputDir is the directory where each generated part is written in a
+	// separate file (i.e. 'app.gen.go', 'types.gen.go'). If set, OutputPath is
+	// not used.
+	OutputDir string
+
 	// PackageName is the package name of the generated code
 	PackageName string
 
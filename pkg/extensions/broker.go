@@ -10,6 +10,7 @@ import (
 type BrokerChannelSubscription struct {
 	messages chan AcknowledgeableBrokerMessage
 	cancel   chan any
+	done     chan any
 }
 
 // NewBrokerChannelSubscription creates a new broker channel subscription based
@@ -21,6 +22,7 @@ func NewBrokerChannelSubscription(
 	return BrokerChannelSubscription{
 		messages: messages,
 		cancel:   cancel,
+		done:     make(chan any),
 	}
 }
 
@@ -49,8 +51,9 @@ func (bcs BrokerChannelSubscription) WaitForCancellationAsync(cleanup func()) {
 		// Close messages in order to avoid new messages
 		close(bcs.messages)
 
-		// Close cancel to let listeners know that the cancellation is complete
+		// Close cancel and done to let listeners know that the cancellation is complete
 		close(bcs.cancel)
+		close(bcs.done)
 	}()
 }
 
@@ -63,7 +66,7 @@ func (bcs BrokerChannelSubscription) Cancel(ctx context.Context) {
 
 	// Wait for the cancellation to be effective
 	select {
-	case <-bcs.cancel:
+	case <-bcs.done:
 	case <-ctx.Done():
 	}
 }
@@ -72,6 +75,16 @@ func (bcs BrokerChannelSubscription) Cancel(ctx context.Context) {
 type BrokerMessage struct {
 	Headers map[string][]byte
 	Payload []byte
+
+	// Key is the key of the message, used by the brokers supporting it to
+	// choose the partition of the message (i.e. Kafka), so the messages with
+	// the same key keep their order. It can be empty.
+	Key []byte
+
+	// Channel is the address of the channel the message has been received
+	// from. It is set by the brokers on reception, at least for wildcard
+	// subscriptions (see BrokerWildcardSubscriber), and ignored on publication.
+	Channel string
 }
 
 // IsUninitialized check if the BrokerMessage is at zero value, i.e. the
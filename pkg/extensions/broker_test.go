@@ -1,6 +1,7 @@
 package extensions
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -21,3 +22,18 @@ func (suite *BrokerSuite) TestIsUninitialized() {
 		Headers: make(map[string][]byte),
 	}.IsUninitialized())
 }
+
+func (suite *BrokerSuite) TestCancelWaitsForCleanup() {
+	sub := NewBrokerChannelSubscription(
+		make(chan AcknowledgeableBrokerMessage, 1),
+		make(chan any, 1),
+	)
+
+	cleaned := false
+	sub.WaitForCancellationAsync(func() { cleaned = true })
+	sub.Cancel(context.Background())
+
+	suite.Require().True(cleaned)
+	_, open := <-sub.MessagesChannel()
+	suite.Require().False(open)
+}
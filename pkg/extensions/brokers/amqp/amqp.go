@@ -0,0 +1,350 @@
+package amqp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	// DefaultExchangeName is the default exchange on which messages are published.
+	DefaultExchangeName = "asyncapi"
+	// DefaultExchangeKind is the default kind of the exchange on which messages are published.
+	DefaultExchangeKind = amqp.ExchangeTopic
+)
+
+// Check that it still fills the interface.
+var _ extensions.BrokerController = (*Controller)(nil)
+
+// Controller is the AMQP 0-9-1 (RabbitMQ) implementation for asyncapi-codegen.
+type Controller struct {
+	url        string
+	config     *amqp.Config
+	connection *amqp.Connection
+
+	// publishChannel is the AMQP channel used to publish, protected by
+	// publishMutex as AMQP channels should not be shared between goroutines
+	publishChannel *amqp.Channel
+	publishMutex   sync.Mutex
+
+	exchangeName string
+	exchangeKind string
+	queueGroup   string
+	durable      bool
+	prefetch     int
+	requeueOnNak bool
+	routingKeyFn func(channel string) string
+
+	logger extensions.Logger
+}
+
+// ControllerOption is a function that can be used to configure an AMQP controller
+// Examples: WithExchange(), WithQueueGroup(), WithPrefetch(), WithLogger().
+type ControllerOption func(controller *Controller) error
+
+// NewController creates a new AMQP controller.
+func NewController(url string, options ...ControllerOption) (*Controller, error) {
+	// Creates default controller
+	controller := &Controller{
+		url:          url,
+		exchangeName: DefaultExchangeName,
+		exchangeKind: DefaultExchangeKind,
+		queueGroup:   brokers.DefaultQueueGroupID,
+		requeueOnNak: true,
+		routingKeyFn: func(channel string) string { return channel },
+		logger:       extensions.DummyLogger{},
+	}
+
+	// Execute options
+	for _, option := range options {
+		if err := option(controller); err != nil {
+			return nil, fmt.Errorf("could not apply option to controller: %w", err)
+		}
+	}
+
+	// Connect to AMQP broker
+	if err := controller.connect(); err != nil {
+		return nil, err
+	}
+
+	return controller, nil
+}
+
+func (c *Controller) connect() error {
+	var err error
+
+	// Connect with config, if one has been provided with WithConnectionConfig
+	if c.config != nil {
+		c.connection, err = amqp.DialConfig(c.url, *c.config)
+	} else {
+		c.connection, err = amqp.Dial(c.url)
+	}
+	if err != nil {
+		return fmt.Errorf("could not connect to amqp: %w", err)
+	}
+
+	// Open the channel used for publication
+	c.publishChannel, err = c.connection.Channel()
+	if err != nil {
+		return fmt.Errorf("could not open amqp channel: %w", err)
+	}
+
+	// Declare the exchange on which messages will be published
+	if c.exchangeName != "" {
+		if err := c.publishChannel.ExchangeDeclare(
+			c.exchangeName, c.exchangeKind, true, false, false, false, nil,
+		); err != nil {
+			return fmt.Errorf("could not declare amqp exchange %q: %w", c.exchangeName, err)
+		}
+	}
+
+	return nil
+}
+
+// NewControllerFromServer creates a new AMQP controller from a server of the
+// specification, with its variables already replaced. The pathname is used as
+// virtual host (i.e. '/production').
+func NewControllerFromServer(srv extensions.Server, options ...ControllerOption) (*Controller, error) {
+	return NewController(srv.URL(), options...)
+}
+
+// WithExchange set a custom exchange, with its kind (direct, fanout, topic,
+// headers), on which messages will be published and queues will be bound.
+// An empty name will use the AMQP default exchange, where the routing key is
+// the queue name.
+func WithExchange(name, kind string) ControllerOption {
+	return func(controller *Controller) error {
+		controller.exchangeName = name
+		controller.exchangeKind = kind
+		return nil
+	}
+}
+
+// WithQueueGroup set a custom queue group for channel subscription. Every
+// controller with the same queue group will share a queue per channel, and
+// messages will be distributed between them. Without queue group, each
+// subscription will get its own exclusive queue and receive every message.
+func WithQueueGroup(name string) ControllerOption {
+	return func(controller *Controller) error {
+		controller.queueGroup = name
+		return nil
+	}
+}
+
+// WithDurableQueues set if the queues declared for queue groups should survive
+// a broker restart.
+func WithDurableQueues(enabled bool) ControllerOption {
+	return func(controller *Controller) error {
+		controller.durable = enabled
+		return nil
+	}
+}
+
+// WithPrefetch set the maximum number of unacknowledged messages that will be
+// delivered to a subscription. Zero means no limit.
+func WithPrefetch(count int) ControllerOption {
+	return func(controller *Controller) error {
+		controller.prefetch = count
+		return nil
+	}
+}
+
+// WithRequeueOnNak set if a nak'd message should be requeued (basic.nack with
+// requeue) or discarded/dead-lettered by the broker.
+func WithRequeueOnNak(enabled bool) ControllerOption {
+	return func(controller *Controller) error {
+		controller.requeueOnNak = enabled
+		return nil
+	}
+}
+
+// WithRoutingKey set the function that will compute the routing key from the
+// channel address. By default, the channel address is used as routing key.
+func WithRoutingKey(fn func(channel string) string) ControllerOption {
+	return func(controller *Controller) error {
+		controller.routingKeyFn = fn
+		return nil
+	}
+}
+
+// WithLogger set a custom logger that will log operations on broker controller.
+func WithLogger(logger extensions.Logger) ControllerOption {
+	return func(controller *Controller) error {
+		controller.logger = logger
+		return nil
+	}
+}
+
+// WithConnectionConfig set the amqp.Config (TLS, SASL, vhost, heartbeat, etc)
+// used to connect to the broker.
+func WithConnectionConfig(config amqp.Config) ControllerOption {
+	return func(controller *Controller) error {
+		controller.config = &config
+		return nil
+	}
+}
+
+// Publish a message to the broker.
+func (c *Controller) Publish(ctx context.Context, channel string, bm extensions.BrokerMessage) error {
+	// Set message headers
+	headers := make(amqp.Table, len(bm.Headers))
+	for k, v := range bm.Headers {
+		headers[k] = v
+	}
+
+	// Publish message
+	c.publishMutex.Lock()
+	defer c.publishMutex.Unlock()
+
+	return c.publishChannel.PublishWithContext(ctx, c.exchangeName, c.routingKeyFn(channel), false, false,
+		amqp.Publishing{
+			Headers:      headers,
+			DeliveryMode: amqp.Persistent,
+			Body:         bm.Payload,
+		})
+}
+
+// Subscribe to messages from the broker.
+func (c *Controller) Subscribe(ctx context.Context, channel string) (extensions.BrokerChannelSubscription, error) {
+	// Open a dedicated AMQP channel, as prefetch and consumers are per channel
+	ch, err := c.connection.Channel()
+	if err != nil {
+		return extensions.BrokerChannelSubscription{}, fmt.Errorf("could not open amqp channel: %w", err)
+	}
+
+	// Declare the queue and bind it to the exchange
+	queue, err := c.declareQueue(ch, channel)
+	if err != nil {
+		_ = ch.Close()
+		return extensions.BrokerChannelSubscription{}, err
+	}
+
+	// Set prefetch
+	if c.prefetch > 0 {
+		if err := ch.Qos(c.prefetch, 0, false); err != nil {
+			_ = ch.Close()
+			return extensions.BrokerChannelSubscription{}, fmt.Errorf("could not set amqp prefetch: %w", err)
+		}
+	}
+
+	// Start consuming
+	deliveries, err := ch.Consume(queue, "", false, false, false, false, nil)
+	if err != nil {
+		_ = ch.Close()
+		return extensions.BrokerChannelSubscription{}, fmt.Errorf("could not consume amqp queue %q: %w", queue, err)
+	}
+
+	// Create a new subscription
+	sub := extensions.NewBrokerChannelSubscription(
+		make(chan extensions.AcknowledgeableBrokerMessage, brokers.BrokerMessagesQueueSize),
+		make(chan any, 1),
+	)
+
+	// Handle events
+	go c.messagesHandler(ctx, deliveries, sub)
+
+	// Wait for cancellation and close the AMQP channel, which will stop the consumer
+	sub.WaitForCancellationAsync(func() {
+		if err := ch.Close(); err != nil {
+			c.logger.Error(ctx, err.Error())
+		}
+	})
+
+	return sub, nil
+}
+
+func (c *Controller) declareQueue(ch *amqp.Channel, channel string) (string, error) {
+	var queue amqp.Queue
+	var err error
+
+	// Declare a shared queue for the queue group, or an exclusive one for the subscription
+	if c.queueGroup != "" {
+		queue, err = ch.QueueDeclare(c.queueGroup+"."+channel, c.durable, !c.durable, false, false, nil)
+	} else {
+		queue, err = ch.QueueDeclare("", false, true, true, false, nil)
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not declare amqp queue for channel %q: %w", channel, err)
+	}
+
+	// Bind the queue to the exchange, except on default exchange where it is implicit
+	if c.exchangeName == "" {
+		return queue.Name, nil
+	}
+
+	if err := ch.QueueBind(queue.Name, c.routingKeyFn(channel), c.exchangeName, false, nil); err != nil {
+		return "", fmt.Errorf("could not bind amqp queue %q: %w", queue.Name, err)
+	}
+
+	return queue.Name, nil
+}
+
+func (c *Controller) messagesHandler(
+	ctx context.Context,
+	deliveries <-chan amqp.Delivery,
+	sub extensions.BrokerChannelSubscription,
+) {
+	for d := range deliveries {
+		// Get headers
+		headers := make(map[string][]byte, len(d.Headers))
+		for k, v := range d.Headers {
+			switch value := v.(type) {
+			case []byte:
+				headers[k] = value
+			case string:
+				headers[k] = []byte(value)
+			default:
+				headers[k] = []byte(fmt.Sprint(value))
+			}
+		}
+
+		// Create and transmit message to user
+		delivery := d
+		sub.TransmitReceivedMessage(extensions.NewAcknowledgeableBrokerMessage(
+			extensions.BrokerMessage{
+				Headers: headers,
+				Payload: d.Body,
+			},
+			AcknowledgementHandler{
+				doAck: func() {
+					if err := delivery.Ack(false); err != nil {
+						c.logger.Error(ctx, fmt.Sprintf("error on ack message: %q", err.Error()))
+					}
+				},
+				doNak: func() {
+					if err := delivery.Nack(false, c.requeueOnNak); err != nil {
+						c.logger.Error(ctx, fmt.Sprintf("error on nak message: %q", err.Error()))
+					}
+				},
+			}))
+	}
+}
+
+// Close closes everything related to the broker.
+func (c *Controller) Close() {
+	if err := c.connection.Close(); err != nil {
+		c.logger.Error(context.Background(), err.Error())
+	}
+}
+
+var _ extensions.BrokerAcknowledgment = (*AcknowledgementHandler)(nil)
+
+// AcknowledgementHandler for AMQP broker, using basic.ack and basic.nack.
+type AcknowledgementHandler struct {
+	doAck func()
+	doNak func()
+}
+
+// AckMessage acknowledges the message.
+func (k AcknowledgementHandler) AckMessage() {
+	k.doAck()
+}
+
+// NakMessage negatively acknowledges the message.
+func (k AcknowledgementHandler) NakMessage() {
+	k.doNak()
+}
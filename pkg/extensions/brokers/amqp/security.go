@@ -0,0 +1,58 @@
+package amqp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// SecurityOptions returns the options to authenticate to the server with the
+// first of its security schemes that is supported, and the credentials from
+// the provider:
+//
+//   - 'userPassword' and 'plain' use SASL PLAIN with the username and password;
+//   - 'X509' uses TLS with a client certificate and SASL EXTERNAL.
+//
+// The returned option sets the connection configuration: it should not be used
+// with another WithConnectionConfig option.
+//
+// No option is returned if the server has no security scheme.
+func SecurityOptions(
+	ctx context.Context,
+	srv extensions.Server,
+	provider extensions.CredentialsProvider,
+) ([]ControllerOption, error) {
+	scheme, creds, err := srv.SecurityCredentials(ctx, provider,
+		extensions.SecuritySchemeUserPassword,
+		extensions.SecuritySchemePlain,
+		extensions.SecuritySchemeX509)
+	if err != nil || scheme == nil {
+		return nil, err
+	}
+
+	// Use the same defaults as amqp.Dial
+	config := amqp.Config{
+		Heartbeat: 10 * time.Second,
+		Locale:    "en_US",
+	}
+
+	if scheme.Type == extensions.SecuritySchemeX509 {
+		config.TLSClientConfig, err = creds.TLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		config.SASL = []amqp.Authentication{&amqp.ExternalAuth{}}
+		return []ControllerOption{WithConnectionConfig(config)}, nil
+	}
+
+	if creds.Username == "" || creds.Password == "" {
+		return nil, fmt.Errorf("%w: username and password for %q security scheme of server %q",
+			extensions.ErrMissingCredentials, scheme.Name, srv.Name)
+	}
+
+	config.SASL = []amqp.Authentication{&amqp.PlainAuth{Username: creds.Username, Password: creds.Password}}
+	return []ControllerOption{WithConnectionConfig(config)}, nil
+}
@@ -0,0 +1,469 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers"
+)
+
+// Check that it still fills the interfaces.
+var (
+	_ extensions.BrokerController         = (*Controller)(nil)
+	_ extensions.BrokerWildcardSubscriber = (*Controller)(nil)
+	_ extensions.BrokerOptionsSubscriber  = (*Controller)(nil)
+
+	_ extensions.BrokerWildcardOptionsSubscriber = (*Controller)(nil)
+)
+
+// Broker is the in-memory message broker that can be shared between several
+// controllers, in the same way that several clients would connect to the same
+// broker server.
+type Broker struct {
+	mutex         sync.Mutex
+	subscriptions map[string][]*subscription
+	// wildcards are the regular expressions of the addresses with parameters
+	// that have subscriptions, by address
+	wildcards map[string]*regexp.Regexp
+	// nextMember is the index of the next queue group member that will receive
+	// a message, per channel and per queue group
+	nextMember map[string]map[string]int
+}
+
+// NewBroker creates a new in-memory broker.
+func NewBroker() *Broker {
+	return &Broker{
+		subscriptions: make(map[string][]*subscription),
+		wildcards:     make(map[string]*regexp.Regexp),
+		nextMember:    make(map[string]map[string]int),
+	}
+}
+
+// Controller is the in-memory implementation for asyncapi-codegen.
+type Controller struct {
+	broker     *Broker
+	logger     extensions.Logger
+	queueGroup string
+
+	nakDelay      time.Duration
+	maxDeliveries int
+	ordered       bool
+}
+
+// ControllerOption is a function that can be used to configure an in-memory controller
+// Examples: WithBroker(), WithQueueGroup(), WithLogger().
+type ControllerOption func(controller *Controller)
+
+// NewController creates a new in-memory controller.
+func NewController(options ...ControllerOption) (*Controller, error) {
+	// Creates default controller
+	controller := &Controller{
+		queueGroup: brokers.DefaultQueueGroupID,
+		logger:     extensions.DummyLogger{},
+	}
+
+	// Execute options
+	for _, option := range options {
+		option(controller)
+	}
+
+	// If no broker has been set with WithBroker, create a dedicated one
+	if controller.broker == nil {
+		controller.broker = NewBroker()
+	}
+
+	return controller, nil
+}
+
+// WithBroker set the in-memory broker that will be used by the controller.
+// It can be used to share messages between several controllers.
+func WithBroker(broker *Broker) ControllerOption {
+	return func(controller *Controller) {
+		controller.broker = broker
+	}
+}
+
+// WithQueueGroup set a custom queue group for channel subscription.
+func WithQueueGroup(name string) ControllerOption {
+	return func(controller *Controller) {
+		controller.queueGroup = name
+	}
+}
+
+// WithLogger set a custom logger that will log operations on broker controller.
+func WithLogger(logger extensions.Logger) ControllerOption {
+	return func(controller *Controller) {
+		controller.logger = logger
+	}
+}
+
+// WithNakDelay set the delay before redelivering a message that has been nak'd.
+func WithNakDelay(duration time.Duration) ControllerOption {
+	return func(controller *Controller) {
+		controller.nakDelay = duration
+	}
+}
+
+// WithMaxDeliveries set the maximum number of times a message will be delivered
+// to a subscription, including redeliveries after nak. Zero means unlimited.
+func WithMaxDeliveries(count int) ControllerOption {
+	return func(controller *Controller) {
+		controller.maxDeliveries = count
+	}
+}
+
+// WithOrdering set if the messages should be delivered in order. If enabled,
+// a subscription will receive the next message only when the previous one has
+// been ack'd, and a nak'd message will be redelivered before the next ones.
+func WithOrdering(enabled bool) ControllerOption {
+	return func(controller *Controller) {
+		controller.ordered = enabled
+	}
+}
+
+// Publish a message to the broker.
+func (c *Controller) Publish(_ context.Context, channel string, bm extensions.BrokerMessage) error {
+	c.broker.mutex.Lock()
+	defer c.broker.mutex.Unlock()
+
+	bm.Channel = channel
+
+	// Deliver the message to the subscriptions on the channel, and to the
+	// wildcard subscriptions matching it
+	c.broker.deliver(channel, bm)
+	for address, re := range c.broker.wildcards {
+		if re.MatchString(channel) {
+			c.broker.deliver(address, bm)
+		}
+	}
+
+	return nil
+}
+
+// deliver delivers the message to every subscription on the address without
+// queue group and to one member of each queue group.
+func (b *Broker) deliver(address string, bm extensions.BrokerMessage) {
+	groups := make(map[string][]*subscription)
+	for _, s := range b.subscriptions[address] {
+		if s.queueGroup == "" {
+			s.enqueue(delivery{msg: copyBrokerMessage(bm)})
+			continue
+		}
+		groups[s.queueGroup] = append(groups[s.queueGroup], s)
+	}
+
+	for name, members := range groups {
+		if b.nextMember[address] == nil {
+			b.nextMember[address] = make(map[string]int)
+		}
+
+		i := b.nextMember[address][name] % len(members)
+		members[i].enqueue(delivery{msg: copyBrokerMessage(bm)})
+		b.nextMember[address][name] = i + 1
+	}
+}
+
+// Subscribe to messages from the broker.
+func (c *Controller) Subscribe(ctx context.Context, channel string) (extensions.BrokerChannelSubscription, error) {
+	return c.subscribe(ctx, channel, nil, extensions.SubscribeOptions{})
+}
+
+// SubscribeWithOptions subscribes to messages from the broker, with the queue
+// group and buffer size of the options. As the in-memory broker doesn't keep
+// the messages, start position and durable name are not supported.
+func (c *Controller) SubscribeWithOptions(
+	ctx context.Context,
+	channel string,
+	opts extensions.SubscribeOptions,
+) (extensions.BrokerChannelSubscription, error) {
+	if err := checkSubscribeOptions(opts); err != nil {
+		return extensions.BrokerChannelSubscription{}, err
+	}
+
+	return c.subscribe(ctx, channel, nil, opts)
+}
+
+// SubscribeWildcardWithOptions subscribes to messages from the broker, on every
+// channel matching the address with parameters, with the queue group and buffer
+// size of the options.
+func (c *Controller) SubscribeWildcardWithOptions(
+	ctx context.Context,
+	address string,
+	opts extensions.SubscribeOptions,
+) (extensions.BrokerChannelSubscription, error) {
+	if err := checkSubscribeOptions(opts); err != nil {
+		return extensions.BrokerChannelSubscription{}, err
+	}
+
+	return c.subscribe(ctx, address, extensions.ChannelAddressRegexp(address), opts)
+}
+
+// checkSubscribeOptions returns an error if the options need the messages to
+// be kept, as the in-memory broker doesn't keep them.
+func checkSubscribeOptions(opts extensions.SubscribeOptions) error {
+	if opts.StartPosition != extensions.StartPositionDefault || opts.DurableName != "" {
+		return fmt.Errorf("%w: in-memory broker doesn't keep messages for start position or durable name",
+			extensions.ErrSubscribeOptionNotSupported)
+	}
+	return nil
+}
+
+// SubscribeWildcard subscribes to messages from the broker, on every channel
+// matching the address with parameters (i.e. 'user.{userId}.events').
+func (c *Controller) SubscribeWildcard(
+	ctx context.Context,
+	address string,
+) (extensions.BrokerChannelSubscription, error) {
+	return c.subscribe(ctx, address, extensions.ChannelAddressRegexp(address), extensions.SubscribeOptions{})
+}
+
+func (c *Controller) subscribe(
+	ctx context.Context,
+	address string,
+	wildcard *regexp.Regexp,
+	opts extensions.SubscribeOptions,
+) (extensions.BrokerChannelSubscription, error) {
+	// Create a new subscription
+	bufferSize := brokers.BrokerMessagesQueueSize
+	if opts.BufferSize > 0 {
+		bufferSize = opts.BufferSize
+	}
+	messages := make(chan extensions.AcknowledgeableBrokerMessage, bufferSize)
+	sub := extensions.NewBrokerChannelSubscription(messages, make(chan any, 1))
+
+	// Register it on the broker, with the queue group of the options if any
+	queueGroup := c.queueGroup
+	if opts.QueueGroup != "" {
+		queueGroup = opts.QueueGroup
+	}
+	s := &subscription{
+		controller: c,
+		queueGroup: queueGroup,
+		messages:   messages,
+		signal:     make(chan any, 1),
+		done:       make(chan any),
+	}
+	c.broker.mutex.Lock()
+	c.broker.subscriptions[address] = append(c.broker.subscriptions[address], s)
+	if wildcard != nil {
+		c.broker.wildcards[address] = wildcard
+	}
+	c.broker.mutex.Unlock()
+
+	// Deliver messages asynchronously
+	s.wg.Add(1)
+	go s.deliver(ctx)
+
+	// Wait for cancellation and remove the subscription from the broker
+	sub.WaitForCancellationAsync(func() {
+		c.broker.removeSubscription(address, s)
+		close(s.done)
+		s.wg.Wait()
+	})
+
+	return sub, nil
+}
+
+func (b *Broker) removeSubscription(channel string, s *subscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	subs := b.subscriptions[channel]
+	for i, sub := range subs {
+		if sub == s {
+			b.subscriptions[channel] = append(subs[:i], subs[i+1:]...)
+			break
+		}
+	}
+
+	if len(b.subscriptions[channel]) == 0 {
+		delete(b.subscriptions, channel)
+		delete(b.wildcards, channel)
+	}
+}
+
+// delivery is a message waiting to be delivered to a subscription.
+type delivery struct {
+	msg      extensions.BrokerMessage
+	attempts int
+}
+
+// subscription is the broker side of a channel subscription.
+type subscription struct {
+	controller *Controller
+	queueGroup string
+
+	mutex   sync.Mutex
+	pending []delivery
+	signal  chan any
+
+	messages chan extensions.AcknowledgeableBrokerMessage
+	done     chan any
+	wg       sync.WaitGroup
+}
+
+// enqueue adds a message at the end of the pending messages without blocking.
+func (s *subscription) enqueue(d delivery) {
+	s.mutex.Lock()
+	s.pending = append(s.pending, d)
+	s.mutex.Unlock()
+	s.notify()
+}
+
+// requeue adds a message at the beginning of the pending messages without blocking.
+func (s *subscription) requeue(d delivery) {
+	s.mutex.Lock()
+	s.pending = append([]delivery{d}, s.pending...)
+	s.mutex.Unlock()
+	s.notify()
+}
+
+func (s *subscription) notify() {
+	select {
+	case s.signal <- true:
+	default:
+	}
+}
+
+func (s *subscription) next() (delivery, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.pending) == 0 {
+		return delivery{}, false
+	}
+
+	d := s.pending[0]
+	s.pending = s.pending[1:]
+	return d, true
+}
+
+func (s *subscription) deliver(ctx context.Context) {
+	defer s.wg.Done()
+
+	for {
+		// Get next message or wait for one
+		d, ok := s.next()
+		if !ok {
+			select {
+			case <-s.signal:
+				continue
+			case <-s.done:
+				return
+			}
+		}
+		d.attempts++
+
+		// Transmit the message to the user
+		result := make(chan bool, 1)
+		msg := extensions.NewAcknowledgeableBrokerMessage(
+			copyBrokerMessage(d.msg),
+			AcknowledgementHandler{
+				doAck: func() { result <- true },
+				doNak: func() { result <- false },
+			})
+		select {
+		case s.messages <- msg:
+		case <-s.done:
+			return
+		}
+
+		// If the delivery is not ordered, then handle acknowledgment asynchronously
+		if !s.controller.ordered {
+			go func() {
+				select {
+				case acked := <-result:
+					s.handleAcknowledgment(ctx, d, acked)
+				case <-s.done:
+				}
+			}()
+			continue
+		}
+
+		// Otherwise wait for acknowledgment before the next message
+		select {
+		case acked := <-result:
+			s.handleAcknowledgment(ctx, d, acked)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *subscription) handleAcknowledgment(ctx context.Context, d delivery, acked bool) {
+	if acked {
+		return
+	}
+
+	// Drop the message if it has been delivered too many times
+	if m := s.controller.maxDeliveries; m > 0 && d.attempts >= m {
+		s.controller.logger.Warning(ctx, "Message nak'd too many times, dropping it",
+			extensions.LogInfo{Key: "attempts", Value: d.attempts})
+		return
+	}
+
+	// Redeliver at the beginning of the queue if ordered, at the end otherwise
+	redeliver := s.enqueue
+	if s.controller.ordered {
+		redeliver = s.requeue
+	}
+
+	if s.controller.nakDelay == 0 {
+		redeliver(d)
+		return
+	}
+
+	// Wait for the delay before redelivering, while keeping the order if needed
+	if s.controller.ordered {
+		select {
+		case <-time.After(s.controller.nakDelay):
+			redeliver(d)
+		case <-s.done:
+		}
+		return
+	}
+	time.AfterFunc(s.controller.nakDelay, func() { redeliver(d) })
+}
+
+func copyBrokerMessage(bm extensions.BrokerMessage) extensions.BrokerMessage {
+	cp := extensions.BrokerMessage{Channel: bm.Channel}
+
+	if bm.Headers != nil {
+		cp.Headers = make(map[string][]byte, len(bm.Headers))
+		for k, v := range bm.Headers {
+			cp.Headers[k] = append([]byte(nil), v...)
+		}
+	}
+
+	if bm.Payload != nil {
+		cp.Payload = append([]byte(nil), bm.Payload...)
+	}
+
+	if bm.Key != nil {
+		cp.Key = append([]byte(nil), bm.Key...)
+	}
+
+	return cp
+}
+
+var _ extensions.BrokerAcknowledgment = (*AcknowledgementHandler)(nil)
+
+// AcknowledgementHandler for in-memory broker.
+type AcknowledgementHandler struct {
+	doAck func()
+	doNak func()
+}
+
+// AckMessage acknowledges the message.
+func (k AcknowledgementHandler) AckMessage() {
+	k.doAck()
+}
+
+// NakMessage negatively acknowledges the message.
+func (k AcknowledgementHandler) NakMessage() {
+	k.doNak()
+}
@@ -0,0 +1,197 @@
+package inmemory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestControllerSuite(t *testing.T) {
+	suite.Run(t, new(ControllerSuite))
+}
+
+type ControllerSuite struct {
+	suite.Suite
+}
+
+func (suite *ControllerSuite) newController(options ...ControllerOption) *Controller {
+	c, err := NewController(options...)
+	suite.Require().NoError(err)
+	return c
+}
+
+func (suite *ControllerSuite) subscribe(c *Controller, channel string) extensions.BrokerChannelSubscription {
+	sub, err := c.Subscribe(context.Background(), channel)
+	suite.Require().NoError(err)
+	suite.T().Cleanup(func() { sub.Cancel(context.Background()) })
+	return sub
+}
+
+func (suite *ControllerSuite) publish(c *Controller, channel, payload string) {
+	err := c.Publish(context.Background(), channel, extensions.BrokerMessage{
+		Headers: map[string][]byte{"key": []byte("value")},
+		Payload: []byte(payload),
+	})
+	suite.Require().NoError(err)
+}
+
+func (suite *ControllerSuite) receive(sub extensions.BrokerChannelSubscription) extensions.AcknowledgeableBrokerMessage {
+	select {
+	case msg := <-sub.MessagesChannel():
+		return msg
+	case <-time.After(time.Second):
+		suite.FailNow("no message received")
+		return extensions.AcknowledgeableBrokerMessage{}
+	}
+}
+
+func (suite *ControllerSuite) noReceive(sub extensions.BrokerChannelSubscription) {
+	select {
+	case msg := <-sub.MessagesChannel():
+		suite.FailNow("unexpected message received", msg.String())
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func (suite *ControllerSuite) TestPublishSubscribe() {
+	c := suite.newController()
+	sub := suite.subscribe(c, "channel")
+
+	suite.publish(c, "channel", "hello")
+	msg := suite.receive(sub)
+	msg.Ack()
+
+	suite.Require().Equal("hello", string(msg.Payload))
+	suite.Require().Equal("value", string(msg.Headers["key"]))
+	suite.noReceive(sub)
+}
+
+func (suite *ControllerSuite) TestFanOut() {
+	broker := NewBroker()
+	sub1 := suite.subscribe(suite.newController(WithBroker(broker)), "channel")
+	sub2 := suite.subscribe(suite.newController(WithBroker(broker)), "channel")
+
+	suite.publish(suite.newController(WithBroker(broker)), "channel", "hello")
+
+	suite.Require().Equal("hello", string(suite.receive(sub1).Payload))
+	suite.Require().Equal("hello", string(suite.receive(sub2).Payload))
+}
+
+func (suite *ControllerSuite) TestQueueGroup() {
+	broker := NewBroker()
+	sub1 := suite.subscribe(suite.newController(WithBroker(broker), WithQueueGroup("group")), "channel")
+	sub2 := suite.subscribe(suite.newController(WithBroker(broker), WithQueueGroup("group")), "channel")
+	sub3 := suite.subscribe(suite.newController(WithBroker(broker), WithQueueGroup("other")), "channel")
+
+	publisher := suite.newController(WithBroker(broker))
+	suite.publish(publisher, "channel", "first")
+	suite.publish(publisher, "channel", "second")
+
+	// Each group member gets one message
+	suite.Require().Equal("first", string(suite.receive(sub1).Payload))
+	suite.Require().Equal("second", string(suite.receive(sub2).Payload))
+	suite.noReceive(sub1)
+	suite.noReceive(sub2)
+
+	// Other group get all messages
+	suite.Require().Equal("first", string(suite.receive(sub3).Payload))
+	suite.Require().Equal("second", string(suite.receive(sub3).Payload))
+}
+
+func (suite *ControllerSuite) TestSubscribeWithOptions() {
+	c := suite.newController(WithQueueGroup("group"))
+	sub1 := suite.subscribe(c, "channel")
+
+	// Subscription in another queue group get all messages
+	sub2, err := c.SubscribeWithOptions(context.Background(), "channel", extensions.NewSubscribeOptions(
+		extensions.WithQueueGroup("other"),
+		extensions.WithBufferSize(1)))
+	suite.Require().NoError(err)
+	defer sub2.Cancel(context.Background())
+
+	suite.publish(c, "channel", "hello")
+	suite.Require().Equal("hello", string(suite.receive(sub1).Payload))
+	suite.Require().Equal("hello", string(suite.receive(sub2).Payload))
+
+	// Messages are not kept for a start position
+	_, err = c.SubscribeWithOptions(context.Background(), "channel", extensions.NewSubscribeOptions(
+		extensions.WithStartEarliest()))
+	suite.Require().ErrorIs(err, extensions.ErrSubscribeOptionNotSupported)
+}
+
+func (suite *ControllerSuite) TestNakRedelivery() {
+	c := suite.newController(WithMaxDeliveries(2))
+	sub := suite.subscribe(c, "channel")
+
+	suite.publish(c, "channel", "hello")
+
+	msg := suite.receive(sub)
+	msg.Nak()
+
+	msg = suite.receive(sub)
+	suite.Require().Equal("hello", string(msg.Payload))
+	msg.Nak()
+
+	// Max deliveries reached
+	suite.noReceive(sub)
+}
+
+func (suite *ControllerSuite) TestOrdering() {
+	c := suite.newController(WithOrdering(true))
+	sub := suite.subscribe(c, "channel")
+
+	suite.publish(c, "channel", "first")
+	suite.publish(c, "channel", "second")
+
+	// Next message is not delivered until the first one is acknowledged
+	msg := suite.receive(sub)
+	suite.Require().Equal("first", string(msg.Payload))
+	suite.noReceive(sub)
+
+	// Nak'd message is redelivered before the next ones
+	msg.Nak()
+	msg = suite.receive(sub)
+	suite.Require().Equal("first", string(msg.Payload))
+
+	msg.Ack()
+	msg = suite.receive(sub)
+	suite.Require().Equal("second", string(msg.Payload))
+	msg.Ack()
+}
+
+func (suite *ControllerSuite) TestCancel() {
+	c := suite.newController()
+
+	sub, err := c.Subscribe(context.Background(), "channel")
+	suite.Require().NoError(err)
+	sub.Cancel(context.Background())
+
+	// Channel should be closed and no longer registered
+	_, open := <-sub.MessagesChannel()
+	suite.Require().False(open)
+	suite.publish(c, "channel", "hello")
+	suite.Require().Empty(c.broker.subscriptions)
+}
+
+func (suite *ControllerSuite) TestSubscribeWildcard() {
+	c := suite.newController()
+	sub, err := c.SubscribeWildcard(context.Background(), "user.{userId}.events")
+	suite.Require().NoError(err)
+
+	suite.publish(c, "user.1234.events", "hello")
+	msg := suite.receive(sub)
+	msg.Ack()
+	suite.Require().Equal("hello", string(msg.Payload))
+	suite.Require().Equal("user.1234.events", msg.Channel)
+
+	suite.publish(c, "order.1234.events", "hello")
+	suite.noReceive(sub)
+
+	// Subscription should be removed on cancel
+	sub.Cancel(context.Background())
+	suite.Require().Empty(c.broker.subscriptions)
+	suite.Require().Empty(c.broker.wildcards)
+}
@@ -6,6 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
 	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers"
@@ -13,8 +17,14 @@ import (
 	"github.com/segmentio/kafka-go/sasl"
 )
 
-// Check that it still fills the interface.
-var _ extensions.BrokerController = (*Controller)(nil)
+// Check that it still fills the interfaces.
+var (
+	_ extensions.BrokerController         = (*Controller)(nil)
+	_ extensions.BrokerWildcardSubscriber = (*Controller)(nil)
+	_ extensions.BrokerOptionsSubscriber  = (*Controller)(nil)
+
+	_ extensions.BrokerWildcardOptionsSubscriber = (*Controller)(nil)
+)
 
 // Controller is the Kafka implementation for asyncapi-codegen.
 type Controller struct {
@@ -30,9 +40,48 @@ type Controller struct {
 
 	connectionTest bool
 
+	// topicPartitions is the number of partitions of the topics created by
+	// the controller
+	topicPartitions int
+
+	// Subscription only
+	groupBalancers []kafka.GroupBalancer
+	// topicsRefreshInterval is the interval between two listings of the topics
+	// matching a wildcard subscription
+	topicsRefreshInterval time.Duration
+
+	// Publication only
+	transport    kafka.RoundTripper
+	balancer     kafka.Balancer
+	batchSize    int
+	batchTimeout time.Duration
+	async        bool
+	asyncErrors  AsyncErrorHandler
+	requiredAcks kafka.RequiredAcks
+	compression  kafka.Compression
+
+	// writers are the long-lived writers, by topic, and closed is set when
+	// the controller is closed so no writer is created afterward
+	writers      map[string]*kafka.Writer
+	closed       bool
+	writersMutex sync.Mutex
+
 	logger extensions.Logger
 }
 
+// AsyncErrorHandler is a function called with the messages that couldn't be
+// published on a topic, when publishing asynchronously.
+type AsyncErrorHandler func(topic string, msgs []extensions.BrokerMessage, err error)
+
+const (
+	// DefaultBatchSize is the default maximum number of messages written at
+	// once on a topic.
+	DefaultBatchSize = 100
+	// DefaultBatchTimeout is the default linger time: the maximum time to wait
+	// for a batch to be full before writing it.
+	DefaultBatchTimeout = 5 * time.Millisecond
+)
+
 // MessagesHandler is a function that can be used to process messages from the broker.
 type MessagesHandler func(
 	ctx context.Context,
@@ -46,16 +95,26 @@ type ControllerOption func(controller *Controller)
 
 // NewController creates a new KafkaController that fulfill the BrokerLinker interface.
 func NewController(hosts []string, options ...ControllerOption) (*Controller, error) {
+	// Copy the default dialer, so the options don't modify it
+	dialer := *kafka.DefaultDialer
+
 	// Create default controller
 	controller := &Controller{
-		hosts:          hosts,
-		logger:         extensions.DummyLogger{},
-		groupID:        brokers.DefaultQueueGroupID,
-		dialer:         kafka.DefaultDialer,
-		partition:      0,
-		maxBytes:       10e6, // 10MB
-		autoCommit:     true,
-		connectionTest: true,
+		hosts:                 hosts,
+		logger:                extensions.DummyLogger{},
+		groupID:               brokers.DefaultQueueGroupID,
+		dialer:                &dialer,
+		partition:             0,
+		maxBytes:              10e6, // 10MB
+		autoCommit:            true,
+		connectionTest:        true,
+		topicPartitions:       1,
+		topicsRefreshInterval: DefaultTopicsRefreshInterval,
+		balancer:              &kafka.Hash{},
+		batchSize:             DefaultBatchSize,
+		batchTimeout:          DefaultBatchTimeout,
+		requiredAcks:          kafka.RequireAll,
+		writers:               make(map[string]*kafka.Writer),
 	}
 
 	// Execute options
@@ -63,6 +122,15 @@ func NewController(hosts []string, options ...ControllerOption) (*Controller, er
 		option(controller)
 	}
 
+	// Create the transport shared by the writers, reusing the optional TLS and
+	// SASL mechanism from the dialer
+	if controller.transport == nil {
+		controller.transport = &kafka.Transport{
+			TLS:  controller.dialer.TLS.Clone(),
+			SASL: controller.dialer.SASLMechanism,
+		}
+	}
+
 	// kafka has no ping or something like this to test if dialer can create a successful connection to kafka
 	// so if connectionTest is enabled create a connection and try to list brokers from kafka and validate
 	// we can make a connection to kafka
@@ -82,6 +150,17 @@ func NewController(hosts []string, options ...ControllerOption) (*Controller, er
 	return controller, nil
 }
 
+// NewControllerFromServer creates a new KafkaController from a server of the
+// specification, with its variables already replaced. The host can contain
+// several hosts separated by commas, and TLS is used for 'kafka-secure' protocol.
+func NewControllerFromServer(srv extensions.Server, options ...ControllerOption) (*Controller, error) {
+	if srv.Protocol == "kafka-secure" {
+		options = append([]ControllerOption{WithTLS(&tls.Config{MinVersion: tls.VersionTLS12})}, options...)
+	}
+
+	return NewController(strings.Split(srv.Host, ","), options...)
+}
+
 // WithGroupID set a custom group ID for channel subscription.
 func WithGroupID(groupID string) ControllerOption {
 	return func(controller *Controller) {
@@ -89,13 +168,41 @@ func WithGroupID(groupID string) ControllerOption {
 	}
 }
 
-// WithPartition set the partition to use for the topic.
+// WithPartition set the partition to read from the topic, when there is no
+// group ID (see WithGroupID). With a group ID, the partitions of the topic are
+// assigned to the members of the consumer group.
 func WithPartition(partition int) ControllerOption {
 	return func(controller *Controller) {
 		controller.partition = partition
 	}
 }
 
+// WithGroupBalancers set the strategies used to assign the partitions of the
+// topics to the members of the consumer group (i.e. kafka.RoundRobinGroupBalancer).
+// The default strategies are kafka.RangeGroupBalancer and kafka.RoundRobinGroupBalancer.
+func WithGroupBalancers(balancers ...kafka.GroupBalancer) ControllerOption {
+	return func(controller *Controller) {
+		controller.groupBalancers = balancers
+	}
+}
+
+// WithTopicPartitions set the number of partitions of the topics created by the
+// controller (default is 1).
+func WithTopicPartitions(partitions int) ControllerOption {
+	return func(controller *Controller) {
+		controller.topicPartitions = partitions
+	}
+}
+
+// WithTopicsRefreshInterval set the interval between two listings of the topics
+// matching a wildcard subscription, to consume the topics created afterward
+// (default is DefaultTopicsRefreshInterval).
+func WithTopicsRefreshInterval(interval time.Duration) ControllerOption {
+	return func(controller *Controller) {
+		controller.topicsRefreshInterval = interval
+	}
+}
+
 // WithMaxBytes set the maximum size of a message.
 func WithMaxBytes(maxBytes int) ControllerOption {
 	return func(controller *Controller) {
@@ -140,30 +247,86 @@ func WithConnectionTest(enabled bool) ControllerOption {
 	}
 }
 
+// WithBalancer set the balancer choosing the partition of the published
+// messages. The default is kafka.Hash, sending the messages with the same key
+// to the same partition (and messages without key in round-robin). Other
+// balancers include kafka.Murmur2Balancer (compatible with the Java client),
+// kafka.RoundRobin and kafka.LeastBytes.
+func WithBalancer(balancer kafka.Balancer) ControllerOption {
+	return func(controller *Controller) {
+		controller.balancer = balancer
+	}
+}
+
+// WithBatchSize set the maximum number of messages written at once on a topic
+// (default is DefaultBatchSize).
+func WithBatchSize(size int) ControllerOption {
+	return func(controller *Controller) {
+		controller.batchSize = size
+	}
+}
+
+// WithBatchTimeout set the linger time: the maximum time to wait for a batch to
+// be full before writing it (default is DefaultBatchTimeout). When publishing
+// synchronously, a publication can wait up to this time.
+func WithBatchTimeout(linger time.Duration) ControllerOption {
+	return func(controller *Controller) {
+		controller.batchTimeout = linger
+	}
+}
+
+// WithAsync set the publication as asynchronous: Publish returns as soon as the
+// message is added to a batch, without waiting for it to be written. The errors
+// are given to the handler if not nil, or logged otherwise. Topics are created
+// on first publication if they don't exist.
+func WithAsync(onError AsyncErrorHandler) ControllerOption {
+	return func(controller *Controller) {
+		controller.async = true
+		controller.asyncErrors = onError
+	}
+}
+
+// WithRequiredAcks set the number of acknowledgments required from the
+// partition replicas for a write to succeed (default is kafka.RequireAll).
+func WithRequiredAcks(acks kafka.RequiredAcks) ControllerOption {
+	return func(controller *Controller) {
+		controller.requiredAcks = acks
+	}
+}
+
+// WithCompression set the compression codec of the published messages
+// (i.e. kafka.Snappy, kafka.Zstd). Messages are not compressed by default.
+func WithCompression(codec kafka.Compression) ControllerOption {
+	return func(controller *Controller) {
+		controller.compression = codec
+	}
+}
+
+// WithTransport set the transport used by the writers to publish messages,
+// instead of a transport created with the TLS configuration and the SASL
+// mechanism of the controller.
+func WithTransport(transport kafka.RoundTripper) ControllerOption {
+	return func(controller *Controller) {
+		controller.transport = transport
+	}
+}
+
 // Publish a message to the broker.
 func (c *Controller) Publish(ctx context.Context, channel string, um extensions.BrokerMessage) error {
-	// Create new writer
-	w := kafka.Writer{
-		Addr:     kafka.TCP(c.hosts...),
-		Topic:    channel,
-		Balancer: &kafka.LeastBytes{},
-		Transport: &kafka.Transport{
-			// reuse the optionally TLS and SASLMechanism from dialer provided by the user to pass it to the writer
-			// it can be nil
-			TLS:  c.dialer.TLS.Clone(),
-			SASL: c.dialer.SASLMechanism,
-		},
+	// Get the writer of the topic
+	w, err := c.writer(ctx, channel)
+	if err != nil {
+		return err
 	}
 
-	defer w.Close()
-
 	// Create the message
 	msg := kafka.Message{
 		Headers: make([]kafka.Header, 0),
 	}
 
-	// Set message content and headers
+	// Set message content, key and headers
 	msg.Value = um.Payload
+	msg.Key = um.Key
 	for k, v := range um.Headers {
 		msg.Headers = append(msg.Headers, kafka.Header{Key: k, Value: v})
 	}
@@ -195,33 +358,150 @@ func (c *Controller) Publish(ctx context.Context, channel string, um extensions.
 
 // Subscribe to messages from the broker.
 func (c *Controller) Subscribe(ctx context.Context, channel string) (extensions.BrokerChannelSubscription, error) {
+	return c.SubscribeWithOptions(ctx, channel, extensions.SubscribeOptions{})
+}
+
+// SubscribeWithOptions subscribes to messages from the broker, with the options.
+//
+// The queue group (or else the durable name) replaces the controller group ID.
+// Earliest and latest start positions apply when the group has no committed
+// offset yet. Starting at a time or an offset reads the partition (see
+// WithPartition) without consumer group, so it can't be used with a queue group
+// or a durable name.
+func (c *Controller) SubscribeWithOptions(
+	ctx context.Context,
+	channel string,
+	opts extensions.SubscribeOptions,
+) (extensions.BrokerChannelSubscription, error) {
+	config, err := c.readerConfig(channel, opts)
+	if err != nil {
+		return extensions.BrokerChannelSubscription{}, err
+	}
+
 	// Check that topic exists before
 	if err := c.checkTopicExistOrCreateIt(ctx, channel); err != nil {
 		return extensions.BrokerChannelSubscription{}, err
 	}
 
-	// Create reader
-	r := kafka.NewReader(kafka.ReaderConfig{
-		Brokers:   c.hosts,
-		Topic:     channel,
-		Partition: c.partition,
-		MaxBytes:  c.maxBytes,
-		GroupID:   c.groupID,
-		Dialer:    c.dialer,
-	})
+	// Create the reader and move it to the start position
+	r := c.newReader(config)
+	if err := setReaderOffset(ctx, r, opts); err != nil {
+		if errClose := r.Close(); errClose != nil {
+			c.logger.Error(ctx, errClose.Error())
+		}
+		return extensions.BrokerChannelSubscription{}, err
+	}
+
+	return c.read(ctx, r, c.bufferSize(opts.BufferSize)), nil
+}
+
+// readerConfig returns the configuration of the reader of a subscription with
+// the options.
+func (c *Controller) readerConfig(channel string, opts extensions.SubscribeOptions) (kafka.ReaderConfig, error) {
+	config := kafka.ReaderConfig{Topic: channel, GroupID: c.groupID}
+	if opts.QueueGroup != "" {
+		config.GroupID = opts.QueueGroup
+	} else if opts.DurableName != "" {
+		config.GroupID = opts.DurableName
+	}
+
+	switch opts.StartPosition {
+	case extensions.StartPositionDefault:
+	case extensions.StartPositionEarliest:
+		config.StartOffset = kafka.FirstOffset
+	case extensions.StartPositionLatest:
+		config.StartOffset = kafka.LastOffset
+	case extensions.StartPositionTime, extensions.StartPositionOffset:
+		if opts.QueueGroup != "" || opts.DurableName != "" {
+			return kafka.ReaderConfig{}, fmt.Errorf(
+				"%w: starting at a %s is not possible with a consumer group",
+				extensions.ErrSubscribeOptionNotSupported, opts.StartPosition)
+		}
+		config.GroupID = ""
+	default:
+		return kafka.ReaderConfig{}, fmt.Errorf("%w: start position %s",
+			extensions.ErrSubscribeOptionNotSupported, opts.StartPosition)
+	}
+
+	// Read the partition only without consumer group, as the partitions are
+	// assigned to the group members otherwise
+	if config.GroupID == "" {
+		config.Partition = c.partition
+	}
+
+	return config, nil
+}
+
+// setReaderOffset sets the offset of a reader without consumer group to the
+// start position of the options.
+func setReaderOffset(ctx context.Context, r *kafka.Reader, opts extensions.SubscribeOptions) error {
+	if r.Config().GroupID != "" {
+		return nil
+	}
+
+	switch opts.StartPosition {
+	case extensions.StartPositionLatest:
+		return r.SetOffset(kafka.LastOffset)
+	case extensions.StartPositionTime:
+		return r.SetOffsetAt(ctx, opts.StartTime)
+	case extensions.StartPositionOffset:
+		return r.SetOffset(opts.StartOffset)
+	default:
+		return nil
+	}
+}
+
+// matchingTopics returns the existing topics matching the address with parameters.
+func (c *Controller) matchingTopics(address string) ([]string, error) {
+	// Get connection to first host
+	conn, err := c.dialer.Dial("tcp", c.hosts[0])
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// Read partitions
+	partitions, err := conn.ReadPartitions()
+	if err != nil {
+		return nil, err
+	}
+
+	// Get matching topics from partitions
+	re := extensions.ChannelAddressRegexp(address)
+	topics := make([]string, 0)
+	for _, p := range partitions {
+		if re.MatchString(p.Topic) && !slices.Contains(topics, p.Topic) {
+			topics = append(topics, p.Topic)
+		}
+	}
 
+	return topics, nil
+}
+
+// newReader creates a reader from the configuration, completed with the
+// controller parameters.
+func (c *Controller) newReader(config kafka.ReaderConfig) *kafka.Reader {
+	config.Brokers = c.hosts
+	config.MaxBytes = c.maxBytes
+	config.GroupBalancers = c.groupBalancers
+	config.Dialer = c.dialer
+	return kafka.NewReader(config)
+}
+
+// read transmits the messages of the reader to a new subscription.
+func (c *Controller) read(
+	ctx context.Context,
+	r *kafka.Reader,
+	bufferSize int,
+) extensions.BrokerChannelSubscription {
 	// Create subscription
 	sub := extensions.NewBrokerChannelSubscription(
-		make(chan extensions.AcknowledgeableBrokerMessage, brokers.BrokerMessagesQueueSize),
+		make(chan extensions.AcknowledgeableBrokerMessage, bufferSize),
 		make(chan any, 1),
 	)
 
 	// Handle events
-	if c.autoCommit {
-		go autoCommitMessagesHandler(&c.logger)(ctx, r, sub)
-	} else {
-		go manualCommitMessagesHandler(&c.logger)(ctx, r, sub)
-	}
+	c.handleMessages(ctx, r, sub)
 
 	// Wait for cancellation and stop the kafka listener when it happens
 	sub.WaitForCancellationAsync(func() {
@@ -230,7 +510,26 @@ func (c *Controller) Subscribe(ctx context.Context, channel string) (extensions.
 		}
 	})
 
-	return sub, nil
+	return sub
+}
+
+// handleMessages transmits asynchronously the messages of the reader to the
+// subscription, until the reader is closed.
+func (c *Controller) handleMessages(ctx context.Context, r *kafka.Reader, sub extensions.BrokerChannelSubscription) {
+	if c.autoCommit {
+		go autoCommitMessagesHandler(&c.logger)(ctx, r, sub)
+	} else {
+		go manualCommitMessagesHandler(&c.logger)(ctx, r, sub)
+	}
+}
+
+// bufferSize returns the size of the buffer of a subscription, the controller
+// one if it is not set.
+func (c *Controller) bufferSize(size int) int {
+	if size > 0 {
+		return size
+	}
+	return brokers.BrokerMessagesQueueSize
 }
 
 func (c *Controller) checkTopicExistOrCreateIt(ctx context.Context, topic string) error {
@@ -245,7 +544,7 @@ func (c *Controller) checkTopicExistOrCreateIt(ctx context.Context, topic string
 		// Create topic
 		topicConfigs := []kafka.TopicConfig{{
 			Topic:             topic,
-			NumPartitions:     1,
+			NumPartitions:     c.topicPartitions,
 			ReplicationFactor: 1,
 		}}
 		err = conn.CreateTopics(topicConfigs...)
@@ -305,6 +604,8 @@ func autoCommitMessagesHandler(
 				extensions.BrokerMessage{
 					Headers: headers,
 					Payload: msg.Value,
+					Key:     msg.Key,
+					Channel: msg.Topic,
 				},
 				BrokerAcknowledgment{NoopCommit}))
 		}
@@ -339,6 +640,8 @@ func manualCommitMessagesHandler(
 				extensions.BrokerMessage{
 					Headers: headers,
 					Payload: msg.Value,
+					Key:     msg.Key,
+					Channel: msg.Topic,
 				},
 				BrokerAcknowledgment{doCommit: func() {
 					if err := r.CommitMessages(ctx, msg); err != nil {
@@ -3,8 +3,11 @@ package kafka
 import (
 	"crypto/tls"
 	"testing"
+	"time"
 
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
 	testutil "github.com/lerenn/asyncapi-codegen/pkg/utils/test"
+	"github.com/segmentio/kafka-go"
 	"github.com/segmentio/kafka-go/sasl/scram"
 	"github.com/stretchr/testify/assert"
 )
@@ -78,3 +81,35 @@ func TestSecureConnectionToKafka(t *testing.T) {
 			assert.NoError(t, err, "new connection to TLS secured kafka broker with TLS config and basic credentials should return no error") //nolint:lll
 		})
 }
+
+func TestReaderConfig(t *testing.T) {
+	c := &Controller{groupID: "controller", partition: 2}
+
+	t.Run("queue group replaces the group ID", func(t *testing.T) {
+		config, err := c.readerConfig("topic", extensions.NewSubscribeOptions(
+			extensions.WithQueueGroup("group"),
+			extensions.WithDurableName("durable"),
+			extensions.WithStartEarliest()))
+		assert.NoError(t, err)
+		assert.Equal(t, kafka.ReaderConfig{Topic: "topic", GroupID: "group", StartOffset: kafka.FirstOffset}, config)
+	})
+
+	t.Run("durable name replaces the group ID", func(t *testing.T) {
+		config, err := c.readerConfig("topic", extensions.NewSubscribeOptions(
+			extensions.WithDurableName("durable"),
+			extensions.WithStartLatest()))
+		assert.NoError(t, err)
+		assert.Equal(t, kafka.ReaderConfig{Topic: "topic", GroupID: "durable", StartOffset: kafka.LastOffset}, config)
+	})
+
+	t.Run("start offset reads the partition without group", func(t *testing.T) {
+		config, err := c.readerConfig("topic", extensions.NewSubscribeOptions(extensions.WithStartOffset(42)))
+		assert.NoError(t, err)
+		assert.Equal(t, kafka.ReaderConfig{Topic: "topic", Partition: 2}, config)
+
+		_, err = c.readerConfig("topic", extensions.NewSubscribeOptions(
+			extensions.WithStartTime(time.Now()),
+			extensions.WithQueueGroup("group")))
+		assert.ErrorIs(t, err, extensions.ErrSubscribeOptionNotSupported)
+	})
+}
@@ -0,0 +1,64 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// SecurityOptions returns the options to authenticate to the server with the
+// first of its security schemes that is supported, and the credentials from
+// the provider:
+//
+//   - 'plain' and 'userPassword' use SASL/PLAIN;
+//   - 'scramSha256' and 'scramSha512' use SASL/SCRAM;
+//   - 'X509' uses TLS with a client certificate.
+//
+// No option is returned if the server has no security scheme.
+func SecurityOptions(
+	ctx context.Context,
+	srv extensions.Server,
+	provider extensions.CredentialsProvider,
+) ([]ControllerOption, error) {
+	scheme, creds, err := srv.SecurityCredentials(ctx, provider,
+		extensions.SecuritySchemePlain,
+		extensions.SecuritySchemeUserPassword,
+		extensions.SecuritySchemeScramSha256,
+		extensions.SecuritySchemeScramSha512,
+		extensions.SecuritySchemeX509)
+	if err != nil || scheme == nil {
+		return nil, err
+	}
+
+	// Use TLS with client certificate
+	if scheme.Type == extensions.SecuritySchemeX509 {
+		tlsConfig, err := creds.TLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		return []ControllerOption{WithTLS(tlsConfig)}, nil
+	}
+
+	// Otherwise use SASL
+	if creds.Username == "" || creds.Password == "" {
+		return nil, fmt.Errorf("%w: username and password for %q security scheme of server %q",
+			extensions.ErrMissingCredentials, scheme.Name, srv.Name)
+	}
+
+	var mechanism sasl.Mechanism = plain.Mechanism{Username: creds.Username, Password: creds.Password}
+	switch scheme.Type {
+	case extensions.SecuritySchemeScramSha256:
+		mechanism, err = scram.Mechanism(scram.SHA256, creds.Username, creds.Password)
+	case extensions.SecuritySchemeScramSha512:
+		mechanism, err = scram.Mechanism(scram.SHA512, creds.Username, creds.Password)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return []ControllerOption{WithSasl(mechanism)}, nil
+}
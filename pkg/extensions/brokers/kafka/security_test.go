@@ -0,0 +1,71 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurityOptions(t *testing.T) {
+	provider := extensions.CredentialsProviderFunc(
+		func(context.Context, string, extensions.SecurityScheme) (extensions.Credentials, error) {
+			return extensions.Credentials{Username: "user", Password: "secret"}, nil
+		})
+
+	t.Run("no security scheme", func(t *testing.T) {
+		opts, err := SecurityOptions(context.Background(), extensions.Server{Name: "production"}, provider)
+		require.NoError(t, err)
+		assert.Empty(t, opts)
+	})
+
+	t.Run("plain security scheme", func(t *testing.T) {
+		srv := extensions.Server{
+			Name:     "production",
+			Security: []extensions.SecurityScheme{{Name: "sasl", Type: extensions.SecuritySchemePlain}},
+		}
+		opts, err := SecurityOptions(context.Background(), srv, provider)
+		require.NoError(t, err)
+
+		ctrl, err := NewController([]string{"localhost:9092"}, append(opts, WithConnectionTest(false))...)
+		require.NoError(t, err)
+		assert.Equal(t, plain.Mechanism{Username: "user", Password: "secret"}, ctrl.dialer.SASLMechanism)
+	})
+
+	t.Run("scram security scheme", func(t *testing.T) {
+		srv := extensions.Server{
+			Name:     "production",
+			Security: []extensions.SecurityScheme{{Name: "saslScram", Type: extensions.SecuritySchemeScramSha512}},
+		}
+		opts, err := SecurityOptions(context.Background(), srv, provider)
+		require.NoError(t, err)
+
+		ctrl, err := NewController([]string{"localhost:9092"}, append(opts, WithConnectionTest(false))...)
+		require.NoError(t, err)
+		assert.Equal(t, "SCRAM-SHA-512", ctrl.dialer.SASLMechanism.Name())
+	})
+
+	t.Run("missing credentials", func(t *testing.T) {
+		srv := extensions.Server{
+			Name:     "production",
+			Security: []extensions.SecurityScheme{{Name: "saslScram", Type: extensions.SecuritySchemeScramSha256}},
+		}
+		_, err := SecurityOptions(context.Background(), srv, extensions.CredentialsProviderFunc(
+			func(context.Context, string, extensions.SecurityScheme) (extensions.Credentials, error) {
+				return extensions.Credentials{}, nil
+			}))
+		require.ErrorIs(t, err, extensions.ErrMissingCredentials)
+	})
+
+	t.Run("unsupported security scheme", func(t *testing.T) {
+		srv := extensions.Server{
+			Name:     "production",
+			Security: []extensions.SecurityScheme{{Name: "oauth", Type: extensions.SecuritySchemeOAuth2}},
+		}
+		_, err := SecurityOptions(context.Background(), srv, provider)
+		require.ErrorIs(t, err, extensions.ErrUnsupportedSecurityScheme)
+	})
+}
@@ -0,0 +1,175 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/segmentio/kafka-go"
+)
+
+// DefaultTopicsRefreshInterval is the default interval between two listings of
+// the topics matching a wildcard subscription.
+const DefaultTopicsRefreshInterval = 10 * time.Second
+
+// wildcardReader reads the topics matching the address of a wildcard
+// subscription, and recreates its reader when the matching topics change.
+type wildcardReader struct {
+	controller *Controller
+	sub        extensions.BrokerChannelSubscription
+	config     kafka.ReaderConfig
+
+	// listTopics returns the topics currently matching the address
+	listTopics func() ([]string, error)
+
+	// reader is the reader of the topics, nil if there is no matching topic.
+	// It is only modified by the refreshing goroutine once started.
+	reader *kafka.Reader
+	topics []string
+
+	stop chan any
+	done chan any
+}
+
+// SubscribeWildcard subscribes to messages from the broker, on every topic
+// matching the address with parameters (i.e. 'user.{userId}.events').
+//
+// The matching topics are listed again periodically (see
+// WithTopicsRefreshInterval): when they change, the reader is recreated to
+// consume the new topics too. A group ID is required.
+func (c *Controller) SubscribeWildcard(
+	ctx context.Context,
+	address string,
+) (extensions.BrokerChannelSubscription, error) {
+	return c.SubscribeWildcardWithOptions(ctx, address, extensions.SubscribeOptions{})
+}
+
+// SubscribeWildcardWithOptions subscribes to messages from the broker, on every
+// topic matching the address with parameters, with the options.
+//
+// The queue group (or else the durable name) replaces the controller group ID,
+// and earliest and latest start positions apply to the topics without committed
+// offset for the group. As the topics are read with a consumer group, starting
+// at a time or an offset is not supported.
+func (c *Controller) SubscribeWildcardWithOptions(
+	ctx context.Context,
+	address string,
+	opts extensions.SubscribeOptions,
+) (extensions.BrokerChannelSubscription, error) {
+	config := kafka.ReaderConfig{GroupID: c.groupID}
+	if opts.QueueGroup != "" {
+		config.GroupID = opts.QueueGroup
+	} else if opts.DurableName != "" {
+		config.GroupID = opts.DurableName
+	}
+	if config.GroupID == "" {
+		return extensions.BrokerChannelSubscription{}, fmt.Errorf(
+			"%w: a group ID is required to subscribe to several topics", extensions.ErrWildcardNotSupported)
+	}
+
+	switch opts.StartPosition {
+	case extensions.StartPositionDefault:
+	case extensions.StartPositionEarliest:
+		config.StartOffset = kafka.FirstOffset
+	case extensions.StartPositionLatest:
+		config.StartOffset = kafka.LastOffset
+	default:
+		return extensions.BrokerChannelSubscription{}, fmt.Errorf(
+			"%w: starting at a %s is not possible with a wildcard subscription",
+			extensions.ErrSubscribeOptionNotSupported, opts.StartPosition)
+	}
+
+	return c.subscribeWildcard(ctx, address, config, c.bufferSize(opts.BufferSize))
+}
+
+// subscribeWildcard reads every topic matching the address with the reader
+// configuration, listing the matching topics periodically.
+func (c *Controller) subscribeWildcard(
+	ctx context.Context,
+	address string,
+	config kafka.ReaderConfig,
+	bufferSize int,
+) (extensions.BrokerChannelSubscription, error) {
+	w := &wildcardReader{
+		controller: c,
+		sub: extensions.NewBrokerChannelSubscription(
+			make(chan extensions.AcknowledgeableBrokerMessage, bufferSize),
+			make(chan any, 1),
+		),
+		config:     config,
+		listTopics: func() ([]string, error) { return c.matchingTopics(address) },
+		stop:       make(chan any),
+		done:       make(chan any),
+	}
+
+	// Get the topics currently matching the address
+	topics, err := w.listTopics()
+	if err != nil {
+		return extensions.BrokerChannelSubscription{}, err
+	}
+	w.update(ctx, topics)
+
+	// Look for new topics until the subscription is canceled
+	go w.refresh(ctx, c.topicsRefreshInterval)
+	w.sub.WaitForCancellationAsync(func() {
+		close(w.stop)
+		<-w.done
+		w.update(ctx, nil)
+	})
+
+	return w.sub, nil
+}
+
+// refresh lists the matching topics at each interval and updates the reader
+// accordingly, until the subscription is stopped.
+func (w *wildcardReader) refresh(ctx context.Context, interval time.Duration) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		}
+
+		topics, err := w.listTopics()
+		if err != nil {
+			w.controller.logger.Warning(ctx, fmt.Sprintf("Error when listing topics: %q", err.Error()))
+			continue
+		}
+		w.update(ctx, topics)
+	}
+}
+
+// update recreates the reader if the topics are not the ones currently read.
+// Without topic, the current reader is closed and none is created.
+func (w *wildcardReader) update(ctx context.Context, topics []string) {
+	topics = slices.Clone(topics)
+	slices.Sort(topics)
+	if slices.Equal(topics, w.topics) && (w.reader != nil || len(topics) == 0) {
+		return
+	}
+
+	// Close the current reader, its messages handler stops on closing
+	if w.reader != nil {
+		if err := w.reader.Close(); err != nil {
+			w.controller.logger.Error(ctx, err.Error())
+		}
+		w.reader = nil
+	}
+	w.topics = topics
+
+	if len(topics) == 0 {
+		return
+	}
+
+	config := w.config
+	config.GroupTopics = topics
+	w.reader = w.controller.newReader(config)
+	w.controller.handleMessages(ctx, w.reader, w.sub)
+}
@@ -0,0 +1,91 @@
+package kafka
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWildcardReader(t *testing.T, listTopics func() ([]string, error)) *wildcardReader {
+	return &wildcardReader{
+		controller: newStandInController(t, &standIn{}),
+		sub: extensions.NewBrokerChannelSubscription(
+			make(chan extensions.AcknowledgeableBrokerMessage, 1),
+			make(chan any, 1),
+		),
+		config:     kafka.ReaderConfig{GroupID: "group"},
+		listTopics: listTopics,
+		stop:       make(chan any),
+		done:       make(chan any),
+	}
+}
+
+func TestWildcardReader(t *testing.T) {
+	t.Run("reader is recreated when the matching topics change", func(t *testing.T) {
+		w := newTestWildcardReader(t, nil)
+		ctx := context.Background()
+
+		// No reader without matching topic
+		w.update(ctx, nil)
+		assert.Nil(t, w.reader)
+
+		w.update(ctx, []string{"user.2.events", "user.1.events"})
+		require.NotNil(t, w.reader)
+		assert.Equal(t, []string{"user.1.events", "user.2.events"}, w.reader.Config().GroupTopics)
+		assert.Equal(t, "group", w.reader.Config().GroupID)
+
+		// Same topics keep the reader
+		r := w.reader
+		w.update(ctx, []string{"user.1.events", "user.2.events"})
+		assert.Same(t, r, w.reader)
+
+		// A new topic recreates it
+		w.update(ctx, []string{"user.1.events", "user.2.events", "user.3.events"})
+		assert.NotSame(t, r, w.reader)
+		assert.Len(t, w.reader.Config().GroupTopics, 3)
+
+		w.update(ctx, nil)
+		assert.Nil(t, w.reader)
+	})
+
+	t.Run("topics created afterward are read", func(t *testing.T) {
+		var listings atomic.Int64
+		listed := make(chan any, 10)
+		w := newTestWildcardReader(t, func() ([]string, error) {
+			listed <- true
+			if listings.Add(1) == 1 {
+				return nil, nil
+			}
+			return []string{"user.1.events"}, nil
+		})
+		ctx := context.Background()
+
+		// No topic at subscription
+		topics, err := w.listTopics()
+		require.NoError(t, err)
+		w.update(ctx, topics)
+		assert.Nil(t, w.reader)
+
+		// The topic is found on refresh
+		go w.refresh(ctx, time.Millisecond)
+		for i := 0; i < 2; i++ {
+			select {
+			case <-listed:
+			case <-time.After(time.Second):
+				require.FailNow(t, "topics not listed")
+			}
+		}
+		close(w.stop)
+		<-w.done
+
+		require.NotNil(t, w.reader)
+		assert.Equal(t, []string{"user.1.events"}, w.reader.Config().GroupTopics)
+		w.update(ctx, nil)
+	})
+}
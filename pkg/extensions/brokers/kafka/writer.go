@@ -0,0 +1,149 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/segmentio/kafka-go"
+)
+
+// ErrClosed is raised when publishing with a closed controller.
+var ErrClosed = fmt.Errorf("%w: kafka controller is closed", extensions.ErrAsyncAPI)
+
+// writer returns the long-lived writer of the topic, creating it if needed.
+func (c *Controller) writer(ctx context.Context, topic string) (*kafka.Writer, error) {
+	// Reuse the writer if it already exists
+	if w, exists, err := c.existingWriter(topic); err != nil || exists {
+		return w, err
+	}
+
+	// Errors can't be retried when publishing asynchronously, so the topic
+	// should exist before the first publication. It is created without lock, to
+	// avoid blocking the publications on other topics.
+	if c.async {
+		if err := c.createTopic(ctx, topic); err != nil {
+			return nil, err
+		}
+	}
+
+	c.writersMutex.Lock()
+	defer c.writersMutex.Unlock()
+
+	// Check again, as the controller could have been closed or the writer
+	// created by another publication in the meantime
+	if c.closed {
+		return nil, ErrClosed
+	}
+	if w, exists := c.writers[topic]; exists {
+		return w, nil
+	}
+
+	w := &kafka.Writer{
+		Addr:         kafka.TCP(c.hosts...),
+		Topic:        topic,
+		Balancer:     c.balancer,
+		Transport:    c.transport,
+		BatchSize:    c.batchSize,
+		BatchTimeout: c.batchTimeout,
+		RequiredAcks: c.requiredAcks,
+		Compression:  c.compression,
+		Async:        c.async,
+	}
+	if c.async {
+		w.Completion = func(msgs []kafka.Message, err error) {
+			if err != nil {
+				c.handleAsyncError(topic, msgs, err)
+			}
+		}
+	}
+	c.writers[topic] = w
+
+	return w, nil
+}
+
+// existingWriter returns the writer of the topic if it exists, or an error if
+// the controller is closed.
+func (c *Controller) existingWriter(topic string) (*kafka.Writer, bool, error) {
+	c.writersMutex.Lock()
+	defer c.writersMutex.Unlock()
+
+	if c.closed {
+		return nil, false, ErrClosed
+	}
+
+	w, exists := c.writers[topic]
+	return w, exists, nil
+}
+
+// createTopic creates the topic with the writers transport, if it doesn't
+// already exist.
+func (c *Controller) createTopic(ctx context.Context, topic string) error {
+	client := &kafka.Client{
+		Addr:      kafka.TCP(c.hosts...),
+		Transport: c.transport,
+	}
+
+	res, err := client.CreateTopics(ctx, &kafka.CreateTopicsRequest{
+		Topics: []kafka.TopicConfig{{
+			Topic:             topic,
+			NumPartitions:     c.topicPartitions,
+			ReplicationFactor: 1,
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := res.Errors[topic]; err != nil && !errors.Is(err, kafka.TopicAlreadyExists) {
+		return err
+	}
+
+	return nil
+}
+
+// handleAsyncError gives the messages that couldn't be published asynchronously
+// to the error handler, or logs the error if there is none.
+func (c *Controller) handleAsyncError(topic string, msgs []kafka.Message, err error) {
+	if c.asyncErrors == nil {
+		c.logger.Error(context.Background(), fmt.Sprintf("Error when publishing %d messages on %q: %s",
+			len(msgs), topic, err.Error()))
+		return
+	}
+
+	bms := make([]extensions.BrokerMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		headers := make(map[string][]byte, len(msg.Headers))
+		for _, header := range msg.Headers {
+			headers[header.Key] = header.Value
+		}
+		bms = append(bms, extensions.BrokerMessage{
+			Headers: headers,
+			Payload: msg.Value,
+			Key:     msg.Key,
+			Channel: topic,
+		})
+	}
+	c.asyncErrors(topic, bms, err)
+}
+
+// Close closes everything related to the broker: the pending messages are
+// written before closing the writers. Publishing afterward returns ErrClosed.
+func (c *Controller) Close() {
+	c.writersMutex.Lock()
+	defer c.writersMutex.Unlock()
+
+	c.closed = true
+
+	for topic, w := range c.writers {
+		if err := w.Close(); err != nil {
+			c.logger.Error(context.Background(), fmt.Sprintf("Error when closing writer of %q: %s", topic, err.Error()))
+		}
+		delete(c.writers, topic)
+	}
+
+	if t, ok := c.transport.(*kafka.Transport); ok {
+		t.CloseIdleConnections()
+	}
+}
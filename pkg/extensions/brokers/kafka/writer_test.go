@@ -0,0 +1,370 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol"
+	"github.com/segmentio/kafka-go/protocol/createtopics"
+	"github.com/segmentio/kafka-go/protocol/metadata"
+	"github.com/segmentio/kafka-go/protocol/produce"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// standIn is a kafka.RoundTripper standing in for a single broker, that answers
+// in memory after a simulated latency.
+type standIn struct {
+	// latency is the time taken by each request sent to the broker, and
+	// handshake the time taken by the first request (connection and metadata).
+	// Metadata is then cached, like kafka.Transport does.
+	latency   time.Duration
+	handshake time.Duration
+	// produceErr is the error code returned for each produced message, if any.
+	produceErr kafka.Error
+	// partitions is the number of partitions per topic (1 if not set).
+	partitions int
+	// onCreateTopic is called with each topic to create, if set.
+	onCreateTopic func(topic string)
+
+	connected  sync.Once
+	produces   atomic.Int64
+	messages   atomic.Int64
+	topicsLock sync.Mutex
+	topics     []string
+	keysLock   sync.Mutex
+	keys       map[string][]int32 // partitions of the received messages, by key
+}
+
+func (s *standIn) RoundTrip(ctx context.Context, addr net.Addr, req protocol.Message) (protocol.Message, error) {
+	s.connected.Do(func() { time.Sleep(s.handshake) })
+	if _, ok := req.(*metadata.Request); !ok {
+		time.Sleep(s.latency)
+	}
+
+	switch req := req.(type) {
+	case *metadata.Request:
+		res := &metadata.Response{Brokers: []metadata.ResponseBroker{{NodeID: 0, Host: "localhost", Port: 9092}}}
+		for _, t := range req.TopicNames {
+			rt := metadata.ResponseTopic{Name: t}
+			for i := 0; i < max(s.partitions, 1); i++ {
+				rt.Partitions = append(rt.Partitions, metadata.ResponsePartition{PartitionIndex: int32(i)})
+			}
+			res.Topics = append(res.Topics, rt)
+		}
+		return res, nil
+	case *createtopics.Request:
+		res := &createtopics.Response{}
+		for _, t := range req.Topics {
+			if s.onCreateTopic != nil {
+				s.onCreateTopic(t.Name)
+			}
+			s.topicsLock.Lock()
+			s.topics = append(s.topics, t.Name)
+			s.topicsLock.Unlock()
+			res.Topics = append(res.Topics, createtopics.ResponseTopic{Name: t.Name})
+		}
+		return res, nil
+	case *produce.Request:
+		s.produces.Add(1)
+		res := &produce.Response{}
+		for _, t := range req.Topics {
+			rt := produce.ResponseTopic{Topic: t.Topic}
+			for _, p := range t.Partitions {
+				n, err := s.readRecords(p.Partition, p.RecordSet.Records)
+				if err != nil {
+					return nil, err
+				}
+				if s.produceErr == 0 {
+					s.messages.Add(n)
+				}
+				rt.Partitions = append(rt.Partitions, produce.ResponsePartition{
+					Partition: p.Partition,
+					ErrorCode: int16(s.produceErr),
+				})
+			}
+			res.Topics = append(res.Topics, rt)
+		}
+		if req.Acks == int16(kafka.RequireNone) {
+			return nil, nil
+		}
+		return res, nil
+	default:
+		return nil, errors.New("unsupported request by stand-in")
+	}
+}
+
+// readRecords reads the records produced on a partition, keeping the partition
+// of each key, and returns the number of records.
+func (s *standIn) readRecords(partition int32, records protocol.RecordReader) (int64, error) {
+	s.keysLock.Lock()
+	defer s.keysLock.Unlock()
+
+	var n int64
+	for {
+		r, err := records.ReadRecord()
+		if errors.Is(err, io.EOF) {
+			return n, nil
+		} else if err != nil {
+			return n, err
+		}
+		if r.Key != nil {
+			key, err := io.ReadAll(r.Key)
+			if err != nil {
+				return n, err
+			}
+			if s.keys == nil {
+				s.keys = make(map[string][]int32)
+			}
+			s.keys[string(key)] = append(s.keys[string(key)], partition)
+		}
+		if r.Value != nil {
+			r.Value.Close()
+		}
+		n++
+	}
+}
+
+func newStandInController(t testing.TB, s *standIn, options ...ControllerOption) *Controller {
+	options = append([]ControllerOption{WithConnectionTest(false), WithTransport(s)}, options...)
+	ctrl, err := NewController([]string{"localhost:9092"}, options...)
+	require.NoError(t, err)
+	return ctrl
+}
+
+func TestWriters(t *testing.T) {
+	msg := extensions.BrokerMessage{
+		Headers: map[string][]byte{"key": []byte("value")},
+		Payload: []byte("payload"),
+	}
+
+	t.Run("writers are reused per topic", func(t *testing.T) {
+		s := &standIn{}
+		ctrl := newStandInController(t, s)
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, ctrl.Publish(context.Background(), "topic.a", msg))
+		}
+		w := ctrl.writers["topic.a"]
+		require.NoError(t, ctrl.Publish(context.Background(), "topic.b", msg))
+
+		assert.Len(t, ctrl.writers, 2)
+		assert.Same(t, w, ctrl.writers["topic.a"])
+		assert.Equal(t, int64(4), s.messages.Load())
+		assert.Equal(t, int64(4), w.Stats().Messages+ctrl.writers["topic.b"].Stats().Messages)
+
+		ctrl.Close()
+		assert.Empty(t, ctrl.writers)
+	})
+
+	t.Run("concurrent publications are batched", func(t *testing.T) {
+		s := &standIn{}
+		ctrl := newStandInController(t, s, WithBatchSize(10), WithBatchTimeout(time.Second))
+		defer ctrl.Close()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, ctrl.Publish(context.Background(), "topic", msg))
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int64(10), s.messages.Load())
+		assert.Equal(t, int64(1), s.produces.Load())
+	})
+
+	t.Run("close writes pending asynchronous messages", func(t *testing.T) {
+		s := &standIn{}
+		ctrl := newStandInController(t, s, WithAsync(nil), WithBatchTimeout(time.Hour))
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, ctrl.Publish(context.Background(), "topic", msg))
+		}
+		assert.Equal(t, []string{"topic"}, s.topics)
+		assert.Equal(t, int64(0), s.messages.Load())
+
+		ctrl.Close()
+		assert.Equal(t, int64(5), s.messages.Load())
+	})
+
+	t.Run("asynchronous errors are given to the handler", func(t *testing.T) {
+		s := &standIn{produceErr: kafka.MessageSizeTooLarge}
+
+		var failed []extensions.BrokerMessage
+		ctrl := newStandInController(t, s, WithAsync(func(topic string, msgs []extensions.BrokerMessage, err error) {
+			assert.Equal(t, "topic", topic)
+			assert.ErrorIs(t, err, kafka.MessageSizeTooLarge)
+			failed = append(failed, msgs...)
+		}))
+
+		require.NoError(t, ctrl.Publish(context.Background(), "topic", msg))
+		ctrl.Close()
+
+		require.Len(t, failed, 1)
+		assert.Equal(t, msg.Payload, failed[0].Payload)
+		assert.Equal(t, msg.Headers, failed[0].Headers)
+		assert.Equal(t, "topic", failed[0].Channel)
+	})
+
+	t.Run("topic creation doesn't block the other topics", func(t *testing.T) {
+		release := make(chan any)
+		s := &standIn{onCreateTopic: func(topic string) {
+			if topic == "slow" {
+				<-release
+			}
+		}}
+		ctrl := newStandInController(t, s, WithAsync(nil))
+		defer ctrl.Close()
+
+		slow := make(chan error)
+		go func() { slow <- ctrl.Publish(context.Background(), "slow", msg) }()
+
+		fast := make(chan error)
+		go func() { fast <- ctrl.Publish(context.Background(), "fast", msg) }()
+		select {
+		case err := <-fast:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			assert.Fail(t, "publication blocked by the topic creation of another topic")
+		}
+
+		close(release)
+		assert.NoError(t, <-slow)
+	})
+
+	t.Run("publishing after close returns an error", func(t *testing.T) {
+		ctrl := newStandInController(t, &standIn{})
+		require.NoError(t, ctrl.Publish(context.Background(), "topic", msg))
+
+		ctrl.Close()
+		assert.ErrorIs(t, ctrl.Publish(context.Background(), "topic", msg), ErrClosed)
+		assert.ErrorIs(t, ctrl.Publish(context.Background(), "other", msg), ErrClosed)
+		assert.Empty(t, ctrl.writers)
+	})
+
+	t.Run("messages with the same key are published on the same partition", func(t *testing.T) {
+		s := &standIn{partitions: 8}
+		ctrl := newStandInController(t, s)
+		defer ctrl.Close()
+
+		for i := 0; i < 20; i++ {
+			key := []byte(fmt.Sprintf("order-%d", i%4))
+			require.NoError(t, ctrl.Publish(context.Background(), "topic", extensions.BrokerMessage{Key: key}))
+		}
+
+		require.Len(t, s.keys, 4)
+		used := make(map[int32]bool)
+		for key, partitions := range s.keys {
+			assert.Len(t, partitions, 5, key)
+			for _, p := range partitions {
+				assert.Equal(t, partitions[0], p, key)
+			}
+			used[partitions[0]] = true
+		}
+		assert.Greater(t, len(used), 1, "keys should be spread over several partitions")
+	})
+
+	t.Run("balancer can be changed", func(t *testing.T) {
+		s := &standIn{partitions: 4}
+		ctrl := newStandInController(t, s, WithBalancer(&kafka.RoundRobin{}))
+		defer ctrl.Close()
+
+		for i := 0; i < 4; i++ {
+			require.NoError(t, ctrl.Publish(context.Background(), "topic", extensions.BrokerMessage{Key: []byte("key")}))
+		}
+		assert.ElementsMatch(t, []int32{0, 1, 2, 3}, s.keys["key"])
+	})
+
+	t.Run("writers get the batching options", func(t *testing.T) {
+		ctrl := newStandInController(t, &standIn{},
+			WithBatchSize(42),
+			WithBatchTimeout(time.Millisecond),
+			WithRequiredAcks(kafka.RequireOne),
+			WithCompression(kafka.Snappy))
+		defer ctrl.Close()
+
+		w, err := ctrl.writer(context.Background(), "topic")
+		require.NoError(t, err)
+		assert.Equal(t, 42, w.BatchSize)
+		assert.Equal(t, time.Millisecond, w.BatchTimeout)
+		assert.Equal(t, kafka.RequireOne, w.RequiredAcks)
+		assert.Equal(t, kafka.Snappy, w.Compression)
+		assert.False(t, w.Async)
+	})
+}
+
+// benchmarkLatency is the simulated latency of the stand-in broker.
+const benchmarkLatency = 100 * time.Microsecond
+
+// BenchmarkPublishNewWriter publishes with a new writer and transport per
+// message, like the controller used to.
+func BenchmarkPublishNewWriter(b *testing.B) {
+	msg := kafka.Message{Value: []byte("payload")}
+	for i := 0; i < b.N; i++ {
+		w := kafka.Writer{
+			Addr:      kafka.TCP("localhost:9092"),
+			Topic:     "topic",
+			Balancer:  &kafka.LeastBytes{},
+			Transport: &standIn{latency: benchmarkLatency, handshake: time.Millisecond},
+		}
+		if err := w.WriteMessages(context.Background(), msg); err != nil {
+			b.Fatal(err)
+		}
+		w.Close()
+	}
+}
+
+// BenchmarkPublish publishes sequentially with the long-lived writers.
+func BenchmarkPublish(b *testing.B) {
+	ctrl := newStandInController(b, &standIn{latency: benchmarkLatency, handshake: time.Millisecond})
+	defer ctrl.Close()
+
+	msg := extensions.BrokerMessage{Payload: []byte("payload")}
+	for i := 0; i < b.N; i++ {
+		if err := ctrl.Publish(context.Background(), "topic", msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkPublishParallel publishes concurrently with the long-lived writers,
+// so the messages are batched.
+func BenchmarkPublishParallel(b *testing.B) {
+	ctrl := newStandInController(b, &standIn{latency: benchmarkLatency, handshake: time.Millisecond})
+	defer ctrl.Close()
+
+	msg := extensions.BrokerMessage{Payload: []byte("payload")}
+	b.SetParallelism(100)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := ctrl.Publish(context.Background(), "topic", msg); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+}
+
+// BenchmarkPublishAsync publishes asynchronously with the long-lived writers.
+func BenchmarkPublishAsync(b *testing.B) {
+	ctrl := newStandInController(b, &standIn{latency: benchmarkLatency, handshake: time.Millisecond}, WithAsync(nil))
+
+	msg := extensions.BrokerMessage{Payload: []byte("payload")}
+	for i := 0; i < b.N; i++ {
+		if err := ctrl.Publish(context.Background(), "topic", msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+	ctrl.Close()
+}
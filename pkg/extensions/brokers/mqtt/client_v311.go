@@ -0,0 +1,92 @@
+package mqtt
+
+import (
+	"context"
+	"fmt"
+
+	pahov311 "github.com/eclipse/paho.mqtt.golang"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+)
+
+// ErrHeadersNotSupported is returned when publishing a message with headers
+// that can't be transmitted with MQTT 3.1.1.
+var ErrHeadersNotSupported = fmt.Errorf("%w: headers are not supported with MQTT 3.1.1", extensions.ErrAsyncAPI)
+
+// clientV311 is the MQTT 3.1.1 client.
+//
+// NOTE: MQTT 3.1.1 does not support headers, so they are not transmitted.
+// Only the content type and message name headers are dropped, as they can be
+// found on reception from the specification and the payload discriminator.
+// Publishing a message with other headers fails with ErrHeadersNotSupported.
+type clientV311 struct {
+	client pahov311.Client
+}
+
+func newClientV311(c *Controller) (*clientV311, error) {
+	opts := pahov311.NewClientOptions().
+		AddBroker(c.url).
+		SetClientID(c.clientID).
+		SetProtocolVersion(uint(ProtocolVersion311)).
+		SetAutoAckDisabled(true).
+		SetOrderMatters(true).
+		SetDefaultPublishHandler(func(_ pahov311.Client, msg pahov311.Message) {
+			c.dispatch(msg.Topic(), extensions.BrokerMessage{
+				Headers: make(map[string][]byte),
+				Payload: msg.Payload(),
+			}, msg.Ack)
+		})
+	if c.username != "" {
+		opts = opts.SetUsername(c.username).SetPassword(c.password)
+	}
+	if c.tlsConfig != nil {
+		opts = opts.SetTLSConfig(c.tlsConfig)
+	}
+
+	client := pahov311.NewClient(opts)
+	if err := waitToken(context.Background(), client.Connect()); err != nil {
+		return nil, err
+	}
+
+	return &clientV311{client: client}, nil
+}
+
+func (c *clientV311) publish(
+	ctx context.Context,
+	topic string,
+	qos byte,
+	retain bool,
+	bm extensions.BrokerMessage,
+) error {
+	for name := range bm.Headers {
+		if name != extensions.ContentTypeHeader && name != extensions.MessageNameHeader {
+			return fmt.Errorf("%w: %q on topic %q", ErrHeadersNotSupported, name, topic)
+		}
+	}
+
+	return waitToken(ctx, c.client.Publish(topic, qos, retain, bm.Payload))
+}
+
+func (c *clientV311) subscribe(ctx context.Context, filter string, qos byte) error {
+	// No callback as messages are handled by the default publish handler
+	return waitToken(ctx, c.client.Subscribe(filter, qos, nil))
+}
+
+func (c *clientV311) unsubscribe(ctx context.Context, filter string) error {
+	return waitToken(ctx, c.client.Unsubscribe(filter))
+}
+
+func (c *clientV311) disconnect() {
+	c.client.Disconnect(0)
+}
+
+func waitToken(ctx context.Context, token pahov311.Token) error {
+	select {
+	case <-token.Done():
+		if err := token.Error(); err != nil {
+			return fmt.Errorf("mqtt operation failed: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,146 @@
+package mqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/eclipse/paho.golang/packets"
+	pahov5 "github.com/eclipse/paho.golang/paho"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+)
+
+// clientV5 is the MQTT 5 client, where headers are transmitted as user properties.
+type clientV5 struct {
+	client *pahov5.Client
+	logger extensions.Logger
+}
+
+func newClientV5(c *Controller) (*clientV5, error) {
+	conn, err := dial(c.url, c.tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	cv5 := &clientV5{logger: c.logger}
+	cv5.client = pahov5.NewClient(pahov5.ClientConfig{
+		ClientID:                   c.clientID,
+		Conn:                       conn,
+		EnableManualAcknowledgment: true,
+		OnPublishReceived: []func(pahov5.PublishReceived) (bool, error){
+			func(pr pahov5.PublishReceived) (bool, error) {
+				c.dispatch(pr.Packet.Topic, publishToBrokerMessage(pr.Packet), func() {
+					if err := pr.Client.Ack(pr.Packet); err != nil {
+						c.logger.Error(context.Background(), fmt.Sprintf("error on ack message: %q", err.Error()))
+					}
+				})
+				return true, nil
+			},
+		},
+		OnClientError: func(err error) {
+			c.logger.Error(context.Background(), fmt.Sprintf("mqtt client error: %q", err.Error()))
+		},
+	})
+
+	// Connect to the broker
+	cp := &pahov5.Connect{
+		ClientID:   c.clientID,
+		KeepAlive:  30,
+		CleanStart: true,
+	}
+	if c.username != "" {
+		cp.Username, cp.UsernameFlag = c.username, true
+		cp.Password, cp.PasswordFlag = []byte(c.password), true
+	}
+
+	ca, err := cv5.client.Connect(context.Background(), cp)
+	if err != nil {
+		return nil, err
+	}
+	if ca.ReasonCode != 0 {
+		return nil, fmt.Errorf("connection refused with reason code %d", ca.ReasonCode)
+	}
+
+	return cv5, nil
+}
+
+// dial opens the network connection to the broker, based on the URL scheme.
+func dial(rawURL string, tlsConfig *tls.Config) (net.Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mqtt url %q: %w", rawURL, err)
+	}
+
+	switch u.Scheme {
+	case "mqtt", "tcp":
+		if tlsConfig == nil {
+			return net.Dial("tcp", u.Host)
+		}
+		fallthrough
+	case "mqtts", "ssl", "tls":
+		conn, err := tls.Dial("tcp", u.Host, tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+		return packets.NewThreadSafeConn(conn), nil
+	default:
+		return nil, fmt.Errorf("unsupported mqtt url scheme %q", u.Scheme)
+	}
+}
+
+func publishToBrokerMessage(p *pahov5.Publish) extensions.BrokerMessage {
+	headers := make(map[string][]byte)
+	if p.Properties != nil {
+		for _, prop := range p.Properties.User {
+			headers[prop.Key] = []byte(prop.Value)
+		}
+	}
+
+	return extensions.BrokerMessage{
+		Headers: headers,
+		Payload: p.Payload,
+	}
+}
+
+func (c *clientV5) publish(
+	ctx context.Context,
+	topic string,
+	qos byte,
+	retain bool,
+	bm extensions.BrokerMessage,
+) error {
+	// Set headers as user properties
+	props := &pahov5.PublishProperties{}
+	for k, v := range bm.Headers {
+		props.User.Add(k, string(v))
+	}
+
+	_, err := c.client.Publish(ctx, &pahov5.Publish{
+		Topic:      topic,
+		QoS:        qos,
+		Retain:     retain,
+		Payload:    bm.Payload,
+		Properties: props,
+	})
+	return err
+}
+
+func (c *clientV5) subscribe(ctx context.Context, filter string, qos byte) error {
+	_, err := c.client.Subscribe(ctx, &pahov5.Subscribe{
+		Subscriptions: []pahov5.SubscribeOptions{{Topic: filter, QoS: qos}},
+	})
+	return err
+}
+
+func (c *clientV5) unsubscribe(ctx context.Context, filter string) error {
+	_, err := c.client.Unsubscribe(ctx, &pahov5.Unsubscribe{Topics: []string{filter}})
+	return err
+}
+
+func (c *clientV5) disconnect() {
+	if err := c.client.Disconnect(&pahov5.Disconnect{ReasonCode: 0}); err != nil {
+		c.logger.Error(context.Background(), err.Error())
+	}
+}
@@ -0,0 +1,427 @@
+package mqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers"
+)
+
+// ProtocolVersion is the version of the MQTT protocol used to connect to the broker.
+type ProtocolVersion uint
+
+const (
+	// ProtocolVersion311 is the MQTT 3.1.1 protocol version.
+	ProtocolVersion311 ProtocolVersion = 4
+	// ProtocolVersion5 is the MQTT 5 protocol version.
+	ProtocolVersion5 ProtocolVersion = 5
+)
+
+// Check that it still fills the interfaces.
+var (
+	_ extensions.BrokerController         = (*Controller)(nil)
+	_ extensions.BrokerWildcardSubscriber = (*Controller)(nil)
+)
+
+// client is the interface implemented for each MQTT protocol version.
+type client interface {
+	publish(ctx context.Context, topic string, qos byte, retain bool, bm extensions.BrokerMessage) error
+	subscribe(ctx context.Context, filter string, qos byte) error
+	unsubscribe(ctx context.Context, filter string) error
+	disconnect()
+}
+
+// Controller is the MQTT implementation for asyncapi-codegen.
+type Controller struct {
+	url             string
+	protocolVersion ProtocolVersion
+	clientID        string
+	username        string
+	password        string
+	tlsConfig       *tls.Config
+
+	qos        byte
+	retain     bool
+	queueGroup string
+
+	client client
+	logger extensions.Logger
+
+	subscriptionsMutex sync.RWMutex
+	subscriptions      map[string][]*subscription
+}
+
+// subscription is a channel subscription, as a pointer to be identified when
+// there are several subscriptions on the same channel.
+type subscription struct {
+	extensions.BrokerChannelSubscription
+	messages chan extensions.AcknowledgeableBrokerMessage
+
+	// canceled is closed when the subscription is canceled, and the mutex is
+	// held by transmissions so the messages channel isn't closed meanwhile.
+	canceled chan struct{}
+	mutex    sync.RWMutex
+}
+
+// newSubscription creates a new subscription.
+func newSubscription() *subscription {
+	messages := make(chan extensions.AcknowledgeableBrokerMessage, brokers.BrokerMessagesQueueSize)
+	return &subscription{
+		BrokerChannelSubscription: extensions.NewBrokerChannelSubscription(messages, make(chan any, 1)),
+		messages:                  messages,
+		canceled:                  make(chan struct{}),
+	}
+}
+
+// transmit transmits the message to the user, waiting for room in the messages
+// channel. The message is dropped if the subscription is canceled.
+func (s *subscription) transmit(msg extensions.AcknowledgeableBrokerMessage) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	select {
+	case <-s.canceled:
+		return
+	default:
+	}
+
+	select {
+	case s.messages <- msg:
+	case <-s.canceled:
+	}
+}
+
+// cancel stops the transmissions and waits for the ongoing ones to end, so the
+// messages channel can be closed safely.
+func (s *subscription) cancel() {
+	close(s.canceled)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+}
+
+// ControllerOption is a function that can be used to configure a MQTT controller
+// Examples: WithProtocolVersion(), WithQoS(), WithQueueGroup(), WithLogger().
+type ControllerOption func(controller *Controller) error
+
+// NewController creates a new MQTT controller.
+func NewController(url string, options ...ControllerOption) (*Controller, error) {
+	// Creates default controller
+	controller := &Controller{
+		url:             url,
+		protocolVersion: ProtocolVersion5,
+		clientID:        uuid.New().String(),
+		queueGroup:      brokers.DefaultQueueGroupID,
+		logger:          extensions.DummyLogger{},
+		subscriptions:   make(map[string][]*subscription),
+	}
+
+	// Execute options
+	for _, option := range options {
+		if err := option(controller); err != nil {
+			return nil, fmt.Errorf("could not apply option to controller: %w", err)
+		}
+	}
+
+	// Connect to MQTT based on protocol version
+	var err error
+	switch controller.protocolVersion {
+	case ProtocolVersion311:
+		controller.client, err = newClientV311(controller)
+	case ProtocolVersion5:
+		controller.client, err = newClientV5(controller)
+	default:
+		err = fmt.Errorf("unsupported protocol version %d", controller.protocolVersion)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to mqtt: %w", err)
+	}
+
+	return controller, nil
+}
+
+// NewControllerFromServer creates a new MQTT controller from a server of the
+// specification, with its variables already replaced. TLS is used for
+// 'secure-mqtt' and 'mqtts' protocols, and MQTT 3.1.1 is used if it is the
+// server protocol version.
+func NewControllerFromServer(srv extensions.Server, options ...ControllerOption) (*Controller, error) {
+	scheme := "mqtt"
+	if srv.Protocol == "secure-mqtt" || srv.Protocol == "mqtts" {
+		scheme = "mqtts"
+	}
+
+	if srv.ProtocolVersion == "3.1.1" {
+		options = append([]ControllerOption{WithProtocolVersion(ProtocolVersion311)}, options...)
+	}
+
+	return NewController(scheme+"://"+srv.Host, options...)
+}
+
+// WithProtocolVersion set the MQTT protocol version used to connect to the broker.
+// If not specified, MQTT 5 is used.
+func WithProtocolVersion(version ProtocolVersion) ControllerOption {
+	return func(controller *Controller) error {
+		controller.protocolVersion = version
+		return nil
+	}
+}
+
+// WithClientID set the client ID used to connect to the broker.
+func WithClientID(id string) ControllerOption {
+	return func(controller *Controller) error {
+		controller.clientID = id
+		return nil
+	}
+}
+
+// WithCredentials set the username and password used to connect to the broker.
+func WithCredentials(username, password string) ControllerOption {
+	return func(controller *Controller) error {
+		controller.username = username
+		controller.password = password
+		return nil
+	}
+}
+
+// WithTLS set the tls.Config that will be used to connect to the broker.
+func WithTLS(config *tls.Config) ControllerOption {
+	return func(controller *Controller) error {
+		controller.tlsConfig = config
+		return nil
+	}
+}
+
+// WithQoS set the default QoS used to publish and subscribe.
+func WithQoS(qos byte) ControllerOption {
+	return func(controller *Controller) error {
+		if qos > 2 {
+			return fmt.Errorf("invalid QoS %d", qos)
+		}
+		controller.qos = qos
+		return nil
+	}
+}
+
+// WithRetain set if published messages should be retained by default by the broker.
+func WithRetain(retain bool) ControllerOption {
+	return func(controller *Controller) error {
+		controller.retain = retain
+		return nil
+	}
+}
+
+// WithQueueGroup set a custom queue group for channel subscription, using
+// MQTT shared subscriptions ('$share/<group>/<topic>').
+func WithQueueGroup(name string) ControllerOption {
+	return func(controller *Controller) error {
+		controller.queueGroup = name
+		return nil
+	}
+}
+
+// WithLogger set a custom logger that will log operations on broker controller.
+func WithLogger(logger extensions.Logger) ControllerOption {
+	return func(controller *Controller) error {
+		controller.logger = logger
+		return nil
+	}
+}
+
+type contextKey string
+
+const (
+	contextKeyQoS    contextKey = "mqtt-qos"
+	contextKeyRetain contextKey = "mqtt-retain"
+)
+
+// ContextWithQoS returns a context that will make the publication of messages
+// use the given QoS, instead of the one of the controller.
+func ContextWithQoS(ctx context.Context, qos byte) context.Context {
+	return context.WithValue(ctx, contextKeyQoS, qos)
+}
+
+// ContextWithRetain returns a context that will make the publication of messages
+// use the given retain flag, instead of the one of the controller.
+func ContextWithRetain(ctx context.Context, retain bool) context.Context {
+	return context.WithValue(ctx, contextKeyRetain, retain)
+}
+
+// Publish a message to the broker.
+func (c *Controller) Publish(ctx context.Context, channel string, bm extensions.BrokerMessage) error {
+	// Get QoS and retain flag from context, or from controller
+	qos, retain := c.qos, c.retain
+	if v, ok := ctx.Value(contextKeyQoS).(byte); ok {
+		qos = v
+	}
+	if v, ok := ctx.Value(contextKeyRetain).(bool); ok {
+		retain = v
+	}
+
+	return c.client.publish(ctx, channel, qos, retain, bm)
+}
+
+// Subscribe to messages from the broker.
+func (c *Controller) Subscribe(ctx context.Context, channel string) (extensions.BrokerChannelSubscription, error) {
+	// Create a new subscription
+	sub := newSubscription()
+
+	// Register it before subscribing, as messages can arrive right after
+	c.subscriptionsMutex.Lock()
+	first := len(c.subscriptions[channel]) == 0
+	c.subscriptions[channel] = append(c.subscriptions[channel], sub)
+	c.subscriptionsMutex.Unlock()
+
+	// Subscribe on topic if this is the first subscription on it, with shared
+	// subscription if there is a queue group
+	filter := c.subscriptionFilter(channel)
+	if first {
+		if err := c.client.subscribe(ctx, filter, c.qos); err != nil {
+			c.removeSubscription(channel, sub)
+			return extensions.BrokerChannelSubscription{}, err
+		}
+	}
+
+	// Wait for cancellation and unsubscribe from the topic if this was the last subscription
+	sub.WaitForCancellationAsync(func() {
+		sub.cancel()
+		if last := c.removeSubscription(channel, sub); !last {
+			return
+		}
+
+		if err := c.client.unsubscribe(ctx, filter); err != nil {
+			c.logger.Error(ctx, err.Error())
+		}
+	})
+
+	return sub.BrokerChannelSubscription, nil
+}
+
+// SubscribeWildcard subscribes to messages from the broker, on every topic
+// matching the address with parameters (i.e. 'user/{userId}/events' is
+// subscribed as 'user/+/events').
+func (c *Controller) SubscribeWildcard(
+	ctx context.Context,
+	address string,
+) (extensions.BrokerChannelSubscription, error) {
+	filter, err := extensions.ChannelAddressWildcard(address, "/", "+")
+	if err != nil {
+		return extensions.BrokerChannelSubscription{}, err
+	}
+
+	return c.Subscribe(ctx, filter)
+}
+
+func (c *Controller) subscriptionFilter(channel string) string {
+	if c.queueGroup == "" {
+		return channel
+	}
+	return "$share/" + c.queueGroup + "/" + channel
+}
+
+// removeSubscription removes the subscription and returns true if this was
+// the last subscription on the channel.
+func (c *Controller) removeSubscription(channel string, sub *subscription) bool {
+	c.subscriptionsMutex.Lock()
+	defer c.subscriptionsMutex.Unlock()
+
+	subs := c.subscriptions[channel]
+	for i, s := range subs {
+		if s == sub {
+			c.subscriptions[channel] = append(subs[:i], subs[i+1:]...)
+			break
+		}
+	}
+
+	if len(c.subscriptions[channel]) > 0 {
+		return false
+	}
+
+	delete(c.subscriptions, channel)
+	return true
+}
+
+// dispatch transmits a received message to every subscription matching its
+// topic. The message is acknowledged to the broker once every subscription
+// has acknowledged it.
+//
+// NOTE: as messages are received in order, dispatch blocks while a subscription
+// messages channel is full, until there is room or the subscription is canceled.
+func (c *Controller) dispatch(topic string, bm extensions.BrokerMessage, ack func()) {
+	c.subscriptionsMutex.RLock()
+	matching := make([]*subscription, 0, 1)
+	for filter, subs := range c.subscriptions {
+		if topicMatches(filter, topic) {
+			matching = append(matching, subs...)
+		}
+	}
+	c.subscriptionsMutex.RUnlock()
+
+	// Acknowledge directly if there is no subscription for this message
+	if len(matching) == 0 {
+		ack()
+		return
+	}
+
+	bm.Channel = topic
+	remaining := int32(len(matching))
+	handler := AcknowledgementHandler{
+		doAck: func() {
+			if atomic.AddInt32(&remaining, -1) == 0 {
+				ack()
+			}
+		},
+	}
+
+	for _, sub := range matching {
+		sub.transmit(extensions.NewAcknowledgeableBrokerMessage(bm, handler))
+	}
+}
+
+// topicMatches checks if a topic matches a topic filter, with single level
+// ('+') and multi level ('#') wildcards.
+func topicMatches(filter, topic string) bool {
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+
+	for i, level := range filterLevels {
+		switch {
+		case level == "#":
+			return true
+		case i >= len(topicLevels):
+			return false
+		case level != "+" && level != topicLevels[i]:
+			return false
+		}
+	}
+
+	return len(filterLevels) == len(topicLevels)
+}
+
+// Close closes everything related to the broker.
+func (c *Controller) Close() {
+	c.client.disconnect()
+}
+
+var _ extensions.BrokerAcknowledgment = (*AcknowledgementHandler)(nil)
+
+// AcknowledgementHandler for MQTT broker.
+// Naks are not supported by MQTT: a nak'd message is acknowledged, as the
+// broker would otherwise keep waiting for the acknowledgement.
+type AcknowledgementHandler struct {
+	doAck func()
+}
+
+// AckMessage acknowledges the message.
+func (k AcknowledgementHandler) AckMessage() {
+	k.doAck()
+}
+
+// NakMessage negatively acknowledges the message.
+func (k AcknowledgementHandler) NakMessage() {
+	k.doAck()
+}
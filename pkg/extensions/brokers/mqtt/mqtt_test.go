@@ -0,0 +1,126 @@
+package mqtt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers"
+	testutil "github.com/lerenn/asyncapi-codegen/pkg/utils/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopicMatches(t *testing.T) {
+	cases := []struct {
+		filter  string
+		topic   string
+		matches bool
+	}{
+		{filter: "a/b", topic: "a/b", matches: true},
+		{filter: "a/b", topic: "a/c", matches: false},
+		{filter: "a/+/c", topic: "a/b/c", matches: true},
+		{filter: "a/+", topic: "a/b/c", matches: false},
+		{filter: "a/#", topic: "a/b/c", matches: true},
+		{filter: "a/b/c", topic: "a/b", matches: false},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.matches, topicMatches(c.filter, c.topic), "filter %q on topic %q", c.filter, c.topic)
+	}
+}
+
+// noopClient is a client doing nothing, to test the controller without broker.
+type noopClient struct{}
+
+func (noopClient) publish(context.Context, string, byte, bool, extensions.BrokerMessage) error {
+	return nil
+}
+func (noopClient) subscribe(context.Context, string, byte) error { return nil }
+func (noopClient) unsubscribe(context.Context, string) error     { return nil }
+func (noopClient) disconnect()                                   {}
+
+func TestDispatchToCanceledSubscription(t *testing.T) {
+	c := &Controller{
+		client:        noopClient{},
+		logger:        extensions.DummyLogger{},
+		subscriptions: make(map[string][]*subscription),
+	}
+
+	sub, err := c.Subscribe(context.Background(), "topic")
+	require.NoError(t, err)
+
+	// Fill the messages channel, so the next dispatch blocks
+	for i := 0; i < brokers.BrokerMessagesQueueSize; i++ {
+		c.dispatch("topic", extensions.BrokerMessage{}, func() {})
+	}
+	dispatched := make(chan struct{})
+	go func() {
+		c.dispatch("topic", extensions.BrokerMessage{}, func() {})
+		close(dispatched)
+	}()
+
+	// Canceling should stop the dispatch instead of sending on a closed channel
+	sub.Cancel(context.Background())
+	select {
+	case <-dispatched:
+	case <-time.After(time.Second):
+		require.FailNow(t, "dispatch still blocked after cancellation")
+	}
+}
+
+func TestPublishHeadersV311(t *testing.T) {
+	c := &clientV311{}
+
+	err := c.publish(context.Background(), "topic", 0, false, extensions.BrokerMessage{
+		Headers: map[string][]byte{"correlationId": []byte("1234")},
+	})
+	require.ErrorIs(t, err, ErrHeadersNotSupported)
+}
+
+func receive(t *testing.T, sub extensions.BrokerChannelSubscription) extensions.AcknowledgeableBrokerMessage {
+	select {
+	case msg := <-sub.MessagesChannel():
+		return msg
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no message received")
+		return extensions.AcknowledgeableBrokerMessage{}
+	}
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	for _, version := range []ProtocolVersion{ProtocolVersion311, ProtocolVersion5} {
+		broker, err := NewController(
+			testutil.BrokerAddress(testutil.BrokerAddressParams{
+				Schema:         "mqtt",
+				DockerizedAddr: "mosquitto",
+				Port:           "1883",
+			}),
+			WithProtocolVersion(version),
+			WithQoS(1),
+			WithQueueGroup("mqttPublishSubscribe"),
+		)
+		require.NoError(t, err, "new controller should not return error")
+		defer broker.Close()
+
+		topic := "mqtt/publish-subscribe"
+		sub, err := broker.Subscribe(context.Background(), topic)
+		require.NoError(t, err, "subscribe should not return error")
+
+		err = broker.Publish(context.Background(), topic, extensions.BrokerMessage{
+			Headers: map[string][]byte{"key": []byte("value")},
+			Payload: []byte("testmessage"),
+		})
+		require.NoError(t, err, "publish should not return error")
+
+		msg := receive(t, sub)
+		msg.Ack()
+		assert.Equal(t, "testmessage", string(msg.Payload))
+		if version == ProtocolVersion5 {
+			assert.Equal(t, "value", string(msg.Headers["key"]), "headers should be transmitted as user properties")
+		}
+
+		sub.Cancel(context.Background())
+	}
+}
@@ -0,0 +1,44 @@
+package mqtt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+)
+
+// SecurityOptions returns the options to authenticate to the server with the
+// first of its security schemes that is supported, and the credentials from
+// the provider:
+//
+//   - 'userPassword' uses the username and password;
+//   - 'X509' uses TLS with a client certificate.
+//
+// No option is returned if the server has no security scheme.
+func SecurityOptions(
+	ctx context.Context,
+	srv extensions.Server,
+	provider extensions.CredentialsProvider,
+) ([]ControllerOption, error) {
+	scheme, creds, err := srv.SecurityCredentials(ctx, provider,
+		extensions.SecuritySchemeUserPassword,
+		extensions.SecuritySchemeX509)
+	if err != nil || scheme == nil {
+		return nil, err
+	}
+
+	if scheme.Type == extensions.SecuritySchemeX509 {
+		tlsConfig, err := creds.TLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		return []ControllerOption{WithTLS(tlsConfig)}, nil
+	}
+
+	if creds.Username == "" || creds.Password == "" {
+		return nil, fmt.Errorf("%w: username and password for %q security scheme of server %q",
+			extensions.ErrMissingCredentials, scheme.Name, srv.Name)
+	}
+
+	return []ControllerOption{WithCredentials(creds.Username, creds.Password)}, nil
+}
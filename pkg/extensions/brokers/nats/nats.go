@@ -9,8 +9,14 @@ import (
 	"github.com/nats-io/nats.go"
 )
 
-// Check that it still fills the interface.
-var _ extensions.BrokerController = (*Controller)(nil)
+// Check that it still fills the interfaces.
+var (
+	_ extensions.BrokerController         = (*Controller)(nil)
+	_ extensions.BrokerWildcardSubscriber = (*Controller)(nil)
+	_ extensions.BrokerOptionsSubscriber  = (*Controller)(nil)
+
+	_ extensions.BrokerWildcardOptionsSubscriber = (*Controller)(nil)
+)
 
 // Controller is the Controller implementation for asyncapi-codegen.
 type Controller struct {
@@ -53,6 +59,12 @@ func NewController(url string, options ...ControllerOption) (*Controller, error)
 	return controller, nil
 }
 
+// NewControllerFromServer creates a new NATS controller from a server of the
+// specification, with its variables already replaced.
+func NewControllerFromServer(srv extensions.Server, options ...ControllerOption) (*Controller, error) {
+	return NewController(srv.URL(), options...)
+}
+
 // WithQueueGroup set a custom queue group for channel subscription.
 func WithQueueGroup(name string) ControllerOption {
 	return func(controller *Controller) error {
@@ -102,14 +114,41 @@ func (c *Controller) Publish(_ context.Context, channel string, bm extensions.Br
 
 // Subscribe to messages from the broker.
 func (c *Controller) Subscribe(ctx context.Context, channel string) (extensions.BrokerChannelSubscription, error) {
+	return c.SubscribeWithOptions(ctx, channel, extensions.SubscribeOptions{})
+}
+
+// SubscribeWithOptions subscribes to messages from the broker, with the queue
+// group and buffer size of the options. As core NATS doesn't keep the messages,
+// start position and durable name are not supported (see natsjetstream package).
+func (c *Controller) SubscribeWithOptions(
+	ctx context.Context,
+	channel string,
+	opts extensions.SubscribeOptions,
+) (extensions.BrokerChannelSubscription, error) {
+	if opts.StartPosition != extensions.StartPositionDefault || opts.DurableName != "" {
+		return extensions.BrokerChannelSubscription{}, fmt.Errorf(
+			"%w: core NATS doesn't keep messages for start position or durable name",
+			extensions.ErrSubscribeOptionNotSupported)
+	}
+
+	queueGroup := c.queueGroup
+	if opts.QueueGroup != "" {
+		queueGroup = opts.QueueGroup
+	}
+
+	bufferSize := brokers.BrokerMessagesQueueSize
+	if opts.BufferSize > 0 {
+		bufferSize = opts.BufferSize
+	}
+
 	// Create a new subscription
 	sub := extensions.NewBrokerChannelSubscription(
-		make(chan extensions.AcknowledgeableBrokerMessage, brokers.BrokerMessagesQueueSize),
+		make(chan extensions.AcknowledgeableBrokerMessage, bufferSize),
 		make(chan any, 1),
 	)
 
 	// Subscribe on subject
-	natsSub, err := c.connection.QueueSubscribe(channel, c.queueGroup, c.messagesHandler(ctx, sub))
+	natsSub, err := c.connection.QueueSubscribe(channel, queueGroup, c.messagesHandler(ctx, sub))
 	if err != nil {
 		return extensions.BrokerChannelSubscription{}, err
 	}
@@ -124,6 +163,37 @@ func (c *Controller) Subscribe(ctx context.Context, channel string) (extensions.
 	return sub, nil
 }
 
+// SubscribeWildcard subscribes to messages from the broker, on every subject
+// matching the address with parameters (i.e. 'user.{userId}.events' is
+// subscribed as 'user.*.events').
+func (c *Controller) SubscribeWildcard(
+	ctx context.Context,
+	address string,
+) (extensions.BrokerChannelSubscription, error) {
+	subject, err := extensions.ChannelAddressWildcard(address, ".", "*")
+	if err != nil {
+		return extensions.BrokerChannelSubscription{}, err
+	}
+
+	return c.Subscribe(ctx, subject)
+}
+
+// SubscribeWildcardWithOptions subscribes to messages from the broker, on every
+// subject matching the address with parameters, with the options (see
+// SubscribeWithOptions).
+func (c *Controller) SubscribeWildcardWithOptions(
+	ctx context.Context,
+	address string,
+	opts extensions.SubscribeOptions,
+) (extensions.BrokerChannelSubscription, error) {
+	subject, err := extensions.ChannelAddressWildcard(address, ".", "*")
+	if err != nil {
+		return extensions.BrokerChannelSubscription{}, err
+	}
+
+	return c.SubscribeWithOptions(ctx, subject, opts)
+}
+
 func (c *Controller) messagesHandler(_ context.Context, sub extensions.BrokerChannelSubscription) nats.MsgHandler {
 	return func(msg *nats.Msg) {
 		// Get headers
@@ -139,6 +209,7 @@ func (c *Controller) messagesHandler(_ context.Context, sub extensions.BrokerCha
 			extensions.BrokerMessage{
 				Headers: headers,
 				Payload: msg.Data,
+				Channel: msg.Subject,
 			},
 			NoopAcknowledgementHandler{},
 		))
@@ -0,0 +1,95 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/nats-io/nats.go"
+)
+
+// SecurityOptions returns the options to authenticate to the server with the
+// first of its security schemes that is supported, and the credentials from
+// the provider (see SecurityConnectionOpts).
+//
+// The returned option connects to NATS: it should not be used with another
+// WithConnectionOpts option.
+func SecurityOptions(
+	ctx context.Context,
+	srv extensions.Server,
+	provider extensions.CredentialsProvider,
+) ([]ControllerOption, error) {
+	opts, err := SecurityConnectionOpts(ctx, srv, provider)
+	if err != nil || len(opts) == 0 {
+		return nil, err
+	}
+
+	return []ControllerOption{WithConnectionOpts(opts...)}, nil
+}
+
+// SecurityConnectionOpts returns the NATS connection options to authenticate to
+// the server with the first of its security schemes that is supported, and
+// the credentials from the provider:
+//
+//   - 'userPassword' uses the username and password;
+//   - 'apiKey' and 'httpApiKey' use the token;
+//   - 'asymmetricEncryption' uses the credentials file or the NKey seed file;
+//   - 'X509' uses TLS with a client certificate.
+//
+// No option is returned if the server has no security scheme.
+func SecurityConnectionOpts(
+	ctx context.Context,
+	srv extensions.Server,
+	provider extensions.CredentialsProvider,
+) ([]nats.Option, error) {
+	scheme, creds, err := srv.SecurityCredentials(ctx, provider,
+		extensions.SecuritySchemeUserPassword,
+		extensions.SecuritySchemeAPIKey,
+		extensions.SecuritySchemeHTTPAPIKey,
+		extensions.SecuritySchemeAsymmetricEncryption,
+		extensions.SecuritySchemeX509)
+	if err != nil || scheme == nil {
+		return nil, err
+	}
+
+	missing := func(credentials string) error {
+		return fmt.Errorf("%w: %s for %q security scheme of server %q",
+			extensions.ErrMissingCredentials, credentials, scheme.Name, srv.Name)
+	}
+
+	switch scheme.Type {
+	case extensions.SecuritySchemeUserPassword:
+		if creds.Username == "" || creds.Password == "" {
+			return nil, missing("username and password")
+		}
+		return []nats.Option{nats.UserInfo(creds.Username, creds.Password)}, nil
+	case extensions.SecuritySchemeAPIKey, extensions.SecuritySchemeHTTPAPIKey:
+		if creds.Token == "" {
+			return nil, missing("token")
+		}
+		return []nats.Option{nats.Token(creds.Token)}, nil
+	case extensions.SecuritySchemeAsymmetricEncryption:
+		if creds.CredsFile != "" {
+			return []nats.Option{nats.UserCredentials(creds.CredsFile)}, nil
+		}
+		if creds.NKeySeedFile == "" {
+			return nil, missing("credentials file or NKey seed file")
+		}
+
+		opt, err := nats.NkeyOptionFromSeed(creds.NKeySeedFile)
+		if err != nil {
+			return nil, err
+		}
+		return []nats.Option{opt}, nil
+	default:
+		if creds.CertFile == "" || creds.KeyFile == "" {
+			return nil, missing("certificate and key files")
+		}
+
+		opts := []nats.Option{nats.ClientCert(creds.CertFile, creds.KeyFile)}
+		if creds.CAFile != "" {
+			opts = append(opts, nats.RootCAs(creds.CAFile))
+		}
+		return opts, nil
+	}
+}
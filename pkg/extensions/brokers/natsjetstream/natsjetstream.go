@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
@@ -12,8 +13,14 @@ import (
 	"github.com/nats-io/nats.go/jetstream"
 )
 
-// Check that it still fills the interface.
-var _ extensions.BrokerController = (*Controller)(nil)
+// Check that it still fills the interfaces.
+var (
+	_ extensions.BrokerController         = (*Controller)(nil)
+	_ extensions.BrokerWildcardSubscriber = (*Controller)(nil)
+	_ extensions.BrokerOptionsSubscriber  = (*Controller)(nil)
+
+	_ extensions.BrokerWildcardOptionsSubscriber = (*Controller)(nil)
+)
 
 // Controller is the Controller implementation for asyncapi-codegen.
 type Controller struct {
@@ -26,6 +33,7 @@ type Controller struct {
 	consumerName   string
 	consumeContext jetstream.ConsumeContext
 	channels       map[string]chan jetstream.Msg
+	wildcards      map[string]*regexp.Regexp
 	streamConfig   *jetstream.StreamConfig
 	consumerConfig *jetstream.ConsumerConfig
 
@@ -39,6 +47,7 @@ func NewController(url string, options ...ControllerOption) (*Controller, error)
 		url:            url,
 		logger:         extensions.DummyLogger{},
 		channels:       make(map[string]chan jetstream.Msg),
+		wildcards:      make(map[string]*regexp.Regexp),
 		consumeContext: nil,
 		nakDelay:       time.Second * 5,
 	}
@@ -88,6 +97,12 @@ func NewController(url string, options ...ControllerOption) (*Controller, error)
 // ControllerOption is a function that can be used to configure a NATS controller.
 type ControllerOption func(controller *Controller) error
 
+// NewControllerFromServer creates a new NATS JetStream controller from a server
+// of the specification, with its variables already replaced.
+func NewControllerFromServer(srv extensions.Server, options ...ControllerOption) (*Controller, error) {
+	return NewController(srv.URL(), options...)
+}
+
 // WithLogger set a custom logger that will log operations on broker controller.
 func WithLogger(logger extensions.Logger) ControllerOption {
 	return func(controller *Controller) error {
@@ -223,15 +238,197 @@ func (c *Controller) Publish(ctx context.Context, channel string, bm extensions.
 
 // Subscribe to messages from the broker.
 func (c *Controller) Subscribe(ctx context.Context, channel string) (extensions.BrokerChannelSubscription, error) {
+	return c.subscribe(ctx, channel, nil, brokers.BrokerMessagesQueueSize)
+}
+
+// SubscribeWithOptions subscribes to messages from the broker, with the options.
+//
+// With a start position, a durable name or a queue group, a consumer dedicated
+// to the subscription is created on the stream instead of using the controller
+// one. The durable name (or else the queue group) is used as the durable
+// consumer name, so the subscriptions sharing it share the messages and the
+// position is kept. Without it, the consumer is ephemeral and deleted with the
+// subscription.
+func (c *Controller) SubscribeWithOptions(
+	ctx context.Context,
+	channel string,
+	opts extensions.SubscribeOptions,
+) (extensions.BrokerChannelSubscription, error) {
+	return c.subscribeWithOptions(ctx, channel, channel, nil, opts)
+}
+
+// SubscribeWildcardWithOptions subscribes to messages from the broker, on every
+// subject matching the address with parameters, with the options (see
+// SubscribeWithOptions). The consumer created for the subscription, if any,
+// filters the subjects with wildcards (i.e. 'user.*.events').
+func (c *Controller) SubscribeWildcardWithOptions(
+	ctx context.Context,
+	address string,
+	opts extensions.SubscribeOptions,
+) (extensions.BrokerChannelSubscription, error) {
+	subject, err := extensions.ChannelAddressWildcard(address, ".", "*")
+	if err != nil {
+		return extensions.BrokerChannelSubscription{}, err
+	}
+
+	return c.subscribeWithOptions(ctx, address, subject, extensions.ChannelAddressRegexp(address), opts)
+}
+
+// subscribeWithOptions subscribes with the controller consumer if the options
+// don't need a dedicated consumer, or with a consumer filtering the subject.
+func (c *Controller) subscribeWithOptions(
+	ctx context.Context,
+	channel, subject string,
+	wildcard *regexp.Regexp,
+	opts extensions.SubscribeOptions,
+) (extensions.BrokerChannelSubscription, error) {
+	bufferSize := brokers.BrokerMessagesQueueSize
+	if opts.BufferSize > 0 {
+		bufferSize = opts.BufferSize
+	}
+
+	if opts.StartPosition == extensions.StartPositionDefault && opts.DurableName == "" && opts.QueueGroup == "" {
+		return c.subscribe(ctx, channel, wildcard, bufferSize)
+	}
+
+	config, err := c.subscriptionConsumerConfig(subject, opts)
+	if err != nil {
+		return extensions.BrokerChannelSubscription{}, err
+	}
+
+	return c.subscribeWithConsumer(ctx, config, bufferSize)
+}
+
+// subscriptionConsumerConfig returns the configuration of the consumer dedicated
+// to a subscription with options, based on the controller consumer configuration
+// if there is one.
+func (c *Controller) subscriptionConsumerConfig(
+	subject string,
+	opts extensions.SubscribeOptions,
+) (jetstream.ConsumerConfig, error) {
+	var config jetstream.ConsumerConfig
+	if c.consumerConfig != nil {
+		config = *c.consumerConfig
+	}
+	config.Name = ""
+	config.Durable = opts.DurableName
+	if config.Durable == "" {
+		config.Durable = opts.QueueGroup
+	}
+	config.FilterSubject = subject
+	config.FilterSubjects = nil
+
+	// Replace the start position of the controller consumer, if any
+	if opts.StartPosition != extensions.StartPositionDefault {
+		config.OptStartSeq = 0
+		config.OptStartTime = nil
+	}
+
+	switch opts.StartPosition {
+	case extensions.StartPositionDefault:
+	case extensions.StartPositionEarliest:
+		config.DeliverPolicy = jetstream.DeliverAllPolicy
+	case extensions.StartPositionLatest:
+		config.DeliverPolicy = jetstream.DeliverNewPolicy
+	case extensions.StartPositionTime:
+		config.DeliverPolicy = jetstream.DeliverByStartTimePolicy
+		config.OptStartTime = &opts.StartTime
+	case extensions.StartPositionOffset:
+		if opts.StartOffset < 1 {
+			return jetstream.ConsumerConfig{}, fmt.Errorf("%w: stream sequence should be positive, got %d",
+				extensions.ErrSubscribeOptionNotSupported, opts.StartOffset)
+		}
+		config.DeliverPolicy = jetstream.DeliverByStartSequencePolicy
+		config.OptStartSeq = uint64(opts.StartOffset)
+	default:
+		return jetstream.ConsumerConfig{}, fmt.Errorf("%w: start position %s",
+			extensions.ErrSubscribeOptionNotSupported, opts.StartPosition)
+	}
+
+	return config, nil
+}
+
+// subscribeWithConsumer subscribes with a consumer dedicated to the subscription,
+// created (or updated) with the configuration.
+func (c *Controller) subscribeWithConsumer(
+	ctx context.Context,
+	config jetstream.ConsumerConfig,
+	bufferSize int,
+) (extensions.BrokerChannelSubscription, error) {
+	consumer, err := c.jetStream.CreateOrUpdateConsumer(ctx, c.streamName, config)
+	if err != nil {
+		return extensions.BrokerChannelSubscription{}, fmt.Errorf("could not create or update consumer: %w", err)
+	}
+
+	// Create a new subscription
+	sub := extensions.NewBrokerChannelSubscription(
+		make(chan extensions.AcknowledgeableBrokerMessage, bufferSize),
+		make(chan any, 1),
+	)
+
+	consumeContext, err := consumer.Consume(func(msg jetstream.Msg) {
+		c.HandleMessage(ctx, msg, sub)
+	}, jetstream.PullMaxMessages(bufferSize))
+	if err != nil {
+		c.deleteEphemeralConsumer(ctx, config, consumer)
+		return extensions.BrokerChannelSubscription{}, err
+	}
+
+	// Wait for cancellation, stop consuming and delete the consumer if ephemeral
+	sub.WaitForCancellationAsync(func() {
+		consumeContext.Stop()
+		c.deleteEphemeralConsumer(ctx, config, consumer)
+	})
+
+	return sub, nil
+}
+
+// deleteEphemeralConsumer deletes the consumer dedicated to a subscription if
+// it is not durable, as nothing else will use it.
+func (c *Controller) deleteEphemeralConsumer(
+	ctx context.Context,
+	config jetstream.ConsumerConfig,
+	consumer jetstream.Consumer,
+) {
+	if config.Durable != "" {
+		return
+	}
+
+	name := consumer.CachedInfo().Name
+	if err := c.jetStream.DeleteConsumer(context.Background(), c.streamName, name); err != nil {
+		c.logger.Error(ctx, fmt.Sprintf("could not delete consumer %q: %s", name, err.Error()))
+	}
+}
+
+// SubscribeWildcard subscribes to messages from the broker, on every subject
+// matching the address with parameters (i.e. 'user.{userId}.events').
+//
+// The stream should contain the matching subjects (i.e. 'user.*.events').
+func (c *Controller) SubscribeWildcard(
+	ctx context.Context,
+	address string,
+) (extensions.BrokerChannelSubscription, error) {
+	return c.subscribe(ctx, address, extensions.ChannelAddressRegexp(address), brokers.BrokerMessagesQueueSize)
+}
+
+func (c *Controller) subscribe(
+	ctx context.Context,
+	channel string,
+	wildcard *regexp.Regexp,
+	bufferSize int,
+) (extensions.BrokerChannelSubscription, error) {
 	// Create a new subscription
 	sub := extensions.NewBrokerChannelSubscription(
-		make(chan extensions.AcknowledgeableBrokerMessage, brokers.BrokerMessagesQueueSize),
+		make(chan extensions.AcknowledgeableBrokerMessage, bufferSize),
 		make(chan any, 1),
 	)
 
 	if c.channels[channel] == nil {
 		c.channels[channel] = make(chan jetstream.Msg)
 	}
+	if wildcard != nil {
+		c.wildcards[channel] = wildcard
+	}
 	if err := c.ConsumeIfNeeded(ctx); err != nil {
 		return extensions.BrokerChannelSubscription{}, err
 	}
@@ -250,6 +447,7 @@ func (c *Controller) Subscribe(ctx context.Context, channel string) (extensions.
 	sub.WaitForCancellationAsync(func() {
 		close(c.channels[channel])
 		delete(c.channels, channel)
+		delete(c.wildcards, channel)
 		c.StopConsumeIfNeeded()
 	})
 
@@ -271,6 +469,7 @@ func (c *Controller) HandleMessage(ctx context.Context, msg jetstream.Msg, sub e
 		extensions.BrokerMessage{
 			Headers: headers,
 			Payload: msg.Data(),
+			Channel: msg.Subject(),
 		},
 		AcknowledgementHandler{
 			doAck: func() {
@@ -322,7 +521,8 @@ func (c *Controller) StopConsumeIfNeeded() {
 // there is no subscription the message will be acknowledged.
 func (c *Controller) ConsumeMessage(ctx context.Context) jetstream.MessageHandler {
 	return func(msg jetstream.Msg) {
-		if c.channels[msg.Subject()] == nil {
+		ch := c.channelFromSubject(msg.Subject())
+		if ch == nil {
 			c.logger.Warning(
 				ctx,
 				fmt.Sprintf(
@@ -337,10 +537,26 @@ func (c *Controller) ConsumeMessage(ctx context.Context) jetstream.MessageHandle
 
 			return
 		}
-		c.channels[msg.Subject()] <- msg
+		ch <- msg
 	}
 }
 
+// channelFromSubject returns the channel of the subscription on the subject
+// or, if there is none, of a wildcard subscription matching the subject.
+func (c *Controller) channelFromSubject(subject string) chan jetstream.Msg {
+	if ch := c.channels[subject]; ch != nil {
+		return ch
+	}
+
+	for address, re := range c.wildcards {
+		if re.MatchString(subject) {
+			return c.channels[address]
+		}
+	}
+
+	return nil
+}
+
 var _ extensions.BrokerAcknowledgment = (*AcknowledgementHandler)(nil)
 
 // AcknowledgementHandler for nats jetstream broker.
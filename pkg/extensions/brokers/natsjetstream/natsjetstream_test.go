@@ -5,7 +5,9 @@ import (
 	"crypto/tls"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
 	testutil "github.com/lerenn/asyncapi-codegen/pkg/utils/test"
 	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
@@ -214,3 +216,63 @@ func TestExistingNatsConnection(t *testing.T) {
 
 	assert.True(t, nc.IsConnected(), "our connection should still be intact")
 }
+
+func TestSubscriptionConsumerConfig(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	c := &Controller{consumerConfig: &jetstream.ConsumerConfig{
+		Name:          "controller",
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		DeliverPolicy: jetstream.DeliverByStartSequencePolicy,
+		OptStartSeq:   10,
+	}}
+
+	t.Run("durable name and start time", func(t *testing.T) {
+		config, err := c.subscriptionConsumerConfig("subject", extensions.NewSubscribeOptions(
+			extensions.WithDurableName("durable"),
+			extensions.WithQueueGroup("group"),
+			extensions.WithStartTime(start)))
+		require.NoError(t, err)
+		assert.Equal(t, jetstream.ConsumerConfig{
+			Durable:       "durable",
+			FilterSubject: "subject",
+			AckPolicy:     jetstream.AckExplicitPolicy,
+			DeliverPolicy: jetstream.DeliverByStartTimePolicy,
+			OptStartTime:  &start,
+		}, config)
+	})
+
+	t.Run("queue group shares a durable consumer", func(t *testing.T) {
+		config, err := c.subscriptionConsumerConfig("subject", extensions.NewSubscribeOptions(
+			extensions.WithQueueGroup("group")))
+		require.NoError(t, err)
+		assert.Equal(t, "group", config.Durable)
+		assert.Equal(t, jetstream.DeliverByStartSequencePolicy, config.DeliverPolicy)
+		assert.Equal(t, uint64(10), config.OptStartSeq)
+	})
+
+	t.Run("ephemeral from sequence", func(t *testing.T) {
+		config, err := c.subscriptionConsumerConfig("subject", extensions.NewSubscribeOptions(
+			extensions.WithStartOffset(42)))
+		require.NoError(t, err)
+		assert.Empty(t, config.Durable)
+		assert.Equal(t, jetstream.DeliverByStartSequencePolicy, config.DeliverPolicy)
+		assert.Equal(t, uint64(42), config.OptStartSeq)
+
+		_, err = c.subscriptionConsumerConfig("subject", extensions.NewSubscribeOptions(
+			extensions.WithStartOffset(0)))
+		assert.ErrorIs(t, err, extensions.ErrSubscribeOptionNotSupported)
+	})
+
+	t.Run("earliest and latest", func(t *testing.T) {
+		config, err := c.subscriptionConsumerConfig("subject", extensions.NewSubscribeOptions(
+			extensions.WithStartEarliest()))
+		require.NoError(t, err)
+		assert.Equal(t, jetstream.DeliverAllPolicy, config.DeliverPolicy)
+		assert.Zero(t, config.OptStartSeq)
+
+		config, err = c.subscriptionConsumerConfig("subject", extensions.NewSubscribeOptions(
+			extensions.WithStartLatest()))
+		require.NoError(t, err)
+		assert.Equal(t, jetstream.DeliverNewPolicy, config.DeliverPolicy)
+	})
+}
@@ -0,0 +1,27 @@
+package natsjetstream
+
+import (
+	"context"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	natsbroker "github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers/nats"
+)
+
+// SecurityOptions returns the options to authenticate to the server with the
+// first of its security schemes that is supported, and the credentials from
+// the provider (see nats.SecurityConnectionOpts from NATS broker).
+//
+// The returned option connects to NATS: it should not be used with another
+// WithConnectionOpts or WithConnection option.
+func SecurityOptions(
+	ctx context.Context,
+	srv extensions.Server,
+	provider extensions.CredentialsProvider,
+) ([]ControllerOption, error) {
+	opts, err := natsbroker.SecurityConnectionOpts(ctx, srv, provider)
+	if err != nil || len(opts) == 0 {
+		return nil, err
+	}
+
+	return []ControllerOption{WithConnectionOpts(opts...)}, nil
+}
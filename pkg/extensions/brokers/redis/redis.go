@@ -0,0 +1,443 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	// PayloadField is the stream entry field containing the message payload.
+	PayloadField = "payload"
+	// HeaderFieldPrefix is the prefix of the stream entry fields containing
+	// the message headers.
+	HeaderFieldPrefix = "header:"
+)
+
+const (
+	// minReadErrorBackoff is the delay before reading again after an error,
+	// doubled on each consecutive error up to maxReadErrorBackoff.
+	minReadErrorBackoff = 100 * time.Millisecond
+	maxReadErrorBackoff = 5 * time.Second
+)
+
+// Check that it still fills the interface.
+var _ extensions.BrokerController = (*Controller)(nil)
+
+// Controller is the Redis Streams implementation for asyncapi-codegen.
+type Controller struct {
+	client goredis.UniversalClient
+	// ownsClient states if the client should be closed with the controller
+	ownsClient bool
+	username   string
+	password   string
+
+	// Reception only
+	groupID      string
+	consumerName string
+
+	maxLen        int64
+	blockDuration time.Duration
+	claimInterval time.Duration
+	claimMinIdle  time.Duration
+
+	logger extensions.Logger
+}
+
+// ControllerOption is a function that can be used to configure a Redis controller
+// Examples: WithGroupID(), WithConsumerName(), WithMaxLen(), WithLogger().
+type ControllerOption func(controller *Controller)
+
+// NewController creates a new Redis controller from a Redis URL (i.e.
+// 'redis://<user>:<password>@<host>:<port>/<db>').
+func NewController(url string, options ...ControllerOption) (*Controller, error) {
+	// Create default controller
+	controller := &Controller{
+		groupID:       brokers.DefaultQueueGroupID,
+		consumerName:  uuid.New().String(),
+		blockDuration: time.Second,
+		claimInterval: 5 * time.Second,
+		claimMinIdle:  30 * time.Second,
+		logger:        extensions.DummyLogger{},
+	}
+
+	// Execute options
+	for _, option := range options {
+		option(controller)
+	}
+
+	// If client not already set with WithClient, connect to Redis
+	if controller.client == nil {
+		opts, err := goredis.ParseURL(url)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse redis url: %w", err)
+		}
+		if controller.username != "" {
+			opts.Username = controller.username
+		}
+		if controller.password != "" {
+			opts.Password = controller.password
+		}
+
+		controller.client = goredis.NewClient(opts)
+		controller.ownsClient = true
+	}
+
+	// Check connection
+	if err := controller.client.Ping(context.Background()).Err(); err != nil {
+		return nil, fmt.Errorf("could not connect to redis: %w", err)
+	}
+
+	return controller, nil
+}
+
+// NewControllerFromServer creates a new Redis controller from a server of the
+// specification, with its variables already replaced. The pathname is used as
+// database (i.e. '/0').
+func NewControllerFromServer(srv extensions.Server, options ...ControllerOption) (*Controller, error) {
+	return NewController(srv.URL(), options...)
+}
+
+// WithClient uses the existing redis client, instead of creating one from URL.
+func WithClient(client goredis.UniversalClient) ControllerOption {
+	return func(controller *Controller) {
+		controller.client = client
+	}
+}
+
+// WithCredentials set the username and password used to connect to Redis,
+// instead of the ones from the URL. It is not used with WithClient.
+func WithCredentials(username, password string) ControllerOption {
+	return func(controller *Controller) {
+		controller.username = username
+		controller.password = password
+	}
+}
+
+// WithGroupID set a custom consumer group ID for channel subscription.
+// Without group ID, each subscription will receive every message, without
+// acknowledgement.
+func WithGroupID(groupID string) ControllerOption {
+	return func(controller *Controller) {
+		controller.groupID = groupID
+	}
+}
+
+// WithConsumerName set the name of the consumer in the consumer group.
+// If not set, a random name is used.
+func WithConsumerName(name string) ControllerOption {
+	return func(controller *Controller) {
+		controller.consumerName = name
+	}
+}
+
+// WithMaxLen set the approximate maximum length of the streams when publishing.
+// Zero means no limit.
+func WithMaxLen(maxLen int64) ControllerOption {
+	return func(controller *Controller) {
+		controller.maxLen = maxLen
+	}
+}
+
+// WithClaim set the interval at which pending entries idle for more than
+// minIdle (i.e. nak'd or not acknowledged by a dead consumer) will be claimed
+// and redelivered.
+func WithClaim(interval, minIdle time.Duration) ControllerOption {
+	return func(controller *Controller) {
+		controller.claimInterval = interval
+		controller.claimMinIdle = minIdle
+	}
+}
+
+// WithLogger set a custom logger that will log operations on broker controller.
+func WithLogger(logger extensions.Logger) ControllerOption {
+	return func(controller *Controller) {
+		controller.logger = logger
+	}
+}
+
+// Publish a message to the broker.
+func (c *Controller) Publish(ctx context.Context, channel string, bm extensions.BrokerMessage) error {
+	// Set message content and headers
+	values := make(map[string]any, len(bm.Headers)+1)
+	values[PayloadField] = bm.Payload
+	for k, v := range bm.Headers {
+		values[HeaderFieldPrefix+k] = v
+	}
+
+	// Publish message
+	return c.client.XAdd(ctx, &goredis.XAddArgs{
+		Stream: channel,
+		MaxLen: c.maxLen,
+		Approx: c.maxLen > 0,
+		Values: values,
+	}).Err()
+}
+
+// Subscribe to messages from the broker.
+func (c *Controller) Subscribe(ctx context.Context, channel string) (extensions.BrokerChannelSubscription, error) {
+	// Create the consumer group if needed, starting at new messages
+	if c.groupID != "" {
+		err := c.client.XGroupCreateMkStream(ctx, channel, c.groupID, "$").Err()
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			return extensions.BrokerChannelSubscription{}, fmt.Errorf("could not create consumer group: %w", err)
+		}
+	}
+
+	// Create subscription
+	messages := make(chan extensions.AcknowledgeableBrokerMessage, brokers.BrokerMessagesQueueSize)
+	sub := extensions.NewBrokerChannelSubscription(messages, make(chan any, 1))
+	s := &subscription{
+		controller: c,
+		channel:    channel,
+		messages:   messages,
+	}
+
+	// Handle events until cancellation
+	subCtx, cancel := context.WithCancel(ctx)
+	if c.groupID != "" {
+		s.run(subCtx, s.readGroup)
+		s.run(subCtx, s.claimStale)
+	} else {
+		lastID, err := c.lastID(ctx, channel)
+		if err != nil {
+			cancel()
+			return extensions.BrokerChannelSubscription{}, err
+		}
+		s.run(subCtx, func(ctx context.Context) { s.read(ctx, lastID) })
+	}
+
+	// Wait for cancellation and stop the readers when it happens
+	sub.WaitForCancellationAsync(func() {
+		cancel()
+		s.wg.Wait()
+	})
+
+	return sub, nil
+}
+
+// lastID returns the ID of the last entry of the stream, or "0" if it is empty.
+func (c *Controller) lastID(ctx context.Context, channel string) (string, error) {
+	entries, err := c.client.XRevRangeN(ctx, channel, "+", "-", 1).Result()
+	if err != nil {
+		return "", fmt.Errorf("could not get last stream entry: %w", err)
+	}
+
+	if len(entries) == 0 {
+		return "0", nil
+	}
+	return entries[0].ID, nil
+}
+
+// Close closes everything related to the broker.
+func (c *Controller) Close() {
+	if c.ownsClient {
+		if err := c.client.Close(); err != nil {
+			c.logger.Error(context.Background(), err.Error())
+		}
+	}
+}
+
+type subscription struct {
+	controller *Controller
+	channel    string
+	messages   chan extensions.AcknowledgeableBrokerMessage
+	wg         sync.WaitGroup
+}
+
+func (s *subscription) run(ctx context.Context, fn func(ctx context.Context)) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		fn(ctx)
+	}()
+}
+
+// read reads every new entry of the stream, without consumer group.
+func (s *subscription) read(ctx context.Context, lastID string) {
+	var backoff time.Duration
+	for {
+		streams, err := s.controller.client.XRead(ctx, &goredis.XReadArgs{
+			Streams: []string{s.channel, lastID},
+			Count:   brokers.BrokerMessagesQueueSize,
+			Block:   s.controller.blockDuration,
+		}).Result()
+		if !s.handleReadError(ctx, err, &backoff) {
+			return
+		}
+
+		for _, stream := range streams {
+			for _, entry := range stream.Messages {
+				lastID = entry.ID
+				if !s.transmit(ctx, entry, AcknowledgementHandler{doAck: func() {}, doNak: func() {}}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// readGroup reads new entries of the stream for the consumer of the group.
+func (s *subscription) readGroup(ctx context.Context) {
+	var backoff time.Duration
+	for {
+		streams, err := s.controller.client.XReadGroup(ctx, &goredis.XReadGroupArgs{
+			Group:    s.controller.groupID,
+			Consumer: s.controller.consumerName,
+			Streams:  []string{s.channel, ">"},
+			Count:    brokers.BrokerMessagesQueueSize,
+			Block:    s.controller.blockDuration,
+		}).Result()
+		if !s.handleReadError(ctx, err, &backoff) {
+			return
+		}
+
+		for _, stream := range streams {
+			for _, entry := range stream.Messages {
+				if !s.transmit(ctx, entry, s.groupAcknowledgment(entry.ID)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// claimStale periodically claims entries pending for too long (nak'd or not
+// acknowledged by a dead consumer) and redelivers them.
+func (s *subscription) claimStale(ctx context.Context) {
+	ticker := time.NewTicker(s.controller.claimInterval)
+	defer ticker.Stop()
+
+	var backoff time.Duration
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for start := "0-0"; ; {
+			entries, next, err := s.controller.client.XAutoClaim(ctx, &goredis.XAutoClaimArgs{
+				Stream:   s.channel,
+				Group:    s.controller.groupID,
+				Consumer: s.controller.consumerName,
+				MinIdle:  s.controller.claimMinIdle,
+				Start:    start,
+				Count:    brokers.BrokerMessagesQueueSize,
+			}).Result()
+			if !s.handleReadError(ctx, err, &backoff) {
+				return
+			}
+
+			for _, entry := range entries {
+				if !s.transmit(ctx, entry, s.groupAcknowledgment(entry.ID)) {
+					return
+				}
+			}
+
+			if next == "0-0" || len(entries) == 0 {
+				break
+			}
+			start = next
+		}
+	}
+}
+
+// handleReadError returns true if the reading should continue. After an error,
+// it waits for the backoff, which grows on consecutive errors so persistent
+// errors (i.e. unreachable server) don't make the reading loop spin.
+func (s *subscription) handleReadError(ctx context.Context, err error, backoff *time.Duration) bool {
+	switch {
+	case err == nil, errors.Is(err, goredis.Nil):
+		*backoff = 0
+		return true
+	case ctx.Err() != nil:
+		return false
+	}
+
+	*backoff = min(max(2*(*backoff), minReadErrorBackoff), maxReadErrorBackoff)
+	s.controller.logger.Warning(ctx, fmt.Sprintf("Error when reading message: %q, retrying in %s", err.Error(), *backoff))
+
+	timer := time.NewTimer(*backoff)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (s *subscription) groupAcknowledgment(id string) AcknowledgementHandler {
+	return AcknowledgementHandler{
+		doAck: func() {
+			err := s.controller.client.XAck(context.Background(), s.channel, s.controller.groupID, id).Err()
+			if err != nil {
+				s.controller.logger.Error(context.Background(), fmt.Sprintf("error on ack message: %q", err.Error()))
+			}
+		},
+		// Nak'd entry is left pending, to be claimed again after the claim minimum idle time
+		doNak: func() {},
+	}
+}
+
+// transmit sends the entry to the user and returns false if the subscription
+// has been canceled.
+func (s *subscription) transmit(ctx context.Context, entry goredis.XMessage, ack AcknowledgementHandler) bool {
+	msg := extensions.NewAcknowledgeableBrokerMessage(entryToBrokerMessage(entry), ack)
+
+	select {
+	case s.messages <- msg:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func entryToBrokerMessage(entry goredis.XMessage) extensions.BrokerMessage {
+	bm := extensions.BrokerMessage{
+		Headers: make(map[string][]byte, len(entry.Values)-1),
+		Payload: []byte{},
+	}
+
+	for k, v := range entry.Values {
+		value, _ := v.(string)
+		switch {
+		case k == PayloadField:
+			bm.Payload = []byte(value)
+		case strings.HasPrefix(k, HeaderFieldPrefix):
+			bm.Headers[strings.TrimPrefix(k, HeaderFieldPrefix)] = []byte(value)
+		}
+	}
+
+	return bm
+}
+
+var _ extensions.BrokerAcknowledgment = (*AcknowledgementHandler)(nil)
+
+// AcknowledgementHandler for redis broker.
+// Acks are done with XACK when using a consumer group, while nak'd entries
+// are left pending to be redelivered by the claim loop.
+type AcknowledgementHandler struct {
+	doAck func()
+	doNak func()
+}
+
+// AckMessage acknowledges the message.
+func (k AcknowledgementHandler) AckMessage() {
+	k.doAck()
+}
+
+// NakMessage negatively acknowledges the message.
+func (k AcknowledgementHandler) NakMessage() {
+	k.doNak()
+}
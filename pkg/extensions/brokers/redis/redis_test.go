@@ -0,0 +1,55 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// NOTE: tests with a Redis server are in the 'test/brokers/redis' module, as
+// they need dependencies that are not needed by this package.
+
+func TestHandleReadErrorBackoff(t *testing.T) {
+	s := &subscription{controller: &Controller{logger: extensions.DummyLogger{}}}
+	errRead := errors.New("connection refused")
+
+	var backoff time.Duration
+	t.Run("validate errors make the reading wait more and more", func(t *testing.T) {
+		start := time.Now()
+		require.True(t, s.handleReadError(context.Background(), errRead, &backoff))
+		assert.Equal(t, minReadErrorBackoff, backoff)
+
+		require.True(t, s.handleReadError(context.Background(), errRead, &backoff))
+		assert.Equal(t, 2*minReadErrorBackoff, backoff)
+		assert.GreaterOrEqual(t, time.Since(start), 3*minReadErrorBackoff)
+	})
+
+	t.Run("validate successful reads reset the backoff", func(t *testing.T) {
+		require.True(t, s.handleReadError(context.Background(), goredis.Nil, &backoff))
+		assert.Zero(t, backoff)
+	})
+
+	t.Run("validate the backoff is limited", func(t *testing.T) {
+		backoff = maxReadErrorBackoff
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		s.handleReadError(ctx, errRead, &backoff)
+		assert.Equal(t, maxReadErrorBackoff, backoff)
+	})
+
+	t.Run("validate cancellation interrupts the backoff", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		start := time.Now()
+		require.False(t, s.handleReadError(ctx, errRead, &backoff))
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
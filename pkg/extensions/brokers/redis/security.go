@@ -0,0 +1,31 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+)
+
+// SecurityOptions returns the options to authenticate to the server with the
+// first of its security schemes that is supported, and the credentials from
+// the provider: 'userPassword' uses the username (optional) and password.
+//
+// No option is returned if the server has no security scheme.
+func SecurityOptions(
+	ctx context.Context,
+	srv extensions.Server,
+	provider extensions.CredentialsProvider,
+) ([]ControllerOption, error) {
+	scheme, creds, err := srv.SecurityCredentials(ctx, provider, extensions.SecuritySchemeUserPassword)
+	if err != nil || scheme == nil {
+		return nil, err
+	}
+
+	if creds.Password == "" {
+		return nil, fmt.Errorf("%w: password for %q security scheme of server %q",
+			extensions.ErrMissingCredentials, scheme.Name, srv.Name)
+	}
+
+	return []ControllerOption{WithCredentials(creds.Username, creds.Password)}, nil
+}
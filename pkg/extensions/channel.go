@@ -0,0 +1,83 @@
+package extensions
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrWildcardNotSupported is raised when subscribing to every value of
+	// channel parameters with a broker that doesn't support it.
+	ErrWildcardNotSupported = fmt.Errorf("%w: wildcard subscription not supported", ErrAsyncAPI)
+
+	// ErrChannelAddressMismatch is raised when a channel address doesn't match
+	// the address with parameters it is parsed with.
+	ErrChannelAddressMismatch = fmt.Errorf("%w: channel address mismatch", ErrAsyncAPI)
+)
+
+// BrokerWildcardSubscriber represents the function that can be implemented by
+// a broker controller to subscribe to every value of channel parameters.
+type BrokerWildcardSubscriber interface {
+	// SubscribeWildcard subscribes to messages from the broker, on every channel
+	// matching the address with parameters (i.e. 'user.{userId}.events').
+	// The received messages should have their actual channel address set in
+	// BrokerMessage.Channel.
+	SubscribeWildcard(ctx context.Context, address string) (BrokerChannelSubscription, error)
+}
+
+// channelParameterRegexp matches the parameters of a channel address.
+var channelParameterRegexp = regexp.MustCompile("{[^{}]*}")
+
+// ChannelAddressWildcard returns the address with parameters where every
+// parameter is replaced by the wildcard (i.e. 'user.*.events' for
+// 'user.{userId}.events'), for brokers with wildcards matching whole levels
+// separated by the separator.
+//
+// An error is returned if a parameter is only a part of a level.
+func ChannelAddressWildcard(address, separator, wildcard string) (string, error) {
+	levels := strings.Split(address, separator)
+	for i, level := range levels {
+		if !channelParameterRegexp.MatchString(level) {
+			continue
+		}
+
+		if channelParameterRegexp.FindString(level) != level {
+			return "", fmt.Errorf("%w: parameter should be a whole level of %q, separated by %q",
+				ErrWildcardNotSupported, address, separator)
+		}
+		levels[i] = wildcard
+	}
+
+	return strings.Join(levels, separator), nil
+}
+
+// ChannelAddressRegexp returns a regular expression matching every channel
+// address of the address with parameters, with one group per parameter.
+func ChannelAddressRegexp(address string) *regexp.Regexp {
+	parts := channelParameterRegexp.Split(address, -1)
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+
+	return regexp.MustCompile("^" + strings.Join(parts, "(.+?)") + "$")
+}
+
+// ChannelAddressParameters returns the parameters values of a channel address,
+// by name, from the address with parameters (i.e. 'userId' is '1234' for
+// 'user.1234.events' from 'user.{userId}.events').
+func ChannelAddressParameters(address, actual string) (map[string]string, error) {
+	values := ChannelAddressRegexp(address).FindStringSubmatch(actual)
+	if values == nil {
+		return nil, fmt.Errorf("%w: %q doesn't match %q", ErrChannelAddressMismatch, actual, address)
+	}
+
+	names := channelParameterRegexp.FindAllString(address, -1)
+	params := make(map[string]string, len(names))
+	for i, n := range names {
+		params[strings.Trim(n, "{}")] = values[i+1]
+	}
+
+	return params, nil
+}
@@ -0,0 +1,54 @@
+package extensions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestChannelSuite(t *testing.T) {
+	suite.Run(t, new(ChannelSuite))
+}
+
+type ChannelSuite struct {
+	suite.Suite
+}
+
+func (suite *ChannelSuite) TestChannelAddressWildcard() {
+	cases := []struct {
+		address   string
+		separator string
+		wildcard  string
+		expected  string
+	}{
+		{address: "user.{userId}.events", separator: ".", wildcard: "*", expected: "user.*.events"},
+		{address: "{tenant}/user/{userId}", separator: "/", wildcard: "+", expected: "+/user/+"},
+		{address: "user.events", separator: ".", wildcard: "*", expected: "user.events"},
+	}
+
+	for _, c := range cases {
+		res, err := ChannelAddressWildcard(c.address, c.separator, c.wildcard)
+		suite.Require().NoError(err, c.address)
+		suite.Require().Equal(c.expected, res)
+	}
+
+	_, err := ChannelAddressWildcard("user-{userId}.events", ".", "*")
+	suite.Require().ErrorIs(err, ErrWildcardNotSupported)
+}
+
+func (suite *ChannelSuite) TestChannelAddressParameters() {
+	params, err := ChannelAddressParameters("user.{userId}.events.{event-type}", "user.1234.events.signup")
+	suite.Require().NoError(err)
+	suite.Require().Equal(map[string]string{"userId": "1234", "event-type": "signup"}, params)
+
+	// Parameters can be a part of a level
+	params, err = ChannelAddressParameters("user-{userId}", "user-1234")
+	suite.Require().NoError(err)
+	suite.Require().Equal(map[string]string{"userId": "1234"}, params)
+
+	// Address should match
+	_, err = ChannelAddressParameters("user.{userId}.events", "order.1234.events")
+	suite.Require().ErrorIs(err, ErrChannelAddressMismatch)
+	_, err = ChannelAddressParameters("user.{userId}.events", "user..events")
+	suite.Require().ErrorIs(err, ErrChannelAddressMismatch)
+}
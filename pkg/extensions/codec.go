@@ -0,0 +1,191 @@
+package extensions
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	// ContentTypeHeader is the header containing the content type of the
+	// message payload.
+	ContentTypeHeader = "content-type"
+
+	// DefaultContentType is the content type used when neither the message
+	// nor the specification sets one.
+	DefaultContentType = "application/json"
+)
+
+// Codec encodes and decodes message payloads for a content type.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	codecsMutex sync.RWMutex
+	codecs      = map[string]Codec{
+		"application/json":        JSONCodec{},
+		"application/msgpack":     MessagePackCodec{},
+		"application/x-msgpack":   MessagePackCodec{},
+		"application/vnd.msgpack": MessagePackCodec{},
+		"application/cbor":        CBORCodec{},
+		"text/plain":              TextCodec{},
+	}
+)
+
+// RegisterCodec registers a codec for a content type, replacing the existing
+// one if there is any.
+func RegisterCodec(contentType string, codec Codec) {
+	codecsMutex.Lock()
+	defer codecsMutex.Unlock()
+
+	codecs[normalizeContentType(contentType)] = codec
+}
+
+// GetCodec returns the codec registered for the content type.
+// Parameters (i.e. '; charset=utf-8') are ignored and structured syntax
+// suffixes ('+json', '+cbor') fall back on the corresponding codec.
+func GetCodec(contentType string) (Codec, error) {
+	ct := normalizeContentType(contentType)
+
+	codecsMutex.RLock()
+	defer codecsMutex.RUnlock()
+
+	if codec, ok := codecs[ct]; ok {
+		return codec, nil
+	}
+
+	if i := strings.LastIndex(ct, "+"); i >= 0 {
+		if codec, ok := codecs["application/"+ct[i+1:]]; ok {
+			return codec, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnknownContentType, contentType)
+}
+
+// SpecificationCodec returns the codec registered for the content type set in
+// the specification. If there is none, the JSON codec is returned, as payloads
+// were always encoded in JSON before codecs were supported: register a codec
+// with RegisterCodec to use another encoding.
+func SpecificationCodec(contentType string) Codec {
+	codec, err := GetCodec(contentType)
+	if err != nil {
+		return JSONCodec{}
+	}
+	return codec
+}
+
+// CodecForBrokerMessage returns the codec corresponding to the content type
+// header of the broker message. If there is none or if it is the content type
+// of the specification, the codec from SpecificationCodec is returned.
+func CodecForBrokerMessage(bm BrokerMessage, specContentType string) (Codec, error) {
+	ct := normalizeContentType(string(bm.Headers[ContentTypeHeader]))
+	if ct != "" && ct != normalizeContentType(specContentType) {
+		return GetCodec(ct)
+	}
+
+	return SpecificationCodec(specContentType), nil
+}
+
+func normalizeContentType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mediaType
+}
+
+// JSONCodec is the codec for 'application/json' content type.
+type JSONCodec struct{}
+
+// Marshal encodes the value in JSON.
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes the JSON data into the value.
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// MessagePackCodec is the codec for 'application/msgpack' content type.
+// As generated types only have 'json' tags, they are used for field names.
+type MessagePackCodec struct{}
+
+// Marshal encodes the value in MessagePack.
+func (MessagePackCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes the MessagePack data into the value.
+func (MessagePackCodec) Unmarshal(data []byte, v any) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
+
+// CBORCodec is the codec for 'application/cbor' content type.
+// Fields without 'cbor' tag use their 'json' tag.
+type CBORCodec struct{}
+
+// Marshal encodes the value in CBOR.
+func (CBORCodec) Marshal(v any) ([]byte, error) {
+	return cbor.Marshal(v)
+}
+
+// Unmarshal decodes the CBOR data into the value.
+func (CBORCodec) Unmarshal(data []byte, v any) error {
+	return cbor.Unmarshal(data, v)
+}
+
+// TextCodec is the codec for 'text/plain' content type.
+// It supports strings, byte slices and values implementing encoding.TextMarshaler
+// and encoding.TextUnmarshaler.
+type TextCodec struct{}
+
+// Marshal encodes the value as text.
+func (TextCodec) Marshal(v any) ([]byte, error) {
+	switch tv := v.(type) {
+	case string:
+		return []byte(tv), nil
+	case []byte:
+		return tv, nil
+	case encoding.TextMarshaler:
+		return tv.MarshalText()
+	case fmt.Stringer:
+		return []byte(tv.String()), nil
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedTextValue, v)
+	}
+}
+
+// Unmarshal decodes the text into the value.
+func (TextCodec) Unmarshal(data []byte, v any) error {
+	switch tv := v.(type) {
+	case *string:
+		*tv = string(data)
+	case *[]byte:
+		*tv = append((*tv)[:0], data...)
+	case encoding.TextUnmarshaler:
+		return tv.UnmarshalText(data)
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedTextValue, v)
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package extensions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestCodecSuite(t *testing.T) {
+	suite.Run(t, new(CodecSuite))
+}
+
+type CodecSuite struct {
+	suite.Suite
+}
+
+type codecTestPayload struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+func (suite *CodecSuite) TestRoundTrip() {
+	for _, ct := range []string{
+		"application/json",
+		"application/msgpack",
+		"application/x-msgpack",
+		"application/cbor",
+	} {
+		suite.Run(ct, func() {
+			codec, err := GetCodec(ct)
+			suite.Require().NoError(err)
+
+			in := codecTestPayload{Name: "test", Count: 42, Tags: []string{"a", "b"}}
+			data, err := codec.Marshal(in)
+			suite.Require().NoError(err)
+
+			var out codecTestPayload
+			suite.Require().NoError(codec.Unmarshal(data, &out))
+			suite.Require().Equal(in, out)
+		})
+	}
+}
+
+func (suite *CodecSuite) TestMessagePackUsesJSONTags() {
+	data, err := MessagePackCodec{}.Marshal(codecTestPayload{Name: "test"})
+	suite.Require().NoError(err)
+
+	var out map[string]any
+	suite.Require().NoError(MessagePackCodec{}.Unmarshal(data, &out))
+	suite.Require().Contains(out, "name")
+	suite.Require().NotContains(out, "tags")
+}
+
+func (suite *CodecSuite) TestText() {
+	codec, err := GetCodec("text/plain; charset=utf-8")
+	suite.Require().NoError(err)
+
+	data, err := codec.Marshal("hello")
+	suite.Require().NoError(err)
+	suite.Require().Equal("hello", string(data))
+
+	var out string
+	suite.Require().NoError(codec.Unmarshal(data, &out))
+	suite.Require().Equal("hello", out)
+
+	_, err = codec.Marshal(codecTestPayload{})
+	suite.Require().ErrorIs(err, ErrUnsupportedTextValue)
+}
+
+func (suite *CodecSuite) TestGetCodec() {
+	codec, err := GetCodec("application/cloudevents+json")
+	suite.Require().NoError(err)
+	suite.Require().Equal(JSONCodec{}, codec)
+
+	_, err = GetCodec("application/x-test")
+	suite.Require().ErrorIs(err, ErrUnknownContentType)
+
+	RegisterCodec("Application/X-Test", TextCodec{})
+	suite.T().Cleanup(func() {
+		codecsMutex.Lock()
+		defer codecsMutex.Unlock()
+		delete(codecs, "application/x-test")
+	})
+	codec, err = GetCodec("application/x-test")
+	suite.Require().NoError(err)
+	suite.Require().Equal(TextCodec{}, codec)
+}
+
+func (suite *CodecSuite) TestCodecForBrokerMessage() {
+	codec, err := CodecForBrokerMessage(BrokerMessage{
+		Headers: map[string][]byte{ContentTypeHeader: []byte("application/cbor")},
+	}, "application/json")
+	suite.Require().NoError(err)
+	suite.Require().Equal(CBORCodec{}, codec)
+
+	codec, err = CodecForBrokerMessage(BrokerMessage{}, "application/msgpack")
+	suite.Require().NoError(err)
+	suite.Require().Equal(MessagePackCodec{}, codec)
+}
+
+func (suite *CodecSuite) TestSpecificationCodec() {
+	// Content types without codec are encoded in JSON, as before codecs
+	suite.Require().Equal(JSONCodec{}, SpecificationCodec("application/octet-stream"))
+	suite.Require().Equal(CBORCodec{}, SpecificationCodec("application/cbor"))
+
+	codec, err := CodecForBrokerMessage(BrokerMessage{
+		Headers: map[string][]byte{ContentTypeHeader: []byte("application/octet-stream")},
+	}, "application/octet-stream")
+	suite.Require().NoError(err)
+	suite.Require().Equal(JSONCodec{}, codec)
+
+	// Unknown content types other than the specification one can't be decoded
+	_, err = CodecForBrokerMessage(BrokerMessage{
+		Headers: map[string][]byte{ContentTypeHeader: []byte("application/x-test")},
+	}, "application/json")
+	suite.Require().ErrorIs(err, ErrUnknownContentType)
+}
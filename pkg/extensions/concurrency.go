@@ -0,0 +1,138 @@
+package extensions
+
+import (
+	"sync"
+)
+
+// KeyExtractor returns the ordering key of a received message: messages with
+// the same key are processed sequentially, in the order they were received.
+// An empty key means that the message can be processed in any order.
+type KeyExtractor func(msg BrokerMessage) string
+
+// KeyFromHeader returns a key extractor using the value of the header as key
+// (i.e. the header containing the correlation ID).
+func KeyFromHeader(header string) KeyExtractor {
+	return func(msg BrokerMessage) string {
+		return string(msg.Headers[header])
+	}
+}
+
+// ConcurrentExecutor executes the processing of received messages with a
+// maximum concurrency, while keeping the order of the messages with the same key.
+type ConcurrentExecutor struct {
+	slots chan struct{}
+	key   KeyExtractor
+
+	// lanes contains the tasks waiting for the active task of the same key,
+	// and laneFreed is signaled when a task is removed from a lane
+	lanes      map[string][]func()
+	lanesMutex sync.Mutex
+	laneFreed  *sync.Cond
+
+	wg sync.WaitGroup
+}
+
+// NewConcurrentExecutor creates a new executor processing up to 'concurrency'
+// messages at the same time. If key is not nil, messages with the same key are
+// processed sequentially.
+func NewConcurrentExecutor(concurrency int, key KeyExtractor) *ConcurrentExecutor {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	e := &ConcurrentExecutor{
+		slots: make(chan struct{}, concurrency),
+		key:   key,
+		lanes: make(map[string][]func()),
+	}
+	e.laneFreed = sync.NewCond(&e.lanesMutex)
+
+	return e
+}
+
+// Execute executes the processing function of the message. It blocks until a
+// slot is available and is executed synchronously if the concurrency is 1.
+//
+// A message with the same key as a message being processed is queued behind
+// it without taking a slot, so a burst on one key doesn't prevent the messages
+// with other keys from being processed. Up to 'concurrency' messages can be
+// queued for each key: then it blocks until there is room in the queue, so the
+// reception of messages slows down instead of queuing them without limit.
+func (e *ConcurrentExecutor) Execute(msg BrokerMessage, fn func()) {
+	// Process synchronously if there is no concurrency
+	if cap(e.slots) == 1 {
+		fn()
+		return
+	}
+
+	var k string
+	if e.key != nil {
+		k = e.key(msg)
+	}
+
+	// Queue the task if another one with the same key is being processed,
+	// otherwise mark the key as being processed
+	if k != "" {
+		e.lanesMutex.Lock()
+		for queue, active := e.lanes[k]; active && len(queue) >= cap(e.slots); queue, active = e.lanes[k] {
+			e.laneFreed.Wait()
+		}
+		if queue, active := e.lanes[k]; active {
+			e.wg.Add(1)
+			e.lanes[k] = append(queue, fn)
+			e.lanesMutex.Unlock()
+			return
+		}
+		e.lanes[k] = nil
+		e.lanesMutex.Unlock()
+	}
+
+	// Wait for an available slot, released when the task is done
+	e.slots <- struct{}{}
+	e.wg.Add(1)
+
+	// Execute directly if there is no ordering key
+	if k == "" {
+		go func() {
+			defer e.release()
+			fn()
+		}()
+		return
+	}
+
+	go e.runLane(k, fn)
+}
+
+// release releases the slot of a done task.
+func (e *ConcurrentExecutor) release() {
+	<-e.slots
+	e.wg.Done()
+}
+
+// runLane executes the task then the tasks queued on the same key, until
+// there is no more. The slot is released between tasks, so the queued tasks
+// wait for a slot like the other ones.
+func (e *ConcurrentExecutor) runLane(key string, task func()) {
+	for {
+		task()
+		e.release()
+
+		e.lanesMutex.Lock()
+		queue := e.lanes[key]
+		if len(queue) == 0 {
+			delete(e.lanes, key)
+			e.lanesMutex.Unlock()
+			return
+		}
+		task, e.lanes[key] = queue[0], queue[1:]
+		e.laneFreed.Broadcast()
+		e.lanesMutex.Unlock()
+
+		e.slots <- struct{}{}
+	}
+}
+
+// Wait waits for every executed task to be done.
+func (e *ConcurrentExecutor) Wait() {
+	e.wg.Wait()
+}
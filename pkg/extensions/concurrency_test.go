@@ -0,0 +1,126 @@
+package extensions
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestConcurrencySuite(t *testing.T) {
+	suite.Run(t, new(ConcurrencySuite))
+}
+
+type ConcurrencySuite struct {
+	suite.Suite
+}
+
+func (suite *ConcurrencySuite) TestMaximumConcurrency() {
+	e := NewConcurrentExecutor(3, nil)
+
+	var current, maximum atomic.Int32
+	for i := 0; i < 20; i++ {
+		e.Execute(BrokerMessage{}, func() {
+			n := current.Add(1)
+			for m := maximum.Load(); n > m && !maximum.CompareAndSwap(m, n); m = maximum.Load() {
+			}
+			time.Sleep(5 * time.Millisecond)
+			current.Add(-1)
+		})
+	}
+	e.Wait()
+
+	suite.Require().Equal(int32(3), maximum.Load())
+}
+
+func (suite *ConcurrencySuite) TestSynchronousWithoutConcurrency() {
+	e := NewConcurrentExecutor(0, nil)
+
+	done := false
+	e.Execute(BrokerMessage{}, func() { done = true })
+	suite.Require().True(done)
+}
+
+func (suite *ConcurrencySuite) TestOrderingByKey() {
+	e := NewConcurrentExecutor(4, KeyFromHeader("key"))
+
+	var mutex sync.Mutex
+	received := make(map[string][]int)
+	for i := 0; i < 40; i++ {
+		i, key := i, []string{"a", "b", "c"}[i%3]
+		msg := BrokerMessage{Headers: map[string][]byte{"key": []byte(key)}}
+
+		e.Execute(msg, func() {
+			// Sleep more on first messages to reorder them without ordering
+			time.Sleep(time.Duration(40-i) * 100 * time.Microsecond)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			received[key] = append(received[key], i)
+		})
+	}
+	e.Wait()
+
+	for key, indexes := range received {
+		suite.Require().IsIncreasing(indexes, key)
+	}
+	suite.Require().Len(received["a"], 14)
+}
+
+func (suite *ConcurrencySuite) TestHotKeyDoesNotStarveOtherKeys() {
+	e := NewConcurrentExecutor(2, KeyFromHeader("key"))
+	msg := func(key string) BrokerMessage {
+		return BrokerMessage{Headers: map[string][]byte{"key": []byte(key)}}
+	}
+
+	// A burst of messages on one key, filling its queue
+	var hotDone atomic.Int32
+	for i := 0; i < 3; i++ {
+		e.Execute(msg("hot"), func() {
+			time.Sleep(20 * time.Millisecond)
+			hotDone.Add(1)
+		})
+	}
+
+	// A message on another key is processed without waiting for the burst
+	var hotDoneBeforeCold atomic.Int32
+	e.Execute(msg("cold"), func() { hotDoneBeforeCold.Store(hotDone.Load()) })
+	e.Wait()
+
+	suite.Require().Equal(int32(3), hotDone.Load())
+	suite.Require().Less(hotDoneBeforeCold.Load(), int32(2))
+}
+
+func (suite *ConcurrencySuite) TestHotKeyBackpressure() {
+	e := NewConcurrentExecutor(2, KeyFromHeader("key"))
+	msg := BrokerMessage{Headers: map[string][]byte{"key": []byte("hot")}}
+	release := make(chan struct{})
+
+	// The processed message and as many queued messages as the concurrency
+	// are accepted without blocking
+	for i := 0; i < 3; i++ {
+		e.Execute(msg, func() { <-release })
+	}
+
+	// Then the next one waits for room in the queue
+	executed := make(chan struct{})
+	go func() {
+		e.Execute(msg, func() {})
+		close(executed)
+	}()
+	select {
+	case <-executed:
+		suite.FailNow("execution should wait for room in the queue")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-executed:
+	case <-time.After(time.Second):
+		suite.FailNow("execution should be done once there is room in the queue")
+	}
+	e.Wait()
+}
@@ -34,4 +34,36 @@ var (
 	// ErrChannelAddressEmpty is raised when a given channel address is empty,
 	// when dynamically set from message.
 	ErrChannelAddressEmpty = fmt.Errorf("%w: channel address empty", ErrAsyncAPI)
+
+	// ErrValidation is raised when a message doesn't respect the constraints
+	// set in the AsyncAPI specification.
+	ErrValidation = fmt.Errorf("%w: validation failed", ErrAsyncAPI)
+
+	// ErrUnknownContentType is raised when there is no codec registered for
+	// the content type of a message.
+	ErrUnknownContentType = fmt.Errorf("%w: no codec for content type", ErrAsyncAPI)
+
+	// ErrUnsupportedTextValue is raised when the text codec is used with a
+	// value that cannot be represented as text.
+	ErrUnsupportedTextValue = fmt.Errorf("%w: value is not supported by text codec", ErrAsyncAPI)
+
+	// ErrNoChannelInContext is raised when the channel of a message is expected
+	// in context, but none is set.
+	ErrNoChannelInContext = fmt.Errorf("%w: no channel in context", ErrAsyncAPI)
+
+	// ErrUnknownMessage is raised when a received message cannot be identified
+	// among the messages of an operation.
+	ErrUnknownMessage = fmt.Errorf("%w: unknown message", ErrAsyncAPI)
+
+	// ErrNotSubscribedChannel is raised when a message is injected in a fake
+	// controller on a channel that has not been subscribed.
+	ErrNotSubscribedChannel = fmt.Errorf("%w: the channel has not been subscribed", ErrAsyncAPI)
+
+	// ErrNoFakeReply is raised when a request is done on a fake controller
+	// without reply function set.
+	ErrNoFakeReply = fmt.Errorf("%w: no reply set on fake controller", ErrAsyncAPI)
+
+	// ErrSubscriptionNotDrained is raised when a subscription still has messages
+	// being processed at the end of a graceful shutdown.
+	ErrSubscriptionNotDrained = fmt.Errorf("%w: subscription not drained", ErrAsyncAPI)
 )
@@ -0,0 +1,132 @@
+package extensions
+
+import (
+	"context"
+	"sync"
+)
+
+// ListenerTracker tracks the messages being processed by the listener of a
+// subscription, so they can be awaited before stopping it (i.e. on a graceful
+// shutdown).
+type ListenerTracker struct {
+	mutex         sync.Mutex
+	inFlight      int
+	intakeStopped bool
+
+	// idle is closed when there is no message being processed
+	idle chan struct{}
+	// stopped is closed when the listener is stopped
+	stopped chan struct{}
+
+	// ctx is the parent context of the messages processing, canceled by
+	// CancelProcessing
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewListenerTracker creates a new tracker for the listener of a subscription.
+func NewListenerTracker() *ListenerTracker {
+	idle := make(chan struct{})
+	close(idle)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &ListenerTracker{
+		idle:    idle,
+		stopped: make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Context returns the context from which the contexts of the messages
+// processing should be created, so they can be canceled by CancelProcessing.
+func (t *ListenerTracker) Context() context.Context {
+	return t.ctx
+}
+
+// CancelProcessing cancels the context of the messages being processed (i.e.
+// when unsubscribing, or when they are not processed in time on shutdown).
+func (t *ListenerTracker) CancelProcessing() {
+	t.cancel()
+}
+
+// MessageReceived registers a received message as being processed, until
+// MessageProcessed is called. It returns false if the intake of new messages
+// has been stopped: the message should then be rejected (i.e. with a Nak) to be
+// processed later, possibly by another instance.
+func (t *ListenerTracker) MessageReceived() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.intakeStopped {
+		return false
+	}
+
+	if t.inFlight == 0 {
+		t.idle = make(chan struct{})
+	}
+	t.inFlight++
+
+	return true
+}
+
+// MessageProcessed registers a message as processed (i.e. acknowledged).
+func (t *ListenerTracker) MessageProcessed() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.inFlight--
+	if t.inFlight == 0 {
+		close(t.idle)
+	}
+}
+
+// InFlight returns the number of messages being processed.
+func (t *ListenerTracker) InFlight() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.inFlight
+}
+
+// StopIntake stops the intake of new messages: MessageReceived will return
+// false from now on.
+func (t *ListenerTracker) StopIntake() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.intakeStopped = true
+}
+
+// Drain stops the intake of new messages and waits for the messages being
+// processed, until the context is done.
+func (t *ListenerTracker) Drain(ctx context.Context) error {
+	t.mutex.Lock()
+	t.intakeStopped = true
+	idle := t.idle
+	t.mutex.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stopped should be called by the listener when it is stopped, once every
+// message has been processed.
+func (t *ListenerTracker) Stopped() {
+	close(t.stopped)
+}
+
+// WaitStopped waits for the listener to be stopped, until the context is done.
+func (t *ListenerTracker) WaitStopped(ctx context.Context) error {
+	select {
+	case <-t.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,84 @@
+package extensions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestListenerTrackerSuite(t *testing.T) {
+	suite.Run(t, new(ListenerTrackerSuite))
+}
+
+type ListenerTrackerSuite struct {
+	suite.Suite
+}
+
+func (suite *ListenerTrackerSuite) TestDrain() {
+	t := NewListenerTracker()
+
+	// Nothing to drain
+	suite.Require().NoError(t.Drain(context.Background()))
+
+	// Messages should not be accepted once draining
+	suite.Require().False(t.MessageReceived())
+	suite.Require().Equal(0, t.InFlight())
+}
+
+func (suite *ListenerTrackerSuite) TestStopIntake() {
+	t := NewListenerTracker()
+	suite.Require().True(t.MessageReceived())
+
+	t.StopIntake()
+	suite.Require().False(t.MessageReceived())
+	suite.Require().Equal(1, t.InFlight())
+}
+
+func (suite *ListenerTrackerSuite) TestDrainWaitsForMessages() {
+	t := NewListenerTracker()
+	suite.Require().True(t.MessageReceived())
+	suite.Require().True(t.MessageReceived())
+	suite.Require().Equal(2, t.InFlight())
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		t.MessageProcessed()
+		t.MessageProcessed()
+	}()
+
+	suite.Require().NoError(t.Drain(context.Background()))
+	suite.Require().Equal(0, t.InFlight())
+}
+
+func (suite *ListenerTrackerSuite) TestDrainTimeout() {
+	t := NewListenerTracker()
+	suite.Require().True(t.MessageReceived())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	suite.Require().ErrorIs(t.Drain(ctx), context.DeadlineExceeded)
+	suite.Require().Equal(1, t.InFlight())
+}
+
+func (suite *ListenerTrackerSuite) TestWaitStopped() {
+	t := NewListenerTracker()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	suite.Require().ErrorIs(t.WaitStopped(ctx), context.DeadlineExceeded)
+
+	t.Stopped()
+	suite.Require().NoError(t.WaitStopped(context.Background()))
+}
+
+func (suite *ListenerTrackerSuite) TestCancelProcessing() {
+	t := NewListenerTracker()
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	suite.Require().NoError(ctx.Err())
+
+	t.CancelProcessing()
+	suite.Require().ErrorIs(ctx.Err(), context.Canceled)
+}
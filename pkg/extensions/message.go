@@ -0,0 +1,28 @@
+package extensions
+
+import "fmt"
+
+// MessageNameHeader is the header containing the name of the message, used to
+// identify it when an operation has multiple messages.
+const MessageNameHeader = "x-message-name"
+
+// PayloadDiscriminator returns the value of a field of the payload, decoded with
+// the codec corresponding to the broker message content type. It is used to
+// identify the message when an operation has multiple messages.
+func PayloadDiscriminator(bm BrokerMessage, defaultContentType, field string) (string, bool) {
+	codec, err := CodecForBrokerMessage(bm, defaultContentType)
+	if err != nil {
+		return "", false
+	}
+
+	var payload map[string]any
+	if err := codec.Unmarshal(bm.Payload, &payload); err != nil {
+		return "", false
+	}
+
+	v, ok := payload[field]
+	if !ok || v == nil {
+		return "", false
+	}
+	return fmt.Sprint(v), true
+}
@@ -0,0 +1,75 @@
+package middlewares
+
+import (
+	"context"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+)
+
+// MetricsLabels are the labels of the metrics recorded by the metrics middleware,
+// coming from the context of the message.
+type MetricsLabels struct {
+	// Channel is the channel of the message (from extensions.ContextKeyIsChannel)
+	Channel string
+	// Provider is the controller of the message, 'app' or 'user'
+	// (from extensions.ContextKeyIsProvider)
+	Provider string
+	// Direction is either 'publication' or 'reception'
+	// (from extensions.ContextKeyIsDirection)
+	Direction string
+}
+
+// MetricsCollector is the interface that needs to be implemented to expose
+// the metrics recorded by the metrics middleware (i.e. with Prometheus).
+type MetricsCollector interface {
+	// IncMessages increments the number of processed messages.
+	IncMessages(labels MetricsLabels)
+	// IncErrors increments the number of messages whose processing failed.
+	IncErrors(labels MetricsLabels)
+	// AddPayloadBytes adds the size of the message payload.
+	AddPayloadBytes(labels MetricsLabels, n int)
+	// ObserveDuration records the duration of the message processing.
+	ObserveDuration(labels MetricsLabels, d time.Duration)
+}
+
+// Metrics is a middleware that records, for each channel, provider and direction,
+// the number of messages, the number of errors, the size of payloads and the
+// duration of the processing of messages (including following middlewares and
+// subscription function or publication).
+func Metrics(collector MetricsCollector) extensions.Middleware {
+	return func(ctx context.Context, msg *extensions.BrokerMessage, next extensions.NextMiddleware) error {
+		labels := metricsLabelsFromContext(ctx)
+
+		// Record message before processing, as payload can be modified
+		collector.IncMessages(labels)
+		collector.AddPayloadBytes(labels, len(msg.Payload))
+
+		// Execute next middlewares and operation
+		start := time.Now()
+		err := next(ctx)
+		collector.ObserveDuration(labels, time.Since(start))
+
+		if err != nil {
+			collector.IncErrors(labels)
+		}
+
+		return err
+	}
+}
+
+func metricsLabelsFromContext(ctx context.Context) MetricsLabels {
+	var labels MetricsLabels
+
+	extensions.IfContextSetWith(ctx, extensions.ContextKeyIsChannel, func(v string) {
+		labels.Channel = v
+	})
+	extensions.IfContextSetWith(ctx, extensions.ContextKeyIsProvider, func(v string) {
+		labels.Provider = v
+	})
+	extensions.IfContextSetWith(ctx, extensions.ContextKeyIsDirection, func(v string) {
+		labels.Direction = v
+	})
+
+	return labels
+}
@@ -0,0 +1,75 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestMetricsSuite(t *testing.T) {
+	suite.Run(t, new(MetricsSuite))
+}
+
+type MetricsSuite struct {
+	suite.Suite
+}
+
+type fakeCollector struct {
+	messages  map[MetricsLabels]int
+	errors    map[MetricsLabels]int
+	bytes     map[MetricsLabels]int
+	durations map[MetricsLabels][]time.Duration
+}
+
+func newFakeCollector() *fakeCollector {
+	return &fakeCollector{
+		messages:  make(map[MetricsLabels]int),
+		errors:    make(map[MetricsLabels]int),
+		bytes:     make(map[MetricsLabels]int),
+		durations: make(map[MetricsLabels][]time.Duration),
+	}
+}
+
+func (c *fakeCollector) IncMessages(labels MetricsLabels) { c.messages[labels]++ }
+func (c *fakeCollector) IncErrors(labels MetricsLabels)   { c.errors[labels]++ }
+func (c *fakeCollector) AddPayloadBytes(labels MetricsLabels, n int) {
+	c.bytes[labels] += n
+}
+func (c *fakeCollector) ObserveDuration(labels MetricsLabels, d time.Duration) {
+	c.durations[labels] = append(c.durations[labels], d)
+}
+
+func (suite *MetricsSuite) TestMetrics() {
+	collector := newFakeCollector()
+	mw := Metrics(collector)
+
+	ctx := context.WithValue(context.Background(), extensions.ContextKeyIsChannel, "orders")
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsProvider, "app")
+	recvCtx := context.WithValue(ctx, extensions.ContextKeyIsDirection, "reception")
+	pubCtx := context.WithValue(ctx, extensions.ContextKeyIsDirection, "publication")
+
+	msg := &extensions.BrokerMessage{Payload: []byte("12345")}
+	suite.Require().NoError(mw(recvCtx, msg, func(context.Context) error {
+		time.Sleep(time.Millisecond)
+		return nil
+	}))
+	suite.Require().Error(mw(recvCtx, msg, func(context.Context) error { return errors.New("error") }))
+	suite.Require().NoError(mw(pubCtx, msg, func(context.Context) error { return nil }))
+
+	recv := MetricsLabels{Channel: "orders", Provider: "app", Direction: "reception"}
+	pub := MetricsLabels{Channel: "orders", Provider: "app", Direction: "publication"}
+
+	suite.Require().Equal(2, collector.messages[recv])
+	suite.Require().Equal(1, collector.errors[recv])
+	suite.Require().Equal(10, collector.bytes[recv])
+	suite.Require().Len(collector.durations[recv], 2)
+	suite.Require().GreaterOrEqual(collector.durations[recv][0], time.Millisecond)
+
+	suite.Require().Equal(1, collector.messages[pub])
+	suite.Require().Equal(0, collector.errors[pub])
+	suite.Require().Equal(5, collector.bytes[pub])
+}
@@ -0,0 +1,256 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+)
+
+const (
+	// RetryAttemptsHeader is the header containing the number of failed
+	// processing attempts of the message, when sent to the dead-letter channel.
+	RetryAttemptsHeader = "x-retry-attempts"
+	// DeadLetterChannelHeader is the header containing the channel the message
+	// was originally received from, when sent to the dead-letter channel.
+	DeadLetterChannelHeader = "x-original-channel"
+	// DeadLetterErrorHeader is the header containing the error of the last
+	// processing attempt, when sent to the dead-letter channel.
+	DeadLetterErrorHeader = "x-error"
+
+	// DefaultDeadLetterSuffix is the suffix added to the channel address to get
+	// the dead-letter channel, if no channel is set with WithDeadLetterChannel.
+	DefaultDeadLetterSuffix = ".dlq"
+)
+
+// Backoff returns the duration to wait before the retry following the given
+// number of failed attempts (starting at 1).
+type Backoff func(attempts int) time.Duration
+
+// FixedBackoff waits the same duration before each retry.
+func FixedBackoff(d time.Duration) Backoff {
+	return func(_ int) time.Duration {
+		return d
+	}
+}
+
+// ExponentialBackoff doubles the duration before each retry, starting with
+// initial duration and without exceeding the maximum duration.
+func ExponentialBackoff(initial, maximum time.Duration) Backoff {
+	return func(attempts int) time.Duration {
+		d := float64(initial) * math.Pow(2, float64(attempts-1))
+		if d > float64(maximum) {
+			return maximum
+		}
+		return time.Duration(d)
+	}
+}
+
+// WithJitter adds a random variation of up to +/- factor (between 0 and 1) to
+// the duration of the backoff, to avoid retrying many messages at the same time.
+func WithJitter(backoff Backoff, factor float64) Backoff {
+	return func(attempts int) time.Duration {
+		d := float64(backoff(attempts))
+		return time.Duration(d + d*factor*(2*rand.Float64()-1))
+	}
+}
+
+type retry struct {
+	broker            extensions.BrokerController
+	maxAttempts       int
+	backoff           Backoff
+	deadLetterChannel string
+	logger            extensions.Logger
+}
+
+// RetryOption is a function that can be used to configure the retry middleware.
+// Examples: WithMaxAttempts(), WithBackoff(), WithDeadLetterChannel().
+type RetryOption func(r *retry)
+
+// WithMaxAttempts set the maximum number of processing attempts of a message
+// (including the first one) before sending it to the dead-letter channel.
+func WithMaxAttempts(n int) RetryOption {
+	return func(r *retry) {
+		r.maxAttempts = n
+	}
+}
+
+// WithBackoff set the backoff used to wait before retrying a message.
+func WithBackoff(backoff Backoff) RetryOption {
+	return func(r *retry) {
+		r.backoff = backoff
+	}
+}
+
+// WithDeadLetterChannel set the channel where messages are sent when every
+// attempt has failed. If not set, the channel address suffixed with
+// DefaultDeadLetterSuffix is used.
+func WithDeadLetterChannel(channel string) RetryOption {
+	return func(r *retry) {
+		r.deadLetterChannel = channel
+	}
+}
+
+// WithRetryLogger set a custom logger that will log retries and dead letters.
+func WithRetryLogger(logger extensions.Logger) RetryOption {
+	return func(r *retry) {
+		r.logger = logger
+	}
+}
+
+// Retry is a middleware that retries received messages when their processing
+// fails, by calling the following middlewares and the subscription function
+// again after waiting for the backoff. As the message is not published again,
+// the other subscribers of the channel don't receive it twice.
+//
+// The number of failed attempts is tracked in the RetryAttemptsHeader header: a
+// received message with this header continues from its number of attempts.
+//
+// When the maximum number of attempts is reached, the message is published on
+// the dead-letter channel, with the original channel, the error and the number
+// of attempts in headers, and the received message is acknowledged.
+//
+// The wait is interrupted when the context of the message is canceled (i.e.
+// when unsubscribing or closing the controller): the message is then published
+// again on its channel with its number of attempts, so the count is kept when it
+// is received again, and the received message is acknowledged. If it cannot be
+// published, the error is returned, so the message is negatively acknowledged.
+//
+// NOTE: the wait blocks the processing of the following messages of the
+// subscription, unless they are processed concurrently (see WithConcurrency).
+//
+// It should be placed before the middlewares that could return errors that
+// should be retried.
+func Retry(broker extensions.BrokerController, options ...RetryOption) extensions.Middleware {
+	r := retry{
+		broker:      broker,
+		maxAttempts: 3,
+		backoff:     WithJitter(ExponentialBackoff(100*time.Millisecond, 10*time.Second), 0.2),
+		logger:      extensions.DummyLogger{},
+	}
+
+	for _, option := range options {
+		option(&r)
+	}
+
+	return func(ctx context.Context, msg *extensions.BrokerMessage, next extensions.NextMiddleware) error {
+		// Only retry received messages
+		if ctx.Value(extensions.ContextKeyIsDirection) != "reception" {
+			return next(ctx)
+		}
+
+		// Don't process messages received after the cancellation
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Continue from the attempts of the previous deliveries
+		for attempts := receivedAttempts(msg) + 1; ; attempts++ {
+			err := next(ctx)
+			if err == nil {
+				return nil
+			}
+
+			// Send it to the dead-letter channel if there is no more attempt
+			if attempts >= r.maxAttempts {
+				if dlqErr := r.sendToDeadLetter(ctx, msg, attempts, err); dlqErr != nil {
+					return errors.Join(err, dlqErr)
+				}
+				return nil
+			}
+
+			// Wait before retrying, or publish it again to keep the attempts
+			// if the wait is interrupted
+			if waitErr := r.wait(ctx, attempts); waitErr != nil {
+				if pubErr := r.publishAgain(ctx, msg, attempts); pubErr != nil {
+					return errors.Join(err, waitErr, pubErr)
+				}
+				return nil
+			}
+
+			r.logger.Info(ctx, fmt.Sprintf("Retrying message after %d failed attempts", attempts))
+		}
+	}
+}
+
+// receivedAttempts returns the number of failed attempts from the message
+// headers, or 0 if there is none.
+func receivedAttempts(msg *extensions.BrokerMessage) int {
+	attempts, err := strconv.Atoi(string(msg.Headers[RetryAttemptsHeader]))
+	if err != nil || attempts < 0 {
+		return 0
+	}
+	return attempts
+}
+
+// copyWithAttempts returns a copy of the message with the number of failed
+// attempts in headers, to keep the received one unchanged.
+func copyWithAttempts(msg *extensions.BrokerMessage, attempts int) extensions.BrokerMessage {
+	bm := extensions.BrokerMessage{
+		Headers: make(map[string][]byte, len(msg.Headers)+3),
+		Payload: msg.Payload,
+		Key:     msg.Key,
+	}
+	for k, v := range msg.Headers {
+		bm.Headers[k] = v
+	}
+	bm.Headers[RetryAttemptsHeader] = []byte(strconv.Itoa(attempts))
+	return bm
+}
+
+// publishAgain publishes a copy of the message with its number of failed
+// attempts on its channel.
+func (r retry) publishAgain(ctx context.Context, msg *extensions.BrokerMessage, attempts int) error {
+	channel, ok := ctx.Value(extensions.ContextKeyIsChannel).(string)
+	if !ok {
+		return extensions.ErrNoChannelInContext
+	}
+
+	// Publish it even if the context of the message is canceled
+	r.logger.Warning(ctx, fmt.Sprintf("Publishing message again after %d failed attempts", attempts))
+	return r.broker.Publish(context.WithoutCancel(ctx), channel, copyWithAttempts(msg, attempts))
+}
+
+// wait waits for the backoff following the number of failed attempts, until
+// the context is done.
+func (r retry) wait(ctx context.Context, attempts int) error {
+	timer := time.NewTimer(r.backoff(attempts))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sendToDeadLetter publishes a copy of the message on the dead-letter channel.
+func (r retry) sendToDeadLetter(
+	ctx context.Context,
+	msg *extensions.BrokerMessage,
+	attempts int,
+	processErr error,
+) error {
+	channel, ok := ctx.Value(extensions.ContextKeyIsChannel).(string)
+	if !ok {
+		return extensions.ErrNoChannelInContext
+	}
+
+	dlc := r.deadLetterChannel
+	if dlc == "" {
+		dlc = channel + DefaultDeadLetterSuffix
+	}
+
+	bm := copyWithAttempts(msg, attempts)
+	bm.Headers[DeadLetterChannelHeader] = []byte(channel)
+	bm.Headers[DeadLetterErrorHeader] = []byte(processErr.Error())
+
+	r.logger.Warning(ctx, fmt.Sprintf("Sending message to dead-letter channel %q after %d attempts", dlc, attempts))
+	return r.broker.Publish(ctx, dlc, bm)
+}
@@ -0,0 +1,222 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/stretchr/testify/suite"
+)
+
+var errProcessing = errors.New("processing error")
+
+func TestRetrySuite(t *testing.T) {
+	suite.Run(t, new(RetrySuite))
+}
+
+type RetrySuite struct {
+	suite.Suite
+}
+
+// publishedMessage is a message published on the fake broker.
+type publishedMessage struct {
+	channel string
+	msg     extensions.BrokerMessage
+}
+
+// fakeBroker is a broker controller recording the published messages.
+type fakeBroker struct {
+	published []publishedMessage
+	err       error
+}
+
+func (b *fakeBroker) Publish(_ context.Context, channel string, msg extensions.BrokerMessage) error {
+	b.published = append(b.published, publishedMessage{channel: channel, msg: msg})
+	return b.err
+}
+
+func (b *fakeBroker) Subscribe(_ context.Context, _ string) (extensions.BrokerChannelSubscription, error) {
+	return extensions.BrokerChannelSubscription{}, nil
+}
+
+func receptionContext(channel string) context.Context {
+	ctx := context.WithValue(context.Background(), extensions.ContextKeyIsChannel, channel)
+	return context.WithValue(ctx, extensions.ContextKeyIsDirection, "reception")
+}
+
+// failing returns a next function failing the given number of times, and the
+// number of times it has been called.
+func failing(failures int) (extensions.NextMiddleware, *int) {
+	var calls int
+	return func(context.Context) error {
+		calls++
+		if calls <= failures {
+			return errProcessing
+		}
+		return nil
+	}, &calls
+}
+
+func (suite *RetrySuite) TestBackoffs() {
+	suite.Require().Equal(time.Second, FixedBackoff(time.Second)(5))
+
+	exp := ExponentialBackoff(100*time.Millisecond, time.Second)
+	suite.Require().Equal(100*time.Millisecond, exp(1))
+	suite.Require().Equal(400*time.Millisecond, exp(3))
+	suite.Require().Equal(time.Second, exp(10))
+
+	jitter := WithJitter(FixedBackoff(time.Second), 0.5)
+	for i := 0; i < 100; i++ {
+		d := jitter(1)
+		suite.Require().GreaterOrEqual(d, 500*time.Millisecond)
+		suite.Require().LessOrEqual(d, 1500*time.Millisecond)
+	}
+}
+
+func (suite *RetrySuite) TestRetryUntilSuccess() {
+	broker := &fakeBroker{}
+	mw := Retry(broker, WithMaxAttempts(3), WithBackoff(FixedBackoff(time.Millisecond)))
+
+	next, calls := failing(2)
+	suite.Require().NoError(mw(receptionContext("orders"), &extensions.BrokerMessage{}, next))
+	suite.Require().Equal(3, *calls)
+
+	// The message should not be published again
+	suite.Require().Empty(broker.published)
+}
+
+func (suite *RetrySuite) TestDeadLetter() {
+	broker := &fakeBroker{}
+	mw := Retry(broker, WithMaxAttempts(3), WithBackoff(FixedBackoff(time.Millisecond)))
+
+	msg := &extensions.BrokerMessage{
+		Headers: map[string][]byte{"key": []byte("value")},
+		Payload: []byte("payload"),
+	}
+	next, calls := failing(10)
+	suite.Require().NoError(mw(receptionContext("orders"), msg, next))
+	suite.Require().Equal(3, *calls)
+
+	suite.Require().Len(broker.published, 1)
+	suite.Require().Equal("orders"+DefaultDeadLetterSuffix, broker.published[0].channel)
+	suite.Require().Equal(extensions.BrokerMessage{
+		Headers: map[string][]byte{
+			"key":                   []byte("value"),
+			RetryAttemptsHeader:     []byte("3"),
+			DeadLetterChannelHeader: []byte("orders"),
+			DeadLetterErrorHeader:   []byte(errProcessing.Error()),
+		},
+		Payload: []byte("payload"),
+	}, broker.published[0].msg)
+
+	// The received message should be unchanged
+	suite.Require().Len(msg.Headers, 1)
+
+	// A custom dead-letter channel can be set
+	broker = &fakeBroker{}
+	mw = Retry(broker, WithMaxAttempts(1), WithDeadLetterChannel("dead-letters"))
+	next, _ = failing(1)
+	suite.Require().NoError(mw(receptionContext("orders"), msg, next))
+	suite.Require().Len(broker.published, 1)
+	suite.Require().Equal("dead-letters", broker.published[0].channel)
+}
+
+func (suite *RetrySuite) TestDeadLetterError() {
+	broker := &fakeBroker{err: errors.New("publish error")}
+	mw := Retry(broker, WithMaxAttempts(1))
+
+	next, _ := failing(1)
+	err := mw(receptionContext("orders"), &extensions.BrokerMessage{}, next)
+	suite.Require().ErrorIs(err, errProcessing)
+	suite.Require().ErrorIs(err, broker.err)
+}
+
+func (suite *RetrySuite) TestAttemptsFromHeader() {
+	broker := &fakeBroker{}
+	mw := Retry(broker, WithMaxAttempts(3), WithBackoff(FixedBackoff(time.Millisecond)))
+
+	// The message has already failed twice, so only one attempt is left
+	msg := &extensions.BrokerMessage{Headers: map[string][]byte{RetryAttemptsHeader: []byte("2")}}
+	next, calls := failing(10)
+	suite.Require().NoError(mw(receptionContext("orders"), msg, next))
+	suite.Require().Equal(1, *calls)
+
+	suite.Require().Len(broker.published, 1)
+	suite.Require().Equal("orders"+DefaultDeadLetterSuffix, broker.published[0].channel)
+	suite.Require().Equal("3", string(broker.published[0].msg.Headers[RetryAttemptsHeader]))
+
+	// An invalid header should be ignored
+	broker = &fakeBroker{}
+	mw = Retry(broker, WithMaxAttempts(3), WithBackoff(FixedBackoff(time.Millisecond)))
+	msg = &extensions.BrokerMessage{Headers: map[string][]byte{RetryAttemptsHeader: []byte("invalid")}}
+	next, calls = failing(10)
+	suite.Require().NoError(mw(receptionContext("orders"), msg, next))
+	suite.Require().Equal(3, *calls)
+}
+
+func (suite *RetrySuite) TestBackoffInterrupted() {
+	broker := &fakeBroker{}
+	mw := Retry(broker, WithMaxAttempts(3), WithBackoff(FixedBackoff(time.Hour)))
+
+	ctx, cancel := context.WithCancel(receptionContext("orders"))
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	msg := &extensions.BrokerMessage{
+		Headers: map[string][]byte{"key": []byte("value")},
+		Payload: []byte("payload"),
+		Key:     []byte("id"),
+	}
+	next, calls := failing(10)
+	suite.Require().NoError(mw(ctx, msg, next))
+	suite.Require().Equal(1, *calls)
+
+	// The message should be published again with its attempts
+	suite.Require().Len(broker.published, 1)
+	suite.Require().Equal("orders", broker.published[0].channel)
+	suite.Require().Equal(extensions.BrokerMessage{
+		Headers: map[string][]byte{
+			"key":               []byte("value"),
+			RetryAttemptsHeader: []byte("1"),
+		},
+		Payload: []byte("payload"),
+		Key:     []byte("id"),
+	}, broker.published[0].msg)
+
+	// The error should be returned if the message cannot be published again
+	broker = &fakeBroker{err: errors.New("publish error")}
+	mw = Retry(broker, WithMaxAttempts(3), WithBackoff(FixedBackoff(time.Hour)))
+	ctx, cancel = context.WithCancel(receptionContext("orders"))
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	next, _ = failing(10)
+	err := mw(ctx, msg, next)
+	suite.Require().ErrorIs(err, errProcessing)
+	suite.Require().ErrorIs(err, context.Canceled)
+	suite.Require().ErrorIs(err, broker.err)
+}
+
+func (suite *RetrySuite) TestCanceledNotProcessed() {
+	broker := &fakeBroker{}
+	mw := Retry(broker)
+
+	ctx, cancel := context.WithCancel(receptionContext("orders"))
+	cancel()
+
+	next, calls := failing(0)
+	suite.Require().ErrorIs(mw(ctx, &extensions.BrokerMessage{}, next), context.Canceled)
+	suite.Require().Zero(*calls)
+	suite.Require().Empty(broker.published)
+}
+
+func (suite *RetrySuite) TestPublicationNotRetried() {
+	broker := &fakeBroker{}
+	mw := Retry(broker, WithBackoff(FixedBackoff(time.Millisecond)))
+
+	ctx := context.WithValue(context.Background(), extensions.ContextKeyIsDirection, "publication")
+	next, calls := failing(1)
+	suite.Require().ErrorIs(mw(ctx, &extensions.BrokerMessage{}, next), errProcessing)
+	suite.Require().Equal(1, *calls)
+	suite.Require().Empty(broker.published)
+}
@@ -0,0 +1,164 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the name of the tracer used by the tracing middleware.
+const TracerName = "github.com/lerenn/asyncapi-codegen/pkg/extensions/middlewares"
+
+type tracing struct {
+	tracerProvider trace.TracerProvider
+	propagator     propagation.TextMapPropagator
+	system         string
+}
+
+// TracingOption is a function that can be used to configure the tracing middleware.
+// Examples: WithTracerProvider(), WithPropagator(), WithMessagingSystem().
+type TracingOption func(t *tracing)
+
+// WithTracerProvider set the tracer provider used to create spans.
+// If not set, the global tracer provider is used.
+func WithTracerProvider(tp trace.TracerProvider) TracingOption {
+	return func(t *tracing) {
+		t.tracerProvider = tp
+	}
+}
+
+// WithPropagator set the propagator used to inject and extract the trace context
+// in messages headers. If not set, W3C trace context and baggage are used.
+func WithPropagator(p propagation.TextMapPropagator) TracingOption {
+	return func(t *tracing) {
+		t.propagator = p
+	}
+}
+
+// WithMessagingSystem set the messaging system (i.e. 'kafka', 'rabbitmq', 'nats')
+// used as 'messaging.system' attribute on spans.
+func WithMessagingSystem(system string) TracingOption {
+	return func(t *tracing) {
+		t.system = system
+	}
+}
+
+// Tracing is a middleware that creates OpenTelemetry spans when publishing
+// (producer span) and receiving (consumer span) messages.
+//
+// The trace context is injected in the headers of published messages and
+// extracted from the headers of received messages, so the consumer span is a
+// child of the producer span. The context given to the next middlewares and to
+// the subscription function contains the consumer span.
+func Tracing(options ...TracingOption) extensions.Middleware {
+	t := tracing{
+		propagator: propagation.NewCompositeTextMapPropagator(
+			propagation.TraceContext{}, propagation.Baggage{}),
+	}
+
+	for _, option := range options {
+		option(&t)
+	}
+
+	if t.tracerProvider == nil {
+		t.tracerProvider = otel.GetTracerProvider()
+	}
+	tracer := t.tracerProvider.Tracer(TracerName)
+
+	return func(ctx context.Context, msg *extensions.BrokerMessage, next extensions.NextMiddleware) error {
+		channel, _ := ctx.Value(extensions.ContextKeyIsChannel).(string)
+
+		// Set span kind and operation depending on direction
+		var kind trace.SpanKind
+		var operation attribute.KeyValue
+		switch ctx.Value(extensions.ContextKeyIsDirection) {
+		case "publication":
+			kind, operation = trace.SpanKindProducer, semconv.MessagingOperationPublish
+		case "reception":
+			kind, operation = trace.SpanKindConsumer, semconv.MessagingOperationReceive
+
+			// Continue the trace from the message headers
+			ctx = t.propagator.Extract(ctx, headersCarrier(msg.Headers))
+		default:
+			return next(ctx)
+		}
+
+		// Start span
+		ctx, span := tracer.Start(ctx,
+			fmt.Sprintf("%s %s", channel, operation.Value.AsString()),
+			trace.WithSpanKind(kind),
+			trace.WithAttributes(t.attributes(ctx, channel, operation, msg)...))
+		defer span.End()
+
+		// Propagate the trace in the published message
+		if kind == trace.SpanKindProducer {
+			if msg.Headers == nil {
+				msg.Headers = make(map[string][]byte)
+			}
+			t.propagator.Inject(ctx, headersCarrier(msg.Headers))
+		}
+
+		// Execute next middlewares and operation
+		if err := next(ctx); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+
+		return nil
+	}
+}
+
+func (t tracing) attributes(
+	ctx context.Context,
+	channel string,
+	operation attribute.KeyValue,
+	msg *extensions.BrokerMessage,
+) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		operation,
+		semconv.MessagingDestinationName(channel),
+		semconv.MessagingMessageBodySize(len(msg.Payload)),
+	}
+
+	if t.system != "" {
+		attrs = append(attrs, semconv.MessagingSystemKey.String(t.system))
+	}
+
+	// NOTE: the correlation ID is only in the context of published messages, as
+	// received messages are decoded after the middlewares.
+	extensions.IfContextSetWith(ctx, extensions.ContextKeyIsCorrelationID, func(id string) {
+		attrs = append(attrs, semconv.MessagingMessageConversationID(id))
+	})
+
+	return attrs
+}
+
+// headersCarrier adapts broker message headers to a propagation.TextMapCarrier.
+type headersCarrier map[string][]byte
+
+// Get returns the value associated with the passed key.
+func (c headersCarrier) Get(key string) string {
+	return string(c[key])
+}
+
+// Set stores the key-value pair.
+func (c headersCarrier) Set(key, value string) {
+	c[key] = []byte(value)
+}
+
+// Keys lists the keys stored in this carrier.
+func (c headersCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
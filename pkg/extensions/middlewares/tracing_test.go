@@ -0,0 +1,149 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// NOTE: tests with the OpenTelemetry SDK are in the 'test/v3/features/tracing'
+// module, as it is not needed by this package.
+
+func TestTracingSuite(t *testing.T) {
+	suite.Run(t, new(TracingSuite))
+}
+
+type TracingSuite struct {
+	suite.Suite
+}
+
+// fakeSpan is a span recording its configuration and status.
+type fakeSpan struct {
+	noop.Span
+	name   string
+	sc     trace.SpanContext
+	parent trace.SpanContext
+	config trace.SpanConfig
+	status codes.Code
+	ended  bool
+}
+
+func (s *fakeSpan) SpanContext() trace.SpanContext          { return s.sc }
+func (s *fakeSpan) SetStatus(code codes.Code, _ string)     { s.status = code }
+func (s *fakeSpan) End(...trace.SpanEndOption)              { s.ended = true }
+func (s *fakeSpan) RecordError(error, ...trace.EventOption) {}
+
+// fakeTracerProvider is a tracer provider always giving the same tracer.
+type fakeTracerProvider struct {
+	embedded.TracerProvider
+	tracer *fakeTracer
+}
+
+func (tp fakeTracerProvider) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return tp.tracer
+}
+
+// fakeTracer is a tracer recording the started spans.
+type fakeTracer struct {
+	embedded.Tracer
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) Start(
+	ctx context.Context,
+	name string,
+	opts ...trace.SpanStartOption,
+) (context.Context, trace.Span) {
+	parent := trace.SpanContextFromContext(ctx)
+
+	// Continue the trace of the parent if there is one
+	traceID := parent.TraceID()
+	if !traceID.IsValid() {
+		traceID = trace.TraceID{byte(len(t.spans) + 1)}
+	}
+
+	span := &fakeSpan{
+		name:   name,
+		parent: parent,
+		config: trace.NewSpanStartConfig(opts...),
+		sc: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     trace.SpanID{byte(len(t.spans) + 1)},
+			TraceFlags: trace.FlagsSampled,
+		}),
+	}
+	t.spans = append(t.spans, span)
+
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+func (suite *TracingSuite) TestPublicationAndReception() {
+	tracer := &fakeTracer{}
+	mw := Tracing(WithTracerProvider(fakeTracerProvider{tracer: tracer}), WithMessagingSystem("inmemory"))
+
+	ctx := context.WithValue(context.Background(), extensions.ContextKeyIsChannel, "orders")
+	pubCtx := context.WithValue(ctx, extensions.ContextKeyIsDirection, "publication")
+	pubCtx = context.WithValue(pubCtx, extensions.ContextKeyIsCorrelationID, "1234")
+	recvCtx := context.WithValue(ctx, extensions.ContextKeyIsDirection, "reception")
+
+	// Publish a message
+	msg := &extensions.BrokerMessage{Payload: []byte("12345")}
+	suite.Require().NoError(mw(pubCtx, msg, func(context.Context) error { return nil }))
+	suite.Require().Len(tracer.spans, 1)
+	producer := tracer.spans[0]
+
+	suite.Require().True(producer.ended)
+	suite.Require().Equal("orders publish", producer.name)
+	suite.Require().Equal(trace.SpanKindProducer, producer.config.SpanKind())
+	suite.Require().Subset(producer.config.Attributes(), []attribute.KeyValue{
+		semconv.MessagingOperationPublish,
+		semconv.MessagingSystemKey.String("inmemory"),
+		semconv.MessagingDestinationName("orders"),
+		semconv.MessagingMessageBodySize(5),
+		semconv.MessagingMessageConversationID("1234"),
+	})
+	suite.Require().NotEmpty(msg.Headers["traceparent"])
+
+	// Receive it with an error
+	var nextSpan trace.SpanContext
+	err := mw(recvCtx, msg, func(ctx context.Context) error {
+		nextSpan = trace.SpanContextFromContext(ctx)
+		return errProcessing
+	})
+	suite.Require().ErrorIs(err, errProcessing)
+	suite.Require().Len(tracer.spans, 2)
+	consumer := tracer.spans[1]
+
+	suite.Require().True(consumer.ended)
+	suite.Require().Equal("orders receive", consumer.name)
+	suite.Require().Equal(trace.SpanKindConsumer, consumer.config.SpanKind())
+	suite.Require().Contains(consumer.config.Attributes(), semconv.MessagingOperationReceive)
+	suite.Require().Equal(codes.Error, consumer.status)
+
+	// The consumer span should be a child of the producer span and be given
+	// to the next middlewares
+	suite.Require().True(consumer.parent.IsRemote())
+	suite.Require().Equal(producer.sc.TraceID(), consumer.parent.TraceID())
+	suite.Require().Equal(producer.sc.SpanID(), consumer.parent.SpanID())
+	suite.Require().Equal(consumer.sc, nextSpan)
+}
+
+func (suite *TracingSuite) TestNoDirection() {
+	tracer := &fakeTracer{}
+	mw := Tracing(WithTracerProvider(fakeTracerProvider{tracer: tracer}))
+
+	errNext := errors.New("next error")
+	suite.Require().ErrorIs(mw(context.Background(), &extensions.BrokerMessage{}, func(context.Context) error {
+		return errNext
+	}), errNext)
+	suite.Require().Empty(tracer.spans)
+}
@@ -0,0 +1,61 @@
+package outbox
+
+import "fmt"
+
+// Dialect is the SQL dialect of the database containing the outbox table.
+type Dialect struct {
+	// CreateTableQuery is the query creating the outbox table if it doesn't
+	// exist, with a '%s' verb for the table name. The table should have an
+	// auto-incremented 'id' primary key, and 'channel', 'headers', 'payload',
+	// 'message_key' and 'created_at' columns.
+	CreateTableQuery string
+
+	// Placeholder returns the placeholder of the n-th argument of a query,
+	// starting from 1 (i.e. '?' or '$1').
+	Placeholder func(n int) string
+}
+
+var (
+	// SQLite is the dialect for SQLite databases.
+	SQLite = Dialect{
+		CreateTableQuery: `CREATE TABLE IF NOT EXISTS %s (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			channel TEXT NOT NULL,
+			headers TEXT NOT NULL,
+			payload BLOB,
+			message_key BLOB,
+			created_at TIMESTAMP NOT NULL
+		)`,
+		Placeholder: questionMarkPlaceholder,
+	}
+
+	// PostgreSQL is the dialect for PostgreSQL databases.
+	PostgreSQL = Dialect{
+		CreateTableQuery: `CREATE TABLE IF NOT EXISTS %s (
+			id BIGSERIAL PRIMARY KEY,
+			channel TEXT NOT NULL,
+			headers TEXT NOT NULL,
+			payload BYTEA,
+			message_key BYTEA,
+			created_at TIMESTAMPTZ NOT NULL
+		)`,
+		Placeholder: func(n int) string { return fmt.Sprintf("$%d", n) },
+	}
+
+	// MySQL is the dialect for MySQL and MariaDB databases.
+	MySQL = Dialect{
+		CreateTableQuery: `CREATE TABLE IF NOT EXISTS %s (
+			id BIGINT AUTO_INCREMENT PRIMARY KEY,
+			channel VARCHAR(255) NOT NULL,
+			headers TEXT NOT NULL,
+			payload LONGBLOB,
+			message_key BLOB,
+			created_at TIMESTAMP NOT NULL
+		)`,
+		Placeholder: questionMarkPlaceholder,
+	}
+)
+
+func questionMarkPlaceholder(_ int) string {
+	return "?"
+}
@@ -0,0 +1,191 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+)
+
+// Check that it still fills the interfaces.
+var (
+	_ extensions.BrokerController         = (*Controller)(nil)
+	_ extensions.BrokerWildcardSubscriber = (*Controller)(nil)
+	_ extensions.BrokerOptionsSubscriber  = (*Controller)(nil)
+
+	_ extensions.BrokerWildcardOptionsSubscriber = (*Controller)(nil)
+)
+
+// DefaultTableName is the name of the outbox table if none is specified.
+const DefaultTableName = "asyncapi_outbox"
+
+// ContextKeyTransaction is the database transaction in which the published
+// messages are written to the outbox table.
+const ContextKeyTransaction extensions.ContextKey = extensions.Prefix + "outbox-transaction"
+
+// ContextWithTransaction returns a context with the database transaction in
+// which the messages published with this context will be written, so they are
+// only sent to the broker if the transaction is committed.
+func ContextWithTransaction(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, ContextKeyTransaction, tx)
+}
+
+// Controller is a broker controller wrapper that writes the published messages
+// to an outbox table instead of publishing them to the broker. They are then
+// forwarded to the broker by a Relay.
+//
+// Messages are written in the database transaction from the context (see
+// ContextWithTransaction) if there is one, or directly in the database
+// otherwise. Subscriptions are made directly on the wrapped broker controller.
+type Controller struct {
+	broker  extensions.BrokerController
+	db      *sql.DB
+	table   string
+	dialect Dialect
+	logger  extensions.Logger
+}
+
+// ControllerOption is an option for the outbox controller.
+type ControllerOption func(controller *Controller)
+
+// NewController creates an outbox controller around a broker controller, with
+// the outbox table in the database.
+func NewController(
+	broker extensions.BrokerController,
+	db *sql.DB,
+	options ...ControllerOption,
+) *Controller {
+	// Create default controller
+	controller := &Controller{
+		broker:  broker,
+		db:      db,
+		table:   DefaultTableName,
+		dialect: SQLite,
+		logger:  extensions.DummyLogger{},
+	}
+
+	// Execute options
+	for _, option := range options {
+		option(controller)
+	}
+
+	return controller
+}
+
+// WithTableName sets the name of the outbox table.
+func WithTableName(name string) ControllerOption {
+	return func(controller *Controller) {
+		controller.table = name
+	}
+}
+
+// WithDialect sets the SQL dialect of the database (default is SQLite).
+func WithDialect(dialect Dialect) ControllerOption {
+	return func(controller *Controller) {
+		controller.dialect = dialect
+	}
+}
+
+// WithLogger sets a custom logger that will log operations on the outbox.
+func WithLogger(logger extensions.Logger) ControllerOption {
+	return func(controller *Controller) {
+		controller.logger = logger
+	}
+}
+
+// CreateTable creates the outbox table in the database if it doesn't exist.
+func (c *Controller) CreateTable(ctx context.Context) error {
+	_, err := c.db.ExecContext(ctx, fmt.Sprintf(c.dialect.CreateTableQuery, c.table))
+	return err
+}
+
+// Publish writes the message to the outbox table, in the transaction from the
+// context if there is one.
+func (c *Controller) Publish(ctx context.Context, channel string, bm extensions.BrokerMessage) error {
+	headers, err := json.Marshal(bm.Headers)
+	if err != nil {
+		return fmt.Errorf("could not encode headers: %w", err)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (channel, headers, payload, message_key, created_at) VALUES (%s, %s, %s, %s, %s)",
+		c.table, c.dialect.Placeholder(1), c.dialect.Placeholder(2),
+		c.dialect.Placeholder(3), c.dialect.Placeholder(4), c.dialect.Placeholder(5))
+	args := []any{channel, string(headers), bm.Payload, bm.Key, time.Now().UTC()}
+
+	// Write in the transaction if there is one
+	var tx *sql.Tx
+	extensions.IfContextSetWith(ctx, ContextKeyTransaction, func(t *sql.Tx) { tx = t })
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, args...)
+	} else {
+		_, err = c.db.ExecContext(ctx, query, args...)
+	}
+	if err != nil {
+		return fmt.Errorf("could not write message to outbox: %w", err)
+	}
+
+	c.logger.Info(ctx, "Message written to outbox",
+		extensions.LogInfo{Key: "channel", Value: channel},
+		extensions.LogInfo{Key: "transaction", Value: tx != nil})
+
+	return nil
+}
+
+// Subscribe subscribes to messages from the wrapped broker controller.
+func (c *Controller) Subscribe(ctx context.Context, channel string) (extensions.BrokerChannelSubscription, error) {
+	return c.broker.Subscribe(ctx, channel)
+}
+
+// SubscribeWildcard subscribes to messages from the wrapped broker controller,
+// on every channel matching the address with parameters, if it supports it.
+func (c *Controller) SubscribeWildcard(
+	ctx context.Context,
+	address string,
+) (extensions.BrokerChannelSubscription, error) {
+	wc, ok := c.broker.(extensions.BrokerWildcardSubscriber)
+	if !ok {
+		return extensions.BrokerChannelSubscription{}, extensions.ErrWildcardNotSupported
+	}
+
+	return wc.SubscribeWildcard(ctx, address)
+}
+
+// SubscribeWithOptions subscribes to messages from the wrapped broker controller,
+// with the options, if it supports them.
+func (c *Controller) SubscribeWithOptions(
+	ctx context.Context,
+	channel string,
+	opts extensions.SubscribeOptions,
+) (extensions.BrokerChannelSubscription, error) {
+	subscriber, ok := c.broker.(extensions.BrokerOptionsSubscriber)
+	if !ok {
+		return extensions.BrokerChannelSubscription{}, extensions.ErrSubscribeOptionNotSupported
+	}
+
+	return subscriber.SubscribeWithOptions(ctx, channel, opts)
+}
+
+// SubscribeWildcardWithOptions subscribes to messages from the wrapped broker
+// controller, on every channel matching the address with parameters, with the
+// options, if it supports them.
+func (c *Controller) SubscribeWildcardWithOptions(
+	ctx context.Context,
+	address string,
+	opts extensions.SubscribeOptions,
+) (extensions.BrokerChannelSubscription, error) {
+	subscriber, ok := c.broker.(extensions.BrokerWildcardOptionsSubscriber)
+	if !ok {
+		return extensions.BrokerChannelSubscription{}, extensions.ErrSubscribeOptionNotSupported
+	}
+
+	return subscriber.SubscribeWildcardWithOptions(ctx, address, opts)
+}
+
+// NewRelay creates a relay forwarding the messages from the outbox table of the
+// controller to its wrapped broker controller.
+func (c *Controller) NewRelay(options ...RelayOption) *Relay {
+	return newRelay(c, options...)
+}
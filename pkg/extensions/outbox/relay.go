@@ -0,0 +1,207 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+)
+
+const (
+	// DefaultRelayInterval is the default interval between two polls of the
+	// outbox table, when it has no more message to forward.
+	DefaultRelayInterval = time.Second
+	// DefaultRelayBatchSize is the default maximum number of messages read from
+	// the outbox table on each poll.
+	DefaultRelayBatchSize = 100
+)
+
+// Relay forwards the messages from the outbox table to the broker controller.
+//
+// Messages are removed from the table once published, so they are published at
+// least once: a message can be published again if the relay stops between its
+// publication and its removal. Messages of a channel are published in the order
+// of their ids: if a message can't be published, the following messages of its
+// channel wait for the next poll, while the messages of the other channels are
+// still published. Only one relay should run on a table.
+//
+// NOTE: ids are given when messages are written, not when their transaction is
+// committed. Messages of a channel written by concurrent transactions can then
+// be published in a different order than their commit order, or a message can
+// be published before another one with a lower id is committed. Write messages
+// that should be ordered from transactions that don't overlap (i.e. by locking
+// the row they are about).
+type Relay struct {
+	controller *Controller
+	interval   time.Duration
+	batchSize  int
+}
+
+// RelayOption is an option for the outbox relay.
+type RelayOption func(relay *Relay)
+
+func newRelay(controller *Controller, options ...RelayOption) *Relay {
+	// Create default relay
+	relay := &Relay{
+		controller: controller,
+		interval:   DefaultRelayInterval,
+		batchSize:  DefaultRelayBatchSize,
+	}
+
+	// Execute options
+	for _, option := range options {
+		option(relay)
+	}
+
+	return relay
+}
+
+// WithInterval sets the interval between two polls of the outbox table, when
+// it has no more message to forward.
+func WithInterval(interval time.Duration) RelayOption {
+	return func(relay *Relay) {
+		relay.interval = interval
+	}
+}
+
+// WithBatchSize sets the maximum number of messages read from the outbox table
+// on each poll.
+func WithBatchSize(size int) RelayOption {
+	return func(relay *Relay) {
+		relay.batchSize = size
+	}
+}
+
+// Run forwards the messages from the outbox table to the broker until the
+// context is done. Errors are logged and the messages are retried on the next
+// poll.
+func (r *Relay) Run(ctx context.Context) {
+	for {
+		count, err := r.Process(ctx)
+		if err != nil {
+			r.controller.logger.Error(ctx, err.Error())
+		}
+
+		// Poll again immediately if there may be remaining messages, even if
+		// some channels are blocked
+		if count >= r.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.interval):
+		}
+	}
+}
+
+type outboxMessage struct {
+	id      int64
+	channel string
+	headers string
+	payload []byte
+	key     []byte
+}
+
+// Process forwards one batch of messages from the outbox table to the broker,
+// and returns the number of forwarded messages.
+//
+// When a channel is blocked by a message that can't be published, another batch
+// is read without the blocked channels, so their following messages can't fill
+// the batches and prevent the other channels from being forwarded.
+func (r *Relay) Process(ctx context.Context) (int, error) {
+	var count int
+	var errs []error
+	var blocked []string
+	for {
+		msgs, err := r.read(ctx, blocked)
+		if err != nil {
+			return count, errors.Join(append(errs, err)...)
+		}
+
+		newlyBlocked := false
+		for _, msg := range msgs {
+			// Keep the order of the channel messages
+			if slices.Contains(blocked, msg.channel) {
+				continue
+			}
+
+			if err := r.forward(ctx, msg); err != nil {
+				blocked = append(blocked, msg.channel)
+				newlyBlocked = true
+				errs = append(errs, err)
+				continue
+			}
+			count++
+		}
+
+		// Read the following messages of the other channels, if the batch was
+		// full when channels have been blocked
+		if !newlyBlocked || len(msgs) < r.batchSize {
+			return count, errors.Join(errs...)
+		}
+	}
+}
+
+// read reads a batch of messages from the outbox table, without the messages of
+// the excluded channels.
+func (r *Relay) read(ctx context.Context, excluded []string) ([]outboxMessage, error) {
+	query := fmt.Sprintf("SELECT id, channel, headers, payload, message_key FROM %s", r.controller.table)
+	args := make([]any, 0, len(excluded))
+	if len(excluded) > 0 {
+		placeholders := make([]string, 0, len(excluded))
+		for i, channel := range excluded {
+			placeholders = append(placeholders, r.controller.dialect.Placeholder(i+1))
+			args = append(args, channel)
+		}
+		query += fmt.Sprintf(" WHERE channel NOT IN (%s)", strings.Join(placeholders, ", "))
+	}
+	query += fmt.Sprintf(" ORDER BY id LIMIT %d", r.batchSize)
+
+	rows, err := r.controller.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not read messages from outbox: %w", err)
+	}
+	defer rows.Close()
+
+	msgs := make([]outboxMessage, 0, r.batchSize)
+	for rows.Next() {
+		var msg outboxMessage
+		if err := rows.Scan(&msg.id, &msg.channel, &msg.headers, &msg.payload, &msg.key); err != nil {
+			return nil, fmt.Errorf("could not read message from outbox: %w", err)
+		}
+		msgs = append(msgs, msg)
+	}
+
+	return msgs, rows.Err()
+}
+
+func (r *Relay) forward(ctx context.Context, msg outboxMessage) error {
+	var bm extensions.BrokerMessage
+	if err := json.Unmarshal([]byte(msg.headers), &bm.Headers); err != nil {
+		return fmt.Errorf("could not decode headers of outbox message %d: %w", msg.id, err)
+	}
+	bm.Payload = msg.payload
+	bm.Key = msg.key
+
+	if err := r.controller.broker.Publish(ctx, msg.channel, bm); err != nil {
+		return fmt.Errorf("could not publish outbox message %d on %q: %w", msg.id, msg.channel, err)
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = %s", r.controller.table, r.controller.dialect.Placeholder(1))
+	if _, err := r.controller.db.ExecContext(ctx, query, msg.id); err != nil {
+		return fmt.Errorf("could not remove outbox message %d: %w", msg.id, err)
+	}
+
+	r.controller.logger.Info(ctx, "Message forwarded from outbox",
+		extensions.LogInfo{Key: "channel", Value: msg.channel},
+		extensions.LogInfo{Key: "id", Value: msg.id})
+
+	return nil
+}
@@ -0,0 +1,220 @@
+package extensions
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// Security schemes types from the AsyncAPI specification.
+const (
+	SecuritySchemeUserPassword         = "userPassword"
+	SecuritySchemeAPIKey               = "apiKey"
+	SecuritySchemeX509                 = "X509"
+	SecuritySchemeSymmetricEncryption  = "symmetricEncryption"
+	SecuritySchemeAsymmetricEncryption = "asymmetricEncryption"
+	SecuritySchemeHTTPAPIKey           = "httpApiKey"
+	SecuritySchemeHTTP                 = "http"
+	SecuritySchemeOAuth2               = "oauth2"
+	SecuritySchemeOpenIDConnect        = "openIdConnect"
+	SecuritySchemePlain                = "plain"
+	SecuritySchemeScramSha256          = "scramSha256"
+	SecuritySchemeScramSha512          = "scramSha512"
+	SecuritySchemeGSSAPI               = "gssapi"
+)
+
+var (
+	// ErrUnsupportedSecurityScheme is raised when none of the security schemes
+	// of a server is supported by the broker controller.
+	ErrUnsupportedSecurityScheme = fmt.Errorf("%w: unsupported security scheme", ErrAsyncAPI)
+
+	// ErrMissingCredentials is raised when the credentials required by a
+	// security scheme are not provided.
+	ErrMissingCredentials = fmt.Errorf("%w: missing credentials", ErrAsyncAPI)
+)
+
+// SecurityScheme is a security scheme of a server, from the AsyncAPI
+// specification.
+type SecurityScheme struct {
+	// Name is the name of the security scheme in the specification components
+	// (i.e. 'saslScram'), or its type if it is not a reference.
+	Name string
+	// Type is the type of the security scheme (i.e. 'scramSha256').
+	Type string
+	// Description of the security scheme.
+	Description string
+}
+
+// Credentials are the credentials used to authenticate to a server with a
+// security scheme. Only the fields required by the security scheme are used.
+type Credentials struct {
+	// Username and Password are used by 'userPassword', 'plain', 'scramSha256'
+	// and 'scramSha512' security schemes.
+	Username string
+	Password string
+
+	// Token is used by 'apiKey' and 'httpApiKey' security schemes.
+	Token string
+
+	// CertFile and KeyFile are the paths to the client certificate and key
+	// (PEM encoded), used by the 'X509' security scheme. CAFile is the path to
+	// the certificate authorities used to verify the server, if not the system ones.
+	CertFile string
+	KeyFile  string
+	CAFile   string
+
+	// CredsFile is the path to a NATS credentials file (JWT and NKey seed), and
+	// NKeySeedFile the path to a NATS NKey seed file, used by the
+	// 'asymmetricEncryption' security scheme.
+	CredsFile    string
+	NKeySeedFile string
+}
+
+// TLSConfig returns the TLS configuration with the client certificate and the
+// certificate authorities of the credentials.
+func (c Credentials) TLSConfig() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read certificate authorities: %w", err)
+		}
+
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate authority found in %q", c.CAFile)
+		}
+	}
+
+	return config, nil
+}
+
+// CredentialsProvider provides the credentials for a security scheme of a server.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context, server string, scheme SecurityScheme) (Credentials, error)
+}
+
+// DefaultCredentialsProvider is the credentials provider used when none is
+// specified: environment variables prefixed with 'ASYNCAPI_'.
+var DefaultCredentialsProvider CredentialsProvider = EnvCredentialsProvider{Prefix: "ASYNCAPI_"}
+
+// CredentialsProviderFunc is a function that can be used as CredentialsProvider.
+type CredentialsProviderFunc func(ctx context.Context, server string, scheme SecurityScheme) (Credentials, error)
+
+// Credentials calls the function to get the credentials.
+func (fn CredentialsProviderFunc) Credentials(
+	ctx context.Context,
+	server string,
+	scheme SecurityScheme,
+) (Credentials, error) {
+	return fn(ctx, server, scheme)
+}
+
+// EnvCredentialsProvider provides credentials from environment variables, named
+// from the prefix, the server name and the security scheme name in screaming
+// snake case, followed by the credential name. For example, with 'ASYNCAPI_'
+// prefix, 'production' server and 'saslScram' security scheme:
+//
+//   - ASYNCAPI_PRODUCTION_SASL_SCRAM_USERNAME
+//   - ASYNCAPI_PRODUCTION_SASL_SCRAM_PASSWORD
+//   - ASYNCAPI_PRODUCTION_SASL_SCRAM_TOKEN
+//   - ASYNCAPI_PRODUCTION_SASL_SCRAM_CERT_FILE
+//   - ASYNCAPI_PRODUCTION_SASL_SCRAM_KEY_FILE
+//   - ASYNCAPI_PRODUCTION_SASL_SCRAM_CA_FILE
+//   - ASYNCAPI_PRODUCTION_SASL_SCRAM_CREDS_FILE
+//   - ASYNCAPI_PRODUCTION_SASL_SCRAM_NKEY_SEED_FILE
+type EnvCredentialsProvider struct {
+	Prefix string
+}
+
+// Credentials returns the credentials from the environment variables.
+func (p EnvCredentialsProvider) Credentials(
+	_ context.Context,
+	server string,
+	scheme SecurityScheme,
+) (Credentials, error) {
+	prefix := p.Prefix + strcase.ToScreamingSnake(server) + "_" + strcase.ToScreamingSnake(scheme.Name) + "_"
+
+	return Credentials{
+		Username:     os.Getenv(prefix + "USERNAME"),
+		Password:     os.Getenv(prefix + "PASSWORD"),
+		Token:        os.Getenv(prefix + "TOKEN"),
+		CertFile:     os.Getenv(prefix + "CERT_FILE"),
+		KeyFile:      os.Getenv(prefix + "KEY_FILE"),
+		CAFile:       os.Getenv(prefix + "CA_FILE"),
+		CredsFile:    os.Getenv(prefix + "CREDS_FILE"),
+		NKeySeedFile: os.Getenv(prefix + "NKEY_SEED_FILE"),
+	}, nil
+}
+
+// FileCredentialsProvider provides credentials from files in a directory per
+// server and security scheme (i.e. '<Dir>/production/saslScram/'), like the
+// secrets mounted in a container:
+//
+//   - 'username', 'password' and 'token' contain the corresponding credential;
+//   - 'tls.crt', 'tls.key' and 'ca.crt' are the client certificate, key and
+//     certificate authorities;
+//   - 'user.creds' and 'nkey.seed' are the NATS credentials and NKey seed files.
+//
+// Missing files are ignored.
+type FileCredentialsProvider struct {
+	Dir string
+}
+
+// Credentials returns the credentials from the files.
+func (p FileCredentialsProvider) Credentials(
+	_ context.Context,
+	server string,
+	scheme SecurityScheme,
+) (Credentials, error) {
+	dir := filepath.Join(p.Dir, server, scheme.Name)
+
+	var creds Credentials
+	for name, value := range map[string]*string{
+		"username": &creds.Username,
+		"password": &creds.Password,
+		"token":    &creds.Token,
+	} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return Credentials{}, err
+		}
+		*value = strings.TrimSpace(string(content))
+	}
+
+	for name, path := range map[string]*string{
+		"tls.crt":    &creds.CertFile,
+		"tls.key":    &creds.KeyFile,
+		"ca.crt":     &creds.CAFile,
+		"user.creds": &creds.CredsFile,
+		"nkey.seed":  &creds.NKeySeedFile,
+	} {
+		file := filepath.Join(dir, name)
+		if _, err := os.Stat(file); errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return Credentials{}, err
+		}
+		*path = file
+	}
+
+	return creds, nil
+}
@@ -0,0 +1,103 @@
+package extensions
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSecuritySuite(t *testing.T) {
+	suite.Run(t, new(SecuritySuite))
+}
+
+type SecuritySuite struct {
+	suite.Suite
+}
+
+func (suite *SecuritySuite) TestEnvCredentialsProvider() {
+	suite.T().Setenv("ASYNCAPI_PRODUCTION_SASL_SCRAM_USERNAME", "user")
+	suite.T().Setenv("ASYNCAPI_PRODUCTION_SASL_SCRAM_PASSWORD", "secret")
+
+	creds, err := EnvCredentialsProvider{Prefix: "ASYNCAPI_"}.Credentials(context.Background(),
+		"production", SecurityScheme{Name: "saslScram", Type: SecuritySchemeScramSha512})
+	suite.Require().NoError(err)
+	suite.Require().Equal(Credentials{Username: "user", Password: "secret"}, creds)
+}
+
+func (suite *SecuritySuite) TestFileCredentialsProvider() {
+	dir := suite.T().TempDir()
+	schemeDir := filepath.Join(dir, "production", "certs")
+	suite.Require().NoError(os.MkdirAll(schemeDir, 0o755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(schemeDir, "username"), []byte("user\n"), 0o600))
+	suite.Require().NoError(os.WriteFile(filepath.Join(schemeDir, "tls.crt"), nil, 0o600))
+
+	creds, err := FileCredentialsProvider{Dir: dir}.Credentials(context.Background(),
+		"production", SecurityScheme{Name: "certs", Type: SecuritySchemeX509})
+	suite.Require().NoError(err)
+	suite.Require().Equal(Credentials{
+		Username: "user",
+		CertFile: filepath.Join(schemeDir, "tls.crt"),
+	}, creds)
+
+	// Missing directory should give empty credentials
+	creds, err = FileCredentialsProvider{Dir: dir}.Credentials(context.Background(),
+		"staging", SecurityScheme{Name: "certs", Type: SecuritySchemeX509})
+	suite.Require().NoError(err)
+	suite.Require().Equal(Credentials{}, creds)
+}
+
+func (suite *SecuritySuite) TestSecurityCredentials() {
+	srv := Server{
+		Name: "production",
+		Security: []SecurityScheme{
+			{Name: "oauth", Type: SecuritySchemeOAuth2},
+			{Name: "saslScram", Type: SecuritySchemeScramSha512},
+		},
+	}
+	provider := CredentialsProviderFunc(func(_ context.Context, server string, scheme SecurityScheme) (Credentials, error) {
+		return Credentials{Username: server, Password: scheme.Name}, nil
+	})
+
+	// The first supported scheme should be used
+	scheme, creds, err := srv.SecurityCredentials(context.Background(), provider,
+		SecuritySchemePlain, SecuritySchemeScramSha512)
+	suite.Require().NoError(err)
+	suite.Require().Equal(srv.Security[1], *scheme)
+	suite.Require().Equal(Credentials{Username: "production", Password: "saslScram"}, creds)
+
+	// No scheme is supported
+	_, _, err = srv.SecurityCredentials(context.Background(), provider, SecuritySchemeX509)
+	suite.Require().ErrorIs(err, ErrUnsupportedSecurityScheme)
+
+	// Provider error should be returned
+	errProvider := errors.New("provider error")
+	_, _, err = srv.SecurityCredentials(context.Background(),
+		CredentialsProviderFunc(func(context.Context, string, SecurityScheme) (Credentials, error) {
+			return Credentials{}, errProvider
+		}), SecuritySchemeScramSha512)
+	suite.Require().ErrorIs(err, errProvider)
+
+	// No security scheme
+	scheme, _, err = Server{Name: "staging"}.SecurityCredentials(context.Background(), provider, SecuritySchemeX509)
+	suite.Require().NoError(err)
+	suite.Require().Nil(scheme)
+}
+
+func (suite *SecuritySuite) TestTLSConfig() {
+	config, err := Credentials{}.TLSConfig()
+	suite.Require().NoError(err)
+	suite.Require().Empty(config.Certificates)
+	suite.Require().Nil(config.RootCAs)
+
+	_, err = Credentials{CertFile: "missing.crt", KeyFile: "missing.key"}.TLSConfig()
+	suite.Require().Error(err)
+
+	ca := filepath.Join(suite.T().TempDir(), "ca.crt")
+	suite.Require().NoError(os.WriteFile(ca, []byte("not a certificate"), 0o600))
+	_, err = Credentials{CAFile: ca}.TLSConfig()
+	suite.Require().Error(err)
+}
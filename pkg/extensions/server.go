@@ -0,0 +1,139 @@
+package extensions
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrUnknownServer is raised when a server is not in the specification.
+	ErrUnknownServer = fmt.Errorf("%w: unknown server", ErrAsyncAPI)
+
+	// ErrInvalidServerVariable is raised when a server variable value is
+	// missing, unknown or not in the variable enum.
+	ErrInvalidServerVariable = fmt.Errorf("%w: invalid server variable", ErrAsyncAPI)
+
+	// ErrUnsupportedProtocol is raised when there is no broker controller for
+	// the protocol of a server.
+	ErrUnsupportedProtocol = fmt.Errorf("%w: unsupported protocol", ErrAsyncAPI)
+)
+
+// ServerVariable is a variable of a server host and pathname, from the
+// AsyncAPI specification.
+type ServerVariable struct {
+	// Default is the value used when no value is provided. If empty, a value
+	// is required.
+	Default string
+	// Enum is the list of allowed values. If empty, any value is allowed.
+	Enum []string
+	// Description of the variable.
+	Description string
+}
+
+// Server is a server from the AsyncAPI specification, that can be used to
+// create a broker controller.
+type Server struct {
+	// Name is the name of the server in the specification (i.e. 'production').
+	Name string
+	// Host is the server host, with an optional port (i.e. 'rabbitmq:5672').
+	// It can contain variables (i.e. '{env}.example.com').
+	Host string
+	// Protocol is the protocol supported by the server (i.e. 'kafka', 'nats').
+	Protocol string
+	// ProtocolVersion is the version of the protocol (i.e. '0.9.1', '5').
+	ProtocolVersion string
+	// PathName is the path to a resource in the host (i.e. '/production').
+	// It can contain variables.
+	PathName string
+	// Description of the server.
+	Description string
+	// Variables are the variables of the host and pathname, by name.
+	Variables map[string]ServerVariable
+	// Security are the security schemes that can be used to authenticate to
+	// the server (only one of them is required).
+	Security []SecurityScheme
+}
+
+// WithVariables returns the server with its variables replaced by their values
+// in its host and pathname. The default value of a variable is used if there
+// is no value for it.
+//
+// An error is returned if a variable has no value nor default, if a value is
+// not in the variable enum, or if a value is given for an unknown variable.
+func (s Server) WithVariables(values map[string]string) (Server, error) {
+	// Check that every value is for a variable of the server
+	for name := range values {
+		if _, exists := s.Variables[name]; !exists {
+			return Server{}, fmt.Errorf("%w: server %q has no variable %q",
+				ErrInvalidServerVariable, s.Name, name)
+		}
+	}
+
+	// Replace each variable by its value
+	replacements := make([]string, 0, len(s.Variables)*2)
+	for name, v := range s.Variables {
+		value := values[name]
+		if value == "" {
+			value = v.Default
+		}
+
+		if value == "" {
+			return Server{}, fmt.Errorf("%w: no value for variable %q of server %q",
+				ErrInvalidServerVariable, name, s.Name)
+		}
+		if len(v.Enum) > 0 && !slices.Contains(v.Enum, value) {
+			return Server{}, fmt.Errorf("%w: value %q for variable %q of server %q should be one of %q",
+				ErrInvalidServerVariable, value, name, s.Name, v.Enum)
+		}
+
+		replacements = append(replacements, "{"+name+"}", value)
+	}
+	r := strings.NewReplacer(replacements...)
+
+	s.Host = r.Replace(s.Host)
+	s.PathName = r.Replace(s.PathName)
+	s.Variables = nil
+
+	return s, nil
+}
+
+// SecurityCredentials returns the first security scheme of the server with one
+// of the supported types, and its credentials from the provider. A nil scheme
+// is returned if the server has no security scheme.
+//
+// An error wrapping ErrUnsupportedSecurityScheme is returned if no security
+// scheme of the server is supported.
+func (s Server) SecurityCredentials(
+	ctx context.Context,
+	provider CredentialsProvider,
+	supported ...string,
+) (*SecurityScheme, Credentials, error) {
+	if len(s.Security) == 0 {
+		return nil, Credentials{}, nil
+	}
+
+	for _, scheme := range s.Security {
+		if !slices.Contains(supported, scheme.Type) {
+			continue
+		}
+
+		creds, err := provider.Credentials(ctx, s.Name, scheme)
+		if err != nil {
+			return nil, Credentials{}, fmt.Errorf("could not get credentials for %q security scheme of server %q: %w",
+				scheme.Name, s.Name, err)
+		}
+
+		return &scheme, creds, nil
+	}
+
+	return nil, Credentials{}, fmt.Errorf("%w: server %q security schemes should be one of %q",
+		ErrUnsupportedSecurityScheme, s.Name, supported)
+}
+
+// URL returns the URL of the server from its protocol, host and pathname
+// (i.e. 'amqp://rabbitmq:5672/production').
+func (s Server) URL() string {
+	return s.Protocol + "://" + s.Host + s.PathName
+}
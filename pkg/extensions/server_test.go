@@ -0,0 +1,46 @@
+package extensions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestServerSuite(t *testing.T) {
+	suite.Run(t, new(ServerSuite))
+}
+
+type ServerSuite struct {
+	suite.Suite
+}
+
+func (suite *ServerSuite) TestWithVariables() {
+	srv := Server{
+		Name:     "production",
+		Host:     "{env}.example.com:{port}",
+		Protocol: "amqp",
+		PathName: "/{env}",
+		Variables: map[string]ServerVariable{
+			"env":  {Enum: []string{"prod", "staging"}},
+			"port": {Default: "5672"},
+		},
+	}
+
+	res, err := srv.WithVariables(map[string]string{"env": "prod"})
+	suite.Require().NoError(err)
+	suite.Require().Equal("prod.example.com:5672", res.Host)
+	suite.Require().Equal("amqp://prod.example.com:5672/prod", res.URL())
+	suite.Require().Empty(res.Variables)
+
+	// Original server should not be modified
+	suite.Require().Equal("{env}.example.com:{port}", srv.Host)
+
+	for _, values := range []map[string]string{
+		nil,                                   // Missing value without default
+		{"env": "dev"},                        // Value not in enum
+		{"env": "prod", "unknown": "unknown"}, // Unknown variable
+	} {
+		_, err := srv.WithVariables(values)
+		suite.Require().ErrorIs(err, ErrInvalidServerVariable, values)
+	}
+}
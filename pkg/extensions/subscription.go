@@ -0,0 +1,225 @@
+package extensions
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ErrSubscribeOptionNotSupported is raised when subscribing with an option that
+// is not supported by the broker controller.
+var ErrSubscribeOptionNotSupported = fmt.Errorf("%w: subscribe option not supported", ErrAsyncAPI)
+
+// StartPosition is the position in the channel from which a subscription
+// starts to receive messages, for the brokers keeping them.
+type StartPosition int
+
+const (
+	// StartPositionDefault is the broker controller default start position
+	// (usually where the consumer group or durable consumer stopped).
+	StartPositionDefault StartPosition = iota
+	// StartPositionEarliest starts from the first message kept by the broker.
+	StartPositionEarliest
+	// StartPositionLatest starts from the messages published after the subscription.
+	StartPositionLatest
+	// StartPositionTime starts from the first message published at or after
+	// SubscribeOptions.StartTime.
+	StartPositionTime
+	// StartPositionOffset starts from SubscribeOptions.StartOffset (the offset
+	// for Kafka, the stream sequence for NATS JetStream).
+	StartPositionOffset
+)
+
+// String returns the name of the start position.
+func (p StartPosition) String() string {
+	switch p {
+	case StartPositionDefault:
+		return "default"
+	case StartPositionEarliest:
+		return "earliest"
+	case StartPositionLatest:
+		return "latest"
+	case StartPositionTime:
+		return "time"
+	case StartPositionOffset:
+		return "offset"
+	default:
+		return fmt.Sprintf("StartPosition(%d)", int(p))
+	}
+}
+
+// SubscribeOptions are the options of a subscription, that override the broker
+// controller ones. The zero value keeps the broker controller behavior.
+type SubscribeOptions struct {
+	// StartPosition is the position from which the messages are received.
+	StartPosition StartPosition
+	// StartTime is used with StartPositionTime.
+	StartTime time.Time
+	// StartOffset is used with StartPositionOffset.
+	StartOffset int64
+
+	// DurableName is the name under which the broker keeps the position of the
+	// subscription, so it can be resumed (i.e. JetStream durable consumer).
+	DurableName string
+	// QueueGroup is the group sharing the messages between its subscriptions
+	// (i.e. NATS queue group, Kafka consumer group ID).
+	QueueGroup string
+
+	// BufferSize is the number of received messages buffered before being
+	// processed. If zero, the broker controller one is used.
+	BufferSize int
+}
+
+// IsZero returns true if no option is set.
+func (o SubscribeOptions) IsZero() bool {
+	return o == SubscribeOptions{}
+}
+
+// SubscribeOption is an option to subscribe to a channel.
+type SubscribeOption func(opts *SubscribeOptions)
+
+// NewSubscribeOptions returns the subscription options with the options applied.
+func NewSubscribeOptions(options ...SubscribeOption) SubscribeOptions {
+	var opts SubscribeOptions
+	for _, option := range options {
+		option(&opts)
+	}
+	return opts
+}
+
+// WithStartEarliest starts the subscription from the first message kept by the broker.
+func WithStartEarliest() SubscribeOption {
+	return func(opts *SubscribeOptions) {
+		opts.StartPosition = StartPositionEarliest
+	}
+}
+
+// WithStartLatest starts the subscription from the messages published after it.
+func WithStartLatest() SubscribeOption {
+	return func(opts *SubscribeOptions) {
+		opts.StartPosition = StartPositionLatest
+	}
+}
+
+// WithStartTime starts the subscription from the first message published at
+// or after the time.
+func WithStartTime(t time.Time) SubscribeOption {
+	return func(opts *SubscribeOptions) {
+		opts.StartPosition = StartPositionTime
+		opts.StartTime = t
+	}
+}
+
+// WithStartOffset starts the subscription from the offset (Kafka) or the
+// stream sequence (NATS JetStream).
+func WithStartOffset(offset int64) SubscribeOption {
+	return func(opts *SubscribeOptions) {
+		opts.StartPosition = StartPositionOffset
+		opts.StartOffset = offset
+	}
+}
+
+// WithDurableName sets the name under which the broker keeps the position of
+// the subscription.
+func WithDurableName(name string) SubscribeOption {
+	return func(opts *SubscribeOptions) {
+		opts.DurableName = name
+	}
+}
+
+// WithQueueGroup overrides the queue group (or consumer group) of the broker
+// controller for the subscription.
+func WithQueueGroup(group string) SubscribeOption {
+	return func(opts *SubscribeOptions) {
+		opts.QueueGroup = group
+	}
+}
+
+// WithBufferSize sets the number of received messages buffered before being
+// processed.
+func WithBufferSize(size int) SubscribeOption {
+	return func(opts *SubscribeOptions) {
+		opts.BufferSize = size
+	}
+}
+
+// BrokerOptionsSubscriber represents the function that can be implemented by
+// a broker controller to subscribe with options.
+type BrokerOptionsSubscriber interface {
+	// SubscribeWithOptions subscribes to messages from the broker, like
+	// BrokerController.Subscribe, with the options overriding the broker
+	// controller ones. An error wrapping ErrSubscribeOptionNotSupported should
+	// be returned for the options that are not supported.
+	SubscribeWithOptions(ctx context.Context, channel string, opts SubscribeOptions) (BrokerChannelSubscription, error)
+}
+
+// Subscribe subscribes to the channel with the broker controller and the
+// options. Without options, BrokerController.Subscribe is used.
+//
+// An error wrapping ErrSubscribeOptionNotSupported is returned if there are
+// options and the broker controller doesn't implement BrokerOptionsSubscriber.
+func Subscribe(
+	ctx context.Context,
+	bc BrokerController,
+	channel string,
+	options ...SubscribeOption,
+) (BrokerChannelSubscription, error) {
+	opts := NewSubscribeOptions(options...)
+	if opts.IsZero() {
+		return bc.Subscribe(ctx, channel)
+	}
+
+	subscriber, ok := bc.(BrokerOptionsSubscriber)
+	if !ok {
+		return BrokerChannelSubscription{}, fmt.Errorf("%w: broker controller %T", ErrSubscribeOptionNotSupported, bc)
+	}
+
+	return subscriber.SubscribeWithOptions(ctx, channel, opts)
+}
+
+// BrokerWildcardOptionsSubscriber represents the function that can be
+// implemented by a broker controller to subscribe to every value of channel
+// parameters with options.
+type BrokerWildcardOptionsSubscriber interface {
+	// SubscribeWildcardWithOptions subscribes to messages from the broker, like
+	// BrokerWildcardSubscriber.SubscribeWildcard, with the options overriding
+	// the broker controller ones. An error wrapping ErrSubscribeOptionNotSupported
+	// should be returned for the options that are not supported.
+	SubscribeWildcardWithOptions(
+		ctx context.Context,
+		address string,
+		opts SubscribeOptions,
+	) (BrokerChannelSubscription, error)
+}
+
+// SubscribeWildcard subscribes to every channel matching the address with
+// parameters, with the broker controller and the options. Without options,
+// BrokerWildcardSubscriber.SubscribeWildcard is used.
+//
+// An error wrapping ErrWildcardNotSupported is returned if the broker
+// controller doesn't implement BrokerWildcardSubscriber, and an error wrapping
+// ErrSubscribeOptionNotSupported if there are options and it doesn't implement
+// BrokerWildcardOptionsSubscriber.
+func SubscribeWildcard(
+	ctx context.Context,
+	bc BrokerController,
+	address string,
+	options ...SubscribeOption,
+) (BrokerChannelSubscription, error) {
+	wc, ok := bc.(BrokerWildcardSubscriber)
+	if !ok {
+		return BrokerChannelSubscription{}, fmt.Errorf("%w: broker controller %T", ErrWildcardNotSupported, bc)
+	}
+
+	opts := NewSubscribeOptions(options...)
+	if opts.IsZero() {
+		return wc.SubscribeWildcard(ctx, address)
+	}
+
+	subscriber, ok := bc.(BrokerWildcardOptionsSubscriber)
+	if !ok {
+		return BrokerChannelSubscription{}, fmt.Errorf("%w: broker controller %T", ErrSubscribeOptionNotSupported, bc)
+	}
+
+	return subscriber.SubscribeWildcardWithOptions(ctx, address, opts)
+}
@@ -0,0 +1,138 @@
+package extensions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSubscriptionSuite(t *testing.T) {
+	suite.Run(t, new(SubscriptionSuite))
+}
+
+type SubscriptionSuite struct {
+	suite.Suite
+}
+
+// subscriptionBroker is a broker controller recording the subscriptions.
+type subscriptionBroker struct {
+	subscribed string
+}
+
+func (b *subscriptionBroker) Publish(_ context.Context, _ string, _ BrokerMessage) error {
+	return nil
+}
+
+func (b *subscriptionBroker) Subscribe(_ context.Context, channel string) (BrokerChannelSubscription, error) {
+	b.subscribed = channel
+	return BrokerChannelSubscription{}, nil
+}
+
+// optionsBroker is a broker controller recording the subscriptions with options.
+type optionsBroker struct {
+	subscriptionBroker
+	opts *SubscribeOptions
+}
+
+func (b *optionsBroker) SubscribeWithOptions(
+	_ context.Context,
+	channel string,
+	opts SubscribeOptions,
+) (BrokerChannelSubscription, error) {
+	b.subscribed = channel
+	b.opts = &opts
+	return BrokerChannelSubscription{}, nil
+}
+
+// wildcardBroker is a broker controller recording the wildcard subscriptions.
+type wildcardBroker struct {
+	optionsBroker
+}
+
+func (b *wildcardBroker) SubscribeWildcard(_ context.Context, address string) (BrokerChannelSubscription, error) {
+	b.subscribed = address
+	return BrokerChannelSubscription{}, nil
+}
+
+// wildcardOptionsBroker is a broker controller recording the wildcard
+// subscriptions with options.
+type wildcardOptionsBroker struct {
+	wildcardBroker
+}
+
+func (b *wildcardOptionsBroker) SubscribeWildcardWithOptions(
+	_ context.Context,
+	address string,
+	opts SubscribeOptions,
+) (BrokerChannelSubscription, error) {
+	b.subscribed = address
+	b.opts = &opts
+	return BrokerChannelSubscription{}, nil
+}
+
+func (suite *SubscriptionSuite) TestNewSubscribeOptions() {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	suite.Require().True(NewSubscribeOptions().IsZero())
+	suite.Require().Equal(SubscribeOptions{
+		StartPosition: StartPositionTime,
+		StartTime:     start,
+		DurableName:   "durable",
+		QueueGroup:    "group",
+		BufferSize:    10,
+	}, NewSubscribeOptions(
+		WithStartTime(start),
+		WithDurableName("durable"),
+		WithQueueGroup("group"),
+		WithBufferSize(10)))
+
+	// Last start position wins
+	opts := NewSubscribeOptions(WithStartEarliest(), WithStartOffset(42))
+	suite.Require().Equal(StartPositionOffset, opts.StartPosition)
+	suite.Require().Equal(int64(42), opts.StartOffset)
+	suite.Require().Equal(StartPositionLatest, NewSubscribeOptions(WithStartLatest()).StartPosition)
+	suite.Require().Equal("earliest", StartPositionEarliest.String())
+}
+
+func (suite *SubscriptionSuite) TestSubscribe() {
+	// Without options, the broker controller Subscribe is used
+	bc := &subscriptionBroker{}
+	_, err := Subscribe(context.Background(), bc, "channel")
+	suite.Require().NoError(err)
+	suite.Require().Equal("channel", bc.subscribed)
+
+	// Options need a broker controller supporting them
+	_, err = Subscribe(context.Background(), bc, "channel", WithQueueGroup("group"))
+	suite.Require().ErrorIs(err, ErrSubscribeOptionNotSupported)
+
+	// Options are given to the broker controller
+	obc := &optionsBroker{}
+	_, err = Subscribe(context.Background(), obc, "channel", WithQueueGroup("group"))
+	suite.Require().NoError(err)
+	suite.Require().Equal("channel", obc.subscribed)
+	suite.Require().Equal(&SubscribeOptions{QueueGroup: "group"}, obc.opts)
+}
+
+func (suite *SubscriptionSuite) TestSubscribeWildcard() {
+	// The broker controller should support wildcards
+	_, err := SubscribeWildcard(context.Background(), &optionsBroker{}, "user.{id}")
+	suite.Require().ErrorIs(err, ErrWildcardNotSupported)
+
+	// Without options, SubscribeWildcard is used
+	wb := &wildcardBroker{}
+	_, err = SubscribeWildcard(context.Background(), wb, "user.{id}")
+	suite.Require().NoError(err)
+	suite.Require().Equal("user.{id}", wb.subscribed)
+
+	// Options need a broker controller supporting them with wildcards
+	_, err = SubscribeWildcard(context.Background(), wb, "user.{id}", WithBufferSize(1))
+	suite.Require().ErrorIs(err, ErrSubscribeOptionNotSupported)
+
+	wob := &wildcardOptionsBroker{}
+	_, err = SubscribeWildcard(context.Background(), wob, "user.{id}", WithBufferSize(1))
+	suite.Require().NoError(err)
+	suite.Require().Equal("user.{id}", wob.subscribed)
+	suite.Require().Equal(&SubscribeOptions{BufferSize: 1}, wob.opts)
+}
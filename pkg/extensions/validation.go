@@ -0,0 +1,189 @@
+package extensions
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Number is the constraint for the numeric types that can be validated.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// ValidationError is the error returned when a value doesn't respect the
+// constraints set in the AsyncAPI specification.
+type ValidationError struct {
+	// Field is the path of the invalid field (i.e. 'payload.items[2].name'),
+	// empty if the whole value is invalid.
+	Field string
+	// Reason is the description of the unrespected constraint.
+	Reason string
+}
+
+// NewValidationError creates a new validation error on the field.
+func NewValidationError(field, format string, args ...any) *ValidationError {
+	return &ValidationError{
+		Field:  field,
+		Reason: fmt.Sprintf(format, args...),
+	}
+}
+
+// Error returns the error as a string.
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("%s: %s", ErrValidation.Error(), e.Reason)
+	}
+	return fmt.Sprintf("%s: field %q %s", ErrValidation.Error(), e.Field, e.Reason)
+}
+
+// Unwrap returns ErrValidation, so the error can be checked with errors.Is.
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// WithValidationField prefixes the field of a validation error with the
+// field containing the validated value.
+func WithValidationField(field string, err error) error {
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		return err
+	}
+
+	switch {
+	case vErr.Field == "":
+		return &ValidationError{Field: field, Reason: vErr.Reason}
+	case field == "":
+		return vErr
+	case strings.HasPrefix(vErr.Field, "["):
+		return &ValidationError{Field: field + vErr.Field, Reason: vErr.Reason}
+	default:
+		return &ValidationError{Field: field + "." + vErr.Field, Reason: vErr.Reason}
+	}
+}
+
+// ValidateMinLength checks that the string has at least n characters.
+func ValidateMinLength[T ~string](field string, value T, n uint) error {
+	if uint(utf8.RuneCountInString(string(value))) < n {
+		return NewValidationError(field, "should have at least %d characters", n)
+	}
+	return nil
+}
+
+// ValidateMaxLength checks that the string has at most n characters.
+func ValidateMaxLength[T ~string](field string, value T, n uint) error {
+	if uint(utf8.RuneCountInString(string(value))) > n {
+		return NewValidationError(field, "should have at most %d characters", n)
+	}
+	return nil
+}
+
+var patterns sync.Map // map[string]*regexp.Regexp
+
+// ValidatePattern checks that the string matches the regular expression.
+func ValidatePattern[T ~string](field string, value T, pattern string) error {
+	re, ok := patterns.Load(pattern)
+	if !ok {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("%w: invalid pattern %q: %w", ErrAsyncAPI, pattern, err)
+		}
+		re, _ = patterns.LoadOrStore(pattern, compiled)
+	}
+
+	if !re.(*regexp.Regexp).MatchString(string(value)) {
+		return NewValidationError(field, "should match pattern %q", pattern)
+	}
+	return nil
+}
+
+// ValidateMinimum checks that the number is greater than (or equal to if not
+// exclusive) the minimum.
+func ValidateMinimum[T Number](field string, value T, minimum float64, exclusive bool) error {
+	switch {
+	case exclusive && float64(value) <= minimum:
+		return NewValidationError(field, "should be greater than %g", minimum)
+	case !exclusive && float64(value) < minimum:
+		return NewValidationError(field, "should be greater than or equal to %g", minimum)
+	default:
+		return nil
+	}
+}
+
+// ValidateMaximum checks that the number is lower than (or equal to if not
+// exclusive) the maximum.
+func ValidateMaximum[T Number](field string, value T, maximum float64, exclusive bool) error {
+	switch {
+	case exclusive && float64(value) >= maximum:
+		return NewValidationError(field, "should be lower than %g", maximum)
+	case !exclusive && float64(value) > maximum:
+		return NewValidationError(field, "should be lower than or equal to %g", maximum)
+	default:
+		return nil
+	}
+}
+
+// multipleOfTolerance is the tolerance used to check multiples on floats.
+const multipleOfTolerance = 1e-9
+
+// ValidateMultipleOf checks that the number is a multiple of the divisor.
+func ValidateMultipleOf[T Number](field string, value T, divisor float64) error {
+	quotient := float64(value) / divisor
+	if math.Abs(quotient-math.Round(quotient)) > multipleOfTolerance {
+		return NewValidationError(field, "should be a multiple of %g", divisor)
+	}
+	return nil
+}
+
+// ValidateMinItems checks that the array has at least n items.
+func ValidateMinItems[T any](field string, items []T, n uint) error {
+	if uint(len(items)) < n {
+		return NewValidationError(field, "should have at least %d items", n)
+	}
+	return nil
+}
+
+// ValidateMaxItems checks that the array has at most n items.
+func ValidateMaxItems[T any](field string, items []T, n uint) error {
+	if uint(len(items)) > n {
+		return NewValidationError(field, "should have at most %d items", n)
+	}
+	return nil
+}
+
+// ValidateUniqueItems checks that every item of the array is unique.
+func ValidateUniqueItems[T any](field string, items []T) error {
+	for i := range items {
+		for j := i + 1; j < len(items); j++ {
+			if reflect.DeepEqual(items[i], items[j]) {
+				return NewValidationError(field, "should have unique items (%d and %d are equal)", i, j)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateEnum checks that the value is one of the allowed values.
+func ValidateEnum[T comparable](field string, value T, allowed ...T) error {
+	for _, a := range allowed {
+		if value == a {
+			return nil
+		}
+	}
+	return NewValidationError(field, "should be one of %v", allowed)
+}
+
+// ValidateConst checks that the value is equal to the expected one.
+func ValidateConst[T comparable](field string, value, expected T) error {
+	if value != expected {
+		return NewValidationError(field, "should be equal to %v", expected)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package extensions
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestValidationSuite(t *testing.T) {
+	suite.Run(t, new(ValidationSuite))
+}
+
+type ValidationSuite struct {
+	suite.Suite
+}
+
+func (suite *ValidationSuite) TestChecks() {
+	type status string
+
+	cases := []struct {
+		name    string
+		err     error
+		isValid bool
+	}{
+		{"min length", ValidateMinLength("f", "héllo", 5), true},
+		{"min length too short", ValidateMinLength("f", "hé", 5), false},
+		{"max length", ValidateMaxLength("f", status("ok"), 2), true},
+		{"max length too long", ValidateMaxLength("f", "long", 2), false},
+		{"pattern", ValidatePattern("f", "ORD-12", `^ORD-\d+$`), true},
+		{"pattern mismatch", ValidatePattern("f", "ORD-", `^ORD-\d+$`), false},
+		{"minimum", ValidateMinimum("f", 0, 0, false), true},
+		{"exclusive minimum", ValidateMinimum("f", int32(0), 0, true), false},
+		{"maximum", ValidateMaximum("f", 2.5, 2.5, false), true},
+		{"exclusive maximum", ValidateMaximum("f", 2.5, 2.5, true), false},
+		{"multiple of", ValidateMultipleOf("f", 0.3, 0.1), true},
+		{"not multiple of", ValidateMultipleOf("f", int64(7), 5), false},
+		{"min items", ValidateMinItems("f", []int{1}, 2), false},
+		{"max items", ValidateMaxItems("f", []int{1}, 2), true},
+		{"unique items", ValidateUniqueItems("f", []map[string]int{{"a": 1}, {"a": 2}}), true},
+		{"duplicate items", ValidateUniqueItems("f", []map[string]int{{"a": 1}, {"a": 1}}), false},
+		{"enum", ValidateEnum("f", int64(2), 1, 2, 3), true},
+		{"not in enum", ValidateEnum("f", status("c"), "a", "b"), false},
+		{"const", ValidateConst("f", true, true), true},
+		{"not const", ValidateConst("f", "v2", "v1"), false},
+	}
+
+	for _, c := range cases {
+		suite.Run(c.name, func() {
+			if c.isValid {
+				suite.Require().NoError(c.err)
+			} else {
+				suite.Require().ErrorIs(c.err, ErrValidation)
+			}
+		})
+	}
+}
+
+func (suite *ValidationSuite) TestWithValidationField() {
+	var vErr *ValidationError
+
+	err := WithValidationField("payload", NewValidationError("name", "is required"))
+	suite.Require().ErrorAs(err, &vErr)
+	suite.Require().Equal("payload.name", vErr.Field)
+
+	err = WithValidationField("items", NewValidationError("[2]", "is required"))
+	suite.Require().ErrorAs(err, &vErr)
+	suite.Require().Equal("items[2]", vErr.Field)
+
+	err = WithValidationField("payload", NewValidationError("", "should be one of [a b]"))
+	suite.Require().ErrorAs(err, &vErr)
+	suite.Require().Equal("payload", vErr.Field)
+
+	other := errors.New("other")
+	suite.Require().Equal(other, WithValidationField("payload", other))
+}
@@ -144,13 +144,25 @@ func CutSuffix(s, suffix string) string {
 	return s
 }
 
-var isDateOrDateTimeGenerated = func(format string) bool {
-	return format == "date" || format == "date-time"
+var dateOrDateTimeGeneration = true
+
+// SetDateOrDateTimeGeneration sets whether the date/date-time formats are
+// generated as date or date-time types within types.
+func SetDateOrDateTimeGeneration(enabled bool) {
+	dateOrDateTimeGeneration = enabled
+}
+
+// IsDateOrDateTimeGenerated returns true if the format will be generated as
+// a date or date-time type.
+func IsDateOrDateTimeGenerated(format string) bool {
+	return dateOrDateTimeGeneration && (format == "date" || format == "date-time")
 }
 
-// DisableDateOrTimeGeneration is used to disable the generation of date/date-time formats within types.
-func DisableDateOrTimeGeneration() {
-	isDateOrDateTimeGenerated = func(_ string) bool { return false }
+// IsPatternSupported returns true if the pattern can be compiled by Go regular
+// expressions, which don't support some ECMA-262 constructs (i.e. lookaheads).
+func IsPatternSupported(pattern string) bool {
+	_, err := regexp.Compile(pattern)
+	return err == nil
 }
 
 // HelpersFunctions returns the functions that can be used as helpers
@@ -159,7 +171,8 @@ func HelpersFunctions() template.FuncMap {
 	return template.FuncMap{
 		"namifyWithoutParam":        NamifyWithoutParams,
 		"namify":                    Namify,
-		"isDateOrDateTimeGenerated": isDateOrDateTimeGenerated,
+		"isDateOrDateTimeGenerated": IsDateOrDateTimeGenerated,
+		"isPatternSupported":        IsPatternSupported,
 		"convertKey":                ConvertKey,
 		"snakeCase":                 strcase.ToSnake,
 		"hasField":                  HasField,
@@ -7,8 +7,10 @@ if ! command -v git &> /dev/null; then
     apk add git
 fi
 
-# Execute golang code generation
+# Execute golang code generation (including in test modules)
 go generate ./...
+(cd test/v3/features/outbox && go generate ./...)
+(cd test/v3/features/tracing && go generate ./...)
 
 # Check that there is nothing to commit
 git diff-index HEAD
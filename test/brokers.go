@@ -2,11 +2,16 @@ package test
 
 import (
 	"fmt"
+	"os"
 	"testing"
 
 	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers/amqp"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers/inmemory"
 	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers/kafka"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers/mqtt"
 	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers/nats"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers/redis"
 	testutil "github.com/lerenn/asyncapi-codegen/pkg/utils/test"
 )
 
@@ -24,6 +29,9 @@ type BrokerAddressParams struct {
 
 // BrokerControllers returns a list of BrokerController to test based on the
 // docker-compose file of the project.
+//
+// If the environment variable ASYNCAPI_INMEMORY_ONLY is set, only the
+// in-memory broker is returned, so tests can run without any external service.
 func BrokerControllers(t *testing.T) ([]extensions.BrokerController, func()) {
 	t.Helper() // Set this function as a helper
 
@@ -31,6 +39,18 @@ func BrokerControllers(t *testing.T) ([]extensions.BrokerController, func()) {
 	queueGroupID := fmt.Sprintf("test-%s", t.Name())
 	fmt.Println(queueGroupID)
 
+	// Add in-memory broker, without redelivery to behave like other brokers
+	inmemoryController, err := inmemory.NewController(
+		inmemory.WithQueueGroup(queueGroupID),
+		inmemory.WithMaxDeliveries(1))
+	if err != nil {
+		panic(err)
+	}
+
+	if os.Getenv("ASYNCAPI_INMEMORY_ONLY") != "" {
+		return []extensions.BrokerController{inmemoryController}, func() {}
+	}
+
 	// Add NATS broker
 	natsController, err := nats.NewController(
 		testutil.BrokerAddress(testutil.BrokerAddressParams{
@@ -56,11 +76,57 @@ func BrokerControllers(t *testing.T) ([]extensions.BrokerController, func()) {
 		panic(err)
 	}
 
+	// Add AMQP broker
+	amqpController, err := amqp.NewController(
+		testutil.BrokerAddress(testutil.BrokerAddressParams{
+			Schema:         "amqp",
+			DockerizedAddr: "user:password@rabbitmq",
+			LocalAddr:      "user:password@localhost",
+			Port:           "5672",
+		}),
+		amqp.WithQueueGroup(queueGroupID))
+	if err != nil {
+		panic(err)
+	}
+
+	// Add MQTT broker
+	mqttController, err := mqtt.NewController(
+		testutil.BrokerAddress(testutil.BrokerAddressParams{
+			Schema:         "mqtt",
+			DockerizedAddr: "mosquitto",
+			Port:           "1883",
+		}),
+		mqtt.WithQoS(1),
+		mqtt.WithQueueGroup(queueGroupID))
+	if err != nil {
+		panic(err)
+	}
+
+	// Add Redis broker
+	redisController, err := redis.NewController(
+		testutil.BrokerAddress(testutil.BrokerAddressParams{
+			Schema:         "redis",
+			DockerizedAddr: "redis",
+			Port:           "6379",
+		}),
+		redis.WithGroupID(queueGroupID))
+	if err != nil {
+		panic(err)
+	}
+
 	// Return brokers with their cleanup functions
 	return []extensions.BrokerController{
+			inmemoryController,
 			natsController,
 			kafkaController,
+			amqpController,
+			mqttController,
+			redisController,
 		}, func() {
 			natsController.Close()
+			kafkaController.Close()
+			amqpController.Close()
+			mqttController.Close()
+			redisController.Close()
 		}
 }
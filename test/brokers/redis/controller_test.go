@@ -0,0 +1,111 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers/redis"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func receive(t *testing.T, sub extensions.BrokerChannelSubscription) extensions.AcknowledgeableBrokerMessage {
+	select {
+	case msg := <-sub.MessagesChannel():
+		return msg
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no message received")
+		return extensions.AcknowledgeableBrokerMessage{}
+	}
+}
+
+//nolint:funlen // this is only for testing
+func TestValidateAckMechanism(t *testing.T) {
+	channel := "RedisValidateAckMechanism"
+	server := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	broker, err := redis.NewController("redis://"+server.Addr(),
+		redis.WithGroupID(channel),
+		redis.WithClaim(50*time.Millisecond, 0))
+	require.NoError(t, err, "new controller should not return error")
+	defer broker.Close()
+
+	sub, err := broker.Subscribe(context.Background(), channel)
+	require.NoError(t, err, "subscribe should not return error")
+	defer sub.Cancel(context.Background())
+
+	t.Run("validate headers are transmitted", func(t *testing.T) {
+		err := broker.Publish(context.Background(), channel, extensions.BrokerMessage{
+			Headers: map[string][]byte{"key": []byte("value")},
+			Payload: []byte("testmessage"),
+		})
+		assert.NoError(t, err, "publish should not return error")
+
+		msg := receive(t, sub)
+		msg.Ack()
+
+		assert.Equal(t, "testmessage", string(msg.Payload))
+		assert.Equal(t, "value", string(msg.Headers["key"]))
+	})
+
+	t.Run("validate ack removes the entry from pending", func(t *testing.T) {
+		assert.Eventually(t, func() bool {
+			pending, err := client.XPending(context.Background(), channel, channel).Result()
+			return err == nil && pending.Count == 0
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("validate nak'd message is claimed again", func(t *testing.T) {
+		err := broker.Publish(context.Background(), channel, extensions.BrokerMessage{
+			Payload: []byte("nakmessage"),
+		})
+		assert.NoError(t, err, "publish should not return error")
+
+		msg := receive(t, sub)
+		assert.Equal(t, "nakmessage", string(msg.Payload))
+		msg.Nak()
+
+		msg = receive(t, sub)
+		assert.Equal(t, "nakmessage", string(msg.Payload), "nak'd message should be redelivered")
+		msg.Ack()
+	})
+}
+
+func TestGroupID(t *testing.T) {
+	channel := "RedisGroupID"
+	server := miniredis.RunT(t)
+
+	// Two controllers without group ID should each receive the message
+	b1, err := redis.NewController("redis://" + server.Addr())
+	require.NoError(t, err)
+	defer b1.Close()
+
+	b2, err := redis.NewController("redis://" + server.Addr())
+	require.NoError(t, err)
+	defer b2.Close()
+
+	sub1, err := b1.Subscribe(context.Background(), channel)
+	require.NoError(t, err)
+	defer sub1.Cancel(context.Background())
+
+	sub2, err := b2.Subscribe(context.Background(), channel)
+	require.NoError(t, err)
+	defer sub2.Cancel(context.Background())
+
+	err = b1.Publish(context.Background(), channel, extensions.BrokerMessage{Payload: []byte("fanout")})
+	require.NoError(t, err)
+
+	msg := receive(t, sub1)
+	msg.Ack()
+	assert.Equal(t, "fanout", string(msg.Payload))
+
+	msg = receive(t, sub2)
+	msg.Ack()
+	assert.Equal(t, "fanout", string(msg.Payload))
+}
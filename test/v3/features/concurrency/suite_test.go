@@ -0,0 +1,122 @@
+//go:generate go run ../../../../cmd/asyncapi-codegen -p concurrency -i ./asyncapi.yaml -o ./asyncapi.gen.go
+
+package concurrency
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers/inmemory"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, NewSuite())
+}
+
+type Suite struct {
+	suite.Suite
+}
+
+func NewSuite() *Suite {
+	return &Suite{}
+}
+
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+func (suite *Suite) newControllers(options ...ControllerOption) (*AppController, *UserController) {
+	broker := inmemory.NewBroker()
+
+	appBroker, err := inmemory.NewController(inmemory.WithBroker(broker))
+	suite.Require().NoError(err)
+	app, err := NewAppController(appBroker, options...)
+	suite.Require().NoError(err)
+
+	userBroker, err := inmemory.NewController(inmemory.WithBroker(broker))
+	suite.Require().NoError(err)
+	user, err := NewUserController(userBroker)
+	suite.Require().NoError(err)
+
+	return app, user
+}
+
+func (suite *Suite) TestParallelProcessing() {
+	app, user := suite.newControllers(WithConcurrency(3))
+	defer app.Close(context.Background())
+	defer user.Close(context.Background())
+
+	// Block handlers until 3 of them are running at the same time
+	var wg sync.WaitGroup
+	wg.Add(3)
+	var running, maximum atomic.Int32
+	err := app.SubscribeToReceiveTaskOperation(context.Background(), func(_ context.Context, _ TaskMessage) error {
+		n := running.Add(1)
+		for m := maximum.Load(); n > m && !maximum.CompareAndSwap(m, n); m = maximum.Load() {
+		}
+		wg.Done()
+		wg.Wait()
+		running.Add(-1)
+		return nil
+	})
+	suite.Require().NoError(err)
+
+	for i := int64(0); i < 3; i++ {
+		msg := NewTaskMessage()
+		msg.Payload.Index = Ptr(i)
+		suite.Require().NoError(user.SendToReceiveTaskOperation(context.Background(), msg))
+	}
+
+	done := make(chan struct{})
+	go func() { wg.Wait(); close(done) }()
+	select {
+	case <-done:
+		suite.Require().Equal(int32(3), maximum.Load())
+	case <-time.After(time.Second):
+		suite.FailNow("messages were not processed in parallel")
+	}
+}
+
+func (suite *Suite) TestOrderingByCorrelationID() {
+	app, user := suite.newControllers(WithConcurrency(4, extensions.KeyFromHeader("correlationId")))
+	defer app.Close(context.Background())
+	defer user.Close(context.Background())
+
+	const count = 30
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(count)
+	received := make(map[string][]int64)
+	err := app.SubscribeToReceiveTaskOperation(context.Background(), func(ctx context.Context, msg TaskMessage) error {
+		defer wg.Done()
+
+		// Sleep more on first messages to reorder them without ordering
+		time.Sleep(time.Duration(count-*msg.Payload.Index) * 100 * time.Microsecond)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		id := *msg.Headers.CorrelationId
+		received[id] = append(received[id], *msg.Payload.Index)
+		return nil
+	})
+	suite.Require().NoError(err)
+
+	for i := int64(0); i < count; i++ {
+		msg := NewTaskMessage()
+		msg.SetCorrelationID([]string{"a", "b", "c"}[i%3])
+		msg.Payload.Index = Ptr(i)
+		suite.Require().NoError(user.SendToReceiveTaskOperation(context.Background(), msg))
+	}
+	wg.Wait()
+
+	suite.Require().Len(received, 3)
+	for id, indexes := range received {
+		suite.Require().Len(indexes, count/3, id)
+		suite.Require().IsIncreasing(indexes, id)
+	}
+}
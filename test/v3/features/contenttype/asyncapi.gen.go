@@ -0,0 +1,574 @@
+// Package "contenttype" provides primitives to interact with the AsyncAPI specification.
+//
+// Code generated by github.com/lerenn/asyncapi-codegen version v0.45.1 DO NOT EDIT.
+package contenttype
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+)
+
+// AppController is the structure that provides sending capabilities to the
+// developer and and connect the broker with the App
+type AppController struct {
+	controller
+}
+
+// NewAppController links the App to the broker
+func NewAppController(bc extensions.BrokerController, options ...ControllerOption) (*AppController, error) {
+	// Check if broker controller has been provided
+	if bc == nil {
+		return nil, extensions.ErrNilBrokerController
+	}
+
+	// Create default controller
+	c := &AppController{controller: controller{
+		broker:        bc,
+		subscriptions: make(map[string]subscription),
+		logger:        extensions.DummyLogger{},
+		middlewares:   make([]extensions.Middleware, 0),
+		errorHandler:  extensions.DefaultErrorHandler(),
+		concurrency:   1,
+	}}
+
+	// Apply options
+	for _, option := range options {
+		option(&c.controller)
+	}
+
+	return c, nil
+}
+
+func (c *AppController) wrapMiddlewares(
+	middlewares []extensions.Middleware,
+	callback extensions.NextMiddleware,
+) func(ctx context.Context, msg *extensions.BrokerMessage) error {
+	// If there is no more middleware
+	if len(middlewares) == 0 {
+		return func(ctx context.Context, msg *extensions.BrokerMessage) error {
+			// Call the callback if it exists
+			if callback != nil {
+				return callback(ctx)
+			}
+
+			return nil
+		}
+	}
+
+	// Get the next function to call from next middlewares or callback
+	next := c.wrapMiddlewares(middlewares[1:], callback)
+
+	// Wrap middleware into a function that will execute the middleware and call
+	// the next wrapped middleware if the middleware has not called it already
+	return func(ctx context.Context, msg *extensions.BrokerMessage) error {
+		// Create the next call with the context and the message. It can be
+		// called several times by the middleware (i.e. to retry the processing).
+		var called bool
+		nextWithArgs := func(ctx context.Context) error {
+			called = true
+			return next(ctx, msg)
+		}
+
+		// Call the middleware
+		if err := middlewares[0](ctx, msg, nextWithArgs); err != nil {
+			return err
+		}
+
+		// If next has already been called in middleware, it should not be executed again
+		if called {
+			return nil
+		}
+		return nextWithArgs(ctx)
+	}
+}
+
+func (c *AppController) executeMiddlewares(ctx context.Context, msg *extensions.BrokerMessage, callback extensions.NextMiddleware) error {
+	// Wrap middleware to have 'next' function when calling them
+	wrapped := c.wrapMiddlewares(c.middlewares, callback)
+
+	// Execute wrapped middlewares
+	return wrapped(ctx, msg)
+}
+
+func addAppContextValues(ctx context.Context, addr string) context.Context {
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsVersion, "1.2.3")
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsProvider, "app")
+	return context.WithValue(ctx, extensions.ContextKeyIsChannel, addr)
+}
+
+// Close will clean up any existing resources on the controller
+func (c *AppController) Close(ctx context.Context) {
+	// Unsubscribing remaining channels
+}
+
+// Shutdown will gracefully stop the controller: it stops the intake of new
+// messages, and waits for the messages being processed to be acknowledged
+// before stopping the subscriptions, until the context is done.
+//
+// Messages received after the intake has been stopped are rejected (Nak) in
+// order to be processed again later, possibly by another instance. An error
+// wrapping extensions.ErrSubscriptionNotDrained is returned for each
+// subscription that still has messages being processed when the context is
+// done, and the context given to these messages is canceled.
+func (c *AppController) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// UserController is the structure that provides sending capabilities to the
+// developer and and connect the broker with the User
+type UserController struct {
+	controller
+}
+
+// NewUserController links the User to the broker
+func NewUserController(bc extensions.BrokerController, options ...ControllerOption) (*UserController, error) {
+	// Check if broker controller has been provided
+	if bc == nil {
+		return nil, extensions.ErrNilBrokerController
+	}
+
+	// Create default controller
+	c := &UserController{controller: controller{
+		broker:        bc,
+		subscriptions: make(map[string]subscription),
+		logger:        extensions.DummyLogger{},
+		middlewares:   make([]extensions.Middleware, 0),
+		errorHandler:  extensions.DefaultErrorHandler(),
+		concurrency:   1,
+	}}
+
+	// Apply options
+	for _, option := range options {
+		option(&c.controller)
+	}
+
+	return c, nil
+}
+
+func (c *UserController) wrapMiddlewares(
+	middlewares []extensions.Middleware,
+	callback extensions.NextMiddleware,
+) func(ctx context.Context, msg *extensions.BrokerMessage) error {
+	// If there is no more middleware
+	if len(middlewares) == 0 {
+		return func(ctx context.Context, msg *extensions.BrokerMessage) error {
+			// Call the callback if it exists
+			if callback != nil {
+				return callback(ctx)
+			}
+
+			return nil
+		}
+	}
+
+	// Get the next function to call from next middlewares or callback
+	next := c.wrapMiddlewares(middlewares[1:], callback)
+
+	// Wrap middleware into a function that will execute the middleware and call
+	// the next wrapped middleware if the middleware has not called it already
+	return func(ctx context.Context, msg *extensions.BrokerMessage) error {
+		// Create the next call with the context and the message. It can be
+		// called several times by the middleware (i.e. to retry the processing).
+		var called bool
+		nextWithArgs := func(ctx context.Context) error {
+			called = true
+			return next(ctx, msg)
+		}
+
+		// Call the middleware
+		if err := middlewares[0](ctx, msg, nextWithArgs); err != nil {
+			return err
+		}
+
+		// If next has already been called in middleware, it should not be executed again
+		if called {
+			return nil
+		}
+		return nextWithArgs(ctx)
+	}
+}
+
+func (c *UserController) executeMiddlewares(ctx context.Context, msg *extensions.BrokerMessage, callback extensions.NextMiddleware) error {
+	// Wrap middleware to have 'next' function when calling them
+	wrapped := c.wrapMiddlewares(c.middlewares, callback)
+
+	// Execute wrapped middlewares
+	return wrapped(ctx, msg)
+}
+
+func addUserContextValues(ctx context.Context, addr string) context.Context {
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsVersion, "1.2.3")
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsProvider, "user")
+	return context.WithValue(ctx, extensions.ContextKeyIsChannel, addr)
+}
+
+// Close will clean up any existing resources on the controller
+func (c *UserController) Close(ctx context.Context) {
+	// Unsubscribing remaining channels
+}
+
+// Shutdown will gracefully stop the controller: it stops the intake of new
+// messages, and waits for the messages being processed to be acknowledged
+// before stopping the subscriptions, until the context is done.
+//
+// Messages received after the intake has been stopped are rejected (Nak) in
+// order to be processed again later, possibly by another instance. An error
+// wrapping extensions.ErrSubscriptionNotDrained is returned for each
+// subscription that still has messages being processed when the context is
+// done, and the context given to these messages is canceled.
+func (c *UserController) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// AsyncAPIVersion is the version of the used AsyncAPI document
+const AsyncAPIVersion = "1.2.3"
+
+// controller is the controller that will be used to communicate with the broker
+// It will be used internally by AppController and UserController
+type controller struct {
+	// broker is the broker controller that will be used to communicate
+	broker extensions.BrokerController
+	// subscriptions is a map of all subscriptions
+	subscriptions map[string]subscription
+	// subscriptionsMutex protects the subscriptions map
+	subscriptionsMutex sync.Mutex
+	// logger is the logger that will be used² to log operations on controller
+	logger extensions.Logger
+	// middlewares are the middlewares that will be executed when sending or
+	// receiving messages
+	middlewares []extensions.Middleware
+	// handler to handle errors from consumers and middlewares
+	errorHandler extensions.ErrorHandler
+	// validation is set if messages should be validated against the constraints
+	// of the AsyncAPI specification
+	validation bool
+	// concurrency is the maximum number of messages processed in parallel
+	// for each subscription
+	concurrency int
+	// concurrencyKey is the key extractor used to process messages with the
+	// same key in order when processing them in parallel
+	concurrencyKey extensions.KeyExtractor
+	// autoUnsubscribe is set if subscriptions should be stopped when the
+	// context given on subscription is done
+	autoUnsubscribe bool
+}
+
+// subscription is a subscription to a broker channel, with the tracker of the
+// messages being processed by its listener
+type subscription struct {
+	broker   extensions.BrokerChannelSubscription
+	listener *extensions.ListenerTracker
+}
+
+// ControllerOption is the type of the options that can be passed
+// when creating a new Controller
+type ControllerOption func(controller *controller)
+
+// WithLogger attaches a logger to the controller
+func WithLogger(logger extensions.Logger) ControllerOption {
+	return func(controller *controller) {
+		controller.logger = logger
+	}
+}
+
+// WithMiddlewares attaches middlewares that will be executed when sending or receiving messages
+func WithMiddlewares(middlewares ...extensions.Middleware) ControllerOption {
+	return func(controller *controller) {
+		controller.middlewares = middlewares
+	}
+}
+
+// WithErrorHandler attaches a errorhandler to handle errors from subscriber functions
+func WithErrorHandler(handler extensions.ErrorHandler) ControllerOption {
+	return func(controller *controller) {
+		controller.errorHandler = handler
+	}
+}
+
+// WithValidation enables the validation of messages against the constraints of
+// the AsyncAPI specification: invalid messages are rejected when sent, and
+// given to the error handler instead of the subscriber when received.
+func WithValidation() ControllerOption {
+	return func(controller *controller) {
+		controller.validation = true
+	}
+}
+
+// WithConcurrency sets the maximum number of messages processed in parallel for
+// each subscription (default is 1). If a key extractor is given (i.e.
+// extensions.KeyFromHeader), messages with the same key are processed
+// sequentially, in the order they were received.
+func WithConcurrency(n int, key ...extensions.KeyExtractor) ControllerOption {
+	return func(controller *controller) {
+		controller.concurrency = n
+		if len(key) > 0 {
+			controller.concurrencyKey = key[0]
+		}
+	}
+}
+
+// WithAutoUnsubscribe stops a subscription when the context given on
+// subscription is done, as if the corresponding unsubscribe function was called.
+func WithAutoUnsubscribe() ControllerOption {
+	return func(controller *controller) {
+		controller.autoUnsubscribe = true
+	}
+}
+
+// unsubscribeWhenDone stops the subscription on the channel when the context is
+// done, unless its listener has been stopped before (i.e. unsubscribed).
+func (c *controller) unsubscribeWhenDone(ctx context.Context, addr string, listener *extensions.ListenerTracker) {
+	// Wait for the context to be done or the listener to be stopped
+	if err := listener.WaitStopped(ctx); err == nil {
+		return
+	}
+
+	// Remove the subscription if it is still the same
+	c.subscriptionsMutex.Lock()
+	sub, exists := c.subscriptions[addr]
+	if !exists || sub.listener != listener {
+		c.subscriptionsMutex.Unlock()
+		return
+	}
+	delete(c.subscriptions, addr)
+	c.subscriptionsMutex.Unlock()
+
+	// Stop the subscription, waiting for the broker clean up even if the
+	// context is done, and cancel the context of the messages being processed
+	sub.listener.CancelProcessing()
+	sub.broker.Cancel(context.WithoutCancel(ctx))
+
+	c.logger.Info(ctx, "Unsubscribed from channel as context is done")
+}
+
+type MessageWithCorrelationID interface {
+	CorrelationID() string
+	SetCorrelationID(id string)
+}
+
+type Error struct {
+	Channel string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("channel %q: err %v", e.Channel, e.Err)
+}
+
+// BinaryMessagePayload is a schema from the AsyncAPI specification required in messages
+type BinaryMessagePayload struct {
+	Name *string `json:"name,omitempty"`
+}
+
+// Validate checks that BinaryMessagePayload respects the constraints of the AsyncAPI specification.
+func (s BinaryMessagePayload) Validate() error {
+	return nil
+}
+
+// BinaryMessage is the message expected for 'BinaryMessage' channel.
+type BinaryMessage struct {
+	// Payload will be inserted in the message payload
+	Payload BinaryMessagePayload
+}
+
+func NewBinaryMessage() BinaryMessage {
+	var msg BinaryMessage
+
+	return msg
+}
+
+// brokerMessageToBinaryMessage will fill a new BinaryMessage with data from generic broker message
+func brokerMessageToBinaryMessage(bMsg extensions.BrokerMessage) (BinaryMessage, error) {
+	var msg BinaryMessage
+
+	// Get codec from the message content type, or from the one in specification
+	codec, err := extensions.CodecForBrokerMessage(bMsg, "application/octet-stream")
+	if err != nil {
+		return msg, err
+	}
+
+	// Unmarshal payload to expected message payload format
+	err = codec.Unmarshal(bMsg.Payload, &msg.Payload)
+	if err != nil {
+		return msg, err
+	}
+
+	return msg, nil
+}
+
+// toBrokerMessage will generate a generic broker message from BinaryMessage data
+func (msg BinaryMessage) toBrokerMessage() (extensions.BrokerMessage, error) {
+
+	// Get codec from the content type in specification
+	codec := extensions.SpecificationCodec("application/octet-stream")
+
+	// Marshal payload with the codec
+	payload, err := codec.Marshal(msg.Payload)
+	if err != nil {
+		return extensions.BrokerMessage{}, err
+	}
+
+	// There is no headers here
+	headers := make(map[string][]byte, 0)
+
+	// Set content type, so the receiver can decode the payload
+	headers[extensions.ContentTypeHeader] = []byte("application/octet-stream")
+
+	return extensions.BrokerMessage{
+		Headers: headers,
+		Payload: payload,
+	}, nil
+}
+
+// Validate checks that BinaryMessage respects the constraints of the AsyncAPI specification.
+func (msg BinaryMessage) Validate() error {
+	if err := msg.Payload.Validate(); err != nil {
+		return extensions.WithValidationField("payload", err)
+	}
+	return nil
+}
+
+// CborMessagePayload is a schema from the AsyncAPI specification required in messages
+type CborMessagePayload []string
+
+// Validate checks that CborMessagePayload respects the constraints of the AsyncAPI specification.
+func (s CborMessagePayload) Validate() error {
+	return nil
+}
+
+// CborMessage is the message expected for 'CborMessage' channel.
+type CborMessage struct {
+	// Payload will be inserted in the message payload
+	Payload []string
+}
+
+func NewCborMessage() CborMessage {
+	var msg CborMessage
+
+	return msg
+}
+
+// brokerMessageToCborMessage will fill a new CborMessage with data from generic broker message
+func brokerMessageToCborMessage(bMsg extensions.BrokerMessage) (CborMessage, error) {
+	var msg CborMessage
+
+	// Get codec from the message content type, or from the one in specification
+	codec, err := extensions.CodecForBrokerMessage(bMsg, "application/cbor")
+	if err != nil {
+		return msg, err
+	}
+
+	// Unmarshal payload to expected message payload format
+	err = codec.Unmarshal(bMsg.Payload, &msg.Payload)
+	if err != nil {
+		return msg, err
+	}
+
+	return msg, nil
+}
+
+// toBrokerMessage will generate a generic broker message from CborMessage data
+func (msg CborMessage) toBrokerMessage() (extensions.BrokerMessage, error) {
+
+	// Get codec from the content type in specification
+	codec := extensions.SpecificationCodec("application/cbor")
+
+	// Marshal payload with the codec
+	payload, err := codec.Marshal(msg.Payload)
+	if err != nil {
+		return extensions.BrokerMessage{}, err
+	}
+
+	// There is no headers here
+	headers := make(map[string][]byte, 0)
+
+	// Set content type, so the receiver can decode the payload
+	headers[extensions.ContentTypeHeader] = []byte("application/cbor")
+
+	return extensions.BrokerMessage{
+		Headers: headers,
+		Payload: payload,
+	}, nil
+}
+
+// Validate checks that CborMessage respects the constraints of the AsyncAPI specification.
+func (msg CborMessage) Validate() error {
+	return nil
+}
+
+// DefaultMessagePayload is a schema from the AsyncAPI specification required in messages
+type DefaultMessagePayload struct {
+	Count *int64  `json:"count,omitempty"`
+	Name  *string `json:"name,omitempty"`
+}
+
+// Validate checks that DefaultMessagePayload respects the constraints of the AsyncAPI specification.
+func (s DefaultMessagePayload) Validate() error {
+	return nil
+}
+
+// DefaultMessage is the message expected for 'DefaultMessage' channel.
+type DefaultMessage struct {
+	// Payload will be inserted in the message payload
+	Payload DefaultMessagePayload
+}
+
+func NewDefaultMessage() DefaultMessage {
+	var msg DefaultMessage
+
+	return msg
+}
+
+// brokerMessageToDefaultMessage will fill a new DefaultMessage with data from generic broker message
+func brokerMessageToDefaultMessage(bMsg extensions.BrokerMessage) (DefaultMessage, error) {
+	var msg DefaultMessage
+
+	// Get codec from the message content type, or from the one in specification
+	codec, err := extensions.CodecForBrokerMessage(bMsg, "application/msgpack")
+	if err != nil {
+		return msg, err
+	}
+
+	// Unmarshal payload to expected message payload format
+	err = codec.Unmarshal(bMsg.Payload, &msg.Payload)
+	if err != nil {
+		return msg, err
+	}
+
+	return msg, nil
+}
+
+// toBrokerMessage will generate a generic broker message from DefaultMessage data
+func (msg DefaultMessage) toBrokerMessage() (extensions.BrokerMessage, error) {
+
+	// Get codec from the content type in specification
+	codec := extensions.SpecificationCodec("application/msgpack")
+
+	// Marshal payload with the codec
+	payload, err := codec.Marshal(msg.Payload)
+	if err != nil {
+		return extensions.BrokerMessage{}, err
+	}
+
+	// There is no headers here
+	headers := make(map[string][]byte, 0)
+
+	// Set content type, so the receiver can decode the payload
+	headers[extensions.ContentTypeHeader] = []byte("application/msgpack")
+
+	return extensions.BrokerMessage{
+		Headers: headers,
+		Payload: payload,
+	}, nil
+}
+
+// Validate checks that DefaultMessage respects the constraints of the AsyncAPI specification.
+func (msg DefaultMessage) Validate() error {
+	if err := msg.Payload.Validate(); err != nil {
+		return extensions.WithValidationField("payload", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+//go:generate go run ../../../../cmd/asyncapi-codegen -p contenttype -i ./asyncapi.yaml -o ./asyncapi.gen.go
+
+package contenttype
+
+import (
+	"testing"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, NewSuite())
+}
+
+type Suite struct {
+	suite.Suite
+}
+
+func NewSuite() *Suite {
+	return &Suite{}
+}
+
+func (suite *Suite) TestDefaultContentType() {
+	name, count := "test", int64(42)
+	msg := DefaultMessage{Payload: DefaultMessagePayload{Name: &name, Count: &count}}
+
+	// Message should be encoded with the default content type of the specification
+	bMsg, err := msg.toBrokerMessage()
+	suite.Require().NoError(err)
+	suite.Require().Equal("application/msgpack", string(bMsg.Headers[extensions.ContentTypeHeader]))
+
+	var payload DefaultMessagePayload
+	suite.Require().NoError(extensions.MessagePackCodec{}.Unmarshal(bMsg.Payload, &payload))
+	suite.Require().Equal(msg.Payload, payload)
+
+	// And decoded back
+	res, err := brokerMessageToDefaultMessage(bMsg)
+	suite.Require().NoError(err)
+	suite.Require().Equal(msg, res)
+}
+
+func (suite *Suite) TestMessageContentType() {
+	msg := CborMessage{Payload: []string{"a", "b"}}
+
+	bMsg, err := msg.toBrokerMessage()
+	suite.Require().NoError(err)
+	suite.Require().Equal("application/cbor", string(bMsg.Headers[extensions.ContentTypeHeader]))
+
+	res, err := brokerMessageToCborMessage(bMsg)
+	suite.Require().NoError(err)
+	suite.Require().Equal(msg, res)
+}
+
+func (suite *Suite) TestReceptionUsesContentTypeHeader() {
+	// A JSON message should be decoded based on its header, even if the
+	// specification expects MessagePack
+	res, err := brokerMessageToDefaultMessage(extensions.BrokerMessage{
+		Headers: map[string][]byte{extensions.ContentTypeHeader: []byte("application/json")},
+		Payload: []byte(`{"name":"test"}`),
+	})
+	suite.Require().NoError(err)
+	suite.Require().NotNil(res.Payload.Name)
+	suite.Require().Equal("test", *res.Payload.Name)
+
+	// Without header, the content type of the specification should be used
+	_, err = brokerMessageToDefaultMessage(extensions.BrokerMessage{
+		Payload: []byte(`{"name":"test"}`),
+	})
+	suite.Require().Error(err)
+
+	// An unknown content type should be rejected
+	_, err = brokerMessageToDefaultMessage(extensions.BrokerMessage{
+		Headers: map[string][]byte{extensions.ContentTypeHeader: []byte("application/unknown")},
+		Payload: []byte(`{"name":"test"}`),
+	})
+	suite.Require().ErrorIs(err, extensions.ErrUnknownContentType)
+}
+
+func (suite *Suite) TestContentTypeWithoutCodec() {
+	name := "test"
+	msg := BinaryMessage{Payload: BinaryMessagePayload{Name: &name}}
+
+	// Message should be encoded in JSON, as there is no codec for its content type
+	bMsg, err := msg.toBrokerMessage()
+	suite.Require().NoError(err)
+	suite.Require().Equal("application/octet-stream", string(bMsg.Headers[extensions.ContentTypeHeader]))
+	suite.Require().JSONEq(`{"name":"test"}`, string(bMsg.Payload))
+
+	// And decoded back
+	res, err := brokerMessageToBinaryMessage(bMsg)
+	suite.Require().NoError(err)
+	suite.Require().Equal(msg, res)
+}
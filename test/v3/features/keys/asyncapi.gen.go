@@ -0,0 +1,1385 @@
+// Package "keys" provides primitives to interact with the AsyncAPI specification.
+//
+// Code generated by github.com/lerenn/asyncapi-codegen version v0.45.1 DO NOT EDIT.
+package keys
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+)
+
+// AppController is the structure that provides sending capabilities to the
+// developer and and connect the broker with the App
+type AppController struct {
+	controller
+}
+
+// NewAppController links the App to the broker
+func NewAppController(bc extensions.BrokerController, options ...ControllerOption) (*AppController, error) {
+	// Check if broker controller has been provided
+	if bc == nil {
+		return nil, extensions.ErrNilBrokerController
+	}
+
+	// Create default controller
+	c := &AppController{controller: controller{
+		broker:        bc,
+		subscriptions: make(map[string]subscription),
+		logger:        extensions.DummyLogger{},
+		middlewares:   make([]extensions.Middleware, 0),
+		errorHandler:  extensions.DefaultErrorHandler(),
+		concurrency:   1,
+	}}
+
+	// Apply options
+	for _, option := range options {
+		option(&c.controller)
+	}
+
+	return c, nil
+}
+
+func (c *AppController) wrapMiddlewares(
+	middlewares []extensions.Middleware,
+	callback extensions.NextMiddleware,
+) func(ctx context.Context, msg *extensions.BrokerMessage) error {
+	// If there is no more middleware
+	if len(middlewares) == 0 {
+		return func(ctx context.Context, msg *extensions.BrokerMessage) error {
+			// Call the callback if it exists
+			if callback != nil {
+				return callback(ctx)
+			}
+
+			return nil
+		}
+	}
+
+	// Get the next function to call from next middlewares or callback
+	next := c.wrapMiddlewares(middlewares[1:], callback)
+
+	// Wrap middleware into a function that will execute the middleware and call
+	// the next wrapped middleware if the middleware has not called it already
+	return func(ctx context.Context, msg *extensions.BrokerMessage) error {
+		// Create the next call with the context and the message. It can be
+		// called several times by the middleware (i.e. to retry the processing).
+		var called bool
+		nextWithArgs := func(ctx context.Context) error {
+			called = true
+			return next(ctx, msg)
+		}
+
+		// Call the middleware
+		if err := middlewares[0](ctx, msg, nextWithArgs); err != nil {
+			return err
+		}
+
+		// If next has already been called in middleware, it should not be executed again
+		if called {
+			return nil
+		}
+		return nextWithArgs(ctx)
+	}
+}
+
+func (c *AppController) executeMiddlewares(ctx context.Context, msg *extensions.BrokerMessage, callback extensions.NextMiddleware) error {
+	// Wrap middleware to have 'next' function when calling them
+	wrapped := c.wrapMiddlewares(c.middlewares, callback)
+
+	// Execute wrapped middlewares
+	return wrapped(ctx, msg)
+}
+
+func addAppContextValues(ctx context.Context, addr string) context.Context {
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsVersion, "1.2.3")
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsProvider, "app")
+	return context.WithValue(ctx, extensions.ContextKeyIsChannel, addr)
+}
+
+// Close will clean up any existing resources on the controller
+func (c *AppController) Close(ctx context.Context) {
+	// Unsubscribing remaining channels
+}
+
+// Shutdown will gracefully stop the controller: it stops the intake of new
+// messages, and waits for the messages being processed to be acknowledged
+// before stopping the subscriptions, until the context is done.
+//
+// Messages received after the intake has been stopped are rejected (Nak) in
+// order to be processed again later, possibly by another instance. An error
+// wrapping extensions.ErrSubscriptionNotDrained is returned for each
+// subscription that still has messages being processed when the context is
+// done, and the context given to these messages is canceled.
+func (c *AppController) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// SendAsSendEventOperation will send a EventMessageFromEventsChannel message on Events channel.
+func (c *AppController) SendAsSendEventOperation(
+	ctx context.Context,
+	msg EventMessageFromEventsChannel,
+) error {
+	// Set channel address
+	addr := "v3.features.keys.events"
+
+	// Set context
+	ctx = addAppContextValues(ctx, addr)
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsDirection, "publication")
+
+	// Validate message if required
+	if c.validation {
+		if err := msg.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Convert to BrokerMessage
+	brokerMsg, err := msg.toBrokerMessage()
+	if err != nil {
+		return err
+	}
+
+	// Set broker message to context
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsBrokerMessage, brokerMsg.String())
+
+	// Send the message on event-broker through middlewares
+	return c.executeMiddlewares(ctx, &brokerMsg, func(ctx context.Context) error {
+		return c.broker.Publish(ctx, addr, brokerMsg)
+	})
+}
+
+// SendAsSendOrderOperation will send a OrderMessageFromOrdersChannel message on Orders channel.
+func (c *AppController) SendAsSendOrderOperation(
+	ctx context.Context,
+	msg OrderMessageFromOrdersChannel,
+) error {
+	// Set channel address
+	addr := "v3.features.keys.orders"
+
+	// Set context
+	ctx = addAppContextValues(ctx, addr)
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsDirection, "publication")
+
+	// Validate message if required
+	if c.validation {
+		if err := msg.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Convert to BrokerMessage
+	brokerMsg, err := msg.toBrokerMessage()
+	if err != nil {
+		return err
+	}
+
+	// Set broker message to context
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsBrokerMessage, brokerMsg.String())
+
+	// Send the message on event-broker through middlewares
+	return c.executeMiddlewares(ctx, &brokerMsg, func(ctx context.Context) error {
+		return c.broker.Publish(ctx, addr, brokerMsg)
+	})
+}
+
+// SendAsSendPaymentOperation will send a PaymentMessageFromPaymentsChannel message on Payments channel.
+func (c *AppController) SendAsSendPaymentOperation(
+	ctx context.Context,
+	msg PaymentMessageFromPaymentsChannel,
+) error {
+	// Set channel address
+	addr := "v3.features.keys.payments"
+
+	// Set context
+	ctx = addAppContextValues(ctx, addr)
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsDirection, "publication")
+
+	// Validate message if required
+	if c.validation {
+		if err := msg.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Convert to BrokerMessage
+	brokerMsg, err := msg.toBrokerMessage()
+	if err != nil {
+		return err
+	}
+
+	// Set broker message to context
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsBrokerMessage, brokerMsg.String())
+
+	// Send the message on event-broker through middlewares
+	return c.executeMiddlewares(ctx, &brokerMsg, func(ctx context.Context) error {
+		return c.broker.Publish(ctx, addr, brokerMsg)
+	})
+}
+
+// UserSubscriber contains all handlers that are listening messages for User
+type UserSubscriber interface {
+	// SendEventOperationReceived receive all EventMessageFromEventsChannel messages from Events channel.
+	SendEventOperationReceived(ctx context.Context, msg EventMessageFromEventsChannel) error
+
+	// SendOrderOperationReceived receive all OrderMessageFromOrdersChannel messages from Orders channel.
+	SendOrderOperationReceived(ctx context.Context, msg OrderMessageFromOrdersChannel) error
+
+	// SendPaymentOperationReceived receive all PaymentMessageFromPaymentsChannel messages from Payments channel.
+	SendPaymentOperationReceived(ctx context.Context, msg PaymentMessageFromPaymentsChannel) error
+}
+
+// UserController is the structure that provides sending capabilities to the
+// developer and and connect the broker with the User
+type UserController struct {
+	controller
+}
+
+// NewUserController links the User to the broker
+func NewUserController(bc extensions.BrokerController, options ...ControllerOption) (*UserController, error) {
+	// Check if broker controller has been provided
+	if bc == nil {
+		return nil, extensions.ErrNilBrokerController
+	}
+
+	// Create default controller
+	c := &UserController{controller: controller{
+		broker:        bc,
+		subscriptions: make(map[string]subscription),
+		logger:        extensions.DummyLogger{},
+		middlewares:   make([]extensions.Middleware, 0),
+		errorHandler:  extensions.DefaultErrorHandler(),
+		concurrency:   1,
+	}}
+
+	// Apply options
+	for _, option := range options {
+		option(&c.controller)
+	}
+
+	return c, nil
+}
+
+func (c *UserController) wrapMiddlewares(
+	middlewares []extensions.Middleware,
+	callback extensions.NextMiddleware,
+) func(ctx context.Context, msg *extensions.BrokerMessage) error {
+	// If there is no more middleware
+	if len(middlewares) == 0 {
+		return func(ctx context.Context, msg *extensions.BrokerMessage) error {
+			// Call the callback if it exists
+			if callback != nil {
+				return callback(ctx)
+			}
+
+			return nil
+		}
+	}
+
+	// Get the next function to call from next middlewares or callback
+	next := c.wrapMiddlewares(middlewares[1:], callback)
+
+	// Wrap middleware into a function that will execute the middleware and call
+	// the next wrapped middleware if the middleware has not called it already
+	return func(ctx context.Context, msg *extensions.BrokerMessage) error {
+		// Create the next call with the context and the message. It can be
+		// called several times by the middleware (i.e. to retry the processing).
+		var called bool
+		nextWithArgs := func(ctx context.Context) error {
+			called = true
+			return next(ctx, msg)
+		}
+
+		// Call the middleware
+		if err := middlewares[0](ctx, msg, nextWithArgs); err != nil {
+			return err
+		}
+
+		// If next has already been called in middleware, it should not be executed again
+		if called {
+			return nil
+		}
+		return nextWithArgs(ctx)
+	}
+}
+
+func (c *UserController) executeMiddlewares(ctx context.Context, msg *extensions.BrokerMessage, callback extensions.NextMiddleware) error {
+	// Wrap middleware to have 'next' function when calling them
+	wrapped := c.wrapMiddlewares(c.middlewares, callback)
+
+	// Execute wrapped middlewares
+	return wrapped(ctx, msg)
+}
+
+func addUserContextValues(ctx context.Context, addr string) context.Context {
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsVersion, "1.2.3")
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsProvider, "user")
+	return context.WithValue(ctx, extensions.ContextKeyIsChannel, addr)
+}
+
+// Close will clean up any existing resources on the controller
+func (c *UserController) Close(ctx context.Context) {
+	// Unsubscribing remaining channels
+	c.UnsubscribeFromAllChannels(ctx)
+
+	c.logger.Info(ctx, "Closed user controller")
+}
+
+// Shutdown will gracefully stop the controller: it stops the intake of new
+// messages, and waits for the messages being processed to be acknowledged
+// before stopping the subscriptions, until the context is done.
+//
+// Messages received after the intake has been stopped are rejected (Nak) in
+// order to be processed again later, possibly by another instance. An error
+// wrapping extensions.ErrSubscriptionNotDrained is returned for each
+// subscription that still has messages being processed when the context is
+// done, and the context given to these messages is canceled.
+func (c *UserController) Shutdown(ctx context.Context) error {
+	// Take every subscription from the controller
+	c.subscriptionsMutex.Lock()
+	subscriptions := c.subscriptions
+	c.subscriptions = make(map[string]subscription)
+	c.subscriptionsMutex.Unlock()
+
+	// Stop the intake of new messages on every subscription
+	for _, sub := range subscriptions {
+		sub.listener.StopIntake()
+	}
+
+	var errs []error
+	for addr, sub := range subscriptions {
+		// Set context
+		subCtx := addUserContextValues(ctx, addr)
+
+		// Wait for the messages being processed, so they can still be
+		// acknowledged on the subscription, and cancel the context of the
+		// ones still being processed when the context is done (a timeout is
+		// reported below)
+		if err := sub.listener.Drain(subCtx); err != nil {
+			sub.listener.CancelProcessing()
+		}
+
+		// Stop the subscription and wait for its listener
+		sub.broker.Cancel(subCtx)
+		if err := sub.listener.WaitStopped(subCtx); err != nil {
+			err = fmt.Errorf("%w: %d messages still being processed on channel %q",
+				extensions.ErrSubscriptionNotDrained, sub.listener.InFlight(), addr)
+			c.logger.Error(subCtx, err.Error())
+			errs = append(errs, err)
+		}
+	}
+
+	c.logger.Info(ctx, "Shut down user controller")
+
+	return errors.Join(errs...)
+}
+
+// SubscribeToAllChannels will receive messages from channels where channel has
+// no parameter on which the app is expecting messages. For channels with parameters,
+// they should be subscribed independently.
+func (c *UserController) SubscribeToAllChannels(ctx context.Context, as UserSubscriber) error {
+	if as == nil {
+		return extensions.ErrNilUserSubscriber
+	}
+
+	if err := c.SubscribeToSendEventOperation(ctx, as.SendEventOperationReceived); err != nil {
+		return err
+	}
+	if err := c.SubscribeToSendOrderOperation(ctx, as.SendOrderOperationReceived); err != nil {
+		return err
+	}
+	if err := c.SubscribeToSendPaymentOperation(ctx, as.SendPaymentOperationReceived); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UnsubscribeFromAllChannels will stop the subscription of all remaining subscribed channels
+func (c *UserController) UnsubscribeFromAllChannels(ctx context.Context) {
+	c.UnsubscribeFromSendEventOperation(ctx)
+	c.UnsubscribeFromSendOrderOperation(ctx)
+	c.UnsubscribeFromSendPaymentOperation(ctx)
+}
+
+// SubscribeToSendEventOperation will receive EventMessageFromEventsChannel messages from Events channel.
+//
+// Callback function 'fn' will be called each time a new message is received.
+//
+// Options (i.e. extensions.WithStartEarliest, extensions.WithQueueGroup) can be
+// given to override the broker controller ones for this subscription.
+func (c *UserController) SubscribeToSendEventOperation(
+	ctx context.Context,
+	fn func(ctx context.Context, msg EventMessageFromEventsChannel) error,
+	opts ...extensions.SubscribeOption,
+) error {
+	// Get channel address
+	addr := "v3.features.keys.events"
+
+	// Set context
+	ctx = addUserContextValues(ctx, addr)
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsDirection, "reception")
+
+	// Lock the subscriptions to avoid conflicts
+	c.subscriptionsMutex.Lock()
+	defer c.subscriptionsMutex.Unlock()
+
+	// Check if the controller is already subscribed
+	_, exists := c.subscriptions[addr]
+	if exists {
+		err := fmt.Errorf("%w: controller is already subscribed on channel %q", extensions.ErrAlreadySubscribedChannel, addr)
+		c.logger.Error(ctx, err.Error())
+		return err
+	}
+
+	// Subscribe to broker channel
+	sub, err := extensions.Subscribe(ctx, c.broker, addr, opts...)
+	if err != nil {
+		c.logger.Error(ctx, err.Error())
+		return err
+	}
+	c.logger.Info(ctx, "Subscribed to channel")
+
+	// Asynchronously listen to new messages and pass them to app receiver
+	listener := extensions.NewListenerTracker()
+	go c.listenToSendEventOperationMessages(ctx, addr, sub, listener, fn)
+
+	// Add the cancel channel to the inside map
+	c.subscriptions[addr] = subscription{broker: sub, listener: listener}
+
+	// Stop the subscription when the context is done, if required
+	if c.autoUnsubscribe {
+		go c.unsubscribeWhenDone(ctx, addr, listener)
+	}
+
+	return nil
+}
+
+func (c *UserController) listenToSendEventOperationMessages(
+	ctx context.Context,
+	addr string,
+	sub extensions.BrokerChannelSubscription,
+	listener *extensions.ListenerTracker,
+	fn func(ctx context.Context, msg EventMessageFromEventsChannel) error,
+) {
+	// Mark the listener as stopped once every message has been processed
+	defer listener.Stopped()
+
+	// Process messages with the configured concurrency, and wait for
+	// the messages being processed before stopping
+	executor := extensions.NewConcurrentExecutor(c.concurrency, c.concurrencyKey)
+	defer executor.Wait()
+
+	for {
+		// Listen to next message
+		stop, err := c.listenToSendEventOperationNextMessage(addr, sub, listener, executor, fn)
+		if err != nil {
+			c.logger.Error(ctx, err.Error())
+		}
+
+		// Stop if required
+		if stop {
+			return
+		}
+	}
+}
+
+func (c *UserController) listenToSendEventOperationNextMessage(
+	addr string,
+	sub extensions.BrokerChannelSubscription,
+	listener *extensions.ListenerTracker,
+	executor *extensions.ConcurrentExecutor,
+	fn func(ctx context.Context, msg EventMessageFromEventsChannel) error,
+) (stop bool, err error) {
+	// Wait for next message
+	acknowledgeableBrokerMessage, open := <-sub.MessagesChannel()
+
+	// If subscription is closed and there is no more message
+	// (i.e. uninitialized message), then exit the function
+	if !open && acknowledgeableBrokerMessage.IsUninitialized() {
+		return true, nil
+	}
+
+	// Get the actual channel address, as the subscription address can
+	// have parameters in case of wildcard subscription
+	if acknowledgeableBrokerMessage.Channel != "" {
+		addr = acknowledgeableBrokerMessage.Channel
+	}
+
+	// Reject the message if the intake has been stopped (i.e. on shutdown),
+	// so it can be processed again later
+	if !listener.MessageReceived() {
+		acknowledgeableBrokerMessage.Nak()
+		return false, nil
+	}
+
+	// Process the message, possibly in parallel of other messages
+	executor.Execute(acknowledgeableBrokerMessage.BrokerMessage, func() {
+		// Register the message as processed once acknowledged
+		defer listener.MessageProcessed()
+
+		// Create a context for the received message, canceled when unsubscribing
+		msgCtx, cancel := context.WithCancel(listener.Context())
+		msgCtx = addUserContextValues(msgCtx, addr)
+		msgCtx = context.WithValue(msgCtx, extensions.ContextKeyIsDirection, "reception")
+		defer cancel()
+
+		// Set broker message to context
+		msgCtx = context.WithValue(msgCtx, extensions.ContextKeyIsBrokerMessage, acknowledgeableBrokerMessage.String())
+
+		// Execute middlewares before handling the message
+		if err := c.executeMiddlewares(msgCtx, &acknowledgeableBrokerMessage.BrokerMessage, func(middlewareCtx context.Context) error {
+			// Process message
+			msg, err := brokerMessageToEventMessageFromEventsChannel(acknowledgeableBrokerMessage.BrokerMessage)
+			if err != nil {
+				return err
+			}
+
+			// Validate message if required
+			if c.validation {
+				if err := msg.Validate(); err != nil {
+					return err
+				}
+			}
+
+			// Execute the subscription function
+			return fn(middlewareCtx, msg)
+		}); err != nil {
+			c.errorHandler(msgCtx, addr, &acknowledgeableBrokerMessage, err)
+			// On error execute the acknowledgeableBrokerMessage nack() function and
+			// let the BrokerAcknowledgment decide what is the right nack behavior for the broker
+			acknowledgeableBrokerMessage.Nak()
+			return
+		}
+
+		// Acknowledge the message once the middlewares and the subscription
+		// function have returned without error, even if a middleware has not
+		// called the next one (i.e. a retry middleware sending it to a
+		// dead-letter channel)
+		acknowledgeableBrokerMessage.Ack()
+	})
+
+	return false, nil
+}
+
+// UnsubscribeFromSendEventOperation will stop the reception of EventMessageFromEventsChannel messages from Events channel.
+// The context given to the messages being processed is canceled.
+// A timeout can be set in context to avoid blocking operation, if needed.
+func (c *UserController) UnsubscribeFromSendEventOperation(
+	ctx context.Context,
+) {
+	// Get channel address
+	addr := "v3.features.keys.events"
+
+	// Check if there receivers for this channel and remove it from the receivers
+	c.subscriptionsMutex.Lock()
+	sub, exists := c.subscriptions[addr]
+	delete(c.subscriptions, addr)
+	c.subscriptionsMutex.Unlock()
+	if !exists {
+		return
+	}
+
+	// Set context
+	ctx = addUserContextValues(ctx, addr)
+
+	// Stop the subscription and cancel the context of the messages being processed
+	sub.listener.CancelProcessing()
+	sub.broker.Cancel(ctx)
+
+	c.logger.Info(ctx, "Unsubscribed from channel")
+} // SubscribeToSendOrderOperation will receive OrderMessageFromOrdersChannel messages from Orders channel.
+// Callback function 'fn' will be called each time a new message is received.
+//
+// Options (i.e. extensions.WithStartEarliest, extensions.WithQueueGroup) can be
+// given to override the broker controller ones for this subscription.
+func (c *UserController) SubscribeToSendOrderOperation(
+	ctx context.Context,
+	fn func(ctx context.Context, msg OrderMessageFromOrdersChannel) error,
+	opts ...extensions.SubscribeOption,
+) error {
+	// Get channel address
+	addr := "v3.features.keys.orders"
+
+	// Set context
+	ctx = addUserContextValues(ctx, addr)
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsDirection, "reception")
+
+	// Lock the subscriptions to avoid conflicts
+	c.subscriptionsMutex.Lock()
+	defer c.subscriptionsMutex.Unlock()
+
+	// Check if the controller is already subscribed
+	_, exists := c.subscriptions[addr]
+	if exists {
+		err := fmt.Errorf("%w: controller is already subscribed on channel %q", extensions.ErrAlreadySubscribedChannel, addr)
+		c.logger.Error(ctx, err.Error())
+		return err
+	}
+
+	// Subscribe to broker channel
+	sub, err := extensions.Subscribe(ctx, c.broker, addr, opts...)
+	if err != nil {
+		c.logger.Error(ctx, err.Error())
+		return err
+	}
+	c.logger.Info(ctx, "Subscribed to channel")
+
+	// Asynchronously listen to new messages and pass them to app receiver
+	listener := extensions.NewListenerTracker()
+	go c.listenToSendOrderOperationMessages(ctx, addr, sub, listener, fn)
+
+	// Add the cancel channel to the inside map
+	c.subscriptions[addr] = subscription{broker: sub, listener: listener}
+
+	// Stop the subscription when the context is done, if required
+	if c.autoUnsubscribe {
+		go c.unsubscribeWhenDone(ctx, addr, listener)
+	}
+
+	return nil
+}
+
+func (c *UserController) listenToSendOrderOperationMessages(
+	ctx context.Context,
+	addr string,
+	sub extensions.BrokerChannelSubscription,
+	listener *extensions.ListenerTracker,
+	fn func(ctx context.Context, msg OrderMessageFromOrdersChannel) error,
+) {
+	// Mark the listener as stopped once every message has been processed
+	defer listener.Stopped()
+
+	// Process messages with the configured concurrency, and wait for
+	// the messages being processed before stopping
+	executor := extensions.NewConcurrentExecutor(c.concurrency, c.concurrencyKey)
+	defer executor.Wait()
+
+	for {
+		// Listen to next message
+		stop, err := c.listenToSendOrderOperationNextMessage(addr, sub, listener, executor, fn)
+		if err != nil {
+			c.logger.Error(ctx, err.Error())
+		}
+
+		// Stop if required
+		if stop {
+			return
+		}
+	}
+}
+
+func (c *UserController) listenToSendOrderOperationNextMessage(
+	addr string,
+	sub extensions.BrokerChannelSubscription,
+	listener *extensions.ListenerTracker,
+	executor *extensions.ConcurrentExecutor,
+	fn func(ctx context.Context, msg OrderMessageFromOrdersChannel) error,
+) (stop bool, err error) {
+	// Wait for next message
+	acknowledgeableBrokerMessage, open := <-sub.MessagesChannel()
+
+	// If subscription is closed and there is no more message
+	// (i.e. uninitialized message), then exit the function
+	if !open && acknowledgeableBrokerMessage.IsUninitialized() {
+		return true, nil
+	}
+
+	// Get the actual channel address, as the subscription address can
+	// have parameters in case of wildcard subscription
+	if acknowledgeableBrokerMessage.Channel != "" {
+		addr = acknowledgeableBrokerMessage.Channel
+	}
+
+	// Reject the message if the intake has been stopped (i.e. on shutdown),
+	// so it can be processed again later
+	if !listener.MessageReceived() {
+		acknowledgeableBrokerMessage.Nak()
+		return false, nil
+	}
+
+	// Process the message, possibly in parallel of other messages
+	executor.Execute(acknowledgeableBrokerMessage.BrokerMessage, func() {
+		// Register the message as processed once acknowledged
+		defer listener.MessageProcessed()
+
+		// Create a context for the received message, canceled when unsubscribing
+		msgCtx, cancel := context.WithCancel(listener.Context())
+		msgCtx = addUserContextValues(msgCtx, addr)
+		msgCtx = context.WithValue(msgCtx, extensions.ContextKeyIsDirection, "reception")
+		defer cancel()
+
+		// Set broker message to context
+		msgCtx = context.WithValue(msgCtx, extensions.ContextKeyIsBrokerMessage, acknowledgeableBrokerMessage.String())
+
+		// Execute middlewares before handling the message
+		if err := c.executeMiddlewares(msgCtx, &acknowledgeableBrokerMessage.BrokerMessage, func(middlewareCtx context.Context) error {
+			// Process message
+			msg, err := brokerMessageToOrderMessageFromOrdersChannel(acknowledgeableBrokerMessage.BrokerMessage)
+			if err != nil {
+				return err
+			}
+
+			// Validate message if required
+			if c.validation {
+				if err := msg.Validate(); err != nil {
+					return err
+				}
+			}
+
+			// Execute the subscription function
+			return fn(middlewareCtx, msg)
+		}); err != nil {
+			c.errorHandler(msgCtx, addr, &acknowledgeableBrokerMessage, err)
+			// On error execute the acknowledgeableBrokerMessage nack() function and
+			// let the BrokerAcknowledgment decide what is the right nack behavior for the broker
+			acknowledgeableBrokerMessage.Nak()
+			return
+		}
+
+		// Acknowledge the message once the middlewares and the subscription
+		// function have returned without error, even if a middleware has not
+		// called the next one (i.e. a retry middleware sending it to a
+		// dead-letter channel)
+		acknowledgeableBrokerMessage.Ack()
+	})
+
+	return false, nil
+}
+
+// UnsubscribeFromSendOrderOperation will stop the reception of OrderMessageFromOrdersChannel messages from Orders channel.
+// The context given to the messages being processed is canceled.
+// A timeout can be set in context to avoid blocking operation, if needed.
+func (c *UserController) UnsubscribeFromSendOrderOperation(
+	ctx context.Context,
+) {
+	// Get channel address
+	addr := "v3.features.keys.orders"
+
+	// Check if there receivers for this channel and remove it from the receivers
+	c.subscriptionsMutex.Lock()
+	sub, exists := c.subscriptions[addr]
+	delete(c.subscriptions, addr)
+	c.subscriptionsMutex.Unlock()
+	if !exists {
+		return
+	}
+
+	// Set context
+	ctx = addUserContextValues(ctx, addr)
+
+	// Stop the subscription and cancel the context of the messages being processed
+	sub.listener.CancelProcessing()
+	sub.broker.Cancel(ctx)
+
+	c.logger.Info(ctx, "Unsubscribed from channel")
+} // SubscribeToSendPaymentOperation will receive PaymentMessageFromPaymentsChannel messages from Payments channel.
+// Callback function 'fn' will be called each time a new message is received.
+//
+// Options (i.e. extensions.WithStartEarliest, extensions.WithQueueGroup) can be
+// given to override the broker controller ones for this subscription.
+func (c *UserController) SubscribeToSendPaymentOperation(
+	ctx context.Context,
+	fn func(ctx context.Context, msg PaymentMessageFromPaymentsChannel) error,
+	opts ...extensions.SubscribeOption,
+) error {
+	// Get channel address
+	addr := "v3.features.keys.payments"
+
+	// Set context
+	ctx = addUserContextValues(ctx, addr)
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsDirection, "reception")
+
+	// Lock the subscriptions to avoid conflicts
+	c.subscriptionsMutex.Lock()
+	defer c.subscriptionsMutex.Unlock()
+
+	// Check if the controller is already subscribed
+	_, exists := c.subscriptions[addr]
+	if exists {
+		err := fmt.Errorf("%w: controller is already subscribed on channel %q", extensions.ErrAlreadySubscribedChannel, addr)
+		c.logger.Error(ctx, err.Error())
+		return err
+	}
+
+	// Subscribe to broker channel
+	sub, err := extensions.Subscribe(ctx, c.broker, addr, opts...)
+	if err != nil {
+		c.logger.Error(ctx, err.Error())
+		return err
+	}
+	c.logger.Info(ctx, "Subscribed to channel")
+
+	// Asynchronously listen to new messages and pass them to app receiver
+	listener := extensions.NewListenerTracker()
+	go c.listenToSendPaymentOperationMessages(ctx, addr, sub, listener, fn)
+
+	// Add the cancel channel to the inside map
+	c.subscriptions[addr] = subscription{broker: sub, listener: listener}
+
+	// Stop the subscription when the context is done, if required
+	if c.autoUnsubscribe {
+		go c.unsubscribeWhenDone(ctx, addr, listener)
+	}
+
+	return nil
+}
+
+func (c *UserController) listenToSendPaymentOperationMessages(
+	ctx context.Context,
+	addr string,
+	sub extensions.BrokerChannelSubscription,
+	listener *extensions.ListenerTracker,
+	fn func(ctx context.Context, msg PaymentMessageFromPaymentsChannel) error,
+) {
+	// Mark the listener as stopped once every message has been processed
+	defer listener.Stopped()
+
+	// Process messages with the configured concurrency, and wait for
+	// the messages being processed before stopping
+	executor := extensions.NewConcurrentExecutor(c.concurrency, c.concurrencyKey)
+	defer executor.Wait()
+
+	for {
+		// Listen to next message
+		stop, err := c.listenToSendPaymentOperationNextMessage(addr, sub, listener, executor, fn)
+		if err != nil {
+			c.logger.Error(ctx, err.Error())
+		}
+
+		// Stop if required
+		if stop {
+			return
+		}
+	}
+}
+
+func (c *UserController) listenToSendPaymentOperationNextMessage(
+	addr string,
+	sub extensions.BrokerChannelSubscription,
+	listener *extensions.ListenerTracker,
+	executor *extensions.ConcurrentExecutor,
+	fn func(ctx context.Context, msg PaymentMessageFromPaymentsChannel) error,
+) (stop bool, err error) {
+	// Wait for next message
+	acknowledgeableBrokerMessage, open := <-sub.MessagesChannel()
+
+	// If subscription is closed and there is no more message
+	// (i.e. uninitialized message), then exit the function
+	if !open && acknowledgeableBrokerMessage.IsUninitialized() {
+		return true, nil
+	}
+
+	// Get the actual channel address, as the subscription address can
+	// have parameters in case of wildcard subscription
+	if acknowledgeableBrokerMessage.Channel != "" {
+		addr = acknowledgeableBrokerMessage.Channel
+	}
+
+	// Reject the message if the intake has been stopped (i.e. on shutdown),
+	// so it can be processed again later
+	if !listener.MessageReceived() {
+		acknowledgeableBrokerMessage.Nak()
+		return false, nil
+	}
+
+	// Process the message, possibly in parallel of other messages
+	executor.Execute(acknowledgeableBrokerMessage.BrokerMessage, func() {
+		// Register the message as processed once acknowledged
+		defer listener.MessageProcessed()
+
+		// Create a context for the received message, canceled when unsubscribing
+		msgCtx, cancel := context.WithCancel(listener.Context())
+		msgCtx = addUserContextValues(msgCtx, addr)
+		msgCtx = context.WithValue(msgCtx, extensions.ContextKeyIsDirection, "reception")
+		defer cancel()
+
+		// Set broker message to context
+		msgCtx = context.WithValue(msgCtx, extensions.ContextKeyIsBrokerMessage, acknowledgeableBrokerMessage.String())
+
+		// Execute middlewares before handling the message
+		if err := c.executeMiddlewares(msgCtx, &acknowledgeableBrokerMessage.BrokerMessage, func(middlewareCtx context.Context) error {
+			// Process message
+			msg, err := brokerMessageToPaymentMessageFromPaymentsChannel(acknowledgeableBrokerMessage.BrokerMessage)
+			if err != nil {
+				return err
+			}
+
+			// Validate message if required
+			if c.validation {
+				if err := msg.Validate(); err != nil {
+					return err
+				}
+			}
+
+			// Execute the subscription function
+			return fn(middlewareCtx, msg)
+		}); err != nil {
+			c.errorHandler(msgCtx, addr, &acknowledgeableBrokerMessage, err)
+			// On error execute the acknowledgeableBrokerMessage nack() function and
+			// let the BrokerAcknowledgment decide what is the right nack behavior for the broker
+			acknowledgeableBrokerMessage.Nak()
+			return
+		}
+
+		// Acknowledge the message once the middlewares and the subscription
+		// function have returned without error, even if a middleware has not
+		// called the next one (i.e. a retry middleware sending it to a
+		// dead-letter channel)
+		acknowledgeableBrokerMessage.Ack()
+	})
+
+	return false, nil
+}
+
+// UnsubscribeFromSendPaymentOperation will stop the reception of PaymentMessageFromPaymentsChannel messages from Payments channel.
+// The context given to the messages being processed is canceled.
+// A timeout can be set in context to avoid blocking operation, if needed.
+func (c *UserController) UnsubscribeFromSendPaymentOperation(
+	ctx context.Context,
+) {
+	// Get channel address
+	addr := "v3.features.keys.payments"
+
+	// Check if there receivers for this channel and remove it from the receivers
+	c.subscriptionsMutex.Lock()
+	sub, exists := c.subscriptions[addr]
+	delete(c.subscriptions, addr)
+	c.subscriptionsMutex.Unlock()
+	if !exists {
+		return
+	}
+
+	// Set context
+	ctx = addUserContextValues(ctx, addr)
+
+	// Stop the subscription and cancel the context of the messages being processed
+	sub.listener.CancelProcessing()
+	sub.broker.Cancel(ctx)
+
+	c.logger.Info(ctx, "Unsubscribed from channel")
+}
+
+// AsyncAPIVersion is the version of the used AsyncAPI document
+const AsyncAPIVersion = "1.2.3"
+
+// controller is the controller that will be used to communicate with the broker
+// It will be used internally by AppController and UserController
+type controller struct {
+	// broker is the broker controller that will be used to communicate
+	broker extensions.BrokerController
+	// subscriptions is a map of all subscriptions
+	subscriptions map[string]subscription
+	// subscriptionsMutex protects the subscriptions map
+	subscriptionsMutex sync.Mutex
+	// logger is the logger that will be used² to log operations on controller
+	logger extensions.Logger
+	// middlewares are the middlewares that will be executed when sending or
+	// receiving messages
+	middlewares []extensions.Middleware
+	// handler to handle errors from consumers and middlewares
+	errorHandler extensions.ErrorHandler
+	// validation is set if messages should be validated against the constraints
+	// of the AsyncAPI specification
+	validation bool
+	// concurrency is the maximum number of messages processed in parallel
+	// for each subscription
+	concurrency int
+	// concurrencyKey is the key extractor used to process messages with the
+	// same key in order when processing them in parallel
+	concurrencyKey extensions.KeyExtractor
+	// autoUnsubscribe is set if subscriptions should be stopped when the
+	// context given on subscription is done
+	autoUnsubscribe bool
+}
+
+// subscription is a subscription to a broker channel, with the tracker of the
+// messages being processed by its listener
+type subscription struct {
+	broker   extensions.BrokerChannelSubscription
+	listener *extensions.ListenerTracker
+}
+
+// ControllerOption is the type of the options that can be passed
+// when creating a new Controller
+type ControllerOption func(controller *controller)
+
+// WithLogger attaches a logger to the controller
+func WithLogger(logger extensions.Logger) ControllerOption {
+	return func(controller *controller) {
+		controller.logger = logger
+	}
+}
+
+// WithMiddlewares attaches middlewares that will be executed when sending or receiving messages
+func WithMiddlewares(middlewares ...extensions.Middleware) ControllerOption {
+	return func(controller *controller) {
+		controller.middlewares = middlewares
+	}
+}
+
+// WithErrorHandler attaches a errorhandler to handle errors from subscriber functions
+func WithErrorHandler(handler extensions.ErrorHandler) ControllerOption {
+	return func(controller *controller) {
+		controller.errorHandler = handler
+	}
+}
+
+// WithValidation enables the validation of messages against the constraints of
+// the AsyncAPI specification: invalid messages are rejected when sent, and
+// given to the error handler instead of the subscriber when received.
+func WithValidation() ControllerOption {
+	return func(controller *controller) {
+		controller.validation = true
+	}
+}
+
+// WithConcurrency sets the maximum number of messages processed in parallel for
+// each subscription (default is 1). If a key extractor is given (i.e.
+// extensions.KeyFromHeader), messages with the same key are processed
+// sequentially, in the order they were received.
+func WithConcurrency(n int, key ...extensions.KeyExtractor) ControllerOption {
+	return func(controller *controller) {
+		controller.concurrency = n
+		if len(key) > 0 {
+			controller.concurrencyKey = key[0]
+		}
+	}
+}
+
+// WithAutoUnsubscribe stops a subscription when the context given on
+// subscription is done, as if the corresponding unsubscribe function was called.
+func WithAutoUnsubscribe() ControllerOption {
+	return func(controller *controller) {
+		controller.autoUnsubscribe = true
+	}
+}
+
+// unsubscribeWhenDone stops the subscription on the channel when the context is
+// done, unless its listener has been stopped before (i.e. unsubscribed).
+func (c *controller) unsubscribeWhenDone(ctx context.Context, addr string, listener *extensions.ListenerTracker) {
+	// Wait for the context to be done or the listener to be stopped
+	if err := listener.WaitStopped(ctx); err == nil {
+		return
+	}
+
+	// Remove the subscription if it is still the same
+	c.subscriptionsMutex.Lock()
+	sub, exists := c.subscriptions[addr]
+	if !exists || sub.listener != listener {
+		c.subscriptionsMutex.Unlock()
+		return
+	}
+	delete(c.subscriptions, addr)
+	c.subscriptionsMutex.Unlock()
+
+	// Stop the subscription, waiting for the broker clean up even if the
+	// context is done, and cancel the context of the messages being processed
+	sub.listener.CancelProcessing()
+	sub.broker.Cancel(context.WithoutCancel(ctx))
+
+	c.logger.Info(ctx, "Unsubscribed from channel as context is done")
+}
+
+type MessageWithCorrelationID interface {
+	CorrelationID() string
+	SetCorrelationID(id string)
+}
+
+type Error struct {
+	Channel string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("channel %q: err %v", e.Channel, e.Err)
+}
+
+// EventMessageFromEventsChannelPayload is a schema from the AsyncAPI specification required in messages
+type EventMessageFromEventsChannelPayload struct {
+	Sequence int64 `json:"sequence"`
+}
+
+// Validate checks that EventMessageFromEventsChannelPayload respects the constraints of the AsyncAPI specification.
+func (s EventMessageFromEventsChannelPayload) Validate() error {
+	return nil
+}
+
+// EventMessageFromEventsChannel is the message expected for 'EventMessageFromEventsChannel' channel.
+type EventMessageFromEventsChannel struct {
+	// Payload will be inserted in the message payload
+	Payload EventMessageFromEventsChannelPayload
+}
+
+func NewEventMessageFromEventsChannel() EventMessageFromEventsChannel {
+	var msg EventMessageFromEventsChannel
+
+	return msg
+}
+
+// brokerMessageToEventMessageFromEventsChannel will fill a new EventMessageFromEventsChannel with data from generic broker message
+func brokerMessageToEventMessageFromEventsChannel(bMsg extensions.BrokerMessage) (EventMessageFromEventsChannel, error) {
+	var msg EventMessageFromEventsChannel
+
+	// Get codec from the message content type, or from the one in specification
+	codec, err := extensions.CodecForBrokerMessage(bMsg, "application/json")
+	if err != nil {
+		return msg, err
+	}
+
+	// Unmarshal payload to expected message payload format
+	err = codec.Unmarshal(bMsg.Payload, &msg.Payload)
+	if err != nil {
+		return msg, err
+	}
+
+	return msg, nil
+}
+
+// toBrokerMessage will generate a generic broker message from EventMessageFromEventsChannel data
+func (msg EventMessageFromEventsChannel) toBrokerMessage() (extensions.BrokerMessage, error) {
+
+	// Get codec from the content type in specification
+	codec := extensions.SpecificationCodec("application/json")
+
+	// Marshal payload with the codec
+	payload, err := codec.Marshal(msg.Payload)
+	if err != nil {
+		return extensions.BrokerMessage{}, err
+	}
+
+	// There is no headers here
+	headers := make(map[string][]byte, 0)
+
+	// Set content type, so the receiver can decode the payload
+	headers[extensions.ContentTypeHeader] = []byte("application/json")
+
+	// Set key from '$message.payload#/sequence', so messages with the same
+	// key keep their order on brokers supporting it
+	key := []byte(fmt.Sprint(msg.Payload.Sequence))
+
+	return extensions.BrokerMessage{
+		Headers: headers,
+		Payload: payload,
+		Key:     key,
+	}, nil
+}
+
+// Validate checks that EventMessageFromEventsChannel respects the constraints of the AsyncAPI specification.
+func (msg EventMessageFromEventsChannel) Validate() error {
+	if err := msg.Payload.Validate(); err != nil {
+		return extensions.WithValidationField("payload", err)
+	}
+	return nil
+}
+
+// OrderMessageFromOrdersChannelPayload is a schema from the AsyncAPI specification required in messages
+type OrderMessageFromOrdersChannelPayload struct {
+	Amount  *int64 `json:"amount,omitempty"`
+	OrderId string `json:"orderId"`
+}
+
+// Validate checks that OrderMessageFromOrdersChannelPayload respects the constraints of the AsyncAPI specification.
+func (s OrderMessageFromOrdersChannelPayload) Validate() error {
+	return nil
+}
+
+// OrderMessageFromOrdersChannel is the message expected for 'OrderMessageFromOrdersChannel' channel.
+type OrderMessageFromOrdersChannel struct {
+	// Payload will be inserted in the message payload
+	Payload OrderMessageFromOrdersChannelPayload
+}
+
+func NewOrderMessageFromOrdersChannel() OrderMessageFromOrdersChannel {
+	var msg OrderMessageFromOrdersChannel
+
+	return msg
+}
+
+// brokerMessageToOrderMessageFromOrdersChannel will fill a new OrderMessageFromOrdersChannel with data from generic broker message
+func brokerMessageToOrderMessageFromOrdersChannel(bMsg extensions.BrokerMessage) (OrderMessageFromOrdersChannel, error) {
+	var msg OrderMessageFromOrdersChannel
+
+	// Get codec from the message content type, or from the one in specification
+	codec, err := extensions.CodecForBrokerMessage(bMsg, "application/json")
+	if err != nil {
+		return msg, err
+	}
+
+	// Unmarshal payload to expected message payload format
+	err = codec.Unmarshal(bMsg.Payload, &msg.Payload)
+	if err != nil {
+		return msg, err
+	}
+
+	return msg, nil
+}
+
+// toBrokerMessage will generate a generic broker message from OrderMessageFromOrdersChannel data
+func (msg OrderMessageFromOrdersChannel) toBrokerMessage() (extensions.BrokerMessage, error) {
+
+	// Get codec from the content type in specification
+	codec := extensions.SpecificationCodec("application/json")
+
+	// Marshal payload with the codec
+	payload, err := codec.Marshal(msg.Payload)
+	if err != nil {
+		return extensions.BrokerMessage{}, err
+	}
+
+	// There is no headers here
+	headers := make(map[string][]byte, 0)
+
+	// Set content type, so the receiver can decode the payload
+	headers[extensions.ContentTypeHeader] = []byte("application/json")
+
+	// Set key from '$message.payload#/orderId', so messages with the same
+	// key keep their order on brokers supporting it
+	key := []byte(fmt.Sprint(msg.Payload.OrderId))
+
+	return extensions.BrokerMessage{
+		Headers: headers,
+		Payload: payload,
+		Key:     key,
+	}, nil
+}
+
+// Validate checks that OrderMessageFromOrdersChannel respects the constraints of the AsyncAPI specification.
+func (msg OrderMessageFromOrdersChannel) Validate() error {
+	if err := msg.Payload.Validate(); err != nil {
+		return extensions.WithValidationField("payload", err)
+	}
+	return nil
+}
+
+// HeadersFromPaymentMessageFromPaymentsChannel is a schema from the AsyncAPI specification required in messages
+type HeadersFromPaymentMessageFromPaymentsChannel struct {
+	AccountId *string `json:"accountId,omitempty"`
+}
+
+// Validate checks that HeadersFromPaymentMessageFromPaymentsChannel respects the constraints of the AsyncAPI specification.
+func (s HeadersFromPaymentMessageFromPaymentsChannel) Validate() error {
+	return nil
+}
+
+// PaymentMessageFromPaymentsChannelPayload is a schema from the AsyncAPI specification required in messages
+type PaymentMessageFromPaymentsChannelPayload struct {
+	Amount *int64 `json:"amount,omitempty"`
+}
+
+// Validate checks that PaymentMessageFromPaymentsChannelPayload respects the constraints of the AsyncAPI specification.
+func (s PaymentMessageFromPaymentsChannelPayload) Validate() error {
+	return nil
+}
+
+// PaymentMessageFromPaymentsChannel is the message expected for 'PaymentMessageFromPaymentsChannel' channel.
+type PaymentMessageFromPaymentsChannel struct {
+	// Headers will be used to fill the message headers
+	Headers HeadersFromPaymentMessageFromPaymentsChannel
+
+	// Payload will be inserted in the message payload
+	Payload PaymentMessageFromPaymentsChannelPayload
+}
+
+func NewPaymentMessageFromPaymentsChannel() PaymentMessageFromPaymentsChannel {
+	var msg PaymentMessageFromPaymentsChannel
+
+	return msg
+}
+
+// brokerMessageToPaymentMessageFromPaymentsChannel will fill a new PaymentMessageFromPaymentsChannel with data from generic broker message
+func brokerMessageToPaymentMessageFromPaymentsChannel(bMsg extensions.BrokerMessage) (PaymentMessageFromPaymentsChannel, error) {
+	var msg PaymentMessageFromPaymentsChannel
+
+	// Get codec from the message content type, or from the one in specification
+	codec, err := extensions.CodecForBrokerMessage(bMsg, "application/json")
+	if err != nil {
+		return msg, err
+	}
+
+	// Unmarshal payload to expected message payload format
+	err = codec.Unmarshal(bMsg.Payload, &msg.Payload)
+	if err != nil {
+		return msg, err
+	}
+
+	// Get each headers from broker message
+	for k, v := range bMsg.Headers {
+		switch {
+		case k == "accountId": // Retrieving AccountId header
+			h := string(v)
+			msg.Headers.AccountId = &h
+		default:
+			// TODO: log unknown error
+		}
+	}
+
+	return msg, nil
+}
+
+// toBrokerMessage will generate a generic broker message from PaymentMessageFromPaymentsChannel data
+func (msg PaymentMessageFromPaymentsChannel) toBrokerMessage() (extensions.BrokerMessage, error) {
+
+	// Get codec from the content type in specification
+	codec := extensions.SpecificationCodec("application/json")
+
+	// Marshal payload with the codec
+	payload, err := codec.Marshal(msg.Payload)
+	if err != nil {
+		return extensions.BrokerMessage{}, err
+	}
+
+	// Add each headers to broker message
+	headers := make(map[string][]byte, 1)
+
+	// Adding AccountId header
+	if msg.Headers.AccountId != nil {
+		headers["accountId"] = []byte(*msg.Headers.AccountId)
+	}
+
+	// Set content type, so the receiver can decode the payload
+	headers[extensions.ContentTypeHeader] = []byte("application/json")
+
+	// Set key from '$message.header#/accountId', so messages with the same
+	// key keep their order on brokers supporting it
+	var key []byte
+	if msg.Headers.AccountId != nil {
+		key = []byte(fmt.Sprint(*msg.Headers.AccountId))
+	}
+
+	return extensions.BrokerMessage{
+		Headers: headers,
+		Payload: payload,
+		Key:     key,
+	}, nil
+}
+
+// Validate checks that PaymentMessageFromPaymentsChannel respects the constraints of the AsyncAPI specification.
+func (msg PaymentMessageFromPaymentsChannel) Validate() error {
+	if err := msg.Headers.Validate(); err != nil {
+		return extensions.WithValidationField("headers", err)
+	}
+	if err := msg.Payload.Validate(); err != nil {
+		return extensions.WithValidationField("payload", err)
+	}
+	return nil
+}
+
+const (
+	// EventsChannelPath is the constant representing the 'EventsChannel' channel path.
+	EventsChannelPath = "v3.features.keys.events"
+	// OrdersChannelPath is the constant representing the 'OrdersChannel' channel path.
+	OrdersChannelPath = "v3.features.keys.orders"
+	// PaymentsChannelPath is the constant representing the 'PaymentsChannel' channel path.
+	PaymentsChannelPath = "v3.features.keys.payments"
+)
+
+// ChannelsPaths is an array of all channels paths
+var ChannelsPaths = []string{
+	EventsChannelPath,
+	OrdersChannelPath,
+	PaymentsChannelPath,
+}
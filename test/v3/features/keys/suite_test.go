@@ -0,0 +1,81 @@
+//go:generate go run ../../../../cmd/asyncapi-codegen -p keys -i ./asyncapi.yaml -o ./asyncapi.gen.go
+
+package keys
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers/inmemory"
+	"github.com/lerenn/asyncapi-codegen/pkg/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, NewSuite())
+}
+
+type Suite struct {
+	suite.Suite
+}
+
+func NewSuite() *Suite {
+	return &Suite{}
+}
+
+func (suite *Suite) TestKeyFromExtension() {
+	msg := NewOrderMessageFromOrdersChannel()
+	msg.Payload.OrderId = "order-1234"
+
+	bm, err := msg.toBrokerMessage()
+	suite.Require().NoError(err)
+	suite.Require().Equal([]byte("order-1234"), bm.Key)
+}
+
+func (suite *Suite) TestKeyFromKafkaBinding() {
+	msg := NewPaymentMessageFromPaymentsChannel()
+
+	// No key if the header is not set
+	bm, err := msg.toBrokerMessage()
+	suite.Require().NoError(err)
+	suite.Require().Nil(bm.Key)
+
+	msg.Headers.AccountId = utils.ToPointer("account-42")
+	bm, err = msg.toBrokerMessage()
+	suite.Require().NoError(err)
+	suite.Require().Equal([]byte("account-42"), bm.Key)
+}
+
+func (suite *Suite) TestKeyFromTrait() {
+	msg := NewEventMessageFromEventsChannel()
+	msg.Payload.Sequence = 42
+
+	bm, err := msg.toBrokerMessage()
+	suite.Require().NoError(err)
+	suite.Require().Equal([]byte("42"), bm.Key)
+}
+
+func (suite *Suite) TestKeyIsPublished() {
+	broker, err := inmemory.NewController()
+	suite.Require().NoError(err)
+
+	app, err := NewAppController(broker)
+	suite.Require().NoError(err)
+	defer app.Close(context.Background())
+
+	sub, err := broker.Subscribe(context.Background(), OrdersChannelPath)
+	suite.Require().NoError(err)
+	defer sub.Cancel(context.Background())
+
+	msg := NewOrderMessageFromOrdersChannel()
+	msg.Payload.OrderId = "order-1234"
+	suite.Require().NoError(app.SendAsSendOrderOperation(context.Background(), msg))
+
+	select {
+	case received := <-sub.MessagesChannel():
+		suite.Require().Equal([]byte("order-1234"), received.Key)
+	case <-time.After(time.Second):
+		suite.Require().FailNow("no message received")
+	}
+}
@@ -0,0 +1,2044 @@
+// Package "mocks" provides primitives to interact with the AsyncAPI specification.
+//
+// Code generated by github.com/lerenn/asyncapi-codegen version v0.45.1 DO NOT EDIT.
+package mocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+
+	"github.com/google/uuid"
+)
+
+// AppSubscriber contains all handlers that are listening messages for App
+type AppSubscriber interface {
+	// ReceivePingOperationReceived receive all Ping messages from Ping channel.
+	ReceivePingOperationReceived(ctx context.Context, msg PingMessage) error
+}
+
+// AppController is the structure that provides sending capabilities to the
+// developer and and connect the broker with the App
+type AppController struct {
+	controller
+}
+
+// NewAppController links the App to the broker
+func NewAppController(bc extensions.BrokerController, options ...ControllerOption) (*AppController, error) {
+	// Check if broker controller has been provided
+	if bc == nil {
+		return nil, extensions.ErrNilBrokerController
+	}
+
+	// Create default controller
+	c := &AppController{controller: controller{
+		broker:        bc,
+		subscriptions: make(map[string]subscription),
+		logger:        extensions.DummyLogger{},
+		middlewares:   make([]extensions.Middleware, 0),
+		errorHandler:  extensions.DefaultErrorHandler(),
+		concurrency:   1,
+	}}
+
+	// Apply options
+	for _, option := range options {
+		option(&c.controller)
+	}
+
+	return c, nil
+}
+
+func (c *AppController) wrapMiddlewares(
+	middlewares []extensions.Middleware,
+	callback extensions.NextMiddleware,
+) func(ctx context.Context, msg *extensions.BrokerMessage) error {
+	// If there is no more middleware
+	if len(middlewares) == 0 {
+		return func(ctx context.Context, msg *extensions.BrokerMessage) error {
+			// Call the callback if it exists
+			if callback != nil {
+				return callback(ctx)
+			}
+
+			return nil
+		}
+	}
+
+	// Get the next function to call from next middlewares or callback
+	next := c.wrapMiddlewares(middlewares[1:], callback)
+
+	// Wrap middleware into a function that will execute the middleware and call
+	// the next wrapped middleware if the middleware has not called it already
+	return func(ctx context.Context, msg *extensions.BrokerMessage) error {
+		// Create the next call with the context and the message. It can be
+		// called several times by the middleware (i.e. to retry the processing).
+		var called bool
+		nextWithArgs := func(ctx context.Context) error {
+			called = true
+			return next(ctx, msg)
+		}
+
+		// Call the middleware
+		if err := middlewares[0](ctx, msg, nextWithArgs); err != nil {
+			return err
+		}
+
+		// If next has already been called in middleware, it should not be executed again
+		if called {
+			return nil
+		}
+		return nextWithArgs(ctx)
+	}
+}
+
+func (c *AppController) executeMiddlewares(ctx context.Context, msg *extensions.BrokerMessage, callback extensions.NextMiddleware) error {
+	// Wrap middleware to have 'next' function when calling them
+	wrapped := c.wrapMiddlewares(c.middlewares, callback)
+
+	// Execute wrapped middlewares
+	return wrapped(ctx, msg)
+}
+
+func addAppContextValues(ctx context.Context, addr string) context.Context {
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsVersion, "1.2.3")
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsProvider, "app")
+	return context.WithValue(ctx, extensions.ContextKeyIsChannel, addr)
+}
+
+// Close will clean up any existing resources on the controller
+func (c *AppController) Close(ctx context.Context) {
+	// Unsubscribing remaining channels
+	c.UnsubscribeFromAllChannels(ctx)
+
+	c.logger.Info(ctx, "Closed app controller")
+}
+
+// Shutdown will gracefully stop the controller: it stops the intake of new
+// messages, and waits for the messages being processed to be acknowledged
+// before stopping the subscriptions, until the context is done.
+//
+// Messages received after the intake has been stopped are rejected (Nak) in
+// order to be processed again later, possibly by another instance. An error
+// wrapping extensions.ErrSubscriptionNotDrained is returned for each
+// subscription that still has messages being processed when the context is
+// done, and the context given to these messages is canceled.
+func (c *AppController) Shutdown(ctx context.Context) error {
+	// Take every subscription from the controller
+	c.subscriptionsMutex.Lock()
+	subscriptions := c.subscriptions
+	c.subscriptions = make(map[string]subscription)
+	c.subscriptionsMutex.Unlock()
+
+	// Stop the intake of new messages on every subscription
+	for _, sub := range subscriptions {
+		sub.listener.StopIntake()
+	}
+
+	var errs []error
+	for addr, sub := range subscriptions {
+		// Set context
+		subCtx := addAppContextValues(ctx, addr)
+
+		// Wait for the messages being processed, so they can still be
+		// acknowledged on the subscription, and cancel the context of the
+		// ones still being processed when the context is done (a timeout is
+		// reported below)
+		if err := sub.listener.Drain(subCtx); err != nil {
+			sub.listener.CancelProcessing()
+		}
+
+		// Stop the subscription and wait for its listener
+		sub.broker.Cancel(subCtx)
+		if err := sub.listener.WaitStopped(subCtx); err != nil {
+			err = fmt.Errorf("%w: %d messages still being processed on channel %q",
+				extensions.ErrSubscriptionNotDrained, sub.listener.InFlight(), addr)
+			c.logger.Error(subCtx, err.Error())
+			errs = append(errs, err)
+		}
+	}
+
+	c.logger.Info(ctx, "Shut down app controller")
+
+	return errors.Join(errs...)
+}
+
+// SubscribeToAllChannels will receive messages from channels where channel has
+// no parameter on which the app is expecting messages. For channels with parameters,
+// they should be subscribed independently.
+func (c *AppController) SubscribeToAllChannels(ctx context.Context, as AppSubscriber) error {
+	if as == nil {
+		return extensions.ErrNilAppSubscriber
+	}
+
+	if err := c.SubscribeToReceivePingOperation(ctx, as.ReceivePingOperationReceived); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UnsubscribeFromAllChannels will stop the subscription of all remaining subscribed channels
+func (c *AppController) UnsubscribeFromAllChannels(ctx context.Context) {
+	c.UnsubscribeFromReceivePingOperation(ctx)
+}
+
+// SubscribeToReceivePingOperation will receive Ping messages from Ping channel.
+//
+// Callback function 'fn' will be called each time a new message is received.
+//
+// Options (i.e. extensions.WithStartEarliest, extensions.WithQueueGroup) can be
+// given to override the broker controller ones for this subscription.
+func (c *AppController) SubscribeToReceivePingOperation(
+	ctx context.Context,
+	fn func(ctx context.Context, msg PingMessage) error,
+	opts ...extensions.SubscribeOption,
+) error {
+	// Get channel address
+	addr := "v3.features.mocks.ping"
+
+	// Set context
+	ctx = addAppContextValues(ctx, addr)
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsDirection, "reception")
+
+	// Lock the subscriptions to avoid conflicts
+	c.subscriptionsMutex.Lock()
+	defer c.subscriptionsMutex.Unlock()
+
+	// Check if the controller is already subscribed
+	_, exists := c.subscriptions[addr]
+	if exists {
+		err := fmt.Errorf("%w: controller is already subscribed on channel %q", extensions.ErrAlreadySubscribedChannel, addr)
+		c.logger.Error(ctx, err.Error())
+		return err
+	}
+
+	// Subscribe to broker channel
+	sub, err := extensions.Subscribe(ctx, c.broker, addr, opts...)
+	if err != nil {
+		c.logger.Error(ctx, err.Error())
+		return err
+	}
+	c.logger.Info(ctx, "Subscribed to channel")
+
+	// Asynchronously listen to new messages and pass them to app receiver
+	listener := extensions.NewListenerTracker()
+	go c.listenToReceivePingOperationMessages(ctx, addr, sub, listener, fn)
+
+	// Add the cancel channel to the inside map
+	c.subscriptions[addr] = subscription{broker: sub, listener: listener}
+
+	// Stop the subscription when the context is done, if required
+	if c.autoUnsubscribe {
+		go c.unsubscribeWhenDone(ctx, addr, listener)
+	}
+
+	return nil
+}
+
+func (c *AppController) listenToReceivePingOperationMessages(
+	ctx context.Context,
+	addr string,
+	sub extensions.BrokerChannelSubscription,
+	listener *extensions.ListenerTracker,
+	fn func(ctx context.Context, msg PingMessage) error,
+) {
+	// Mark the listener as stopped once every message has been processed
+	defer listener.Stopped()
+
+	// Process messages with the configured concurrency, and wait for
+	// the messages being processed before stopping
+	executor := extensions.NewConcurrentExecutor(c.concurrency, c.concurrencyKey)
+	defer executor.Wait()
+
+	for {
+		// Listen to next message
+		stop, err := c.listenToReceivePingOperationNextMessage(addr, sub, listener, executor, fn)
+		if err != nil {
+			c.logger.Error(ctx, err.Error())
+		}
+
+		// Stop if required
+		if stop {
+			return
+		}
+	}
+}
+
+func (c *AppController) listenToReceivePingOperationNextMessage(
+	addr string,
+	sub extensions.BrokerChannelSubscription,
+	listener *extensions.ListenerTracker,
+	executor *extensions.ConcurrentExecutor,
+	fn func(ctx context.Context, msg PingMessage) error,
+) (stop bool, err error) {
+	// Wait for next message
+	acknowledgeableBrokerMessage, open := <-sub.MessagesChannel()
+
+	// If subscription is closed and there is no more message
+	// (i.e. uninitialized message), then exit the function
+	if !open && acknowledgeableBrokerMessage.IsUninitialized() {
+		return true, nil
+	}
+
+	// Get the actual channel address, as the subscription address can
+	// have parameters in case of wildcard subscription
+	if acknowledgeableBrokerMessage.Channel != "" {
+		addr = acknowledgeableBrokerMessage.Channel
+	}
+
+	// Reject the message if the intake has been stopped (i.e. on shutdown),
+	// so it can be processed again later
+	if !listener.MessageReceived() {
+		acknowledgeableBrokerMessage.Nak()
+		return false, nil
+	}
+
+	// Process the message, possibly in parallel of other messages
+	executor.Execute(acknowledgeableBrokerMessage.BrokerMessage, func() {
+		// Register the message as processed once acknowledged
+		defer listener.MessageProcessed()
+
+		// Create a context for the received message, canceled when unsubscribing
+		msgCtx, cancel := context.WithCancel(listener.Context())
+		msgCtx = addAppContextValues(msgCtx, addr)
+		msgCtx = context.WithValue(msgCtx, extensions.ContextKeyIsDirection, "reception")
+		defer cancel()
+
+		// Set broker message to context
+		msgCtx = context.WithValue(msgCtx, extensions.ContextKeyIsBrokerMessage, acknowledgeableBrokerMessage.String())
+
+		// Execute middlewares before handling the message
+		if err := c.executeMiddlewares(msgCtx, &acknowledgeableBrokerMessage.BrokerMessage, func(middlewareCtx context.Context) error {
+			// Process message
+			msg, err := brokerMessageToPingMessage(acknowledgeableBrokerMessage.BrokerMessage)
+			if err != nil {
+				return err
+			}
+
+			// Validate message if required
+			if c.validation {
+				if err := msg.Validate(); err != nil {
+					return err
+				}
+			}
+
+			// Add correlation ID to context if it exists
+			if id := msg.CorrelationID(); id != "" {
+				middlewareCtx = context.WithValue(middlewareCtx, extensions.ContextKeyIsCorrelationID, id)
+			}
+
+			// Execute the subscription function
+			return fn(middlewareCtx, msg)
+		}); err != nil {
+			c.errorHandler(msgCtx, addr, &acknowledgeableBrokerMessage, err)
+			// On error execute the acknowledgeableBrokerMessage nack() function and
+			// let the BrokerAcknowledgment decide what is the right nack behavior for the broker
+			acknowledgeableBrokerMessage.Nak()
+			return
+		}
+
+		// Acknowledge the message once the middlewares and the subscription
+		// function have returned without error, even if a middleware has not
+		// called the next one (i.e. a retry middleware sending it to a
+		// dead-letter channel)
+		acknowledgeableBrokerMessage.Ack()
+	})
+
+	return false, nil
+}
+
+// ReplyToReceivePingOperation is a helper function to
+// reply to a Ping message with a Pong message on Pong channel.
+func (c *AppController) ReplyToReceivePingOperation(ctx context.Context, recvMsg PingMessage, fn func(replyMsg *PongMessage)) error {
+	// Create reply message
+	replyMsg := NewPongMessage()
+	replyMsg.SetAsResponseFrom(&recvMsg)
+
+	// Execute callback function
+	fn(&replyMsg)
+
+	// Publish reply
+	return c.SendAsReplyToReceivePingOperation(ctx, replyMsg)
+}
+
+// UnsubscribeFromReceivePingOperation will stop the reception of Ping messages from Ping channel.
+// The context given to the messages being processed is canceled.
+// A timeout can be set in context to avoid blocking operation, if needed.
+func (c *AppController) UnsubscribeFromReceivePingOperation(
+	ctx context.Context,
+) {
+	// Get channel address
+	addr := "v3.features.mocks.ping"
+
+	// Check if there receivers for this channel and remove it from the receivers
+	c.subscriptionsMutex.Lock()
+	sub, exists := c.subscriptions[addr]
+	delete(c.subscriptions, addr)
+	c.subscriptionsMutex.Unlock()
+	if !exists {
+		return
+	}
+
+	// Set context
+	ctx = addAppContextValues(ctx, addr)
+
+	// Stop the subscription and cancel the context of the messages being processed
+	sub.listener.CancelProcessing()
+	sub.broker.Cancel(ctx)
+
+	c.logger.Info(ctx, "Unsubscribed from channel")
+}
+
+// SendAsReplyToReceivePingOperation will send a Pong message on Pong channel.
+func (c *AppController) SendAsReplyToReceivePingOperation(
+	ctx context.Context,
+	msg PongMessage,
+) error {
+	// Set channel address
+	addr := "v3.features.mocks.pong"
+
+	// Set correlation ID if it does not exist
+	if id := msg.CorrelationID(); id == "" {
+		c.logger.Error(ctx, extensions.ErrNoCorrelationIDSet.Error())
+		return extensions.ErrNoCorrelationIDSet
+
+	}
+
+	// Set context
+	ctx = addAppContextValues(ctx, addr)
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsDirection, "publication")
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsCorrelationID, msg.CorrelationID())
+
+	// Validate message if required
+	if c.validation {
+		if err := msg.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Convert to BrokerMessage
+	brokerMsg, err := msg.toBrokerMessage()
+	if err != nil {
+		return err
+	}
+
+	// Set broker message to context
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsBrokerMessage, brokerMsg.String())
+
+	// Send the message on event-broker through middlewares
+	return c.executeMiddlewares(ctx, &brokerMsg, func(ctx context.Context) error {
+		return c.broker.Publish(ctx, addr, brokerMsg)
+	})
+}
+
+// SendAsSendNotificationOperation will send a Notification message on Notification channel.
+func (c *AppController) SendAsSendNotificationOperation(
+	ctx context.Context,
+	params NotificationChannelParameters,
+	msg NotificationMessage,
+) error {
+	// Set channel address
+	addr := fmt.Sprintf("v3.features.mocks.notification.%s", params.UserId)
+
+	// Set context
+	ctx = addAppContextValues(ctx, addr)
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsDirection, "publication")
+
+	// Validate message if required
+	if c.validation {
+		if err := msg.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Convert to BrokerMessage
+	brokerMsg, err := msg.toBrokerMessage()
+	if err != nil {
+		return err
+	}
+
+	// Set broker message to context
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsBrokerMessage, brokerMsg.String())
+
+	// Send the message on event-broker through middlewares
+	return c.executeMiddlewares(ctx, &brokerMsg, func(ctx context.Context) error {
+		return c.broker.Publish(ctx, addr, brokerMsg)
+	})
+}
+
+// UserSubscriber contains all handlers that are listening messages for User
+type UserSubscriber interface {
+	// SendNotificationOperationReceived receive all Notification messages from Notification channel.
+	SendNotificationOperationReceived(ctx context.Context, msg NotificationMessage) error
+}
+
+// UserController is the structure that provides sending capabilities to the
+// developer and and connect the broker with the User
+type UserController struct {
+	controller
+}
+
+// NewUserController links the User to the broker
+func NewUserController(bc extensions.BrokerController, options ...ControllerOption) (*UserController, error) {
+	// Check if broker controller has been provided
+	if bc == nil {
+		return nil, extensions.ErrNilBrokerController
+	}
+
+	// Create default controller
+	c := &UserController{controller: controller{
+		broker:        bc,
+		subscriptions: make(map[string]subscription),
+		logger:        extensions.DummyLogger{},
+		middlewares:   make([]extensions.Middleware, 0),
+		errorHandler:  extensions.DefaultErrorHandler(),
+		concurrency:   1,
+	}}
+
+	// Apply options
+	for _, option := range options {
+		option(&c.controller)
+	}
+
+	return c, nil
+}
+
+func (c *UserController) wrapMiddlewares(
+	middlewares []extensions.Middleware,
+	callback extensions.NextMiddleware,
+) func(ctx context.Context, msg *extensions.BrokerMessage) error {
+	// If there is no more middleware
+	if len(middlewares) == 0 {
+		return func(ctx context.Context, msg *extensions.BrokerMessage) error {
+			// Call the callback if it exists
+			if callback != nil {
+				return callback(ctx)
+			}
+
+			return nil
+		}
+	}
+
+	// Get the next function to call from next middlewares or callback
+	next := c.wrapMiddlewares(middlewares[1:], callback)
+
+	// Wrap middleware into a function that will execute the middleware and call
+	// the next wrapped middleware if the middleware has not called it already
+	return func(ctx context.Context, msg *extensions.BrokerMessage) error {
+		// Create the next call with the context and the message. It can be
+		// called several times by the middleware (i.e. to retry the processing).
+		var called bool
+		nextWithArgs := func(ctx context.Context) error {
+			called = true
+			return next(ctx, msg)
+		}
+
+		// Call the middleware
+		if err := middlewares[0](ctx, msg, nextWithArgs); err != nil {
+			return err
+		}
+
+		// If next has already been called in middleware, it should not be executed again
+		if called {
+			return nil
+		}
+		return nextWithArgs(ctx)
+	}
+}
+
+func (c *UserController) executeMiddlewares(ctx context.Context, msg *extensions.BrokerMessage, callback extensions.NextMiddleware) error {
+	// Wrap middleware to have 'next' function when calling them
+	wrapped := c.wrapMiddlewares(c.middlewares, callback)
+
+	// Execute wrapped middlewares
+	return wrapped(ctx, msg)
+}
+
+func addUserContextValues(ctx context.Context, addr string) context.Context {
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsVersion, "1.2.3")
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsProvider, "user")
+	return context.WithValue(ctx, extensions.ContextKeyIsChannel, addr)
+}
+
+// Close will clean up any existing resources on the controller
+func (c *UserController) Close(ctx context.Context) {
+	// Unsubscribing remaining channels
+	c.UnsubscribeFromAllChannels(ctx)
+
+	c.logger.Info(ctx, "Closed user controller")
+}
+
+// Shutdown will gracefully stop the controller: it stops the intake of new
+// messages, and waits for the messages being processed to be acknowledged
+// before stopping the subscriptions, until the context is done.
+//
+// Messages received after the intake has been stopped are rejected (Nak) in
+// order to be processed again later, possibly by another instance. An error
+// wrapping extensions.ErrSubscriptionNotDrained is returned for each
+// subscription that still has messages being processed when the context is
+// done, and the context given to these messages is canceled.
+func (c *UserController) Shutdown(ctx context.Context) error {
+	// Take every subscription from the controller
+	c.subscriptionsMutex.Lock()
+	subscriptions := c.subscriptions
+	c.subscriptions = make(map[string]subscription)
+	c.subscriptionsMutex.Unlock()
+
+	// Stop the intake of new messages on every subscription
+	for _, sub := range subscriptions {
+		sub.listener.StopIntake()
+	}
+
+	var errs []error
+	for addr, sub := range subscriptions {
+		// Set context
+		subCtx := addUserContextValues(ctx, addr)
+
+		// Wait for the messages being processed, so they can still be
+		// acknowledged on the subscription, and cancel the context of the
+		// ones still being processed when the context is done (a timeout is
+		// reported below)
+		if err := sub.listener.Drain(subCtx); err != nil {
+			sub.listener.CancelProcessing()
+		}
+
+		// Stop the subscription and wait for its listener
+		sub.broker.Cancel(subCtx)
+		if err := sub.listener.WaitStopped(subCtx); err != nil {
+			err = fmt.Errorf("%w: %d messages still being processed on channel %q",
+				extensions.ErrSubscriptionNotDrained, sub.listener.InFlight(), addr)
+			c.logger.Error(subCtx, err.Error())
+			errs = append(errs, err)
+		}
+	}
+
+	c.logger.Info(ctx, "Shut down user controller")
+
+	return errors.Join(errs...)
+}
+
+// SubscribeToAllChannels will receive messages from channels where channel has
+// no parameter on which the app is expecting messages. For channels with parameters,
+// they should be subscribed independently.
+func (c *UserController) SubscribeToAllChannels(ctx context.Context, as UserSubscriber) error {
+	if as == nil {
+		return extensions.ErrNilUserSubscriber
+	}
+
+	return nil
+}
+
+// UnsubscribeFromAllChannels will stop the subscription of all remaining subscribed channels
+func (c *UserController) UnsubscribeFromAllChannels(ctx context.Context) {
+	c.UnsubscribeFromSendNotificationOperationAllParameters(ctx)
+}
+
+// SubscribeToSendNotificationOperation will receive Notification messages from Notification channel.
+//
+// Callback function 'fn' will be called each time a new message is received.
+//
+// Options (i.e. extensions.WithStartEarliest, extensions.WithQueueGroup) can be
+// given to override the broker controller ones for this subscription.
+func (c *UserController) SubscribeToSendNotificationOperation(
+	ctx context.Context,
+	params NotificationChannelParameters,
+	fn func(ctx context.Context, msg NotificationMessage) error,
+	opts ...extensions.SubscribeOption,
+) error {
+	// Get channel address
+	addr := fmt.Sprintf("v3.features.mocks.notification.%s", params.UserId)
+
+	// Set context
+	ctx = addUserContextValues(ctx, addr)
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsDirection, "reception")
+
+	// Lock the subscriptions to avoid conflicts
+	c.subscriptionsMutex.Lock()
+	defer c.subscriptionsMutex.Unlock()
+
+	// Check if the controller is already subscribed
+	_, exists := c.subscriptions[addr]
+	if exists {
+		err := fmt.Errorf("%w: controller is already subscribed on channel %q", extensions.ErrAlreadySubscribedChannel, addr)
+		c.logger.Error(ctx, err.Error())
+		return err
+	}
+
+	// Subscribe to broker channel
+	sub, err := extensions.Subscribe(ctx, c.broker, addr, opts...)
+	if err != nil {
+		c.logger.Error(ctx, err.Error())
+		return err
+	}
+	c.logger.Info(ctx, "Subscribed to channel")
+
+	// Asynchronously listen to new messages and pass them to app receiver
+	listener := extensions.NewListenerTracker()
+	go c.listenToSendNotificationOperationMessages(ctx, addr, sub, listener, fn)
+
+	// Add the cancel channel to the inside map
+	c.subscriptions[addr] = subscription{broker: sub, listener: listener}
+
+	// Stop the subscription when the context is done, if required
+	if c.autoUnsubscribe {
+		go c.unsubscribeWhenDone(ctx, addr, listener)
+	}
+
+	return nil
+}
+
+// SubscribeToSendNotificationOperationAllParameters will receive Notification messages from Notification channel,
+// for every value of the channel parameters.
+//
+// Callback function 'fn' will be called each time a new message is received,
+// with the parameters of the channel address the message has been received on.
+//
+// The broker controller should support wildcard subscriptions, otherwise
+// extensions.ErrWildcardNotSupported is returned. Options can be given as with
+// SubscribeToSendNotificationOperation.
+func (c *UserController) SubscribeToSendNotificationOperationAllParameters(
+	ctx context.Context,
+	fn func(ctx context.Context, params NotificationChannelParameters, msg NotificationMessage) error,
+	opts ...extensions.SubscribeOption,
+) error {
+	// Get channel address with parameters
+	addr := NotificationChannelPath
+
+	// Set context
+	ctx = addUserContextValues(ctx, addr)
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsDirection, "reception")
+
+	// Lock the subscriptions to avoid conflicts
+	c.subscriptionsMutex.Lock()
+	defer c.subscriptionsMutex.Unlock()
+
+	// Check if the controller is already subscribed
+	_, exists := c.subscriptions[addr]
+	if exists {
+		err := fmt.Errorf("%w: controller is already subscribed on channel %q", extensions.ErrAlreadySubscribedChannel, addr)
+		c.logger.Error(ctx, err.Error())
+		return err
+	}
+
+	// Subscribe to every broker channel matching the address
+	sub, err := extensions.SubscribeWildcard(ctx, c.broker, addr, opts...)
+	if err != nil {
+		c.logger.Error(ctx, err.Error())
+		return err
+	}
+	c.logger.Info(ctx, "Subscribed to channel with every parameters")
+
+	// Asynchronously listen to new messages and pass them to app receiver,
+	// with the parameters from the address they have been received on
+	listener := extensions.NewListenerTracker()
+	go c.listenToSendNotificationOperationMessages(ctx, addr, sub, listener, func(ctx context.Context, msg NotificationMessage) error {
+		msgAddr, _ := ctx.Value(extensions.ContextKeyIsChannel).(string)
+		params, err := NewNotificationChannelParametersFromAddress(msgAddr)
+		if err != nil {
+			return err
+		}
+
+		return fn(ctx, params, msg)
+	})
+
+	// Add the cancel channel to the inside map
+	c.subscriptions[addr] = subscription{broker: sub, listener: listener}
+
+	// Stop the subscription when the context is done, if required
+	if c.autoUnsubscribe {
+		go c.unsubscribeWhenDone(ctx, addr, listener)
+	}
+
+	return nil
+}
+
+func (c *UserController) listenToSendNotificationOperationMessages(
+	ctx context.Context,
+	addr string,
+	sub extensions.BrokerChannelSubscription,
+	listener *extensions.ListenerTracker,
+	fn func(ctx context.Context, msg NotificationMessage) error,
+) {
+	// Mark the listener as stopped once every message has been processed
+	defer listener.Stopped()
+
+	// Process messages with the configured concurrency, and wait for
+	// the messages being processed before stopping
+	executor := extensions.NewConcurrentExecutor(c.concurrency, c.concurrencyKey)
+	defer executor.Wait()
+
+	for {
+		// Listen to next message
+		stop, err := c.listenToSendNotificationOperationNextMessage(addr, sub, listener, executor, fn)
+		if err != nil {
+			c.logger.Error(ctx, err.Error())
+		}
+
+		// Stop if required
+		if stop {
+			return
+		}
+	}
+}
+
+func (c *UserController) listenToSendNotificationOperationNextMessage(
+	addr string,
+	sub extensions.BrokerChannelSubscription,
+	listener *extensions.ListenerTracker,
+	executor *extensions.ConcurrentExecutor,
+	fn func(ctx context.Context, msg NotificationMessage) error,
+) (stop bool, err error) {
+	// Wait for next message
+	acknowledgeableBrokerMessage, open := <-sub.MessagesChannel()
+
+	// If subscription is closed and there is no more message
+	// (i.e. uninitialized message), then exit the function
+	if !open && acknowledgeableBrokerMessage.IsUninitialized() {
+		return true, nil
+	}
+
+	// Get the actual channel address, as the subscription address can
+	// have parameters in case of wildcard subscription
+	if acknowledgeableBrokerMessage.Channel != "" {
+		addr = acknowledgeableBrokerMessage.Channel
+	}
+
+	// Reject the message if the intake has been stopped (i.e. on shutdown),
+	// so it can be processed again later
+	if !listener.MessageReceived() {
+		acknowledgeableBrokerMessage.Nak()
+		return false, nil
+	}
+
+	// Process the message, possibly in parallel of other messages
+	executor.Execute(acknowledgeableBrokerMessage.BrokerMessage, func() {
+		// Register the message as processed once acknowledged
+		defer listener.MessageProcessed()
+
+		// Create a context for the received message, canceled when unsubscribing
+		msgCtx, cancel := context.WithCancel(listener.Context())
+		msgCtx = addUserContextValues(msgCtx, addr)
+		msgCtx = context.WithValue(msgCtx, extensions.ContextKeyIsDirection, "reception")
+		defer cancel()
+
+		// Set broker message to context
+		msgCtx = context.WithValue(msgCtx, extensions.ContextKeyIsBrokerMessage, acknowledgeableBrokerMessage.String())
+
+		// Execute middlewares before handling the message
+		if err := c.executeMiddlewares(msgCtx, &acknowledgeableBrokerMessage.BrokerMessage, func(middlewareCtx context.Context) error {
+			// Process message
+			msg, err := brokerMessageToNotificationMessage(acknowledgeableBrokerMessage.BrokerMessage)
+			if err != nil {
+				return err
+			}
+
+			// Validate message if required
+			if c.validation {
+				if err := msg.Validate(); err != nil {
+					return err
+				}
+			}
+
+			// Execute the subscription function
+			return fn(middlewareCtx, msg)
+		}); err != nil {
+			c.errorHandler(msgCtx, addr, &acknowledgeableBrokerMessage, err)
+			// On error execute the acknowledgeableBrokerMessage nack() function and
+			// let the BrokerAcknowledgment decide what is the right nack behavior for the broker
+			acknowledgeableBrokerMessage.Nak()
+			return
+		}
+
+		// Acknowledge the message once the middlewares and the subscription
+		// function have returned without error, even if a middleware has not
+		// called the next one (i.e. a retry middleware sending it to a
+		// dead-letter channel)
+		acknowledgeableBrokerMessage.Ack()
+	})
+
+	return false, nil
+}
+
+// UnsubscribeFromSendNotificationOperation will stop the reception of Notification messages from Notification channel.
+// The context given to the messages being processed is canceled.
+// A timeout can be set in context to avoid blocking operation, if needed.
+func (c *UserController) UnsubscribeFromSendNotificationOperation(
+	ctx context.Context,
+	params NotificationChannelParameters,
+) {
+	// Get channel address
+	addr := fmt.Sprintf("v3.features.mocks.notification.%s", params.UserId)
+
+	// Check if there receivers for this channel and remove it from the receivers
+	c.subscriptionsMutex.Lock()
+	sub, exists := c.subscriptions[addr]
+	delete(c.subscriptions, addr)
+	c.subscriptionsMutex.Unlock()
+	if !exists {
+		return
+	}
+
+	// Set context
+	ctx = addUserContextValues(ctx, addr)
+
+	// Stop the subscription and cancel the context of the messages being processed
+	sub.listener.CancelProcessing()
+	sub.broker.Cancel(ctx)
+
+	c.logger.Info(ctx, "Unsubscribed from channel")
+}
+
+// UnsubscribeFromSendNotificationOperationAllParameters will stop the reception of Notification messages from Notification channel,
+// subscribed with SubscribeToSendNotificationOperationAllParameters.
+// The context given to the messages being processed is canceled.
+// A timeout can be set in context to avoid blocking operation, if needed.
+func (c *UserController) UnsubscribeFromSendNotificationOperationAllParameters(ctx context.Context) {
+	// Get channel address with parameters
+	addr := NotificationChannelPath
+
+	// Check if there receivers for this channel and remove it from the receivers
+	c.subscriptionsMutex.Lock()
+	sub, exists := c.subscriptions[addr]
+	delete(c.subscriptions, addr)
+	c.subscriptionsMutex.Unlock()
+	if !exists {
+		return
+	}
+
+	// Set context
+	ctx = addUserContextValues(ctx, addr)
+
+	// Stop the subscription and cancel the context of the messages being processed
+	sub.listener.CancelProcessing()
+	sub.broker.Cancel(ctx)
+
+	c.logger.Info(ctx, "Unsubscribed from channel with every parameters")
+}
+
+// SendToReceivePingOperation will send a Ping message on Ping channel.
+//
+// NOTE: this won't wait for reply, use the normal version to get the reply or do the catching reply manually.
+func (c *UserController) SendToReceivePingOperation(
+	ctx context.Context,
+	msg PingMessage,
+) error {
+	// Set channel address
+	addr := "v3.features.mocks.ping"
+
+	// Set correlation ID if it does not exist
+	if id := msg.CorrelationID(); id == "" {
+		msg.SetCorrelationID(uuid.New().String())
+	}
+
+	// Set context
+	ctx = addUserContextValues(ctx, addr)
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsDirection, "publication")
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsCorrelationID, msg.CorrelationID())
+
+	// Validate message if required
+	if c.validation {
+		if err := msg.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Convert to BrokerMessage
+	brokerMsg, err := msg.toBrokerMessage()
+	if err != nil {
+		return err
+	}
+
+	// Set broker message to context
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsBrokerMessage, brokerMsg.String())
+
+	// Send the message on event-broker through middlewares
+	return c.executeMiddlewares(ctx, &brokerMsg, func(ctx context.Context) error {
+		return c.broker.Publish(ctx, addr, brokerMsg)
+	})
+}
+
+// RequestToReceivePingOperation will send a Ping message on Ping channel
+// and wait for a Pong message from Pong channel.
+//
+// If a correlation ID is set in the AsyncAPI, then this will wait for the
+// reply with the same correlation ID. Otherwise, it will returns the first
+// message on the reply channel.
+//
+// A timeout can be set in context to avoid blocking operation, if needed.
+
+func (c *UserController) RequestToReceivePingOperation(
+	ctx context.Context,
+	msg PingMessage,
+) (PongMessage, error) {
+	// Get receiving channel address
+	addr := "v3.features.mocks.pong"
+
+	// Set context
+	ctx = addUserContextValues(ctx, addr)
+	ctx = context.WithValue(ctx, extensions.ContextKeyIsDirection, "wait-for")
+
+	// Subscribe to broker channel
+	sub, err := c.broker.Subscribe(ctx, addr)
+	if err != nil {
+		c.logger.Error(ctx, err.Error())
+		return PongMessage{}, err
+	}
+	c.logger.Info(ctx, "Subscribed to channel")
+
+	// Close receiver on leave
+	defer func() {
+		// Stop the subscription
+		sub.Cancel(ctx)
+
+		// Logging unsubscribing
+		c.logger.Info(ctx, "Unsubscribed from channel")
+	}()
+
+	// Set correlation ID if it does not exist
+	if id := msg.CorrelationID(); id == "" {
+		msg.SetCorrelationID(uuid.New().String())
+	}
+
+	// Send the message
+	if err := c.SendToReceivePingOperation(ctx, msg); err != nil {
+		c.logger.Error(ctx, "error happened when sending message", extensions.LogInfo{Key: "error", Value: err.Error()})
+		return PongMessage{}, fmt.Errorf("error happened when sending message: %w", err)
+	}
+
+	// Wait for corresponding response
+	for {
+		// Listen to next message
+		msg, err := c.waitForReceivePingOperationNextResponse(ctx, addr, sub, msg)
+		if err != nil {
+			c.logger.Error(ctx, err.Error())
+		}
+
+		// Continue if the message hasn't been received
+		if msg == nil {
+			continue
+		}
+
+		return *msg, nil
+	}
+}
+
+func (c *UserController) waitForReceivePingOperationNextResponse(
+	ctx context.Context,
+	addr string,
+	sub extensions.BrokerChannelSubscription,
+	msg PingMessage,
+) (*PongMessage, error) {
+	// Create a context for the received response
+	msgCtx, cancel := context.WithCancel(context.Background())
+	msgCtx = addUserContextValues(msgCtx, addr)
+	msgCtx = context.WithValue(msgCtx, extensions.ContextKeyIsDirection, "wait-for")
+	msgCtx = context.WithValue(msgCtx, extensions.ContextKeyIsCorrelationID, msg.CorrelationID())
+	defer cancel()
+
+	select {
+	case acknowledgeableBrokerMessage, open := <-sub.MessagesChannel():
+		// If subscription is closed and there is no more message
+		// (i.e. uninitialized message), then the subscription ended before
+		// receiving the expected message
+		if !open && acknowledgeableBrokerMessage.IsUninitialized() {
+			c.logger.Error(msgCtx, "Channel closed before getting message")
+			return nil, extensions.ErrSubscriptionCanceled
+		}
+
+		// Get new message
+		rmsg, err := brokerMessageToPongMessage(acknowledgeableBrokerMessage.BrokerMessage)
+		if err != nil {
+			c.logger.Error(msgCtx, err.Error())
+		}
+
+		// Acknowledge the message
+		acknowledgeableBrokerMessage.Ack()
+
+		// If message doesn't have corresponding correlation ID, then ingore and continue
+		if msg.CorrelationID() != rmsg.CorrelationID() {
+			return nil, nil
+		}
+
+		// Set context with received values as it is the expected message
+		msgCtx := context.WithValue(msgCtx, extensions.ContextKeyIsBrokerMessage, acknowledgeableBrokerMessage.String())
+
+		// Execute middlewares before returning
+		if err := c.executeMiddlewares(msgCtx, &acknowledgeableBrokerMessage.BrokerMessage, nil); err != nil {
+			return nil, err
+		}
+
+		// Return the message to the caller
+		//
+		// NOTE: it is transformed from the broker again, as it could have
+		// been modified by middlewares
+		rmsg, err = brokerMessageToPongMessage(acknowledgeableBrokerMessage.BrokerMessage)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rmsg, nil
+	case <-ctx.Done(): // Set corresponding error if context is done
+		c.logger.Error(msgCtx, "Context done before getting message")
+		return nil, extensions.ErrContextCanceled
+	}
+}
+
+// AsyncAPIVersion is the version of the used AsyncAPI document
+const AsyncAPIVersion = "1.2.3"
+
+// controller is the controller that will be used to communicate with the broker
+// It will be used internally by AppController and UserController
+type controller struct {
+	// broker is the broker controller that will be used to communicate
+	broker extensions.BrokerController
+	// subscriptions is a map of all subscriptions
+	subscriptions map[string]subscription
+	// subscriptionsMutex protects the subscriptions map
+	subscriptionsMutex sync.Mutex
+	// logger is the logger that will be used² to log operations on controller
+	logger extensions.Logger
+	// middlewares are the middlewares that will be executed when sending or
+	// receiving messages
+	middlewares []extensions.Middleware
+	// handler to handle errors from consumers and middlewares
+	errorHandler extensions.ErrorHandler
+	// validation is set if messages should be validated against the constraints
+	// of the AsyncAPI specification
+	validation bool
+	// concurrency is the maximum number of messages processed in parallel
+	// for each subscription
+	concurrency int
+	// concurrencyKey is the key extractor used to process messages with the
+	// same key in order when processing them in parallel
+	concurrencyKey extensions.KeyExtractor
+	// autoUnsubscribe is set if subscriptions should be stopped when the
+	// context given on subscription is done
+	autoUnsubscribe bool
+}
+
+// subscription is a subscription to a broker channel, with the tracker of the
+// messages being processed by its listener
+type subscription struct {
+	broker   extensions.BrokerChannelSubscription
+	listener *extensions.ListenerTracker
+}
+
+// ControllerOption is the type of the options that can be passed
+// when creating a new Controller
+type ControllerOption func(controller *controller)
+
+// WithLogger attaches a logger to the controller
+func WithLogger(logger extensions.Logger) ControllerOption {
+	return func(controller *controller) {
+		controller.logger = logger
+	}
+}
+
+// WithMiddlewares attaches middlewares that will be executed when sending or receiving messages
+func WithMiddlewares(middlewares ...extensions.Middleware) ControllerOption {
+	return func(controller *controller) {
+		controller.middlewares = middlewares
+	}
+}
+
+// WithErrorHandler attaches a errorhandler to handle errors from subscriber functions
+func WithErrorHandler(handler extensions.ErrorHandler) ControllerOption {
+	return func(controller *controller) {
+		controller.errorHandler = handler
+	}
+}
+
+// WithValidation enables the validation of messages against the constraints of
+// the AsyncAPI specification: invalid messages are rejected when sent, and
+// given to the error handler instead of the subscriber when received.
+func WithValidation() ControllerOption {
+	return func(controller *controller) {
+		controller.validation = true
+	}
+}
+
+// WithConcurrency sets the maximum number of messages processed in parallel for
+// each subscription (default is 1). If a key extractor is given (i.e.
+// extensions.KeyFromHeader), messages with the same key are processed
+// sequentially, in the order they were received.
+func WithConcurrency(n int, key ...extensions.KeyExtractor) ControllerOption {
+	return func(controller *controller) {
+		controller.concurrency = n
+		if len(key) > 0 {
+			controller.concurrencyKey = key[0]
+		}
+	}
+}
+
+// WithAutoUnsubscribe stops a subscription when the context given on
+// subscription is done, as if the corresponding unsubscribe function was called.
+func WithAutoUnsubscribe() ControllerOption {
+	return func(controller *controller) {
+		controller.autoUnsubscribe = true
+	}
+}
+
+// unsubscribeWhenDone stops the subscription on the channel when the context is
+// done, unless its listener has been stopped before (i.e. unsubscribed).
+func (c *controller) unsubscribeWhenDone(ctx context.Context, addr string, listener *extensions.ListenerTracker) {
+	// Wait for the context to be done or the listener to be stopped
+	if err := listener.WaitStopped(ctx); err == nil {
+		return
+	}
+
+	// Remove the subscription if it is still the same
+	c.subscriptionsMutex.Lock()
+	sub, exists := c.subscriptions[addr]
+	if !exists || sub.listener != listener {
+		c.subscriptionsMutex.Unlock()
+		return
+	}
+	delete(c.subscriptions, addr)
+	c.subscriptionsMutex.Unlock()
+
+	// Stop the subscription, waiting for the broker clean up even if the
+	// context is done, and cancel the context of the messages being processed
+	sub.listener.CancelProcessing()
+	sub.broker.Cancel(context.WithoutCancel(ctx))
+
+	c.logger.Info(ctx, "Unsubscribed from channel as context is done")
+}
+
+type MessageWithCorrelationID interface {
+	CorrelationID() string
+	SetCorrelationID(id string)
+}
+
+type Error struct {
+	Channel string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("channel %q: err %v", e.Channel, e.Err)
+}
+
+// NotificationChannelParameters represents NotificationChannel channel parameters
+type NotificationChannelParameters struct {
+	// UserId is a channel parameter: Id of the user
+	UserId string
+}
+
+// NewNotificationChannelParametersFromAddress returns the NotificationChannel channel parameters
+// from the address of a channel (i.e. the address of a received message).
+func NewNotificationChannelParametersFromAddress(addr string) (NotificationChannelParameters, error) {
+	values, err := extensions.ChannelAddressParameters(NotificationChannelPath, addr)
+	if err != nil {
+		return NotificationChannelParameters{}, err
+	}
+
+	return NotificationChannelParameters{
+		UserId: values["userId"],
+	}, nil
+}
+
+// Message 'NotificationMessageFromNotificationChannel' reference another one at '#/components/messages/Notification'.
+// This should be fixed in a future version to allow message override.
+// If you encounter this message, feel free to open an issue on this subject
+// to let know that you need this functionnality.
+
+// Message 'PingMessageFromPingChannel' reference another one at '#/components/messages/Ping'.
+// This should be fixed in a future version to allow message override.
+// If you encounter this message, feel free to open an issue on this subject
+// to let know that you need this functionnality.
+
+// Message 'PongMessageFromPongChannel' reference another one at '#/components/messages/Pong'.
+// This should be fixed in a future version to allow message override.
+// If you encounter this message, feel free to open an issue on this subject
+// to let know that you need this functionnality.
+
+// NotificationMessagePayload is a schema from the AsyncAPI specification required in messages
+type NotificationMessagePayload struct {
+	Text *string `json:"text,omitempty"`
+}
+
+// Validate checks that NotificationMessagePayload respects the constraints of the AsyncAPI specification.
+func (s NotificationMessagePayload) Validate() error {
+	return nil
+}
+
+// NotificationMessage is the message expected for 'NotificationMessage' channel.
+type NotificationMessage struct {
+	// Payload will be inserted in the message payload
+	Payload NotificationMessagePayload
+}
+
+func NewNotificationMessage() NotificationMessage {
+	var msg NotificationMessage
+
+	return msg
+}
+
+// brokerMessageToNotificationMessage will fill a new NotificationMessage with data from generic broker message
+func brokerMessageToNotificationMessage(bMsg extensions.BrokerMessage) (NotificationMessage, error) {
+	var msg NotificationMessage
+
+	// Get codec from the message content type, or from the one in specification
+	codec, err := extensions.CodecForBrokerMessage(bMsg, "application/json")
+	if err != nil {
+		return msg, err
+	}
+
+	// Unmarshal payload to expected message payload format
+	err = codec.Unmarshal(bMsg.Payload, &msg.Payload)
+	if err != nil {
+		return msg, err
+	}
+
+	return msg, nil
+}
+
+// toBrokerMessage will generate a generic broker message from NotificationMessage data
+func (msg NotificationMessage) toBrokerMessage() (extensions.BrokerMessage, error) {
+
+	// Get codec from the content type in specification
+	codec := extensions.SpecificationCodec("application/json")
+
+	// Marshal payload with the codec
+	payload, err := codec.Marshal(msg.Payload)
+	if err != nil {
+		return extensions.BrokerMessage{}, err
+	}
+
+	// There is no headers here
+	headers := make(map[string][]byte, 0)
+
+	// Set content type, so the receiver can decode the payload
+	headers[extensions.ContentTypeHeader] = []byte("application/json")
+
+	return extensions.BrokerMessage{
+		Headers: headers,
+		Payload: payload,
+	}, nil
+}
+
+// Validate checks that NotificationMessage respects the constraints of the AsyncAPI specification.
+func (msg NotificationMessage) Validate() error {
+	if err := msg.Payload.Validate(); err != nil {
+		return extensions.WithValidationField("payload", err)
+	}
+	return nil
+}
+
+// HeadersFromPingMessage is a schema from the AsyncAPI specification required in messages
+type HeadersFromPingMessage struct {
+	CorrelationId *string `json:"correlationId,omitempty"`
+}
+
+// Validate checks that HeadersFromPingMessage respects the constraints of the AsyncAPI specification.
+func (s HeadersFromPingMessage) Validate() error {
+	return nil
+}
+
+// PingMessage is the message expected for 'PingMessage' channel.
+type PingMessage struct {
+	// Headers will be used to fill the message headers
+	Headers HeadersFromPingMessage
+
+	// Payload will be inserted in the message payload
+	Payload string
+}
+
+func NewPingMessage() PingMessage {
+	var msg PingMessage
+
+	// Set correlation ID
+	u := uuid.New().String()
+	msg.Headers.CorrelationId = &u
+
+	return msg
+}
+
+// brokerMessageToPingMessage will fill a new PingMessage with data from generic broker message
+func brokerMessageToPingMessage(bMsg extensions.BrokerMessage) (PingMessage, error) {
+	var msg PingMessage
+
+	// Convert to string
+	payload := string(bMsg.Payload)
+	msg.Payload = payload // No need for type conversion to reference
+
+	// Get each headers from broker message
+	for k, v := range bMsg.Headers {
+		switch {
+		case k == "correlationId": // Retrieving CorrelationId header
+			h := string(v)
+			msg.Headers.CorrelationId = &h
+		default:
+			// TODO: log unknown error
+		}
+	}
+
+	return msg, nil
+}
+
+// toBrokerMessage will generate a generic broker message from PingMessage data
+func (msg PingMessage) toBrokerMessage() (extensions.BrokerMessage, error) {
+
+	// Convert to []byte
+	payload := []byte(msg.Payload)
+
+	// Add each headers to broker message
+	headers := make(map[string][]byte, 1)
+
+	// Adding CorrelationId header
+	if msg.Headers.CorrelationId != nil {
+		headers["correlationId"] = []byte(*msg.Headers.CorrelationId)
+	}
+
+	return extensions.BrokerMessage{
+		Headers: headers,
+		Payload: payload,
+	}, nil
+}
+
+// Validate checks that PingMessage respects the constraints of the AsyncAPI specification.
+func (msg PingMessage) Validate() error {
+	if err := msg.Headers.Validate(); err != nil {
+		return extensions.WithValidationField("headers", err)
+	}
+	return nil
+}
+
+// CorrelationID will give the correlation ID of the message, based on AsyncAPI spec
+func (msg PingMessage) CorrelationID() string {
+	if msg.Headers.CorrelationId != nil {
+		return *msg.Headers.CorrelationId
+	}
+
+	return ""
+}
+
+// SetCorrelationID will set the correlation ID of the message, based on AsyncAPI spec
+func (msg *PingMessage) SetCorrelationID(id string) {
+	msg.Headers.CorrelationId = &id
+}
+
+// SetAsResponseFrom will correlate the message with the one passed in parameter.
+// It will assign the 'req' message correlation ID to the message correlation ID,
+// both specified in AsyncAPI spec.
+func (msg *PingMessage) SetAsResponseFrom(req MessageWithCorrelationID) {
+	id := req.CorrelationID()
+	msg.Headers.CorrelationId = &id
+}
+
+// HeadersFromPongMessage is a schema from the AsyncAPI specification required in messages
+type HeadersFromPongMessage struct {
+	CorrelationId *string `json:"correlationId,omitempty"`
+}
+
+// Validate checks that HeadersFromPongMessage respects the constraints of the AsyncAPI specification.
+func (s HeadersFromPongMessage) Validate() error {
+	return nil
+}
+
+// PongMessage is the message expected for 'PongMessage' channel.
+type PongMessage struct {
+	// Headers will be used to fill the message headers
+	Headers HeadersFromPongMessage
+
+	// Payload will be inserted in the message payload
+	Payload string
+}
+
+func NewPongMessage() PongMessage {
+	var msg PongMessage
+
+	// Set correlation ID
+	u := uuid.New().String()
+	msg.Headers.CorrelationId = &u
+
+	return msg
+}
+
+// brokerMessageToPongMessage will fill a new PongMessage with data from generic broker message
+func brokerMessageToPongMessage(bMsg extensions.BrokerMessage) (PongMessage, error) {
+	var msg PongMessage
+
+	// Convert to string
+	payload := string(bMsg.Payload)
+	msg.Payload = payload // No need for type conversion to reference
+
+	// Get each headers from broker message
+	for k, v := range bMsg.Headers {
+		switch {
+		case k == "correlationId": // Retrieving CorrelationId header
+			h := string(v)
+			msg.Headers.CorrelationId = &h
+		default:
+			// TODO: log unknown error
+		}
+	}
+
+	return msg, nil
+}
+
+// toBrokerMessage will generate a generic broker message from PongMessage data
+func (msg PongMessage) toBrokerMessage() (extensions.BrokerMessage, error) {
+
+	// Convert to []byte
+	payload := []byte(msg.Payload)
+
+	// Add each headers to broker message
+	headers := make(map[string][]byte, 1)
+
+	// Adding CorrelationId header
+	if msg.Headers.CorrelationId != nil {
+		headers["correlationId"] = []byte(*msg.Headers.CorrelationId)
+	}
+
+	return extensions.BrokerMessage{
+		Headers: headers,
+		Payload: payload,
+	}, nil
+}
+
+// Validate checks that PongMessage respects the constraints of the AsyncAPI specification.
+func (msg PongMessage) Validate() error {
+	if err := msg.Headers.Validate(); err != nil {
+		return extensions.WithValidationField("headers", err)
+	}
+	return nil
+}
+
+// CorrelationID will give the correlation ID of the message, based on AsyncAPI spec
+func (msg PongMessage) CorrelationID() string {
+	if msg.Headers.CorrelationId != nil {
+		return *msg.Headers.CorrelationId
+	}
+
+	return ""
+}
+
+// SetCorrelationID will set the correlation ID of the message, based on AsyncAPI spec
+func (msg *PongMessage) SetCorrelationID(id string) {
+	msg.Headers.CorrelationId = &id
+}
+
+// SetAsResponseFrom will correlate the message with the one passed in parameter.
+// It will assign the 'req' message correlation ID to the message correlation ID,
+// both specified in AsyncAPI spec.
+func (msg *PongMessage) SetAsResponseFrom(req MessageWithCorrelationID) {
+	id := req.CorrelationID()
+	msg.Headers.CorrelationId = &id
+}
+
+const (
+	// NotificationChannelPath is the constant representing the 'NotificationChannel' channel path.
+	NotificationChannelPath = "v3.features.mocks.notification.{userId}"
+	// PingChannelPath is the constant representing the 'PingChannel' channel path.
+	PingChannelPath = "v3.features.mocks.ping"
+	// PongChannelPath is the constant representing the 'PongChannel' channel path.
+	PongChannelPath = "v3.features.mocks.pong"
+)
+
+// ChannelsPaths is an array of all channels paths
+var ChannelsPaths = []string{
+	NotificationChannelPath,
+	PingChannelPath,
+	PongChannelPath,
+} // AppControllerInterface is the interface of AppController.
+// It can be used to replace the controller by FakeAppController in tests.
+type AppControllerInterface interface {
+	// Close will clean up any existing resources on the controller
+	Close(ctx context.Context)
+
+	// Shutdown will gracefully stop the controller, waiting for the messages
+	// being processed until the context is done
+	Shutdown(ctx context.Context) error
+
+	// SubscribeToAllChannels will receive messages from channels where channel has
+	// no parameter on which the app is expecting messages.
+	SubscribeToAllChannels(ctx context.Context, as AppSubscriber) error
+	// UnsubscribeFromAllChannels will stop the subscription of all remaining subscribed channels
+	UnsubscribeFromAllChannels(ctx context.Context)
+
+	// SubscribeToReceivePingOperation will receive Ping messages from Ping channel.
+	SubscribeToReceivePingOperation(
+		ctx context.Context,
+		fn func(ctx context.Context, msg PingMessage) error,
+		opts ...extensions.SubscribeOption,
+	) error
+	// ReplyToReceivePingOperation will reply to a Ping message.
+	ReplyToReceivePingOperation(ctx context.Context, recvMsg PingMessage, fn func(replyMsg *PongMessage)) error
+	// UnsubscribeFromReceivePingOperation will stop the reception of Ping messages from Ping channel.
+	UnsubscribeFromReceivePingOperation(
+		ctx context.Context,
+	)
+
+	// SendAsReplyToReceivePingOperation will send a Pong message on Pong channel.
+	SendAsReplyToReceivePingOperation(
+		ctx context.Context,
+		msg PongMessage,
+	) error
+
+	// SendAsSendNotificationOperation will send a Notification message on Notification channel.
+	SendAsSendNotificationOperation(
+		ctx context.Context,
+		params NotificationChannelParameters,
+		msg NotificationMessage,
+	) error
+}
+
+// Check that AppController and FakeAppController implement
+// AppControllerInterface.
+var (
+	_ AppControllerInterface = (*AppController)(nil)
+	_ AppControllerInterface = (*FakeAppController)(nil)
+)
+
+// FakeAppController is a fake implementation of AppControllerInterface
+// that doesn't use any broker, in order to test code using AppController.
+//
+// It records the sent messages for each operation, calls the subscription
+// functions with the messages injected with Inject methods, and replies to
+// requests with the Reply functions set on it.
+type FakeAppController struct {
+	mutex                           sync.Mutex
+	subscriptions                   map[string]any
+	sentReplyToReceivePingOperation []PongMessage
+	sentSendNotificationOperation   []NotificationMessage
+}
+
+// NewFakeAppController creates a new FakeAppController.
+func NewFakeAppController() *FakeAppController {
+	return &FakeAppController{
+		subscriptions: make(map[string]any),
+	}
+}
+
+// Close will remove every subscription of the fake controller
+func (c *FakeAppController) Close(ctx context.Context) {
+	c.UnsubscribeFromAllChannels(ctx)
+}
+
+// Shutdown will remove every subscription of the fake controller. As injected
+// messages are processed synchronously, there is no message to wait for.
+func (c *FakeAppController) Shutdown(ctx context.Context) error {
+	c.Close(ctx)
+	return nil
+}
+
+// SubscribeToAllChannels will subscribe to channels where channel has no
+// parameter on which the fake controller is expecting messages.
+func (c *FakeAppController) SubscribeToAllChannels(ctx context.Context, as AppSubscriber) error {
+	if as == nil {
+		return extensions.ErrNilAppSubscriber
+	}
+
+	if err := c.SubscribeToReceivePingOperation(ctx, as.ReceivePingOperationReceived); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UnsubscribeFromAllChannels will stop the subscription of all remaining subscribed channels
+func (c *FakeAppController) UnsubscribeFromAllChannels(ctx context.Context) {
+	c.UnsubscribeFromReceivePingOperation(ctx)
+}
+
+func (c *FakeAppController) subscribe(addr string, fn any) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, exists := c.subscriptions[addr]; exists {
+		return fmt.Errorf("%w: controller is already subscribed on channel %q", extensions.ErrAlreadySubscribedChannel, addr)
+	}
+	c.subscriptions[addr] = fn
+
+	return nil
+}
+
+func (c *FakeAppController) subscription(addr string) (any, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	fn, exists := c.subscriptions[addr]
+	if !exists {
+		return nil, fmt.Errorf("%w: no subscription on channel %q", extensions.ErrNotSubscribedChannel, addr)
+	}
+
+	return fn, nil
+}
+
+func (c *FakeAppController) unsubscribe(addr string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.subscriptions, addr)
+}
+
+// SubscribeToReceivePingOperation will register 'fn' to be called with the
+// messages injected with InjectReceivePingOperation. The subscription
+// options are ignored.
+func (c *FakeAppController) SubscribeToReceivePingOperation(
+	_ context.Context,
+	fn func(ctx context.Context, msg PingMessage) error,
+	_ ...extensions.SubscribeOption,
+) error {
+	return c.subscribe("v3.features.mocks.ping", fn)
+}
+
+// InjectReceivePingOperation will call the function subscribed with
+// SubscribeToReceivePingOperation with the given message, as if it
+// was received from Ping channel.
+// It returns the error returned by the subscribed function.
+func (c *FakeAppController) InjectReceivePingOperation(
+	ctx context.Context,
+	msg PingMessage,
+) error {
+	fn, err := c.subscription("v3.features.mocks.ping")
+	if err != nil {
+		return err
+	}
+
+	return fn.(func(ctx context.Context, msg PingMessage) error)(ctx, msg)
+}
+
+// ReplyToReceivePingOperation is a helper function to
+// reply to a Ping message with a Pong message on Pong channel.
+func (c *FakeAppController) ReplyToReceivePingOperation(ctx context.Context, recvMsg PingMessage, fn func(replyMsg *PongMessage)) error {
+	// Create reply message
+	replyMsg := NewPongMessage()
+	replyMsg.SetAsResponseFrom(&recvMsg)
+
+	// Execute callback function
+	fn(&replyMsg)
+
+	// Send reply
+	return c.SendAsReplyToReceivePingOperation(ctx, replyMsg)
+}
+
+// UnsubscribeFromReceivePingOperation will remove the function subscribed with
+// SubscribeToReceivePingOperation.
+func (c *FakeAppController) UnsubscribeFromReceivePingOperation(
+	_ context.Context,
+) {
+	c.unsubscribe("v3.features.mocks.ping")
+}
+
+// SendAsReplyToReceivePingOperation will record the Pong message,
+// that can be retrieved with SentReplyToReceivePingOperationMessages.
+func (c *FakeAppController) SendAsReplyToReceivePingOperation(
+	_ context.Context,
+	msg PongMessage,
+) error {
+	// Set correlation ID if it does not exist
+	if id := msg.CorrelationID(); id == "" {
+		return extensions.ErrNoCorrelationIDSet
+
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.sentReplyToReceivePingOperation = append(c.sentReplyToReceivePingOperation, msg)
+
+	return nil
+}
+
+// SentReplyToReceivePingOperationMessages returns the messages sent with
+// SendAsReplyToReceivePingOperation, in sending order.
+func (c *FakeAppController) SentReplyToReceivePingOperationMessages() []PongMessage {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	msgs := make([]PongMessage, len(c.sentReplyToReceivePingOperation))
+	copy(msgs, c.sentReplyToReceivePingOperation)
+
+	return msgs
+}
+
+// SendAsSendNotificationOperation will record the Notification message,
+// that can be retrieved with SentSendNotificationOperationMessages.
+func (c *FakeAppController) SendAsSendNotificationOperation(
+	_ context.Context,
+	_ NotificationChannelParameters,
+	msg NotificationMessage,
+) error {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.sentSendNotificationOperation = append(c.sentSendNotificationOperation, msg)
+
+	return nil
+}
+
+// SentSendNotificationOperationMessages returns the messages sent with
+// SendAsSendNotificationOperation, in sending order.
+func (c *FakeAppController) SentSendNotificationOperationMessages() []NotificationMessage {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	msgs := make([]NotificationMessage, len(c.sentSendNotificationOperation))
+	copy(msgs, c.sentSendNotificationOperation)
+
+	return msgs
+}
+
+// UserControllerInterface is the interface of UserController.
+// It can be used to replace the controller by FakeUserController in tests.
+type UserControllerInterface interface {
+	// Close will clean up any existing resources on the controller
+	Close(ctx context.Context)
+
+	// Shutdown will gracefully stop the controller, waiting for the messages
+	// being processed until the context is done
+	Shutdown(ctx context.Context) error
+
+	// SubscribeToAllChannels will receive messages from channels where channel has
+	// no parameter on which the app is expecting messages.
+	SubscribeToAllChannels(ctx context.Context, as UserSubscriber) error
+	// UnsubscribeFromAllChannels will stop the subscription of all remaining subscribed channels
+	UnsubscribeFromAllChannels(ctx context.Context)
+
+	// SubscribeToSendNotificationOperation will receive Notification messages from Notification channel.
+	SubscribeToSendNotificationOperation(
+		ctx context.Context,
+		params NotificationChannelParameters,
+		fn func(ctx context.Context, msg NotificationMessage) error,
+		opts ...extensions.SubscribeOption,
+	) error
+	// SubscribeToSendNotificationOperationAllParameters will receive Notification messages from Notification channel,
+	// for every value of the channel parameters.
+	SubscribeToSendNotificationOperationAllParameters(
+		ctx context.Context,
+		fn func(ctx context.Context, params NotificationChannelParameters, msg NotificationMessage) error,
+		opts ...extensions.SubscribeOption,
+	) error
+	// UnsubscribeFromSendNotificationOperation will stop the reception of Notification messages from Notification channel.
+	UnsubscribeFromSendNotificationOperation(
+		ctx context.Context,
+		params NotificationChannelParameters,
+	)
+	// UnsubscribeFromSendNotificationOperationAllParameters will stop the reception of Notification messages from Notification channel,
+	// subscribed with SubscribeToSendNotificationOperationAllParameters.
+	UnsubscribeFromSendNotificationOperationAllParameters(ctx context.Context)
+
+	// SendToReceivePingOperation will send a Ping message on Ping channel.
+	SendToReceivePingOperation(
+		ctx context.Context,
+		msg PingMessage,
+	) error
+	// RequestToReceivePingOperation will send a Ping message on Ping channel
+	// and wait for a Pong message from Pong channel.
+	RequestToReceivePingOperation(
+		ctx context.Context,
+		msg PingMessage,
+	) (PongMessage, error)
+}
+
+// Check that UserController and FakeUserController implement
+// UserControllerInterface.
+var (
+	_ UserControllerInterface = (*UserController)(nil)
+	_ UserControllerInterface = (*FakeUserController)(nil)
+)
+
+// FakeUserController is a fake implementation of UserControllerInterface
+// that doesn't use any broker, in order to test code using UserController.
+//
+// It records the sent messages for each operation, calls the subscription
+// functions with the messages injected with Inject methods, and replies to
+// requests with the Reply functions set on it.
+type FakeUserController struct {
+	mutex                    sync.Mutex
+	subscriptions            map[string]any
+	sentReceivePingOperation []PingMessage
+
+	// RequestToReceivePingOperationReply is called by RequestToReceivePingOperation
+	// to get the reply to the request. If not set, ErrNoFakeReply is returned.
+	RequestToReceivePingOperationReply func(
+		ctx context.Context,
+		msg PingMessage,
+	) (PongMessage, error)
+}
+
+// NewFakeUserController creates a new FakeUserController.
+func NewFakeUserController() *FakeUserController {
+	return &FakeUserController{
+		subscriptions: make(map[string]any),
+	}
+}
+
+// Close will remove every subscription of the fake controller
+func (c *FakeUserController) Close(ctx context.Context) {
+	c.UnsubscribeFromAllChannels(ctx)
+}
+
+// Shutdown will remove every subscription of the fake controller. As injected
+// messages are processed synchronously, there is no message to wait for.
+func (c *FakeUserController) Shutdown(ctx context.Context) error {
+	c.Close(ctx)
+	return nil
+}
+
+// SubscribeToAllChannels will subscribe to channels where channel has no
+// parameter on which the fake controller is expecting messages.
+func (c *FakeUserController) SubscribeToAllChannels(ctx context.Context, as UserSubscriber) error {
+	if as == nil {
+		return extensions.ErrNilUserSubscriber
+	}
+
+	return nil
+}
+
+// UnsubscribeFromAllChannels will stop the subscription of all remaining subscribed channels
+func (c *FakeUserController) UnsubscribeFromAllChannels(ctx context.Context) {
+	c.UnsubscribeFromSendNotificationOperationAllParameters(ctx)
+}
+
+func (c *FakeUserController) subscribe(addr string, fn any) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, exists := c.subscriptions[addr]; exists {
+		return fmt.Errorf("%w: controller is already subscribed on channel %q", extensions.ErrAlreadySubscribedChannel, addr)
+	}
+	c.subscriptions[addr] = fn
+
+	return nil
+}
+
+func (c *FakeUserController) subscription(addr string) (any, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	fn, exists := c.subscriptions[addr]
+	if !exists {
+		return nil, fmt.Errorf("%w: no subscription on channel %q", extensions.ErrNotSubscribedChannel, addr)
+	}
+
+	return fn, nil
+}
+
+func (c *FakeUserController) unsubscribe(addr string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.subscriptions, addr)
+}
+
+// SubscribeToSendNotificationOperation will register 'fn' to be called with the
+// messages injected with InjectSendNotificationOperation. The subscription
+// options are ignored.
+func (c *FakeUserController) SubscribeToSendNotificationOperation(
+	_ context.Context,
+	params NotificationChannelParameters,
+	fn func(ctx context.Context, msg NotificationMessage) error,
+	_ ...extensions.SubscribeOption,
+) error {
+	return c.subscribe(fmt.Sprintf("v3.features.mocks.notification.%s", params.UserId), fn)
+}
+
+// SubscribeToSendNotificationOperationAllParameters will register 'fn' to be called with the
+// messages injected with InjectSendNotificationOperation, for every value of the channel parameters.
+// The subscription options are ignored.
+func (c *FakeUserController) SubscribeToSendNotificationOperationAllParameters(
+	_ context.Context,
+	fn func(ctx context.Context, params NotificationChannelParameters, msg NotificationMessage) error,
+	_ ...extensions.SubscribeOption,
+) error {
+	return c.subscribe(NotificationChannelPath, fn)
+}
+
+// InjectSendNotificationOperation will call the function subscribed with
+// SubscribeToSendNotificationOperation (or SubscribeToSendNotificationOperationAllParameters) with the given message, as if it
+// was received from Notification channel.
+// It returns the error returned by the subscribed function.
+func (c *FakeUserController) InjectSendNotificationOperation(
+	ctx context.Context,
+	params NotificationChannelParameters,
+	msg NotificationMessage,
+) error {
+	fn, err := c.subscription(fmt.Sprintf("v3.features.mocks.notification.%s", params.UserId))
+	if err != nil {
+		// Use the subscription for every value of the channel parameters, if any
+		allFn, allErr := c.subscription(NotificationChannelPath)
+		if allErr != nil {
+			return err
+		}
+
+		return allFn.(func(ctx context.Context, params NotificationChannelParameters, msg NotificationMessage) error)(ctx, params, msg)
+	}
+
+	return fn.(func(ctx context.Context, msg NotificationMessage) error)(ctx, msg)
+}
+
+// UnsubscribeFromSendNotificationOperation will remove the function subscribed with
+// SubscribeToSendNotificationOperation.
+func (c *FakeUserController) UnsubscribeFromSendNotificationOperation(
+	_ context.Context,
+	params NotificationChannelParameters,
+) {
+	c.unsubscribe(fmt.Sprintf("v3.features.mocks.notification.%s", params.UserId))
+}
+
+// UnsubscribeFromSendNotificationOperationAllParameters will remove the function subscribed with
+// SubscribeToSendNotificationOperationAllParameters.
+func (c *FakeUserController) UnsubscribeFromSendNotificationOperationAllParameters(_ context.Context) {
+	c.unsubscribe(NotificationChannelPath)
+}
+
+// SendToReceivePingOperation will record the Ping message,
+// that can be retrieved with SentReceivePingOperationMessages.
+func (c *FakeUserController) SendToReceivePingOperation(
+	_ context.Context,
+	msg PingMessage,
+) error {
+	// Set correlation ID if it does not exist
+	if id := msg.CorrelationID(); id == "" {
+		msg.SetCorrelationID(uuid.New().String())
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.sentReceivePingOperation = append(c.sentReceivePingOperation, msg)
+
+	return nil
+}
+
+// SentReceivePingOperationMessages returns the messages sent with
+// SendToReceivePingOperation and RequestToReceivePingOperation, in sending order.
+func (c *FakeUserController) SentReceivePingOperationMessages() []PingMessage {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	msgs := make([]PingMessage, len(c.sentReceivePingOperation))
+	copy(msgs, c.sentReceivePingOperation)
+
+	return msgs
+}
+
+// RequestToReceivePingOperation will record the Ping message
+// and return the reply from RequestToReceivePingOperationReply.
+func (c *FakeUserController) RequestToReceivePingOperation(
+	ctx context.Context,
+	msg PingMessage,
+) (PongMessage, error) {
+	// Set correlation ID if it does not exist
+	if id := msg.CorrelationID(); id == "" {
+		msg.SetCorrelationID(uuid.New().String())
+	}
+
+	// Record the request
+	if err := c.SendToReceivePingOperation(ctx, msg); err != nil {
+		return PongMessage{}, err
+	}
+
+	// Get the reply
+	if c.RequestToReceivePingOperationReply == nil {
+		return PongMessage{}, extensions.ErrNoFakeReply
+	}
+	return c.RequestToReceivePingOperationReply(ctx, msg)
+}
@@ -0,0 +1,133 @@
+//go:generate go run ../../../../cmd/asyncapi-codegen -p mocks -g application,user,types,mocks -i ./asyncapi.yaml -o ./asyncapi.gen.go
+
+package mocks
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, NewSuite())
+}
+
+type Suite struct {
+	suite.Suite
+}
+
+func NewSuite() *Suite {
+	return &Suite{}
+}
+
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// pingService is the code under test: it answers pings and notifies users,
+// using only the controller interface.
+type pingService struct {
+	ctrl AppControllerInterface
+}
+
+func (s pingService) Start(ctx context.Context) error {
+	return s.ctrl.SubscribeToReceivePingOperation(ctx, func(ctx context.Context, msg PingMessage) error {
+		return s.ctrl.ReplyToReceivePingOperation(ctx, msg, func(replyMsg *PongMessage) {
+			replyMsg.Payload = "pong to " + msg.Payload
+		})
+	})
+}
+
+func (s pingService) Notify(ctx context.Context, userID, text string) error {
+	msg := NewNotificationMessage()
+	msg.Payload.Text = &text
+	return s.ctrl.SendAsSendNotificationOperation(ctx, NotificationChannelParameters{UserId: userID}, msg)
+}
+
+func (suite *Suite) TestFakeAppControllerRecordsSentMessages() {
+	fake := NewFakeAppController()
+	svc := pingService{ctrl: fake}
+
+	suite.Require().NoError(svc.Notify(context.Background(), "1234", "hello"))
+	suite.Require().NoError(svc.Notify(context.Background(), "5678", "world"))
+
+	sent := fake.SentSendNotificationOperationMessages()
+	suite.Require().Len(sent, 2)
+	suite.Require().Equal("hello", *sent[0].Payload.Text)
+	suite.Require().Equal("world", *sent[1].Payload.Text)
+}
+
+func (suite *Suite) TestFakeAppControllerInjectsReceivedMessages() {
+	fake := NewFakeAppController()
+	svc := pingService{ctrl: fake}
+	suite.Require().NoError(svc.Start(context.Background()))
+
+	ping := NewPingMessage()
+	ping.Payload = "ping"
+	suite.Require().NoError(fake.InjectReceivePingOperation(context.Background(), ping))
+
+	sent := fake.SentReplyToReceivePingOperationMessages()
+	suite.Require().Len(sent, 1)
+	suite.Require().Equal("pong to ping", sent[0].Payload)
+	suite.Require().Equal(ping.CorrelationID(), sent[0].CorrelationID())
+
+	// Subscribing twice should fail as with the real controller
+	suite.Require().ErrorIs(svc.Start(context.Background()), extensions.ErrAlreadySubscribedChannel)
+
+	// Injecting after unsubscription should fail
+	fake.Close(context.Background())
+	err := fake.InjectReceivePingOperation(context.Background(), ping)
+	suite.Require().ErrorIs(err, extensions.ErrNotSubscribedChannel)
+}
+
+func (suite *Suite) TestFakeUserControllerStubsRequestReplies() {
+	fake := NewFakeUserController()
+
+	// Without reply stub
+	_, err := fake.RequestToReceivePingOperation(context.Background(), NewPingMessage())
+	suite.Require().ErrorIs(err, extensions.ErrNoFakeReply)
+
+	// With reply stub
+	fake.RequestToReceivePingOperationReply = func(_ context.Context, msg PingMessage) (PongMessage, error) {
+		pong := NewPongMessage()
+		pong.SetAsResponseFrom(&msg)
+		pong.Payload = "pong"
+		return pong, nil
+	}
+
+	var ctrl UserControllerInterface = fake
+	ping := NewPingMessage()
+	ping.Payload = "ping"
+	pong, err := ctrl.RequestToReceivePingOperation(context.Background(), ping)
+	suite.Require().NoError(err)
+	suite.Require().Equal("pong", pong.Payload)
+	suite.Require().Equal(ping.CorrelationID(), pong.CorrelationID())
+
+	// Both requests should have been recorded
+	suite.Require().Len(fake.SentReceivePingOperationMessages(), 2)
+}
+
+func (suite *Suite) TestFakeUserControllerInjectsParameterizedMessages() {
+	fake := NewFakeUserController()
+
+	var received []string
+	params := NotificationChannelParameters{UserId: "1234"}
+	err := fake.SubscribeToSendNotificationOperation(context.Background(), params,
+		func(_ context.Context, msg NotificationMessage) error {
+			received = append(received, *msg.Payload.Text)
+			return nil
+		})
+	suite.Require().NoError(err)
+
+	msg := NewNotificationMessage()
+	msg.Payload.Text = Ptr("hello")
+	suite.Require().NoError(fake.InjectSendNotificationOperation(context.Background(), params, msg))
+	suite.Require().Equal([]string{"hello"}, received)
+
+	// Another user has not been subscribed
+	err = fake.InjectSendNotificationOperation(context.Background(),
+		NotificationChannelParameters{UserId: "5678"}, msg)
+	suite.Require().ErrorIs(err, extensions.ErrNotSubscribedChannel)
+}
@@ -0,0 +1,227 @@
+//go:generate go run ../../../../cmd/asyncapi-codegen -p multimessage -i ./asyncapi.yaml -o ./asyncapi.gen.go
+
+package multimessage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers/inmemory"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, NewSuite())
+}
+
+type Suite struct {
+	suite.Suite
+	broker *inmemory.Controller
+	app    *AppController
+	user   *UserController
+	errs   chan error
+}
+
+func NewSuite() *Suite {
+	return &Suite{}
+}
+
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+func (suite *Suite) SetupTest() {
+	broker := inmemory.NewBroker()
+	suite.errs = make(chan error, 1)
+
+	var err error
+	suite.broker, err = inmemory.NewController(inmemory.WithBroker(broker))
+	suite.Require().NoError(err)
+	suite.app, err = NewAppController(suite.broker,
+		WithErrorHandler(func(_ context.Context, _ string, _ *extensions.AcknowledgeableBrokerMessage, err error) {
+			// Don't block on redelivered messages
+			select {
+			case suite.errs <- err:
+			default:
+			}
+		}))
+	suite.Require().NoError(err)
+
+	userBroker, err := inmemory.NewController(inmemory.WithBroker(broker))
+	suite.Require().NoError(err)
+	suite.user, err = NewUserController(userBroker)
+	suite.Require().NoError(err)
+}
+
+func (suite *Suite) TearDownTest() {
+	suite.app.Close(context.Background())
+	suite.user.Close(context.Background())
+}
+
+func (suite *Suite) subscribeToUserEvents() chan ReceiveUserEventOperationMessage {
+	received := make(chan ReceiveUserEventOperationMessage, 1)
+	err := suite.app.SubscribeToReceiveUserEventOperation(
+		context.Background(),
+		func(_ context.Context, msg ReceiveUserEventOperationMessage) error {
+			received <- msg
+			return nil
+		})
+	suite.Require().NoError(err)
+	return received
+}
+
+func (suite *Suite) waitFor(received chan ReceiveUserEventOperationMessage) ReceiveUserEventOperationMessage {
+	select {
+	case msg := <-received:
+		return msg
+	case err := <-suite.errs:
+		suite.FailNow("unexpected error", err)
+	case <-time.After(time.Second):
+		suite.FailNow("no message received")
+	}
+	return nil
+}
+
+func (suite *Suite) TestDispatchByHeader() {
+	received := suite.subscribeToUserEvents()
+
+	created := NewUserCreatedMessage()
+	created.Payload.Name = Ptr("john")
+	suite.Require().NoError(suite.user.SendToReceiveUserEventOperation(context.Background(), created))
+	suite.Require().Equal(created, suite.waitFor(received))
+
+	deleted := NewUserDeletedMessage()
+	deleted.Payload.Id = Ptr("1234")
+	suite.Require().NoError(suite.user.SendToReceiveUserEventOperation(context.Background(), deleted))
+	suite.Require().Equal(deleted, suite.waitFor(received))
+}
+
+func (suite *Suite) TestDispatchByDiscriminator() {
+	received := suite.subscribeToUserEvents()
+
+	// Publish without message name in headers
+	err := suite.broker.Publish(context.Background(), UsersChannelPath, extensions.BrokerMessage{
+		Headers: map[string][]byte{},
+		Payload: []byte(`{"event":"user.deleted","id":"1234"}`),
+	})
+	suite.Require().NoError(err)
+
+	msg, ok := suite.waitFor(received).(UserDeletedMessage)
+	suite.Require().True(ok)
+	suite.Require().Equal("1234", *msg.Payload.Id)
+}
+
+func (suite *Suite) TestDispatchByConstant() {
+	received := make(chan ReceiveShapeOperationMessage, 1)
+	err := suite.app.SubscribeToReceiveShapeOperation(
+		context.Background(),
+		func(_ context.Context, msg ReceiveShapeOperationMessage) error {
+			received <- msg
+			return nil
+		})
+	suite.Require().NoError(err)
+
+	// Publish without message name in headers, the constant 'kind' property
+	// is used as discriminator
+	err = suite.broker.Publish(context.Background(), ShapesChannelPath, extensions.BrokerMessage{
+		Headers: map[string][]byte{},
+		Payload: []byte(`{"kind":"square","side":2}`),
+	})
+	suite.Require().NoError(err)
+
+	select {
+	case msg := <-received:
+		square, ok := msg.(SquareMessage)
+		suite.Require().True(ok)
+		suite.Require().Equal(2.0, square.Payload.Side)
+	case err := <-suite.errs:
+		suite.FailNow("unexpected error", err)
+	case <-time.After(time.Second):
+		suite.FailNow("no message received")
+	}
+}
+
+func (suite *Suite) TestUnknownMessage() {
+	suite.subscribeToUserEvents()
+
+	err := suite.broker.Publish(context.Background(), UsersChannelPath, extensions.BrokerMessage{
+		Headers: map[string][]byte{extensions.MessageNameHeader: []byte("UserUpdated")},
+		Payload: []byte(`{}`),
+	})
+	suite.Require().NoError(err)
+
+	select {
+	case err := <-suite.errs:
+		suite.Require().ErrorIs(err, extensions.ErrUnknownMessage)
+	case <-time.After(time.Second):
+		suite.FailNow("no error received")
+	}
+}
+
+func (suite *Suite) TestNoDiscriminator() {
+	suite.subscribeToUserEvents()
+
+	// Without message name nor discriminator, the message is not guessed
+	err := suite.broker.Publish(context.Background(), UsersChannelPath, extensions.BrokerMessage{
+		Headers: map[string][]byte{},
+		Payload: []byte(`{"id":"1234"}`),
+	})
+	suite.Require().NoError(err)
+
+	select {
+	case err := <-suite.errs:
+		suite.Require().ErrorIs(err, extensions.ErrUnknownMessage)
+	case <-time.After(time.Second):
+		suite.FailNow("no error received")
+	}
+}
+
+func (suite *Suite) TestDispatchByHeaderOnly() {
+	type received struct {
+		msg           ReceiveOrderEventOperationMessage
+		correlationID any
+	}
+	ch := make(chan received, 1)
+	err := suite.app.SubscribeToReceiveOrderEventOperation(
+		context.Background(),
+		func(ctx context.Context, msg ReceiveOrderEventOperationMessage) error {
+			ch <- received{msg: msg, correlationID: ctx.Value(extensions.ContextKeyIsCorrelationID)}
+			return nil
+		})
+	suite.Require().NoError(err)
+
+	// Messages without payload discriminator are identified by the header,
+	// and keep the correlation ID set when sending
+	canceled := NewOrderCanceledMessage()
+	canceled.Payload.Reason = Ptr("out of stock")
+	suite.Require().NoError(suite.user.SendToReceiveOrderEventOperation(context.Background(), canceled))
+
+	select {
+	case r := <-ch:
+		msg, ok := r.msg.(OrderCanceledMessage)
+		suite.Require().True(ok)
+		suite.Require().Equal("out of stock", *msg.Payload.Reason)
+		suite.Require().NotEmpty(msg.CorrelationID())
+		suite.Require().Equal(msg.CorrelationID(), r.correlationID)
+	case err := <-suite.errs:
+		suite.FailNow("unexpected error", err)
+	case <-time.After(time.Second):
+		suite.FailNow("no message received")
+	}
+
+	// Without message name, the message can't be identified
+	err = suite.broker.Publish(context.Background(), OrdersChannelPath, extensions.BrokerMessage{
+		Headers: map[string][]byte{},
+		Payload: []byte(`{"reason":"out of stock"}`),
+	})
+	suite.Require().NoError(err)
+
+	select {
+	case err := <-suite.errs:
+		suite.Require().ErrorIs(err, extensions.ErrUnknownMessage)
+	case <-time.After(time.Second):
+		suite.FailNow("no error received")
+	}
+}
@@ -0,0 +1,220 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers/inmemory"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/outbox"
+	"github.com/stretchr/testify/suite"
+	_ "modernc.org/sqlite"
+)
+
+func TestOutboxSuite(t *testing.T) {
+	suite.Run(t, new(OutboxSuite))
+}
+
+type publication struct {
+	channel string
+	msg     extensions.BrokerMessage
+}
+
+// recordingBroker is a broker controller that records the publications, and
+// fails them on the channels in failing.
+type recordingBroker struct {
+	extensions.BrokerController
+
+	mutex        sync.Mutex
+	failing      map[string]bool
+	publications []publication
+}
+
+func (b *recordingBroker) Publish(_ context.Context, channel string, bm extensions.BrokerMessage) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.failing[channel] {
+		return errors.New("publication failed")
+	}
+	b.publications = append(b.publications, publication{channel: channel, msg: bm})
+
+	return nil
+}
+
+type OutboxSuite struct {
+	suite.Suite
+	db         *sql.DB
+	broker     *recordingBroker
+	controller *outbox.Controller
+}
+
+func (suite *OutboxSuite) SetupTest() {
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(suite.T().TempDir(), "outbox.db")+
+		"?_pragma=busy_timeout(5000)")
+	suite.Require().NoError(err)
+	suite.db = db
+
+	suite.broker = &recordingBroker{failing: make(map[string]bool)}
+	suite.controller = outbox.NewController(suite.broker, db)
+	suite.Require().NoError(suite.controller.CreateTable(context.Background()))
+}
+
+func (suite *OutboxSuite) TearDownTest() {
+	suite.Require().NoError(suite.db.Close())
+}
+
+func (suite *OutboxSuite) outboxCount() int {
+	var count int
+	suite.Require().NoError(suite.db.QueryRow("SELECT COUNT(*) FROM " + outbox.DefaultTableName).Scan(&count))
+	return count
+}
+
+func (suite *OutboxSuite) TestPublishWithoutTransaction() {
+	msg := extensions.BrokerMessage{
+		Headers: map[string][]byte{"correlationId": []byte("1234")},
+		Payload: []byte(`{"hello":"world"}`),
+		Key:     []byte("1234"),
+	}
+	suite.Require().NoError(suite.controller.Publish(context.Background(), "ping", msg))
+	suite.Require().Empty(suite.broker.publications)
+	suite.Require().Equal(1, suite.outboxCount())
+
+	count, err := suite.controller.NewRelay().Process(context.Background())
+	suite.Require().NoError(err)
+	suite.Require().Equal(1, count)
+	suite.Require().Equal([]publication{{channel: "ping", msg: msg}}, suite.broker.publications)
+	suite.Require().Equal(0, suite.outboxCount())
+}
+
+func (suite *OutboxSuite) TestPublishWithTransaction() {
+	msg := extensions.BrokerMessage{Headers: map[string][]byte{}, Payload: []byte("ping")}
+
+	// Rolled back messages should not be forwarded
+	tx, err := suite.db.Begin()
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.controller.Publish(outbox.ContextWithTransaction(context.Background(), tx), "ping", msg))
+	suite.Require().NoError(tx.Rollback())
+	suite.Require().Equal(0, suite.outboxCount())
+
+	// Committed messages should be forwarded
+	tx, err = suite.db.Begin()
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.controller.Publish(outbox.ContextWithTransaction(context.Background(), tx), "ping", msg))
+	suite.Require().Equal(0, suite.outboxCount())
+	suite.Require().NoError(tx.Commit())
+	suite.Require().Equal(1, suite.outboxCount())
+
+	count, err := suite.controller.NewRelay().Process(context.Background())
+	suite.Require().NoError(err)
+	suite.Require().Equal(1, count)
+	suite.Require().Equal([]publication{{channel: "ping", msg: msg}}, suite.broker.publications)
+}
+
+func (suite *OutboxSuite) TestRelayOrderingPerChannel() {
+	for _, p := range []publication{
+		{channel: "a", msg: extensions.BrokerMessage{Payload: []byte("a1")}},
+		{channel: "b", msg: extensions.BrokerMessage{Payload: []byte("b1")}},
+		{channel: "a", msg: extensions.BrokerMessage{Payload: []byte("a2")}},
+		{channel: "b", msg: extensions.BrokerMessage{Payload: []byte("b2")}},
+	} {
+		suite.Require().NoError(suite.controller.Publish(context.Background(), p.channel, p.msg))
+	}
+	relay := suite.controller.NewRelay()
+
+	// Channel 'a' fails: its messages should wait, but not the ones of 'b'
+	suite.broker.failing["a"] = true
+	count, err := relay.Process(context.Background())
+	suite.Require().Error(err)
+	suite.Require().Equal(2, count)
+	suite.Require().Equal(2, suite.outboxCount())
+
+	// Messages of 'a' should be forwarded in order on next poll
+	suite.broker.failing["a"] = false
+	count, err = relay.Process(context.Background())
+	suite.Require().NoError(err)
+	suite.Require().Equal(2, count)
+
+	payloads := make([]string, 0, len(suite.broker.publications))
+	for _, p := range suite.broker.publications {
+		payloads = append(payloads, string(p.msg.Payload))
+	}
+	suite.Require().Equal([]string{"b1", "b2", "a1", "a2"}, payloads)
+}
+
+func (suite *OutboxSuite) TestRelayBlockedChannelDoesNotStarve() {
+	// Channel 'a' has more messages than the batch size, before the ones of 'b'
+	for _, p := range []publication{
+		{channel: "a", msg: extensions.BrokerMessage{Payload: []byte("a1")}},
+		{channel: "a", msg: extensions.BrokerMessage{Payload: []byte("a2")}},
+		{channel: "a", msg: extensions.BrokerMessage{Payload: []byte("a3")}},
+		{channel: "a", msg: extensions.BrokerMessage{Payload: []byte("a4")}},
+		{channel: "b", msg: extensions.BrokerMessage{Payload: []byte("b1")}},
+		{channel: "c", msg: extensions.BrokerMessage{Payload: []byte("c1")}},
+	} {
+		suite.Require().NoError(suite.controller.Publish(context.Background(), p.channel, p.msg))
+	}
+	relay := suite.controller.NewRelay(outbox.WithBatchSize(3))
+
+	// Channel 'a' fails: the messages of the other channels should still be
+	// forwarded
+	suite.broker.failing["a"] = true
+	count, err := relay.Process(context.Background())
+	suite.Require().Error(err)
+	suite.Require().Equal(2, count)
+	suite.Require().Equal(4, suite.outboxCount())
+
+	payloads := make([]string, 0, len(suite.broker.publications))
+	for _, p := range suite.broker.publications {
+		payloads = append(payloads, string(p.msg.Payload))
+	}
+	suite.Require().Equal([]string{"b1", "c1"}, payloads)
+}
+
+func (suite *OutboxSuite) TestRelayBatchSize() {
+	for i := 0; i < 5; i++ {
+		suite.Require().NoError(suite.controller.Publish(context.Background(), "ping",
+			extensions.BrokerMessage{Payload: []byte("ping")}))
+	}
+
+	count, err := suite.controller.NewRelay(outbox.WithBatchSize(3)).Process(context.Background())
+	suite.Require().NoError(err)
+	suite.Require().Equal(3, count)
+	suite.Require().Equal(2, suite.outboxCount())
+}
+
+func (suite *OutboxSuite) TestRelayRun() {
+	broker, err := inmemory.NewController()
+	suite.Require().NoError(err)
+	controller := outbox.NewController(broker, suite.db)
+
+	sub, err := controller.Subscribe(context.Background(), "ping")
+	suite.Require().NoError(err)
+	defer sub.Cancel(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan any)
+	go func() {
+		controller.NewRelay(outbox.WithInterval(10 * time.Millisecond)).Run(ctx)
+		close(done)
+	}()
+
+	suite.Require().NoError(controller.Publish(context.Background(), "ping",
+		extensions.BrokerMessage{Headers: map[string][]byte{}, Payload: []byte("ping")}))
+
+	select {
+	case msg := <-sub.MessagesChannel():
+		suite.Require().Equal([]byte("ping"), msg.Payload)
+		msg.Ack()
+	case <-time.After(time.Second):
+		suite.FailNow("message should have been forwarded")
+	}
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,106 @@
+//go:generate go run -C ../../../.. ./cmd/asyncapi-codegen -p outbox -g application,user,types -i ./test/v3/features/outbox/asyncapi.yaml -o ./test/v3/features/outbox/asyncapi.gen.go
+
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers/inmemory"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/outbox"
+	"github.com/stretchr/testify/suite"
+	_ "modernc.org/sqlite"
+)
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, NewSuite())
+}
+
+type Suite struct {
+	suite.Suite
+	db    *sql.DB
+	relay *outbox.Relay
+	app   *AppController
+	user  *UserController
+}
+
+func NewSuite() *Suite {
+	return &Suite{}
+}
+
+func (suite *Suite) SetupTest() {
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(suite.T().TempDir(), "outbox.db")+
+		"?_pragma=busy_timeout(5000)")
+	suite.Require().NoError(err)
+	suite.db = db
+
+	broker := inmemory.NewBroker()
+
+	appBroker, err := inmemory.NewController(inmemory.WithBroker(broker))
+	suite.Require().NoError(err)
+	suite.app, err = NewAppController(appBroker)
+	suite.Require().NoError(err)
+
+	userBroker, err := inmemory.NewController(inmemory.WithBroker(broker))
+	suite.Require().NoError(err)
+	outboxController := outbox.NewController(userBroker, db)
+	suite.Require().NoError(outboxController.CreateTable(context.Background()))
+	suite.relay = outboxController.NewRelay()
+	suite.user, err = NewUserController(outboxController)
+	suite.Require().NoError(err)
+}
+
+func (suite *Suite) TearDownTest() {
+	suite.app.Close(context.Background())
+	suite.user.Close(context.Background())
+	suite.Require().NoError(suite.db.Close())
+}
+
+func (suite *Suite) TestTransaction() {
+	recvChan := make(chan string, 2)
+	err := suite.app.SubscribeToReceiveOrdersOperation(context.Background(),
+		func(_ context.Context, msg OrderMessageFromOrdersChannel) error {
+			recvChan <- *msg.Payload.Id
+			return nil
+		})
+	suite.Require().NoError(err)
+
+	send := func(id string, commit bool) {
+		tx, err := suite.db.Begin()
+		suite.Require().NoError(err)
+
+		msg := NewOrderMessageFromOrdersChannel()
+		msg.Payload.Id = &id
+		err = suite.user.SendToReceiveOrdersOperation(outbox.ContextWithTransaction(context.Background(), tx), msg)
+		suite.Require().NoError(err)
+
+		if commit {
+			suite.Require().NoError(tx.Commit())
+		} else {
+			suite.Require().NoError(tx.Rollback())
+		}
+	}
+	send("rolled-back", false)
+	send("committed", true)
+
+	// Nothing should be received before the relay forwards the messages
+	select {
+	case id := <-recvChan:
+		suite.FailNow("message should not be received before relay", id)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	count, err := suite.relay.Process(context.Background())
+	suite.Require().NoError(err)
+	suite.Require().Equal(1, count)
+
+	select {
+	case id := <-recvChan:
+		suite.Require().Equal("committed", id)
+	case <-time.After(time.Second):
+		suite.FailNow("message should have been received")
+	}
+}
@@ -0,0 +1,183 @@
+//go:generate go run ../../../../cmd/asyncapi-codegen -p retry -i ./asyncapi.yaml -o ./asyncapi.gen.go
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers/inmemory"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/middlewares"
+	"github.com/stretchr/testify/suite"
+)
+
+var errProcessing = errors.New("processing error")
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, NewSuite())
+}
+
+type Suite struct {
+	suite.Suite
+	broker *inmemory.Controller
+	app    *AppController
+	user   *UserController
+	errs   chan error
+}
+
+func NewSuite() *Suite {
+	return &Suite{}
+}
+
+func (suite *Suite) SetupTest() {
+	broker := inmemory.NewBroker()
+	suite.errs = make(chan error, 10)
+
+	var err error
+	suite.broker, err = inmemory.NewController(inmemory.WithBroker(broker))
+	suite.Require().NoError(err)
+	suite.app, err = NewAppController(
+		suite.broker,
+		WithMiddlewares(middlewares.Retry(suite.broker,
+			middlewares.WithMaxAttempts(3),
+			middlewares.WithBackoff(middlewares.FixedBackoff(time.Millisecond)))),
+		WithErrorHandler(func(_ context.Context, _ string, _ *extensions.AcknowledgeableBrokerMessage, err error) {
+			suite.errs <- err
+		}))
+	suite.Require().NoError(err)
+
+	userBroker, err := inmemory.NewController(inmemory.WithBroker(broker))
+	suite.Require().NoError(err)
+	suite.user, err = NewUserController(userBroker)
+	suite.Require().NoError(err)
+}
+
+func (suite *Suite) TearDownTest() {
+	suite.app.Close(context.Background())
+	suite.user.Close(context.Background())
+}
+
+func (suite *Suite) TestRetryUntilSuccess() {
+	// Listen on the channel as another subscriber
+	other, err := suite.broker.Subscribe(context.Background(), TaskChannelPath)
+	suite.Require().NoError(err)
+	defer other.Cancel(context.Background())
+
+	attempts := make(chan struct{}, 3)
+	err = suite.app.SubscribeToReceiveTaskOperation(context.Background(), func(_ context.Context, _ TaskMessage) error {
+		attempts <- struct{}{}
+		if len(attempts) < 3 {
+			return errProcessing
+		}
+		return nil
+	})
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(suite.user.SendToReceiveTaskOperation(context.Background(), NewTaskMessage()))
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-attempts:
+		case <-time.After(time.Second):
+			suite.FailNow("message not retried", "attempt %d", i+1)
+		}
+	}
+
+	select {
+	case err := <-suite.errs:
+		suite.FailNow("error should be handled by retry middleware", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The other subscriber should receive the message only once
+	<-other.MessagesChannel()
+	select {
+	case <-other.MessagesChannel():
+		suite.FailNow("message received twice by another subscriber")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func (suite *Suite) TestCloseKeepsAttempts() {
+	app, err := NewAppController(
+		suite.broker,
+		WithMiddlewares(middlewares.Retry(suite.broker,
+			middlewares.WithBackoff(middlewares.FixedBackoff(time.Hour)))),
+		WithErrorHandler(func(_ context.Context, _ string, _ *extensions.AcknowledgeableBrokerMessage, err error) {
+			suite.errs <- err
+		}))
+	suite.Require().NoError(err)
+
+	attempted := make(chan struct{}, 1)
+	err = app.SubscribeToReceiveTaskOperation(context.Background(), func(_ context.Context, _ TaskMessage) error {
+		attempted <- struct{}{}
+		return errProcessing
+	})
+	suite.Require().NoError(err)
+
+	// Listen on the channel, as another instance would
+	other, err := suite.broker.Subscribe(context.Background(), TaskChannelPath)
+	suite.Require().NoError(err)
+	defer other.Cancel(context.Background())
+
+	suite.Require().NoError(suite.user.SendToReceiveTaskOperation(context.Background(), NewTaskMessage()))
+	select {
+	case <-attempted:
+	case <-time.After(time.Second):
+		suite.FailNow("message not received")
+	}
+	bm := <-other.MessagesChannel()
+	suite.Require().Empty(bm.Headers[middlewares.RetryAttemptsHeader])
+	bm.Ack()
+
+	// Closing the controller should stop waiting for the backoff and publish
+	// the message again with its attempts
+	app.Close(context.Background())
+	select {
+	case bm := <-other.MessagesChannel():
+		suite.Require().Equal("1", string(bm.Headers[middlewares.RetryAttemptsHeader]))
+		bm.Ack()
+	case <-time.After(time.Second):
+		suite.FailNow("backoff not interrupted")
+	}
+
+	// The received message should have been acknowledged
+	select {
+	case err := <-suite.errs:
+		suite.FailNow("unexpected error", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func (suite *Suite) TestDeadLetter() {
+	// Listen on dead-letter channel
+	dlq, err := suite.broker.Subscribe(context.Background(), TaskChannelPath+middlewares.DefaultDeadLetterSuffix)
+	suite.Require().NoError(err)
+	defer dlq.Cancel(context.Background())
+
+	// Always fail
+	err = suite.app.SubscribeToReceiveTaskOperation(context.Background(), func(_ context.Context, _ TaskMessage) error {
+		return errProcessing
+	})
+	suite.Require().NoError(err)
+
+	msg := NewTaskMessage()
+	msg.Payload.Name = &[]string{"task"}[0]
+	suite.Require().NoError(suite.user.SendToReceiveTaskOperation(context.Background(), msg))
+
+	select {
+	case bm := <-dlq.MessagesChannel():
+		suite.Require().Equal("3", string(bm.Headers[middlewares.RetryAttemptsHeader]))
+		suite.Require().Equal(TaskChannelPath, string(bm.Headers[middlewares.DeadLetterChannelHeader]))
+		suite.Require().Equal(errProcessing.Error(), string(bm.Headers[middlewares.DeadLetterErrorHeader]))
+
+		received, err := brokerMessageToTaskMessage(bm.BrokerMessage)
+		suite.Require().NoError(err)
+		suite.Require().Equal(msg.Payload, received.Payload)
+	case <-time.After(time.Second):
+		suite.FailNow("message not sent to dead-letter channel")
+	}
+}
@@ -0,0 +1,117 @@
+//go:generate go run ../../../../cmd/asyncapi-codegen -p servers -g application,user,types,servers -i ./asyncapi.yaml -o ./asyncapi.gen.go
+
+package servers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/codegen"
+	"github.com/lerenn/asyncapi-codegen/pkg/codegen/options"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, NewSuite())
+}
+
+type Suite struct {
+	suite.Suite
+}
+
+func NewSuite() *Suite {
+	return &Suite{}
+}
+
+func (suite *Suite) TestDescriptors() {
+	suite.Require().Equal("production", ProductionServer.Name)
+	suite.Require().Equal("kafka-secure", ProductionServer.Protocol)
+	suite.Require().Equal([]string{"eu", "us"}, ProductionServer.Variables["region"].Enum)
+	suite.Require().Equal(StagingServer, Servers["staging"])
+	suite.Require().Len(Servers, 3)
+	suite.Require().Equal([]extensions.SecurityScheme{{
+		Name:        "saslScram",
+		Type:        "scramSha512",
+		Description: "SCRAM credentials of the service account.",
+	}}, ProductionServer.Security)
+	suite.Require().Empty(StagingServer.Security)
+}
+
+func (suite *Suite) TestVariables() {
+	// Default value should be used for port
+	srv, err := NewProductionServer(ProductionServerVariables{Region: "eu"})
+	suite.Require().NoError(err)
+	suite.Require().Equal("eu.kafka.example.com:9093", srv.Host)
+
+	srv, err = NewProductionServer(ProductionServerVariables{Region: "us", Port: "19093"})
+	suite.Require().NoError(err)
+	suite.Require().Equal("us.kafka.example.com:19093", srv.Host)
+
+	// Region is required and should be in the enum
+	_, err = NewProductionServer(ProductionServerVariables{})
+	suite.Require().ErrorIs(err, extensions.ErrInvalidServerVariable)
+	_, err = NewProductionServer(ProductionServerVariables{Region: "asia"})
+	suite.Require().ErrorIs(err, extensions.ErrInvalidServerVariable)
+}
+
+func (suite *Suite) TestNewBrokerControllerFromServerErrors() {
+	_, err := NewBrokerControllerFromServer("unknown", nil)
+	suite.Require().ErrorIs(err, extensions.ErrUnknownServer)
+
+	_, err = NewBrokerControllerFromServer("production", map[string]string{"region": "asia"})
+	suite.Require().ErrorIs(err, extensions.ErrInvalidServerVariable)
+
+	_, err = NewBrokerControllerFromServer("staging", map[string]string{"region": "eu"})
+	suite.Require().ErrorIs(err, extensions.ErrInvalidServerVariable)
+
+	_, err = NewBrokerControllerFromServer("websocket", nil)
+	suite.Require().ErrorIs(err, extensions.ErrUnsupportedProtocol)
+}
+
+func (suite *Suite) TestNewBrokerControllerFromServerWithCredentials() {
+	var server string
+	var scheme extensions.SecurityScheme
+	provider := extensions.CredentialsProviderFunc(
+		func(_ context.Context, srv string, sch extensions.SecurityScheme) (extensions.Credentials, error) {
+			server, scheme = srv, sch
+			return extensions.Credentials{}, nil
+		})
+
+	_, err := NewBrokerControllerFromServerWithCredentials(context.Background(),
+		"production", map[string]string{"region": "eu"}, provider)
+	suite.Require().ErrorIs(err, extensions.ErrMissingCredentials)
+	suite.Require().Equal("production", server)
+	suite.Require().Equal("saslScram", scheme.Name)
+}
+
+func (suite *Suite) TestFactoryIsOptIn() {
+	cg, err := codegen.FromFile("./asyncapi.yaml")
+	suite.Require().NoError(err)
+
+	opt := options.Options{
+		PackageName:  "servers",
+		OutputDir:    ".",
+		ConvertKeys:  "none",
+		NamingScheme: "none",
+		Generate:     options.GeneratorOptions{Application: true, User: true, Types: true},
+	}
+
+	// The descriptors are generated without the factory depending on brokers
+	files, err := cg.GenerateFiles(opt)
+	suite.Require().NoError(err)
+	suite.Require().NotContains(files, "servers.gen.go")
+	suite.Require().Contains(string(files["types.gen.go"]), "var ProductionServer = extensions.Server{")
+	for name, content := range files {
+		suite.Require().NotContains(string(content), "pkg/extensions/brokers/", name)
+		suite.Require().NotContains(string(content), "NewBrokerControllerFromServer", name)
+	}
+
+	// The factory is generated in its own part
+	opt.Generate.Servers = true
+	files, err = cg.GenerateFiles(opt)
+	suite.Require().NoError(err)
+	suite.Require().Contains(string(files["servers.gen.go"]), "func NewBrokerControllerFromServer(")
+	suite.Require().Contains(string(files["servers.gen.go"]), "pkg/extensions/brokers/kafka")
+	suite.Require().NotContains(string(files["types.gen.go"]), "pkg/extensions/brokers/")
+}
@@ -0,0 +1,121 @@
+//go:generate go run ../../../../cmd/asyncapi-codegen -p shutdown -i ./asyncapi.yaml -o ./asyncapi.gen.go
+
+package shutdown
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers/inmemory"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, NewSuite())
+}
+
+type Suite struct {
+	suite.Suite
+	app  *AppController
+	user *UserController
+}
+
+func NewSuite() *Suite {
+	return &Suite{}
+}
+
+func (suite *Suite) SetupTest() {
+	broker := inmemory.NewBroker()
+
+	appBroker, err := inmemory.NewController(inmemory.WithBroker(broker))
+	suite.Require().NoError(err)
+	suite.app, err = NewAppController(appBroker, WithConcurrency(2))
+	suite.Require().NoError(err)
+
+	userBroker, err := inmemory.NewController(inmemory.WithBroker(broker))
+	suite.Require().NoError(err)
+	suite.user, err = NewUserController(userBroker)
+	suite.Require().NoError(err)
+}
+
+func (suite *Suite) TearDownTest() {
+	suite.app.Close(context.Background())
+	suite.user.Close(context.Background())
+}
+
+// subscribe subscribes the app with a handler that waits for release, and
+// returns a channel receiving the index of the tasks when they start.
+func (suite *Suite) subscribe(release chan any, processed *atomic.Int32) chan int64 {
+	started := make(chan int64, 2)
+	err := suite.app.SubscribeToReceiveTaskOperation(context.Background(),
+		func(_ context.Context, msg TaskMessageFromTaskChannel) error {
+			started <- *msg.Payload.Index
+			<-release
+			processed.Add(1)
+			return nil
+		})
+	suite.Require().NoError(err)
+
+	return started
+}
+
+func (suite *Suite) send(index int64) {
+	msg := NewTaskMessageFromTaskChannel()
+	msg.Payload.Index = &index
+	suite.Require().NoError(suite.user.SendToReceiveTaskOperation(context.Background(), msg))
+}
+
+func (suite *Suite) TestShutdownWaitsForHandlers() {
+	release := make(chan any)
+	var processed atomic.Int32
+	started := suite.subscribe(release, &processed)
+
+	// Wait for the handlers to be running
+	suite.send(1)
+	suite.send(2)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			suite.FailNow("handler should have started")
+		}
+	}
+
+	// Release the handlers after the shutdown has started
+	time.AfterFunc(20*time.Millisecond, func() { close(release) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	suite.Require().NoError(suite.app.Shutdown(ctx))
+	suite.Require().Equal(int32(2), processed.Load())
+}
+
+func (suite *Suite) TestShutdownTimeout() {
+	release := make(chan any)
+	defer close(release)
+	var processed atomic.Int32
+	started := suite.subscribe(release, &processed)
+
+	suite.send(1)
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		suite.FailNow("handler should have started")
+	}
+
+	// The handler is still running when the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := suite.app.Shutdown(ctx)
+	suite.Require().ErrorIs(err, extensions.ErrSubscriptionNotDrained)
+	suite.Require().ErrorContains(err, "v3.features.shutdown.task")
+	suite.Require().Equal(int32(0), processed.Load())
+}
+
+func (suite *Suite) TestShutdownWithoutSubscription() {
+	suite.Require().NoError(suite.app.Shutdown(context.Background()))
+	suite.Require().NoError(suite.user.Shutdown(context.Background()))
+}
@@ -0,0 +1,513 @@
+// Package "splitfiles" provides primitives to interact with the AsyncAPI specification.
+//
+// Code generated by github.com/lerenn/asyncapi-codegen version v0.45.1 DO NOT EDIT.
+package splitfiles
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+
+	"github.com/google/uuid"
+)
+
+// AppControllerInterface is the interface of AppController.
+// It can be used to replace the controller by FakeAppController in tests.
+type AppControllerInterface interface {
+	// Close will clean up any existing resources on the controller
+	Close(ctx context.Context)
+
+	// Shutdown will gracefully stop the controller, waiting for the messages
+	// being processed until the context is done
+	Shutdown(ctx context.Context) error
+
+	// SubscribeToAllChannels will receive messages from channels where channel has
+	// no parameter on which the app is expecting messages.
+	SubscribeToAllChannels(ctx context.Context, as AppSubscriber) error
+	// UnsubscribeFromAllChannels will stop the subscription of all remaining subscribed channels
+	UnsubscribeFromAllChannels(ctx context.Context)
+
+	// SubscribeToReceivePingOperation will receive Ping messages from Ping channel.
+	SubscribeToReceivePingOperation(
+		ctx context.Context,
+		fn func(ctx context.Context, msg PingMessage) error,
+		opts ...extensions.SubscribeOption,
+	) error
+	// ReplyToReceivePingOperation will reply to a Ping message.
+	ReplyToReceivePingOperation(ctx context.Context, recvMsg PingMessage, fn func(replyMsg *PongMessage)) error
+	// UnsubscribeFromReceivePingOperation will stop the reception of Ping messages from Ping channel.
+	UnsubscribeFromReceivePingOperation(
+		ctx context.Context,
+	)
+
+	// SendAsReplyToReceivePingOperation will send a Pong message on Pong channel.
+	SendAsReplyToReceivePingOperation(
+		ctx context.Context,
+		msg PongMessage,
+	) error
+
+	// SendAsSendNotificationOperation will send a Notification message on Notification channel.
+	SendAsSendNotificationOperation(
+		ctx context.Context,
+		params NotificationChannelParameters,
+		msg NotificationMessage,
+	) error
+}
+
+// Check that AppController and FakeAppController implement
+// AppControllerInterface.
+var (
+	_ AppControllerInterface = (*AppController)(nil)
+	_ AppControllerInterface = (*FakeAppController)(nil)
+)
+
+// FakeAppController is a fake implementation of AppControllerInterface
+// that doesn't use any broker, in order to test code using AppController.
+//
+// It records the sent messages for each operation, calls the subscription
+// functions with the messages injected with Inject methods, and replies to
+// requests with the Reply functions set on it.
+type FakeAppController struct {
+	mutex                           sync.Mutex
+	subscriptions                   map[string]any
+	sentReplyToReceivePingOperation []PongMessage
+	sentSendNotificationOperation   []NotificationMessage
+}
+
+// NewFakeAppController creates a new FakeAppController.
+func NewFakeAppController() *FakeAppController {
+	return &FakeAppController{
+		subscriptions: make(map[string]any),
+	}
+}
+
+// Close will remove every subscription of the fake controller
+func (c *FakeAppController) Close(ctx context.Context) {
+	c.UnsubscribeFromAllChannels(ctx)
+}
+
+// Shutdown will remove every subscription of the fake controller. As injected
+// messages are processed synchronously, there is no message to wait for.
+func (c *FakeAppController) Shutdown(ctx context.Context) error {
+	c.Close(ctx)
+	return nil
+}
+
+// SubscribeToAllChannels will subscribe to channels where channel has no
+// parameter on which the fake controller is expecting messages.
+func (c *FakeAppController) SubscribeToAllChannels(ctx context.Context, as AppSubscriber) error {
+	if as == nil {
+		return extensions.ErrNilAppSubscriber
+	}
+
+	if err := c.SubscribeToReceivePingOperation(ctx, as.ReceivePingOperationReceived); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UnsubscribeFromAllChannels will stop the subscription of all remaining subscribed channels
+func (c *FakeAppController) UnsubscribeFromAllChannels(ctx context.Context) {
+	c.UnsubscribeFromReceivePingOperation(ctx)
+}
+
+func (c *FakeAppController) subscribe(addr string, fn any) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, exists := c.subscriptions[addr]; exists {
+		return fmt.Errorf("%w: controller is already subscribed on channel %q", extensions.ErrAlreadySubscribedChannel, addr)
+	}
+	c.subscriptions[addr] = fn
+
+	return nil
+}
+
+func (c *FakeAppController) subscription(addr string) (any, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	fn, exists := c.subscriptions[addr]
+	if !exists {
+		return nil, fmt.Errorf("%w: no subscription on channel %q", extensions.ErrNotSubscribedChannel, addr)
+	}
+
+	return fn, nil
+}
+
+func (c *FakeAppController) unsubscribe(addr string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.subscriptions, addr)
+}
+
+// SubscribeToReceivePingOperation will register 'fn' to be called with the
+// messages injected with InjectReceivePingOperation. The subscription
+// options are ignored.
+func (c *FakeAppController) SubscribeToReceivePingOperation(
+	_ context.Context,
+	fn func(ctx context.Context, msg PingMessage) error,
+	_ ...extensions.SubscribeOption,
+) error {
+	return c.subscribe("v3.features.splitfiles.ping", fn)
+}
+
+// InjectReceivePingOperation will call the function subscribed with
+// SubscribeToReceivePingOperation with the given message, as if it
+// was received from Ping channel.
+// It returns the error returned by the subscribed function.
+func (c *FakeAppController) InjectReceivePingOperation(
+	ctx context.Context,
+	msg PingMessage,
+) error {
+	fn, err := c.subscription("v3.features.splitfiles.ping")
+	if err != nil {
+		return err
+	}
+
+	return fn.(func(ctx context.Context, msg PingMessage) error)(ctx, msg)
+}
+
+// ReplyToReceivePingOperation is a helper function to
+// reply to a Ping message with a Pong message on Pong channel.
+func (c *FakeAppController) ReplyToReceivePingOperation(ctx context.Context, recvMsg PingMessage, fn func(replyMsg *PongMessage)) error {
+	// Create reply message
+	replyMsg := NewPongMessage()
+	replyMsg.SetAsResponseFrom(&recvMsg)
+
+	// Execute callback function
+	fn(&replyMsg)
+
+	// Send reply
+	return c.SendAsReplyToReceivePingOperation(ctx, replyMsg)
+}
+
+// UnsubscribeFromReceivePingOperation will remove the function subscribed with
+// SubscribeToReceivePingOperation.
+func (c *FakeAppController) UnsubscribeFromReceivePingOperation(
+	_ context.Context,
+) {
+	c.unsubscribe("v3.features.splitfiles.ping")
+}
+
+// SendAsReplyToReceivePingOperation will record the Pong message,
+// that can be retrieved with SentReplyToReceivePingOperationMessages.
+func (c *FakeAppController) SendAsReplyToReceivePingOperation(
+	_ context.Context,
+	msg PongMessage,
+) error {
+	// Set correlation ID if it does not exist
+	if id := msg.CorrelationID(); id == "" {
+		return extensions.ErrNoCorrelationIDSet
+
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.sentReplyToReceivePingOperation = append(c.sentReplyToReceivePingOperation, msg)
+
+	return nil
+}
+
+// SentReplyToReceivePingOperationMessages returns the messages sent with
+// SendAsReplyToReceivePingOperation, in sending order.
+func (c *FakeAppController) SentReplyToReceivePingOperationMessages() []PongMessage {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	msgs := make([]PongMessage, len(c.sentReplyToReceivePingOperation))
+	copy(msgs, c.sentReplyToReceivePingOperation)
+
+	return msgs
+}
+
+// SendAsSendNotificationOperation will record the Notification message,
+// that can be retrieved with SentSendNotificationOperationMessages.
+func (c *FakeAppController) SendAsSendNotificationOperation(
+	_ context.Context,
+	_ NotificationChannelParameters,
+	msg NotificationMessage,
+) error {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.sentSendNotificationOperation = append(c.sentSendNotificationOperation, msg)
+
+	return nil
+}
+
+// SentSendNotificationOperationMessages returns the messages sent with
+// SendAsSendNotificationOperation, in sending order.
+func (c *FakeAppController) SentSendNotificationOperationMessages() []NotificationMessage {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	msgs := make([]NotificationMessage, len(c.sentSendNotificationOperation))
+	copy(msgs, c.sentSendNotificationOperation)
+
+	return msgs
+}
+
+// UserControllerInterface is the interface of UserController.
+// It can be used to replace the controller by FakeUserController in tests.
+type UserControllerInterface interface {
+	// Close will clean up any existing resources on the controller
+	Close(ctx context.Context)
+
+	// Shutdown will gracefully stop the controller, waiting for the messages
+	// being processed until the context is done
+	Shutdown(ctx context.Context) error
+
+	// SubscribeToAllChannels will receive messages from channels where channel has
+	// no parameter on which the app is expecting messages.
+	SubscribeToAllChannels(ctx context.Context, as UserSubscriber) error
+	// UnsubscribeFromAllChannels will stop the subscription of all remaining subscribed channels
+	UnsubscribeFromAllChannels(ctx context.Context)
+
+	// SubscribeToSendNotificationOperation will receive Notification messages from Notification channel.
+	SubscribeToSendNotificationOperation(
+		ctx context.Context,
+		params NotificationChannelParameters,
+		fn func(ctx context.Context, msg NotificationMessage) error,
+		opts ...extensions.SubscribeOption,
+	) error
+	// SubscribeToSendNotificationOperationAllParameters will receive Notification messages from Notification channel,
+	// for every value of the channel parameters.
+	SubscribeToSendNotificationOperationAllParameters(
+		ctx context.Context,
+		fn func(ctx context.Context, params NotificationChannelParameters, msg NotificationMessage) error,
+		opts ...extensions.SubscribeOption,
+	) error
+	// UnsubscribeFromSendNotificationOperation will stop the reception of Notification messages from Notification channel.
+	UnsubscribeFromSendNotificationOperation(
+		ctx context.Context,
+		params NotificationChannelParameters,
+	)
+	// UnsubscribeFromSendNotificationOperationAllParameters will stop the reception of Notification messages from Notification channel,
+	// subscribed with SubscribeToSendNotificationOperationAllParameters.
+	UnsubscribeFromSendNotificationOperationAllParameters(ctx context.Context)
+
+	// SendToReceivePingOperation will send a Ping message on Ping channel.
+	SendToReceivePingOperation(
+		ctx context.Context,
+		msg PingMessage,
+	) error
+	// RequestToReceivePingOperation will send a Ping message on Ping channel
+	// and wait for a Pong message from Pong channel.
+	RequestToReceivePingOperation(
+		ctx context.Context,
+		msg PingMessage,
+	) (PongMessage, error)
+}
+
+// Check that UserController and FakeUserController implement
+// UserControllerInterface.
+var (
+	_ UserControllerInterface = (*UserController)(nil)
+	_ UserControllerInterface = (*FakeUserController)(nil)
+)
+
+// FakeUserController is a fake implementation of UserControllerInterface
+// that doesn't use any broker, in order to test code using UserController.
+//
+// It records the sent messages for each operation, calls the subscription
+// functions with the messages injected with Inject methods, and replies to
+// requests with the Reply functions set on it.
+type FakeUserController struct {
+	mutex                    sync.Mutex
+	subscriptions            map[string]any
+	sentReceivePingOperation []PingMessage
+
+	// RequestToReceivePingOperationReply is called by RequestToReceivePingOperation
+	// to get the reply to the request. If not set, ErrNoFakeReply is returned.
+	RequestToReceivePingOperationReply func(
+		ctx context.Context,
+		msg PingMessage,
+	) (PongMessage, error)
+}
+
+// NewFakeUserController creates a new FakeUserController.
+func NewFakeUserController() *FakeUserController {
+	return &FakeUserController{
+		subscriptions: make(map[string]any),
+	}
+}
+
+// Close will remove every subscription of the fake controller
+func (c *FakeUserController) Close(ctx context.Context) {
+	c.UnsubscribeFromAllChannels(ctx)
+}
+
+// Shutdown will remove every subscription of the fake controller. As injected
+// messages are processed synchronously, there is no message to wait for.
+func (c *FakeUserController) Shutdown(ctx context.Context) error {
+	c.Close(ctx)
+	return nil
+}
+
+// SubscribeToAllChannels will subscribe to channels where channel has no
+// parameter on which the fake controller is expecting messages.
+func (c *FakeUserController) SubscribeToAllChannels(ctx context.Context, as UserSubscriber) error {
+	if as == nil {
+		return extensions.ErrNilUserSubscriber
+	}
+
+	return nil
+}
+
+// UnsubscribeFromAllChannels will stop the subscription of all remaining subscribed channels
+func (c *FakeUserController) UnsubscribeFromAllChannels(ctx context.Context) {
+	c.UnsubscribeFromSendNotificationOperationAllParameters(ctx)
+}
+
+func (c *FakeUserController) subscribe(addr string, fn any) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, exists := c.subscriptions[addr]; exists {
+		return fmt.Errorf("%w: controller is already subscribed on channel %q", extensions.ErrAlreadySubscribedChannel, addr)
+	}
+	c.subscriptions[addr] = fn
+
+	return nil
+}
+
+func (c *FakeUserController) subscription(addr string) (any, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	fn, exists := c.subscriptions[addr]
+	if !exists {
+		return nil, fmt.Errorf("%w: no subscription on channel %q", extensions.ErrNotSubscribedChannel, addr)
+	}
+
+	return fn, nil
+}
+
+func (c *FakeUserController) unsubscribe(addr string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.subscriptions, addr)
+}
+
+// SubscribeToSendNotificationOperation will register 'fn' to be called with the
+// messages injected with InjectSendNotificationOperation. The subscription
+// options are ignored.
+func (c *FakeUserController) SubscribeToSendNotificationOperation(
+	_ context.Context,
+	params NotificationChannelParameters,
+	fn func(ctx context.Context, msg NotificationMessage) error,
+	_ ...extensions.SubscribeOption,
+) error {
+	return c.subscribe(fmt.Sprintf("v3.features.splitfiles.notification.%s", params.UserId), fn)
+}
+
+// SubscribeToSendNotificationOperationAllParameters will register 'fn' to be called with the
+// messages injected with InjectSendNotificationOperation, for every value of the channel parameters.
+// The subscription options are ignored.
+func (c *FakeUserController) SubscribeToSendNotificationOperationAllParameters(
+	_ context.Context,
+	fn func(ctx context.Context, params NotificationChannelParameters, msg NotificationMessage) error,
+	_ ...extensions.SubscribeOption,
+) error {
+	return c.subscribe(NotificationChannelPath, fn)
+}
+
+// InjectSendNotificationOperation will call the function subscribed with
+// SubscribeToSendNotificationOperation (or SubscribeToSendNotificationOperationAllParameters) with the given message, as if it
+// was received from Notification channel.
+// It returns the error returned by the subscribed function.
+func (c *FakeUserController) InjectSendNotificationOperation(
+	ctx context.Context,
+	params NotificationChannelParameters,
+	msg NotificationMessage,
+) error {
+	fn, err := c.subscription(fmt.Sprintf("v3.features.splitfiles.notification.%s", params.UserId))
+	if err != nil {
+		// Use the subscription for every value of the channel parameters, if any
+		allFn, allErr := c.subscription(NotificationChannelPath)
+		if allErr != nil {
+			return err
+		}
+
+		return allFn.(func(ctx context.Context, params NotificationChannelParameters, msg NotificationMessage) error)(ctx, params, msg)
+	}
+
+	return fn.(func(ctx context.Context, msg NotificationMessage) error)(ctx, msg)
+}
+
+// UnsubscribeFromSendNotificationOperation will remove the function subscribed with
+// SubscribeToSendNotificationOperation.
+func (c *FakeUserController) UnsubscribeFromSendNotificationOperation(
+	_ context.Context,
+	params NotificationChannelParameters,
+) {
+	c.unsubscribe(fmt.Sprintf("v3.features.splitfiles.notification.%s", params.UserId))
+}
+
+// UnsubscribeFromSendNotificationOperationAllParameters will remove the function subscribed with
+// SubscribeToSendNotificationOperationAllParameters.
+func (c *FakeUserController) UnsubscribeFromSendNotificationOperationAllParameters(_ context.Context) {
+	c.unsubscribe(NotificationChannelPath)
+}
+
+// SendToReceivePingOperation will record the Ping message,
+// that can be retrieved with SentReceivePingOperationMessages.
+func (c *FakeUserController) SendToReceivePingOperation(
+	_ context.Context,
+	msg PingMessage,
+) error {
+	// Set correlation ID if it does not exist
+	if id := msg.CorrelationID(); id == "" {
+		msg.SetCorrelationID(uuid.New().String())
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.sentReceivePingOperation = append(c.sentReceivePingOperation, msg)
+
+	return nil
+}
+
+// SentReceivePingOperationMessages returns the messages sent with
+// SendToReceivePingOperation and RequestToReceivePingOperation, in sending order.
+func (c *FakeUserController) SentReceivePingOperationMessages() []PingMessage {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	msgs := make([]PingMessage, len(c.sentReceivePingOperation))
+	copy(msgs, c.sentReceivePingOperation)
+
+	return msgs
+}
+
+// RequestToReceivePingOperation will record the Ping message
+// and return the reply from RequestToReceivePingOperationReply.
+func (c *FakeUserController) RequestToReceivePingOperation(
+	ctx context.Context,
+	msg PingMessage,
+) (PongMessage, error) {
+	// Set correlation ID if it does not exist
+	if id := msg.CorrelationID(); id == "" {
+		msg.SetCorrelationID(uuid.New().String())
+	}
+
+	// Record the request
+	if err := c.SendToReceivePingOperation(ctx, msg); err != nil {
+		return PongMessage{}, err
+	}
+
+	// Get the reply
+	if c.RequestToReceivePingOperationReply == nil {
+		return PongMessage{}, extensions.ErrNoFakeReply
+	}
+	return c.RequestToReceivePingOperationReply(ctx, msg)
+}
@@ -0,0 +1,180 @@
+//go:generate go run ../../../../cmd/asyncapi-codegen -p splitfiles -g application,user,types,mocks -i ./asyncapi.yaml -d .
+
+package splitfiles
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/codegen"
+	"github.com/lerenn/asyncapi-codegen/pkg/codegen/options"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers/inmemory"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, NewSuite())
+}
+
+type Suite struct {
+	suite.Suite
+}
+
+func NewSuite() *Suite {
+	return &Suite{}
+}
+
+func (suite *Suite) TestGeneratedFilesCompileTogether() {
+	broker, err := inmemory.NewController()
+	suite.Require().NoError(err)
+
+	app, err := NewAppController(broker)
+	suite.Require().NoError(err)
+	defer app.Close(context.Background())
+
+	user, err := NewUserController(broker)
+	suite.Require().NoError(err)
+	defer user.Close(context.Background())
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	err = app.SubscribeToReceivePingOperation(context.Background(), func(ctx context.Context, msg PingMessage) error {
+		defer wg.Done()
+		return app.ReplyToReceivePingOperation(ctx, msg, func(replyMsg *PongMessage) {
+			replyMsg.Payload = "pong"
+		})
+	})
+	suite.Require().NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	pong, err := user.RequestToReceivePingOperation(ctx, NewPingMessage())
+	suite.Require().NoError(err)
+	suite.Require().Equal("pong", pong.Payload)
+	wg.Wait()
+}
+
+func (suite *Suite) TestGenerateFiles() {
+	cg, err := codegen.FromFile("./asyncapi.yaml")
+	suite.Require().NoError(err)
+
+	files, err := cg.GenerateFiles(options.Options{
+		PackageName:  "splitfiles",
+		OutputDir:    ".",
+		ConvertKeys:  "none",
+		NamingScheme: "none",
+		Generate:     options.GeneratorOptions{Application: true, User: true, Types: true, Mocks: true},
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(files, 4)
+
+	// Generated files should be the same as the ones on disk
+	for _, name := range []string{"app.gen.go", "user.gen.go", "types.gen.go", "mocks.gen.go"} {
+		content, err := os.ReadFile(filepath.Join(".", name))
+		suite.Require().NoError(err)
+		suite.Require().Equal(string(content), string(files[name]), name)
+	}
+
+	// Without output directory, there should be only one file
+	files, err = cg.GenerateFiles(options.Options{
+		PackageName:  "splitfiles",
+		OutputPath:   "./out/asyncapi.gen.go",
+		ConvertKeys:  "none",
+		NamingScheme: "none",
+		Generate:     options.GeneratorOptions{Types: true},
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(files, 1)
+	suite.Require().Contains(string(files["asyncapi.gen.go"]), "package splitfiles")
+}
+
+func (suite *Suite) TestGenerateFilesOptionsDontLeak() {
+	cg, err := codegen.FromFile("./asyncapi.yaml")
+	suite.Require().NoError(err)
+
+	opt := options.Options{
+		PackageName:   "splitfiles",
+		OutputDir:     ".",
+		ConvertKeys:   "none",
+		NamingScheme:  "none",
+		ForcePointers: true,
+		Generate:      options.GeneratorOptions{Types: true},
+	}
+	files, err := cg.GenerateFiles(opt)
+	suite.Require().NoError(err)
+	suite.Require().Regexp(`Text +\*string`, string(files["types.gen.go"]))
+
+	// Forcing pointers should not be kept for the next generation
+	opt.ForcePointers = false
+	files, err = cg.GenerateFiles(opt)
+	suite.Require().NoError(err)
+
+	content, err := os.ReadFile("./types.gen.go")
+	suite.Require().NoError(err)
+	suite.Require().Equal(string(content), string(files["types.gen.go"]))
+}
+
+func (suite *Suite) TestGenerateFilesWithoutOutput() {
+	cg, err := codegen.FromFile("./asyncapi.yaml")
+	suite.Require().NoError(err)
+
+	_, err = cg.GenerateFiles(options.Options{
+		PackageName: "splitfiles",
+		Generate:    options.GeneratorOptions{Types: true},
+	})
+	suite.Require().ErrorIs(err, codegen.ErrNoOutputPath)
+}
+
+func (suite *Suite) TestGenerateFilesConcurrently() {
+	opt := func(forcePointers bool) options.Options {
+		return options.Options{
+			PackageName:   "splitfiles",
+			OutputDir:     ".",
+			ConvertKeys:   "none",
+			NamingScheme:  "none",
+			ForcePointers: forcePointers,
+			Generate:      options.GeneratorOptions{Types: true},
+		}
+	}
+
+	content, err := os.ReadFile("./types.gen.go")
+	suite.Require().NoError(err)
+
+	// Each generation should use its own options
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func(forcePointers bool) {
+			defer wg.Done()
+
+			cg, err := codegen.FromFile("./asyncapi.yaml")
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			files, err := cg.GenerateFiles(opt(forcePointers))
+			switch {
+			case err != nil:
+				errs <- err
+			case forcePointers && string(files["types.gen.go"]) == string(content):
+				errs <- errors.New("pointers not forced")
+			case !forcePointers && string(files["types.gen.go"]) != string(content):
+				errs <- errors.New("pointers forced")
+			}
+		}(i%2 == 0)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		suite.Require().NoError(err)
+	}
+}
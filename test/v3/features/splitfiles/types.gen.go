@@ -0,0 +1,474 @@
+// Package "splitfiles" provides primitives to interact with the AsyncAPI specification.
+//
+// Code generated by github.com/lerenn/asyncapi-codegen version v0.45.1 DO NOT EDIT.
+package splitfiles
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+
+	"github.com/google/uuid"
+)
+
+// AsyncAPIVersion is the version of the used AsyncAPI document
+const AsyncAPIVersion = "1.2.3"
+
+// controller is the controller that will be used to communicate with the broker
+// It will be used internally by AppController and UserController
+type controller struct {
+	// broker is the broker controller that will be used to communicate
+	broker extensions.BrokerController
+	// subscriptions is a map of all subscriptions
+	subscriptions map[string]subscription
+	// subscriptionsMutex protects the subscriptions map
+	subscriptionsMutex sync.Mutex
+	// logger is the logger that will be used² to log operations on controller
+	logger extensions.Logger
+	// middlewares are the middlewares that will be executed when sending or
+	// receiving messages
+	middlewares []extensions.Middleware
+	// handler to handle errors from consumers and middlewares
+	errorHandler extensions.ErrorHandler
+	// validation is set if messages should be validated against the constraints
+	// of the AsyncAPI specification
+	validation bool
+	// concurrency is the maximum number of messages processed in parallel
+	// for each subscription
+	concurrency int
+	// concurrencyKey is the key extractor used to process messages with the
+	// same key in order when processing them in parallel
+	concurrencyKey extensions.KeyExtractor
+	// autoUnsubscribe is set if subscriptions should be stopped when the
+	// context given on subscription is done
+	autoUnsubscribe bool
+}
+
+// subscription is a subscription to a broker channel, with the tracker of the
+// messages being processed by its listener
+type subscription struct {
+	broker   extensions.BrokerChannelSubscription
+	listener *extensions.ListenerTracker
+}
+
+// ControllerOption is the type of the options that can be passed
+// when creating a new Controller
+type ControllerOption func(controller *controller)
+
+// WithLogger attaches a logger to the controller
+func WithLogger(logger extensions.Logger) ControllerOption {
+	return func(controller *controller) {
+		controller.logger = logger
+	}
+}
+
+// WithMiddlewares attaches middlewares that will be executed when sending or receiving messages
+func WithMiddlewares(middlewares ...extensions.Middleware) ControllerOption {
+	return func(controller *controller) {
+		controller.middlewares = middlewares
+	}
+}
+
+// WithErrorHandler attaches a errorhandler to handle errors from subscriber functions
+func WithErrorHandler(handler extensions.ErrorHandler) ControllerOption {
+	return func(controller *controller) {
+		controller.errorHandler = handler
+	}
+}
+
+// WithValidation enables the validation of messages against the constraints of
+// the AsyncAPI specification: invalid messages are rejected when sent, and
+// given to the error handler instead of the subscriber when received.
+func WithValidation() ControllerOption {
+	return func(controller *controller) {
+		controller.validation = true
+	}
+}
+
+// WithConcurrency sets the maximum number of messages processed in parallel for
+// each subscription (default is 1). If a key extractor is given (i.e.
+// extensions.KeyFromHeader), messages with the same key are processed
+// sequentially, in the order they were received.
+func WithConcurrency(n int, key ...extensions.KeyExtractor) ControllerOption {
+	return func(controller *controller) {
+		controller.concurrency = n
+		if len(key) > 0 {
+			controller.concurrencyKey = key[0]
+		}
+	}
+}
+
+// WithAutoUnsubscribe stops a subscription when the context given on
+// subscription is done, as if the corresponding unsubscribe function was called.
+func WithAutoUnsubscribe() ControllerOption {
+	return func(controller *controller) {
+		controller.autoUnsubscribe = true
+	}
+}
+
+// unsubscribeWhenDone stops the subscription on the channel when the context is
+// done, unless its listener has been stopped before (i.e. unsubscribed).
+func (c *controller) unsubscribeWhenDone(ctx context.Context, addr string, listener *extensions.ListenerTracker) {
+	// Wait for the context to be done or the listener to be stopped
+	if err := listener.WaitStopped(ctx); err == nil {
+		return
+	}
+
+	// Remove the subscription if it is still the same
+	c.subscriptionsMutex.Lock()
+	sub, exists := c.subscriptions[addr]
+	if !exists || sub.listener != listener {
+		c.subscriptionsMutex.Unlock()
+		return
+	}
+	delete(c.subscriptions, addr)
+	c.subscriptionsMutex.Unlock()
+
+	// Stop the subscription, waiting for the broker clean up even if the
+	// context is done, and cancel the context of the messages being processed
+	sub.listener.CancelProcessing()
+	sub.broker.Cancel(context.WithoutCancel(ctx))
+
+	c.logger.Info(ctx, "Unsubscribed from channel as context is done")
+}
+
+type MessageWithCorrelationID interface {
+	CorrelationID() string
+	SetCorrelationID(id string)
+}
+
+type Error struct {
+	Channel string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("channel %q: err %v", e.Channel, e.Err)
+}
+
+// NotificationChannelParameters represents NotificationChannel channel parameters
+type NotificationChannelParameters struct {
+	// UserId is a channel parameter: Id of the user
+	UserId string
+}
+
+// NewNotificationChannelParametersFromAddress returns the NotificationChannel channel parameters
+// from the address of a channel (i.e. the address of a received message).
+func NewNotificationChannelParametersFromAddress(addr string) (NotificationChannelParameters, error) {
+	values, err := extensions.ChannelAddressParameters(NotificationChannelPath, addr)
+	if err != nil {
+		return NotificationChannelParameters{}, err
+	}
+
+	return NotificationChannelParameters{
+		UserId: values["userId"],
+	}, nil
+}
+
+// Message 'NotificationMessageFromNotificationChannel' reference another one at '#/components/messages/Notification'.
+// This should be fixed in a future version to allow message override.
+// If you encounter this message, feel free to open an issue on this subject
+// to let know that you need this functionnality.
+
+// Message 'PingMessageFromPingChannel' reference another one at '#/components/messages/Ping'.
+// This should be fixed in a future version to allow message override.
+// If you encounter this message, feel free to open an issue on this subject
+// to let know that you need this functionnality.
+
+// Message 'PongMessageFromPongChannel' reference another one at '#/components/messages/Pong'.
+// This should be fixed in a future version to allow message override.
+// If you encounter this message, feel free to open an issue on this subject
+// to let know that you need this functionnality.
+
+// NotificationMessagePayload is a schema from the AsyncAPI specification required in messages
+type NotificationMessagePayload struct {
+	Text string `json:"text"`
+}
+
+// Validate checks that NotificationMessagePayload respects the constraints of the AsyncAPI specification.
+func (s NotificationMessagePayload) Validate() error {
+	return nil
+}
+
+// NotificationMessage is the message expected for 'NotificationMessage' channel.
+type NotificationMessage struct {
+	// Payload will be inserted in the message payload
+	Payload NotificationMessagePayload
+}
+
+func NewNotificationMessage() NotificationMessage {
+	var msg NotificationMessage
+
+	return msg
+}
+
+// brokerMessageToNotificationMessage will fill a new NotificationMessage with data from generic broker message
+func brokerMessageToNotificationMessage(bMsg extensions.BrokerMessage) (NotificationMessage, error) {
+	var msg NotificationMessage
+
+	// Get codec from the message content type, or from the one in specification
+	codec, err := extensions.CodecForBrokerMessage(bMsg, "application/json")
+	if err != nil {
+		return msg, err
+	}
+
+	// Unmarshal payload to expected message payload format
+	err = codec.Unmarshal(bMsg.Payload, &msg.Payload)
+	if err != nil {
+		return msg, err
+	}
+
+	return msg, nil
+}
+
+// toBrokerMessage will generate a generic broker message from NotificationMessage data
+func (msg NotificationMessage) toBrokerMessage() (extensions.BrokerMessage, error) {
+
+	// Get codec from the content type in specification
+	codec := extensions.SpecificationCodec("application/json")
+
+	// Marshal payload with the codec
+	payload, err := codec.Marshal(msg.Payload)
+	if err != nil {
+		return extensions.BrokerMessage{}, err
+	}
+
+	// There is no headers here
+	headers := make(map[string][]byte, 0)
+
+	// Set content type, so the receiver can decode the payload
+	headers[extensions.ContentTypeHeader] = []byte("application/json")
+
+	return extensions.BrokerMessage{
+		Headers: headers,
+		Payload: payload,
+	}, nil
+}
+
+// Validate checks that NotificationMessage respects the constraints of the AsyncAPI specification.
+func (msg NotificationMessage) Validate() error {
+	if err := msg.Payload.Validate(); err != nil {
+		return extensions.WithValidationField("payload", err)
+	}
+	return nil
+}
+
+// HeadersFromPingMessage is a schema from the AsyncAPI specification required in messages
+type HeadersFromPingMessage struct {
+	CorrelationId *string `json:"correlationId,omitempty"`
+}
+
+// Validate checks that HeadersFromPingMessage respects the constraints of the AsyncAPI specification.
+func (s HeadersFromPingMessage) Validate() error {
+	return nil
+}
+
+// PingMessage is the message expected for 'PingMessage' channel.
+type PingMessage struct {
+	// Headers will be used to fill the message headers
+	Headers HeadersFromPingMessage
+
+	// Payload will be inserted in the message payload
+	Payload string
+}
+
+func NewPingMessage() PingMessage {
+	var msg PingMessage
+
+	// Set correlation ID
+	u := uuid.New().String()
+	msg.Headers.CorrelationId = &u
+
+	return msg
+}
+
+// brokerMessageToPingMessage will fill a new PingMessage with data from generic broker message
+func brokerMessageToPingMessage(bMsg extensions.BrokerMessage) (PingMessage, error) {
+	var msg PingMessage
+
+	// Convert to string
+	payload := string(bMsg.Payload)
+	msg.Payload = payload // No need for type conversion to reference
+
+	// Get each headers from broker message
+	for k, v := range bMsg.Headers {
+		switch {
+		case k == "correlationId": // Retrieving CorrelationId header
+			h := string(v)
+			msg.Headers.CorrelationId = &h
+		default:
+			// TODO: log unknown error
+		}
+	}
+
+	return msg, nil
+}
+
+// toBrokerMessage will generate a generic broker message from PingMessage data
+func (msg PingMessage) toBrokerMessage() (extensions.BrokerMessage, error) {
+
+	// Convert to []byte
+	payload := []byte(msg.Payload)
+
+	// Add each headers to broker message
+	headers := make(map[string][]byte, 1)
+
+	// Adding CorrelationId header
+	if msg.Headers.CorrelationId != nil {
+		headers["correlationId"] = []byte(*msg.Headers.CorrelationId)
+	}
+
+	return extensions.BrokerMessage{
+		Headers: headers,
+		Payload: payload,
+	}, nil
+}
+
+// Validate checks that PingMessage respects the constraints of the AsyncAPI specification.
+func (msg PingMessage) Validate() error {
+	if err := msg.Headers.Validate(); err != nil {
+		return extensions.WithValidationField("headers", err)
+	}
+	return nil
+}
+
+// CorrelationID will give the correlation ID of the message, based on AsyncAPI spec
+func (msg PingMessage) CorrelationID() string {
+	if msg.Headers.CorrelationId != nil {
+		return *msg.Headers.CorrelationId
+	}
+
+	return ""
+}
+
+// SetCorrelationID will set the correlation ID of the message, based on AsyncAPI spec
+func (msg *PingMessage) SetCorrelationID(id string) {
+	msg.Headers.CorrelationId = &id
+}
+
+// SetAsResponseFrom will correlate the message with the one passed in parameter.
+// It will assign the 'req' message correlation ID to the message correlation ID,
+// both specified in AsyncAPI spec.
+func (msg *PingMessage) SetAsResponseFrom(req MessageWithCorrelationID) {
+	id := req.CorrelationID()
+	msg.Headers.CorrelationId = &id
+}
+
+// HeadersFromPongMessage is a schema from the AsyncAPI specification required in messages
+type HeadersFromPongMessage struct {
+	CorrelationId *string `json:"correlationId,omitempty"`
+}
+
+// Validate checks that HeadersFromPongMessage respects the constraints of the AsyncAPI specification.
+func (s HeadersFromPongMessage) Validate() error {
+	return nil
+}
+
+// PongMessage is the message expected for 'PongMessage' channel.
+type PongMessage struct {
+	// Headers will be used to fill the message headers
+	Headers HeadersFromPongMessage
+
+	// Payload will be inserted in the message payload
+	Payload string
+}
+
+func NewPongMessage() PongMessage {
+	var msg PongMessage
+
+	// Set correlation ID
+	u := uuid.New().String()
+	msg.Headers.CorrelationId = &u
+
+	return msg
+}
+
+// brokerMessageToPongMessage will fill a new PongMessage with data from generic broker message
+func brokerMessageToPongMessage(bMsg extensions.BrokerMessage) (PongMessage, error) {
+	var msg PongMessage
+
+	// Convert to string
+	payload := string(bMsg.Payload)
+	msg.Payload = payload // No need for type conversion to reference
+
+	// Get each headers from broker message
+	for k, v := range bMsg.Headers {
+		switch {
+		case k == "correlationId": // Retrieving CorrelationId header
+			h := string(v)
+			msg.Headers.CorrelationId = &h
+		default:
+			// TODO: log unknown error
+		}
+	}
+
+	return msg, nil
+}
+
+// toBrokerMessage will generate a generic broker message from PongMessage data
+func (msg PongMessage) toBrokerMessage() (extensions.BrokerMessage, error) {
+
+	// Convert to []byte
+	payload := []byte(msg.Payload)
+
+	// Add each headers to broker message
+	headers := make(map[string][]byte, 1)
+
+	// Adding CorrelationId header
+	if msg.Headers.CorrelationId != nil {
+		headers["correlationId"] = []byte(*msg.Headers.CorrelationId)
+	}
+
+	return extensions.BrokerMessage{
+		Headers: headers,
+		Payload: payload,
+	}, nil
+}
+
+// Validate checks that PongMessage respects the constraints of the AsyncAPI specification.
+func (msg PongMessage) Validate() error {
+	if err := msg.Headers.Validate(); err != nil {
+		return extensions.WithValidationField("headers", err)
+	}
+	return nil
+}
+
+// CorrelationID will give the correlation ID of the message, based on AsyncAPI spec
+func (msg PongMessage) CorrelationID() string {
+	if msg.Headers.CorrelationId != nil {
+		return *msg.Headers.CorrelationId
+	}
+
+	return ""
+}
+
+// SetCorrelationID will set the correlation ID of the message, based on AsyncAPI spec
+func (msg *PongMessage) SetCorrelationID(id string) {
+	msg.Headers.CorrelationId = &id
+}
+
+// SetAsResponseFrom will correlate the message with the one passed in parameter.
+// It will assign the 'req' message correlation ID to the message correlation ID,
+// both specified in AsyncAPI spec.
+func (msg *PongMessage) SetAsResponseFrom(req MessageWithCorrelationID) {
+	id := req.CorrelationID()
+	msg.Headers.CorrelationId = &id
+}
+
+const (
+	// NotificationChannelPath is the constant representing the 'NotificationChannel' channel path.
+	NotificationChannelPath = "v3.features.splitfiles.notification.{userId}"
+	// PingChannelPath is the constant representing the 'PingChannel' channel path.
+	PingChannelPath = "v3.features.splitfiles.ping"
+	// PongChannelPath is the constant representing the 'PongChannel' channel path.
+	PongChannelPath = "v3.features.splitfiles.pong"
+)
+
+// ChannelsPaths is an array of all channels paths
+var ChannelsPaths = []string{
+	NotificationChannelPath,
+	PingChannelPath,
+	PongChannelPath,
+}
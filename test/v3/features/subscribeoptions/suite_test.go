@@ -0,0 +1,169 @@
+//go:generate go run ../../../../cmd/asyncapi-codegen -p subscribeoptions -i ./asyncapi.yaml -o ./asyncapi.gen.go
+
+package subscribeoptions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers/inmemory"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, NewSuite())
+}
+
+type Suite struct {
+	suite.Suite
+}
+
+func NewSuite() *Suite {
+	return &Suite{}
+}
+
+// subscribe subscribes a new app controller on the broker, with the options,
+// and returns the channel of the received events names.
+func (suite *Suite) subscribe(broker *inmemory.Broker, opts ...extensions.SubscribeOption) <-chan string {
+	bc, err := inmemory.NewController(inmemory.WithBroker(broker), inmemory.WithQueueGroup("shared"))
+	suite.Require().NoError(err)
+
+	app, err := NewAppController(bc)
+	suite.Require().NoError(err)
+	suite.T().Cleanup(func() { app.Close(context.Background()) })
+
+	received := make(chan string, 10)
+	err = app.SubscribeToReceiveEventsOperation(context.Background(),
+		func(_ context.Context, msg EventMessageFromEventsChannel) error {
+			received <- *msg.Payload.Name
+			return nil
+		}, opts...)
+	suite.Require().NoError(err)
+
+	return received
+}
+
+func (suite *Suite) send(broker *inmemory.Broker, name string) {
+	bc, err := inmemory.NewController(inmemory.WithBroker(broker))
+	suite.Require().NoError(err)
+
+	app, err := NewAppController(bc)
+	suite.Require().NoError(err)
+	defer app.Close(context.Background())
+
+	msg := NewEventMessageFromEventsChannel()
+	msg.Payload.Name = &name
+	suite.Require().NoError(app.SendAsSendEventsOperation(context.Background(), msg))
+}
+
+func (suite *Suite) receive(received <-chan string) string {
+	select {
+	case name := <-received:
+		return name
+	case <-time.After(time.Second):
+		suite.Require().FailNow("no message received")
+		return ""
+	}
+}
+
+func (suite *Suite) noReceive(received <-chan string) {
+	select {
+	case name := <-received:
+		suite.Require().FailNow("unexpected message received", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func (suite *Suite) TestQueueGroupOverride() {
+	broker := inmemory.NewBroker()
+
+	// Both subscriptions are in the controllers queue group: only one receives
+	shared1 := suite.subscribe(broker)
+	shared2 := suite.subscribe(broker)
+
+	// The subscription in its own queue group receives every message
+	feature := suite.subscribe(broker, extensions.WithQueueGroup("feature"), extensions.WithBufferSize(1))
+
+	suite.send(broker, "first")
+	suite.send(broker, "second")
+
+	suite.Require().Equal("first", suite.receive(shared1))
+	suite.Require().Equal("second", suite.receive(shared2))
+	suite.noReceive(shared1)
+	suite.noReceive(shared2)
+
+	suite.Require().Equal("first", suite.receive(feature))
+	suite.Require().Equal("second", suite.receive(feature))
+}
+
+func (suite *Suite) TestUnsupportedOption() {
+	bc, err := inmemory.NewController()
+	suite.Require().NoError(err)
+
+	app, err := NewAppController(bc)
+	suite.Require().NoError(err)
+	defer app.Close(context.Background())
+
+	// The in-memory broker doesn't keep messages to replay them
+	err = app.SubscribeToReceiveEventsOperation(context.Background(),
+		func(_ context.Context, _ EventMessageFromEventsChannel) error { return nil },
+		extensions.WithStartEarliest())
+	suite.Require().ErrorIs(err, extensions.ErrSubscribeOptionNotSupported)
+
+	// The failed subscription is not kept
+	err = app.SubscribeToReceiveEventsOperation(context.Background(),
+		func(_ context.Context, _ EventMessageFromEventsChannel) error { return nil })
+	suite.Require().NoError(err)
+}
+
+func (suite *Suite) TestWildcardQueueGroupOverride() {
+	broker := inmemory.NewBroker()
+
+	// Subscribe to every tenant, in the controllers queue group or in its own
+	subscribe := func(opts ...extensions.SubscribeOption) <-chan string {
+		bc, err := inmemory.NewController(inmemory.WithBroker(broker), inmemory.WithQueueGroup("shared"))
+		suite.Require().NoError(err)
+
+		app, err := NewAppController(bc)
+		suite.Require().NoError(err)
+		suite.T().Cleanup(func() { app.Close(context.Background()) })
+
+		received := make(chan string, 10)
+		err = app.SubscribeToReceiveTenantEventsOperationAllParameters(context.Background(),
+			func(_ context.Context, params TenantEventsChannelParameters, _ EventMessageFromTenantEventsChannel) error {
+				received <- params.TenantId
+				return nil
+			}, opts...)
+		suite.Require().NoError(err)
+
+		return received
+	}
+	shared1 := subscribe()
+	shared2 := subscribe()
+	feature := subscribe(extensions.WithQueueGroup("feature"))
+
+	bc, err := inmemory.NewController(inmemory.WithBroker(broker))
+	suite.Require().NoError(err)
+	app, err := NewAppController(bc)
+	suite.Require().NoError(err)
+	defer app.Close(context.Background())
+
+	for _, tenant := range []string{"1", "2"} {
+		suite.Require().NoError(app.SendAsSendTenantEventsOperation(context.Background(),
+			TenantEventsChannelParameters{TenantId: tenant}, NewEventMessageFromTenantEventsChannel()))
+	}
+
+	suite.Require().Equal("1", suite.receive(shared1))
+	suite.Require().Equal("2", suite.receive(shared2))
+	suite.Require().Equal("1", suite.receive(feature))
+	suite.Require().Equal("2", suite.receive(feature))
+
+	// Start position is not supported either with wildcards
+	err = app.SubscribeToReceiveTenantEventsOperationAllParameters(context.Background(),
+		func(_ context.Context, _ TenantEventsChannelParameters, _ EventMessageFromTenantEventsChannel) error {
+			return nil
+		}, extensions.WithStartEarliest())
+	suite.Require().ErrorIs(err, extensions.ErrSubscribeOptionNotSupported)
+}
@@ -0,0 +1,144 @@
+//go:generate go run ../../../../cmd/asyncapi-codegen -p subscriptions -i ./asyncapi.yaml -o ./asyncapi.gen.go
+
+package subscriptions
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers/inmemory"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, NewSuite())
+}
+
+type Suite struct {
+	suite.Suite
+	broker *inmemory.Broker
+	user   *UserController
+}
+
+func NewSuite() *Suite {
+	return &Suite{}
+}
+
+func (suite *Suite) SetupTest() {
+	suite.broker = inmemory.NewBroker()
+
+	userBroker, err := inmemory.NewController(inmemory.WithBroker(suite.broker))
+	suite.Require().NoError(err)
+	suite.user, err = NewUserController(userBroker)
+	suite.Require().NoError(err)
+}
+
+func (suite *Suite) TearDownTest() {
+	suite.user.Close(context.Background())
+}
+
+func (suite *Suite) newApp(options ...ControllerOption) *AppController {
+	appBroker, err := inmemory.NewController(inmemory.WithBroker(suite.broker))
+	suite.Require().NoError(err)
+	app, err := NewAppController(appBroker, options...)
+	suite.Require().NoError(err)
+
+	return app
+}
+
+func (suite *Suite) send(tenant, name string) {
+	msg := NewEventMessageFromTenantEventsChannel()
+	msg.Payload.Name = &name
+	err := suite.user.SendToReceiveTenantEventsOperation(context.Background(),
+		TenantEventsChannelParameters{TenantId: tenant}, msg)
+	suite.Require().NoError(err)
+}
+
+func (suite *Suite) TestConcurrentSubscriptions() {
+	app := suite.newApp()
+	defer app.Close(context.Background())
+
+	var wg sync.WaitGroup
+	var subscribed atomic.Int32
+	for i := 0; i < 20; i++ {
+		// Subscribe twice on the same tenant, and unsubscribe some tenants
+		for j := 0; j < 2; j++ {
+			wg.Add(1)
+			go func(tenant string, unsubscribe bool) {
+				defer wg.Done()
+
+				params := TenantEventsChannelParameters{TenantId: tenant}
+				err := app.SubscribeToReceiveTenantEventsOperation(context.Background(), params,
+					func(_ context.Context, _ EventMessageFromTenantEventsChannel) error { return nil })
+				if err != nil {
+					suite.ErrorIs(err, extensions.ErrAlreadySubscribedChannel)
+					return
+				}
+				subscribed.Add(1)
+
+				if unsubscribe {
+					app.UnsubscribeFromReceiveTenantEventsOperation(context.Background(), params)
+				}
+			}(fmt.Sprintf("tenant-%d", i), i%2 == 0 && j == 0)
+		}
+	}
+	wg.Wait()
+
+	// Every tenant should have been subscribed at least once
+	suite.Require().GreaterOrEqual(subscribed.Load(), int32(20))
+}
+
+func (suite *Suite) TestAutoUnsubscribe() {
+	app := suite.newApp(WithAutoUnsubscribe())
+	defer app.Close(context.Background())
+
+	params := TenantEventsChannelParameters{TenantId: "1234"}
+	received := make(chan string, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	err := app.SubscribeToReceiveTenantEventsOperation(ctx, params,
+		func(_ context.Context, msg EventMessageFromTenantEventsChannel) error {
+			received <- *msg.Payload.Name
+			return nil
+		})
+	suite.Require().NoError(err)
+
+	suite.send("1234", "before")
+	suite.Require().Equal("before", <-received)
+
+	// Cancel the context: the subscription should be stopped
+	cancel()
+	suite.Require().Eventually(func() bool {
+		err := app.SubscribeToReceiveTenantEventsOperation(context.Background(), params,
+			func(_ context.Context, msg EventMessageFromTenantEventsChannel) error {
+				received <- "resubscribed " + *msg.Payload.Name
+				return nil
+			})
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	suite.send("1234", "after")
+	suite.Require().Equal("resubscribed after", <-received)
+}
+
+func (suite *Suite) TestNoAutoUnsubscribe() {
+	app := suite.newApp()
+	defer app.Close(context.Background())
+
+	params := TenantEventsChannelParameters{TenantId: "1234"}
+	ctx, cancel := context.WithCancel(context.Background())
+	err := app.SubscribeToReceiveTenantEventsOperation(ctx, params,
+		func(_ context.Context, _ EventMessageFromTenantEventsChannel) error { return nil })
+	suite.Require().NoError(err)
+
+	// Without the option, the subscription should not be stopped
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	err = app.SubscribeToReceiveTenantEventsOperation(context.Background(), params,
+		func(_ context.Context, _ EventMessageFromTenantEventsChannel) error { return nil })
+	suite.Require().ErrorIs(err, extensions.ErrAlreadySubscribedChannel)
+}
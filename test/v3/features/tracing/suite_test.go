@@ -0,0 +1,137 @@
+//go:generate go run -C ../../../.. ./cmd/asyncapi-codegen -p tracing -i ./test/v3/features/tracing/asyncapi.yaml -o ./test/v3/features/tracing/asyncapi.gen.go
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers/inmemory"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/middlewares"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var errProcessing = errors.New("processing error")
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, NewSuite())
+}
+
+type Suite struct {
+	suite.Suite
+	recorder *tracetest.SpanRecorder
+	provider *sdktrace.TracerProvider
+	app      *AppController
+	user     *UserController
+}
+
+func NewSuite() *Suite {
+	return &Suite{}
+}
+
+func (suite *Suite) SetupTest() {
+	suite.recorder = tracetest.NewSpanRecorder()
+	suite.provider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(suite.recorder))
+	tracing := middlewares.Tracing(
+		middlewares.WithTracerProvider(suite.provider),
+		middlewares.WithMessagingSystem("inmemory"))
+
+	broker := inmemory.NewBroker()
+
+	appBroker, err := inmemory.NewController(inmemory.WithBroker(broker))
+	suite.Require().NoError(err)
+	suite.app, err = NewAppController(appBroker, WithMiddlewares(tracing))
+	suite.Require().NoError(err)
+
+	userBroker, err := inmemory.NewController(inmemory.WithBroker(broker))
+	suite.Require().NoError(err)
+	suite.user, err = NewUserController(userBroker, WithMiddlewares(tracing))
+	suite.Require().NoError(err)
+}
+
+func (suite *Suite) TearDownTest() {
+	suite.app.Close(context.Background())
+	suite.user.Close(context.Background())
+}
+
+func (suite *Suite) receive(ret error) trace.SpanContext {
+	received := make(chan trace.SpanContext, 1)
+	err := suite.app.SubscribeToReceiveTaskOperation(context.Background(), func(ctx context.Context, _ TaskMessage) error {
+		received <- trace.SpanContextFromContext(ctx)
+		return ret
+	})
+	suite.Require().NoError(err)
+
+	msg := NewTaskMessage()
+	msg.SetCorrelationID("1234")
+	suite.Require().NoError(suite.user.SendToReceiveTaskOperation(context.Background(), msg))
+
+	select {
+	case sc := <-received:
+		return sc
+	case <-time.After(time.Second):
+		suite.FailNow("no message received")
+		return trace.SpanContext{}
+	}
+}
+
+func (suite *Suite) endedSpans() (producer, consumer sdktrace.ReadOnlySpan) {
+	suite.Require().Eventually(func() bool {
+		return len(suite.recorder.Ended()) >= 2
+	}, time.Second, time.Millisecond)
+
+	// Get the first spans, as nak'd messages can be redelivered
+	for _, s := range suite.recorder.Ended() {
+		switch {
+		case s.SpanKind() == trace.SpanKindProducer && producer == nil:
+			producer = s
+		case s.SpanKind() == trace.SpanKindConsumer && consumer == nil:
+			consumer = s
+		}
+	}
+	suite.Require().NotNil(producer)
+	suite.Require().NotNil(consumer)
+	return producer, consumer
+}
+
+func (suite *Suite) TestParentChildSpans() {
+	sc := suite.receive(nil)
+	producer, consumer := suite.endedSpans()
+
+	// Subscription function should have the consumer span, child of producer span
+	suite.Require().Equal(consumer.SpanContext().SpanID(), sc.SpanID())
+	suite.Require().Equal(producer.SpanContext().TraceID(), consumer.SpanContext().TraceID())
+	suite.Require().Equal(producer.SpanContext().SpanID(), consumer.Parent().SpanID())
+	suite.Require().True(consumer.Parent().IsRemote())
+
+	// Check names and attributes
+	suite.Require().Equal(TaskChannelPath+" publish", producer.Name())
+	suite.Require().Equal(TaskChannelPath+" receive", consumer.Name())
+	suite.Require().Subset(producer.Attributes(), []attribute.KeyValue{
+		semconv.MessagingSystemKey.String("inmemory"),
+		semconv.MessagingDestinationName(TaskChannelPath),
+		semconv.MessagingOperationPublish,
+		semconv.MessagingMessageConversationID("1234"),
+	})
+	suite.Require().Subset(consumer.Attributes(), []attribute.KeyValue{
+		semconv.MessagingSystemKey.String("inmemory"),
+		semconv.MessagingDestinationName(TaskChannelPath),
+		semconv.MessagingOperationReceive,
+	})
+}
+
+func (suite *Suite) TestErrorStatus() {
+	suite.receive(errProcessing)
+	_, consumer := suite.endedSpans()
+
+	suite.Require().Equal(codes.Error, consumer.Status().Code)
+	suite.Require().Equal(errProcessing.Error(), consumer.Status().Description)
+}
@@ -0,0 +1,144 @@
+//go:generate go run ../../../../cmd/asyncapi-codegen -p validation -i ./asyncapi.yaml -o ./asyncapi.gen.go
+
+package validation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers/inmemory"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, NewSuite())
+}
+
+type Suite struct {
+	suite.Suite
+}
+
+func NewSuite() *Suite {
+	return &Suite{}
+}
+
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+func validOrder() OrderMessage {
+	return OrderMessage{
+		Headers: HeadersFromOrderMessage{Version: "v1"},
+		Payload: OrderSchema{
+			Reference: "ORD-0001",
+			Quantity:  10,
+			Priority:  Ptr[int64](2),
+			Express:   Ptr(false),
+			Lines:     []LineSchema{{Product: "apple", Price: 1.5}},
+			Tags:      []string{"fruit", "red"},
+		},
+	}
+}
+
+func (suite *Suite) TestValidate() {
+	suite.Require().NoError(validOrder().Validate())
+
+	cases := []struct {
+		name   string
+		modify func(msg *OrderMessage)
+		field  string
+	}{
+		{"const", func(msg *OrderMessage) { msg.Headers.Version = "v2" }, "headers.version"},
+		{"pattern", func(msg *OrderMessage) { msg.Payload.Reference = "ORD-1" }, "payload.reference"},
+		{"multipleOf", func(msg *OrderMessage) { msg.Payload.Quantity = 7 }, "payload.quantity"},
+		{"exclusiveMinimum", func(msg *OrderMessage) { msg.Payload.Quantity = 0 }, "payload.quantity"},
+		{"integer enum", func(msg *OrderMessage) { msg.Payload.Priority = Ptr[int64](4) }, "payload.priority"},
+		{"boolean const", func(msg *OrderMessage) { msg.Payload.Express = Ptr(true) }, "payload.express"},
+		{"required array", func(msg *OrderMessage) { msg.Payload.Lines = nil }, "payload.lines"},
+		{"minItems", func(msg *OrderMessage) { msg.Payload.Lines = []LineSchema{} }, "payload.lines"},
+		{"maxItems", func(msg *OrderMessage) {
+			msg.Payload.Lines = make([]LineSchema, 4)
+		}, "payload.lines"},
+		{"nested minimum", func(msg *OrderMessage) { msg.Payload.Lines[0].Price = 0.1 }, "payload.lines[0].price"},
+		{"uniqueItems", func(msg *OrderMessage) { msg.Payload.Tags = []string{"a1", "a1"} }, "payload.tags"},
+		{"items minLength", func(msg *OrderMessage) { msg.Payload.Tags = []string{"ok", "k"} }, "payload.tags[1]"},
+	}
+
+	for _, c := range cases {
+		suite.Run(c.name, func() {
+			msg := validOrder()
+			c.modify(&msg)
+
+			err := msg.Validate()
+			suite.Require().ErrorIs(err, extensions.ErrValidation)
+
+			var vErr *extensions.ValidationError
+			suite.Require().ErrorAs(err, &vErr)
+			suite.Require().Equal(c.field, vErr.Field)
+		})
+	}
+}
+
+func (suite *Suite) TestUnsupportedPattern() {
+	// The pattern can't be compiled in Go, so it is not checked
+	msg := validOrder()
+	msg.Payload.Coupon = Ptr("anything")
+	suite.Require().NoError(msg.Validate())
+}
+
+func (suite *Suite) TestWithValidation() {
+	broker := inmemory.NewBroker()
+	errs := make(chan error, 1)
+
+	appBroker, err := inmemory.NewController(inmemory.WithBroker(broker))
+	suite.Require().NoError(err)
+	app, err := NewAppController(
+		appBroker,
+		WithValidation(),
+		WithErrorHandler(func(_ context.Context, _ string, _ *extensions.AcknowledgeableBrokerMessage, err error) {
+			errs <- err
+		}))
+	suite.Require().NoError(err)
+	defer app.Close(context.Background())
+
+	// Invalid messages should be rejected when sent
+	invalid := validOrder()
+	invalid.Payload.Reference = "invalid"
+	suite.Require().ErrorIs(app.SendAsSendOrderOperation(context.Background(), invalid), extensions.ErrValidation)
+
+	// Invalid messages should be given to the error handler when received
+	received := make(chan OrderMessage, 1)
+	err = app.SubscribeToReceiveOrderOperation(context.Background(), func(_ context.Context, msg OrderMessage) error {
+		received <- msg
+		return nil
+	})
+	suite.Require().NoError(err)
+
+	// Publish it without validation
+	userBroker, err := inmemory.NewController(inmemory.WithBroker(broker))
+	suite.Require().NoError(err)
+	user, err := NewUserController(userBroker)
+	suite.Require().NoError(err)
+	defer user.Close(context.Background())
+	suite.Require().NoError(user.SendToReceiveOrderOperation(context.Background(), invalid))
+
+	select {
+	case err := <-errs:
+		suite.Require().ErrorIs(err, extensions.ErrValidation)
+	case <-received:
+		suite.FailNow("invalid message should not be given to the subscriber")
+	case <-time.After(time.Second):
+		suite.FailNow("no error received")
+	}
+
+	// Valid messages should go through
+	suite.Require().NoError(app.SendAsSendOrderOperation(context.Background(), validOrder()))
+	select {
+	case msg := <-received:
+		suite.Require().Equal(validOrder(), msg)
+	case <-time.After(time.Second):
+		suite.FailNow("no message received")
+	}
+}
@@ -0,0 +1,148 @@
+//go:generate go run ../../../../cmd/asyncapi-codegen -p wildcard -g application,user,types,mocks -i ./asyncapi.yaml -o ./asyncapi.gen.go
+
+package wildcard
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions"
+	"github.com/lerenn/asyncapi-codegen/pkg/extensions/brokers/inmemory"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, NewSuite())
+}
+
+type Suite struct {
+	suite.Suite
+	app  *AppController
+	user *UserController
+}
+
+func NewSuite() *Suite {
+	return &Suite{}
+}
+
+func (suite *Suite) SetupTest() {
+	broker := inmemory.NewBroker()
+
+	appBroker, err := inmemory.NewController(inmemory.WithBroker(broker))
+	suite.Require().NoError(err)
+	suite.app, err = NewAppController(appBroker)
+	suite.Require().NoError(err)
+
+	userBroker, err := inmemory.NewController(inmemory.WithBroker(broker))
+	suite.Require().NoError(err)
+	suite.user, err = NewUserController(userBroker)
+	suite.Require().NoError(err)
+}
+
+func (suite *Suite) TearDownTest() {
+	suite.app.Close(context.Background())
+	suite.user.Close(context.Background())
+}
+
+type received struct {
+	addr   string
+	params UserEventsChannelParameters
+	name   string
+}
+
+func (suite *Suite) TestAllParameters() {
+	recvChan := make(chan received, 2)
+	err := suite.app.SubscribeToReceiveUserEventsOperationAllParameters(context.Background(),
+		func(ctx context.Context, params UserEventsChannelParameters, msg EventMessage) error {
+			addr, _ := ctx.Value(extensions.ContextKeyIsChannel).(string)
+			recvChan <- received{addr: addr, params: params, name: *msg.Payload.Name}
+			return nil
+		})
+	suite.Require().NoError(err)
+
+	expected := []received{
+		{
+			addr:   "v3.features.wildcard.user.1234.events.signup",
+			params: UserEventsChannelParameters{UserId: "1234", EventType: "signup"},
+			name:   "first",
+		},
+		{
+			addr:   "v3.features.wildcard.user.5678.events.login",
+			params: UserEventsChannelParameters{UserId: "5678", EventType: "login"},
+			name:   "second",
+		},
+	}
+	for _, e := range expected {
+		msg := NewEventMessage()
+		msg.Payload.Name = &e.name
+		suite.Require().NoError(suite.user.SendToReceiveUserEventsOperation(context.Background(), e.params, msg))
+	}
+
+	for _, e := range expected {
+		select {
+		case r := <-recvChan:
+			suite.Require().Equal(e, r)
+		case <-time.After(time.Second):
+			suite.Require().FailNow("no message received")
+		}
+	}
+}
+
+func (suite *Suite) TestAlreadySubscribed() {
+	fn := func(_ context.Context, _ UserEventsChannelParameters, _ EventMessage) error { return nil }
+	suite.Require().NoError(suite.app.SubscribeToReceiveUserEventsOperationAllParameters(context.Background(), fn))
+
+	err := suite.app.SubscribeToReceiveUserEventsOperationAllParameters(context.Background(), fn)
+	suite.Require().ErrorIs(err, extensions.ErrAlreadySubscribedChannel)
+
+	// It should be possible to subscribe again after unsubscribing
+	suite.app.UnsubscribeFromReceiveUserEventsOperationAllParameters(context.Background())
+	suite.Require().NoError(suite.app.SubscribeToReceiveUserEventsOperationAllParameters(context.Background(), fn))
+}
+
+// brokerWithoutWildcard is a broker controller that doesn't support wildcard subscriptions.
+type brokerWithoutWildcard struct {
+	extensions.BrokerController
+}
+
+func (suite *Suite) TestNotSupported() {
+	bc, err := inmemory.NewController()
+	suite.Require().NoError(err)
+	app, err := NewAppController(brokerWithoutWildcard{bc})
+	suite.Require().NoError(err)
+	defer app.Close(context.Background())
+
+	err = app.SubscribeToReceiveUserEventsOperationAllParameters(context.Background(),
+		func(_ context.Context, _ UserEventsChannelParameters, _ EventMessage) error { return nil })
+	suite.Require().ErrorIs(err, extensions.ErrWildcardNotSupported)
+}
+
+func (suite *Suite) TestParametersFromAddress() {
+	params, err := NewUserEventsChannelParametersFromAddress("v3.features.wildcard.user.1234.events.signup")
+	suite.Require().NoError(err)
+	suite.Require().Equal(UserEventsChannelParameters{UserId: "1234", EventType: "signup"}, params)
+
+	_, err = NewUserEventsChannelParametersFromAddress("v3.features.wildcard.order.1234")
+	suite.Require().ErrorIs(err, extensions.ErrChannelAddressMismatch)
+}
+
+func (suite *Suite) TestFake() {
+	fake := NewFakeAppController()
+
+	var got UserEventsChannelParameters
+	err := fake.SubscribeToReceiveUserEventsOperationAllParameters(context.Background(),
+		func(_ context.Context, params UserEventsChannelParameters, _ EventMessage) error {
+			got = params
+			return nil
+		})
+	suite.Require().NoError(err)
+
+	params := UserEventsChannelParameters{UserId: "1234", EventType: "signup"}
+	suite.Require().NoError(fake.InjectReceiveUserEventsOperation(context.Background(), params, NewEventMessage()))
+	suite.Require().Equal(params, got)
+
+	fake.UnsubscribeFromReceiveUserEventsOperationAllParameters(context.Background())
+	err = fake.InjectReceiveUserEventsOperation(context.Background(), params, NewEventMessage())
+	suite.Require().ErrorIs(err, extensions.ErrNotSubscribedChannel)
+}